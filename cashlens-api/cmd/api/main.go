@@ -1,42 +1,57 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
+	"github.com/ashmitsharp/cashlens-api/internal/config"
 	"github.com/ashmitsharp/cashlens-api/internal/database"
 	"github.com/ashmitsharp/cashlens-api/internal/database/db"
 	"github.com/ashmitsharp/cashlens-api/internal/handlers"
 	"github.com/ashmitsharp/cashlens-api/internal/middleware"
 	"github.com/ashmitsharp/cashlens-api/internal/services"
+	"github.com/ashmitsharp/cashlens-api/internal/utils"
 )
 
 func main() {
-	// Load environment variables
+	// Load environment variables, then read and validate the configuration so missing
+	// required settings fail here rather than on first use
 	if err := godotenv.Load(); err != nil {
 		log.Println("Warning: .env file not found, using system environment variables")
 	}
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// Connect to database
 	pool, err := database.Connect()
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
-	defer pool.Close()
 
 	log.Println("✓ Connected to database successfully")
 
+	// CURSOR_SECRET signs pagination cursors; every instance must share the same value
+	utils.SetCursorSecret(cfg.CursorSecret)
+
 	// Create database queries instance
 	queries := db.New(pool)
 
 	// Initialize services
 	// Storage service for S3 operations
 	storageService, err := services.NewStorageService(
-		os.Getenv("S3_BUCKET"),      // e.g., "cashlens-uploads"
-		os.Getenv("S3_REGION"),      // e.g., "ap-south-1"
-		os.Getenv("AWS_ENDPOINT"),   // e.g., "http://localhost:4566" for LocalStack
+		cfg.S3Bucket,    // e.g., "cashlens-uploads"
+		cfg.S3Region,    // e.g., "ap-south-1"
+		cfg.AWSEndpoint, // e.g., "http://localhost:4566" for LocalStack
 	)
 	if err != nil {
 		log.Fatalf("Failed to initialize storage service: %v", err)
@@ -44,30 +59,191 @@ func main() {
 	log.Println("✓ Storage service initialized successfully")
 
 	// Parser service for CSV/XLSX/PDF parsing
-	parser := services.NewParser()
+	// (BANK_SCHEMAS_PATH adds bank schemas from a JSON/YAML file on top of the built-in ones)
+	parser, err := services.NewParserFromConfig(cfg.BankSchemasPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize parser: %v", err)
+	}
+	// PDF_SERVICE_URL points at the PDF microservice, with PDF_SERVICE_TIMEOUT per request
+	// (default 30s); leaving it empty or setting PDF_ENABLED=false turns PDF uploads off
+	pdfServiceURL := cfg.PDFServiceURL
+	if !cfg.PDFEnabled {
+		pdfServiceURL = ""
+	} else if pdfServiceURL == "" {
+		log.Println("Warning: PDF_SERVICE_URL not set, PDF uploads are disabled")
+	}
+	parser.SetPDFService(pdfServiceURL, cfg.PDFServiceTimeout)
+	// ZERO_AMOUNT_ROWS decides what happens to rows with zero debit and credit (declined or
+	// informational entries): skip them, warn in the upload summary (default) or keep them
+	if err := parser.SetZeroAmountRows(cfg.ZeroAmountRows); err != nil {
+		log.Fatalf("Invalid ZERO_AMOUNT_ROWS: %v", err)
+	}
+	// AMOUNT_GROUPING=indian|western|any warns in the upload summary about amounts whose
+	// comma grouping is malformed (e.g. "12,3456.00"), which hints at a corrupted cell
+	if err := parser.SetAmountGrouping(cfg.AmountGrouping); err != nil {
+		log.Fatalf("Invalid AMOUNT_GROUPING: %v", err)
+	}
+	// DUPLICATE_HEADERS=error (default) rejects files where a column the parser reads has a
+	// repeated header; first reads the first such column
+	if err := parser.SetDuplicateHeaders(cfg.DuplicateHeaders); err != nil {
+		log.Fatalf("Invalid DUPLICATE_HEADERS: %v", err)
+	}
+	// MONTH_NAMES (comma-separated, e.g. hindi) adds month-name locales to date parsing
+	if err := parser.SetMonthNameLocales(cfg.MonthNames); err != nil {
+		log.Fatalf("Invalid MONTH_NAMES: %v", err)
+	}
+	// STRIP_HEADER_PERIODS (default true) matches "Withdrawal Amt." against "Withdrawal Amt"
+	parser.SetStripHeaderPeriods(cfg.StripHeaderPeriods)
+	// STRICT_KOTAK_DETECTION (default true) requires the "Ref No." column, since Kotak's other
+	// headers are generic enough to match other banks
+	parser.SetStrictKotakDetection(cfg.StrictKotakDetection)
+	// BALANCE_TOLERANCE (default 1 rupee) also bounds the per-row running-balance check
+	parser.SetBalanceTolerance(cfg.BalanceTolerance)
 	log.Println("✓ Parser service initialized successfully")
 
 	// Categorizer service for transaction categorization
 	categorizer := services.NewCategorizer(queries)
+	if cfg.RegexCacheSize > 0 {
+		categorizer.SetRegexCacheSize(cfg.RegexCacheSize)
+	}
+	// Rules are loaded RULE_LOAD_BATCH_SIZE at a time (default 500); refreshes only fetch changed rules
+	if cfg.RuleLoadBatchSize > 0 {
+		categorizer.SetRuleBatchSize(cfg.RuleLoadBatchSize)
+	}
+	// Every RULE_FULL_RELOAD_INTERVAL (default 1h) the caches are reloaded from scratch, picking up
+	// rules committed behind the incremental watermark and hard-deleted rules
+	categorizer.SetFullReloadInterval(cfg.RuleFullReload)
+	// Tie-break weights for equal-priority rules (MATCH_WEIGHT_EXACT/REGEX/SUBSTRING/FUZZY, default 1)
+	categorizer.SetMatchWeights(services.MatchWeights{
+		Exact:     cfg.MatchWeightExact,
+		Regex:     cfg.MatchWeightRegex,
+		Substring: cfg.MatchWeightSubstring,
+		Fuzzy:     cfg.MatchWeightFuzzy,
+	})
+	// DESCRIPTION_PREFIXES (comma-separated words) replaces the channel prefixes trimmed before
+	// matching; unset keeps services.DefaultDescriptionPrefixes, "none" turns trimming off
+	if prefixes := cfg.DescriptionPrefixes; len(prefixes) > 0 {
+		if len(prefixes) == 1 && prefixes[0] == "none" {
+			prefixes = nil
+		}
+		categorizer.SetDescriptionPrefixes(prefixes)
+	}
+	// MERCHANT_DICTIONARY_FALLBACK=true categorizes transactions no rule matches using
+	// the built-in dictionary of common Indian merchants
+	if cfg.MerchantDictionaryFallback {
+		categorizer.SetMerchantDictionaryFallback(true)
+	}
+	// REPORT_MATCHED_KEYWORD=false leaves the matched keyword out of rule test and preview results
+	categorizer.SetReportMatchedKeyword(cfg.ReportMatchedKeyword)
+	// RULE_CACHE_BROADCAST=true shares user rule cache invalidations between API instances
+	// over Postgres LISTEN/NOTIFY (only needed when running more than one replica)
+	if cfg.RuleCacheBroadcast {
+		categorizer.SetBroadcastInvalidations(true)
+		go listenForRuleInvalidations(pool, categorizer)
+	}
 	log.Println("✓ Categorizer service initialized successfully")
 
-	// File validator service (ready for future integration)
-	_ = services.NewFileValidator(10 * 1024 * 1024) // 10MB max
+	// Warm the global rules cache so the first categorization request doesn't pay for it
+	// (set CATEGORIZER_WARMUP=false to skip)
+	if cfg.CategorizerWarmup {
+		if err := categorizer.LoadGlobalRules(context.Background()); err != nil {
+			log.Printf("Warning: failed to warm categorizer cache: %v", err)
+		} else {
+			log.Printf("✓ Categorizer cache warmed with %d global rules", categorizer.GlobalRuleCount())
+		}
+	}
+
+	// Stats service for cached per-user transaction statistics
+	statsService := services.NewStatsService(queries)
+	// STATS_CACHE_TTL bounds how stale another instance's cached stats can be (default 5m)
+	statsService.SetCacheTTL(cfg.StatsCacheTTL)
+	log.Println("✓ Stats service initialized successfully")
+
+	// Reparse service for correcting stored transactions after parser fixes
+	reparseService := services.NewReparseService(queries, parser)
+
+	// Digest service composing weekly/monthly summaries for a mailer to send
+	digestService := services.NewDigestService(queries)
+
+	// File validator enforcing the upload size limit
+	fileValidator := services.NewFileValidator(cfg.MaxUploadBytes)
 	log.Println("✓ File validator service initialized successfully")
 
 	// Initialize handlers
-	usersHandler := handlers.NewUsersHandler(pool) // UsersHandler uses pool directly
+	usersHandler := handlers.NewUsersHandler(queries)
+	preferencesHandler := handlers.NewPreferencesHandler(queries)
 	uploadHandler := handlers.NewUploadHandlerFull(storageService, parser, categorizer, queries)
 	transactionHandler := handlers.NewTransactionHandler(queries, categorizer)
 	rulesHandler := handlers.NewRulesHandler(queries, categorizer)
 	summaryHandler := handlers.NewSummaryHandler(queries)
+	adminHandler := handlers.NewAdminHandler(statsService)
+	healthHandler := handlers.NewHealthHandler(pool)
+
+	// User deletion removes database rows in one transaction, then the user's S3 files
+	usersHandler.SetTxBeginner(pool)
+	usersHandler.SetStorageService(storageService)
+	usersHandler.SetStatsService(statsService)
+	uploadHandler.SetStatsService(statsService)
+	// Duplicate detection; DEDUP_TOLERANCE_DAYS widens the date window (defaults to exact date match)
+	uploadHandler.SetDuplicateDetector(services.NewDuplicateDetector(cfg.DedupToleranceDays))
+	// STORE_RAW_DATA=false stores NULL raw_data to save space and avoid keeping original rows
+	uploadHandler.SetStoreRawData(cfg.StoreRawData)
+	// CATEGORY_MEMO=false categorizes every row instead of once per repeated description in an upload
+	uploadHandler.SetCategoryMemo(cfg.CategoryMemo)
+	// Reversal pairing is opt-in; REVERSAL_WINDOW_DAYS sets how far apart a debit and its reversal may be
+	if cfg.ReversalWindowDays > 0 {
+		uploadHandler.SetReversalDetector(services.NewReversalDetector(cfg.ReversalWindowDays))
+	}
+	// Upload reconciliation accepts differences up to BALANCE_TOLERANCE rupees (default 1)
+	uploadHandler.SetReconciler(services.NewBalanceReconciler(cfg.BalanceTolerance))
+	// Uploads over MAX_UPLOAD_BYTES (default 10MB) are rejected and deleted; the limit is sent with the presigned URL
+	uploadHandler.SetFileValidator(fileValidator)
+	transactionHandler.SetStatsService(statsService)
+	// Salary suggestions for credits of at least SALARY_MIN_AMOUNT (default 10000) over 3+ months
+	transactionHandler.AddRuleSuggester("salary", services.NewSalarySuggester(cfg.SalaryMinAmount, 3))
+	transactionHandler.AddRuleSuggester("cash", services.NewCashWithdrawalSuggester())
+	transactionHandler.AddRuleSuggester("income", services.NewIncomeSuggester())
+	// EMI suggestions for fixed monthly loan debits, categorized as EMI_CATEGORY (default "Loan EMI")
+	transactionHandler.AddRuleSuggester("emi", services.NewEMISuggester(cfg.EMICategory))
+	transactionHandler.SetRuleLearner(services.NewRuleLearner())
+	// Rules learned from corrections are clamped into the same user rule priority bounds
+	transactionHandler.SetPriorityBounds(int32(cfg.UserRulePriorityMin), int32(cfg.UserRulePriorityMax), cfg.UserRulePriorityPolicy)
+	transactionHandler.SetRecurringDetector(services.NewRecurringDetector(services.DefaultRecurringAmountTolerance, services.DefaultRecurringMinMonths))
+	// ALLOWED_CATEGORIES (comma-separated) restricts transaction and rule categories; empty allows any
+	if len(cfg.AllowedCategories) > 0 {
+		categoryWhitelist := services.NewCategoryWhitelist(cfg.AllowedCategories)
+		transactionHandler.SetCategoryWhitelist(categoryWhitelist)
+		rulesHandler.SetCategoryWhitelist(categoryWhitelist)
+	}
+	adminHandler.SetReparseService(reparseService)
+	adminHandler.SetDigestService(digestService)
+	rulesHandler.SetRulePreviewer(categorizer)
+	rulesHandler.SetRuleTester(categorizer)
+	// User rule priorities outside USER_RULE_PRIORITY_MIN..MAX (default 11..1000) are
+	// clamped, or rejected with USER_RULE_PRIORITY_POLICY=reject
+	rulesHandler.SetPriorityBounds(int32(cfg.UserRulePriorityMin), int32(cfg.UserRulePriorityMax), cfg.UserRulePriorityPolicy)
+	// MAX_PAGE_SIZE (default 100) caps the limit of list endpoints; MAX_PAGE_SIZE_TRANSACTIONS,
+	// MAX_PAGE_SIZE_RULES and MAX_PAGE_SIZE_UPLOADS override it per resource. Larger limits are clamped.
+	maxPageSize := pageSizeOr(cfg.MaxPageSize, handlers.DefaultMaxPageSize)
+	transactionHandler.SetMaxPageSize(pageSizeOr(cfg.MaxPageSizeTransactions, maxPageSize))
+	rulesHandler.SetMaxPageSize(pageSizeOr(cfg.MaxPageSizeRules, maxPageSize))
+	uploadHandler.SetMaxPageSize(pageSizeOr(cfg.MaxPageSizeUploads, maxPageSize))
+	// Readiness reports the PDF service only when PDF uploads are enabled (PDF parsing is optional)
+	healthHandler.SetPDFService(pdfServiceURL, cfg.PDFHealthTimeout)
+	// The database ping must answer within DB_HEALTH_TIMEOUT (default 2s) or readiness is 503
+	healthHandler.SetDatabaseTimeout(cfg.DBHealthTimeout)
 
 	app := fiber.New(fiber.Config{
 		AppName: "cashlens API v1.0",
+		// Errors returned by handlers get a JSON body with the request's X-Request-ID
+		ErrorHandler: middleware.ErrorHandler,
 	})
 
 	// Apply global middleware
 	app.Use(middleware.CORS())
+	// One JSON log line per request, tagged with the X-Request-ID it is assigned;
+	// JSON error bodies carry the same ID as request_id
+	app.Use(middleware.RequestLogger())
 
 	// Health check endpoint (public)
 	app.Get("/health", func(c fiber.Ctx) error {
@@ -76,10 +252,17 @@ func main() {
 			"service": "cashlens-api",
 		})
 	})
+	// Readiness check (public): database plus optional PDF service
+	app.Get("/health/ready", healthHandler.GetReadiness)
 
 	// API v1 routes
 	v1 := app.Group("/v1")
 
+	// Opt-in {success, data} envelope; off by default so existing clients keep bare objects
+	if cfg.ResponseEnvelope {
+		v1.Use(middleware.ResponseEnvelope())
+	}
+
 	// Public routes
 	v1.Get("/ping", func(c fiber.Ctx) error {
 		return c.JSON(fiber.Map{"message": "pong"})
@@ -89,6 +272,10 @@ func main() {
 	internal := v1.Group("/internal")
 	internal.Post("/users", usersHandler.CreateUser)
 	internal.Put("/users/:id", usersHandler.UpdateUser)
+	internal.Delete("/users/:clerk_id", middleware.WebhookAuth(), usersHandler.DeleteUser)
+	internal.Post("/stats/recompute", middleware.AdminAuth(), adminHandler.RecomputeAllStats)
+	internal.Post("/transactions/reparse", middleware.AdminAuth(), adminHandler.ReparseTransactions)
+	internal.Post("/digests", middleware.AdminAuth(), adminHandler.GenerateDigests)
 
 	// Protected routes (require authentication)
 	protected := v1.Group("", middleware.ClerkAuth())
@@ -102,36 +289,111 @@ func main() {
 		})
 	})
 
+	// Banks the user has imported from
+	protected.Get("/me/banks", transactionHandler.GetBanks)
+
 	// Get current user details
 	protected.Get("/user", usersHandler.GetUser)
+	protected.Get("/user/preferences", preferencesHandler.GetPreferences)
+	protected.Put("/user/preferences", preferencesHandler.UpdatePreferences)
 
 	// Upload routes
 	protected.Get("/upload/presigned-url", uploadHandler.GetPresignedURL)
+	protected.Get("/upload/download-url", uploadHandler.GetDownloadURL)
 	protected.Post("/upload/process", uploadHandler.ProcessUpload)
 	protected.Get("/upload/history", uploadHandler.GetUploadHistory)
+	protected.Get("/uploads/:id", uploadHandler.GetUploadDetail)
+	protected.Get("/uploads/:id/issues", uploadHandler.GetUploadIssues)
+	protected.Post("/uploads/:id/reconcile", uploadHandler.ReconcileUpload)
 
 	// Transaction routes
 	protected.Get("/transactions", transactionHandler.GetTransactions)
+	protected.Get("/transactions/count", transactionHandler.GetTransactionCount)
+	protected.Get("/transactions/search", transactionHandler.SearchTransactions)
 	protected.Get("/transactions/stats", transactionHandler.GetTransactionStats)
+	protected.Get("/transactions/issues", transactionHandler.GetTransactionIssues)
+	protected.Get("/transactions/recurring", transactionHandler.GetRecurringTransactions)
+	protected.Get("/transactions/suggestions/:kind", transactionHandler.GetRuleSuggestions)
 	protected.Put("/transactions/:id", transactionHandler.UpdateTransaction)
+	protected.Delete("/transactions/:id", transactionHandler.DeleteTransaction)
 	protected.Put("/transactions/bulk", transactionHandler.BulkUpdateTransactions)
+	protected.Post("/transactions/bulk-tag", transactionHandler.BulkTagTransactions)
+	protected.Post("/transactions/clear-categories", transactionHandler.ClearCategories)
+	protected.Post("/transactions/fix-signs", transactionHandler.FixSigns)
 
 	// Categorization rules routes
 	protected.Get("/rules", rulesHandler.GetUserRules)
 	protected.Get("/rules/global", rulesHandler.GetGlobalRules)
+	protected.Get("/rules/effective", rulesHandler.GetEffectiveRules)
 	protected.Get("/rules/stats", rulesHandler.GetRuleStats)
+	protected.Get("/rules/stats/by-match-type", rulesHandler.GetRuleStatsByMatchType)
 	protected.Get("/rules/search", rulesHandler.SearchRules)
 	protected.Post("/rules", rulesHandler.CreateUserRule)
+	protected.Post("/rules/impact", rulesHandler.PreviewRuleImpact)
+	protected.Post("/rules/test", rulesHandler.TestRule)
+	protected.Get("/rules/:id/impact", rulesHandler.PreviewRuleDeletion)
 	protected.Put("/rules/:id", rulesHandler.UpdateUserRule)
 	protected.Delete("/rules/:id", rulesHandler.DeleteUserRule)
 
 	// Summary routes (dashboard KPIs)
 	protected.Get("/summary", summaryHandler.GetSummary)
+	protected.Get("/summary/categories", summaryHandler.GetCategoryBreakdown)
+	protected.Get("/summary/trend/export", summaryHandler.ExportTrend)
 
 	log.Println("✓ All routes configured successfully")
 	log.Println("")
-	log.Println("🚀 cashlens API is running on :8080")
-	log.Println("   Health check: http://localhost:8080/health")
-	log.Println("   API base: http://localhost:8080/v1")
-	log.Fatal(app.Listen(":8080"))
+	addr := fmt.Sprintf(":%d", cfg.Port)
+	log.Printf("🚀 cashlens API is running on %s", addr)
+	log.Printf("   Health check: http://localhost%s/health", addr)
+	log.Printf("   API base: http://localhost%s/v1", addr)
+
+	// Serve until SIGINT/SIGTERM, then let in-flight requests (long /upload/process
+	// calls in particular) finish within SHUTDOWN_TIMEOUT before closing the pool
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- app.Listen(addr)
+	}()
+	select {
+	case err := <-serverErr:
+		pool.Close()
+		log.Fatalf("Server stopped: %v", err)
+	case <-ctx.Done():
+	}
+	stop() // A second signal stops the process immediately
+
+	log.Printf("Shutting down: draining connections (timeout %s)", cfg.ShutdownTimeout)
+	if err := app.ShutdownWithTimeout(cfg.ShutdownTimeout); err != nil {
+		log.Printf("Warning: server did not shut down cleanly: %v", err)
+	} else {
+		log.Println("✓ HTTP server stopped")
+	}
+	pool.Close()
+	log.Println("✓ Database pool closed, bye")
+}
+
+// listenForRuleInvalidations keeps a dedicated connection LISTENing for rule cache
+// invalidations from other instances, reconnecting after failures
+func listenForRuleInvalidations(pool *pgxpool.Pool, categorizer *services.Categorizer) {
+	ctx := context.Background()
+	for {
+		conn, err := pool.Acquire(ctx)
+		if err == nil {
+			// Take the connection out of the pool so it keeps its LISTEN
+			listener := conn.Hijack()
+			err = categorizer.ListenForInvalidations(ctx, listener)
+			listener.Close(ctx)
+		}
+		log.Printf("Warning: rule cache listener stopped: %v (retrying in 5s)", err)
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// pageSizeOr returns a configured page size when it is positive, or fallback
+func pageSizeOr(size, fallback int) int {
+	if size > 0 {
+		return size
+	}
+	return fallback
 }
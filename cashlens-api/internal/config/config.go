@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -22,29 +23,155 @@ type Config struct {
 	ClerkPublishableKey string
 	ClerkSecretKey      string
 
+	// Key signing opaque pagination cursors (shared by all instances)
+	CursorSecret string
+
 	// S3
 	S3Bucket    string
 	S3Region    string
 	AWSEndpoint string // For LocalStack in development
 
+	// Upload processing
+	DedupToleranceDays int     // Days apart two identical transactions are still duplicates (0 = exact date)
+	ReversalWindowDays int     // Days within which a matching credit reverses a debit (0 = disabled)
+	StoreRawData       bool    // Keep the original row in transactions.raw_data
+	CategoryMemo       bool    // Categorize each repeated description once per upload
+	SalaryMinAmount    float64 // Smallest recurring monthly credit suggested as Salary
+	EMICategory        string  // Category suggested for fixed monthly loan EMI debits
+	RegexCacheSize     int     // Compiled regex rule patterns kept before LRU eviction
+	BankSchemasPath    string  // Optional JSON/YAML file with extra bank schemas
+	BalanceTolerance   float64 // Rupees a balance check may be off by before it is reported (bank rounding)
+	ZeroAmountRows     string  // skip, warn or keep rows with zero debit and credit
+	AmountGrouping     string  // indian, western or any to warn on malformed comma grouping; off skips the check
+	DuplicateHeaders   string  // error or first when a column the bank schema reads has a repeated header
+	MaxUploadBytes     int64   // Largest statement file accepted; bigger uploads are deleted from S3
+
+	// Controlled category taxonomy; empty allows any category
+	AllowedCategories []string
+
+	// PDF microservice; readiness only reports it when a URL is set. PDFEnabled=false
+	// turns PDF uploads off, otherwise the URL is required in production.
+	PDFEnabled        bool
+	PDFServiceURL     string
+	PDFServiceTimeout time.Duration // Per parse request
+	PDFHealthTimeout  time.Duration
+
+	DBHealthTimeout time.Duration // Database ping allowed by /health/ready before it reports 503
+
+	StatsCacheTTL time.Duration // Cached per-user stats are recomputed once older than this (0 = until invalidated)
+
+	RuleLoadBatchSize  int           // Categorization rules fetched per query when (re)loading the cache
+	RuleFullReload     time.Duration // Interval between full rule cache reloads (refreshes in between are incremental)
+	RuleCacheBroadcast bool          // Share rule cache invalidations between instances via LISTEN/NOTIFY
+
+	// Channel prefix words trimmed from descriptions before matching (empty uses the
+	// built-in list; "none" turns trimming off)
+	DescriptionPrefixes []string
+
+	// Extra month-name locales recognized when parsing dates (e.g. hindi)
+	MonthNames []string
+
+	// Header detection
+	StripHeaderPeriods   bool // Ignore trailing periods when matching headers ("Withdrawal Amt.")
+	StrictKotakDetection bool // Require Kotak's "Ref No." column before detecting a file as Kotak
+
+	// Largest limit accepted by list endpoints; per-resource values of 0 use MaxPageSize
+	MaxPageSize             int
+	MaxPageSizeTransactions int
+	MaxPageSizeRules        int
+	MaxPageSizeUploads      int
+
+	// Categorize transactions no rule matches with the built-in merchant dictionary
+	MerchantDictionaryFallback bool
+
+	// Include the keyword or text that matched in categorization results
+	ReportMatchedKeyword bool
+
+	// User rule priority bounds; out-of-range priorities are clamped or rejected
+	UserRulePriorityMin    int
+	UserRulePriorityMax    int
+	UserRulePriorityPolicy string // clamp or reject
+
+	// Tie-break weights applied to match scores when rules share a priority
+	MatchWeightExact     float64
+	MatchWeightRegex     float64
+	MatchWeightSubstring float64
+	MatchWeightFuzzy     float64
+
 	// Feature Flags
 	EnableRateLimiting bool
+	CategorizerWarmup  bool // Load global rules at startup
+	ResponseEnvelope   bool // Wrap successful /v1 responses as {success, data}
 }
 
 func LoadFromEnv() (*Config, error) {
 	cfg := &Config{
-		Port:                getEnvInt("PORT", 8080),
-		Environment:         getEnv("ENVIRONMENT", "development"),
-		ShutdownTimeout:     getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
-		DatabaseURL:         getEnv("DATABASE_URL", ""),
-		DBMaxConnections:    getEnvInt("DB_MAX_CONNECTIONS", 25),
-		DBConnectionTimeout: getEnvDuration("DB_CONNECTION_TIMEOUT", 30*time.Second),
-		ClerkPublishableKey: getEnv("CLERK_PUBLISHABLE_KEY", ""),
-		ClerkSecretKey:      getEnv("CLERK_SECRET_KEY", ""),
-		S3Bucket:            getEnv("S3_BUCKET", ""),
-		S3Region:            getEnv("S3_REGION", "ap-south-1"),
-		AWSEndpoint:         getEnv("AWS_ENDPOINT", ""),
-		EnableRateLimiting:  getEnvBool("ENABLE_RATE_LIMITING", false),
+		Port:                 getEnvInt("PORT", 8080),
+		Environment:          getEnv("ENVIRONMENT", "development"),
+		ShutdownTimeout:      getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		DatabaseURL:          getEnv("DATABASE_URL", ""),
+		DBMaxConnections:     getEnvInt("DB_MAX_CONNECTIONS", 25),
+		DBConnectionTimeout:  getEnvDuration("DB_CONNECTION_TIMEOUT", 30*time.Second),
+		ClerkPublishableKey:  getEnv("CLERK_PUBLISHABLE_KEY", ""),
+		ClerkSecretKey:       getEnv("CLERK_SECRET_KEY", ""),
+		CursorSecret:         getEnv("CURSOR_SECRET", ""),
+		S3Bucket:             getEnv("S3_BUCKET", ""),
+		S3Region:             getEnv("S3_REGION", "ap-south-1"),
+		AWSEndpoint:          getEnv("AWS_ENDPOINT", ""),
+		DedupToleranceDays:   getEnvInt("DEDUP_TOLERANCE_DAYS", 0),
+		ReversalWindowDays:   getEnvInt("REVERSAL_WINDOW_DAYS", 0),
+		StoreRawData:         getEnvBool("STORE_RAW_DATA", true),
+		CategoryMemo:         getEnvBool("CATEGORY_MEMO", true),
+		SalaryMinAmount:      getEnvFloat("SALARY_MIN_AMOUNT", 10000),
+		EMICategory:          getEnv("EMI_CATEGORY", "Loan EMI"),
+		RegexCacheSize:       getEnvInt("REGEX_CACHE_SIZE", 512),
+		BankSchemasPath:      getEnv("BANK_SCHEMAS_PATH", ""),
+		BalanceTolerance:     getEnvFloat("BALANCE_TOLERANCE", 1),
+		ZeroAmountRows:       getEnv("ZERO_AMOUNT_ROWS", "warn"),
+		AmountGrouping:       getEnv("AMOUNT_GROUPING", "off"),
+		DuplicateHeaders:     getEnv("DUPLICATE_HEADERS", "error"),
+		MaxUploadBytes:       getEnvInt64("MAX_UPLOAD_BYTES", 10*1024*1024),
+		MatchWeightExact:     getEnvFloat("MATCH_WEIGHT_EXACT", 1),
+		MatchWeightRegex:     getEnvFloat("MATCH_WEIGHT_REGEX", 1),
+		MatchWeightSubstring: getEnvFloat("MATCH_WEIGHT_SUBSTRING", 1),
+		MatchWeightFuzzy:     getEnvFloat("MATCH_WEIGHT_FUZZY", 1),
+		EnableRateLimiting:   getEnvBool("ENABLE_RATE_LIMITING", false),
+		CategorizerWarmup:    getEnvBool("CATEGORIZER_WARMUP", true),
+		ResponseEnvelope:     getEnvBool("RESPONSE_ENVELOPE", false),
+
+		UserRulePriorityMin:    getEnvInt("USER_RULE_PRIORITY_MIN", 11),
+		UserRulePriorityMax:    getEnvInt("USER_RULE_PRIORITY_MAX", 1000),
+		UserRulePriorityPolicy: getEnv("USER_RULE_PRIORITY_POLICY", "clamp"),
+
+		PDFEnabled:        getEnvBool("PDF_ENABLED", true),
+		PDFServiceURL:     getEnv("PDF_SERVICE_URL", ""),
+		PDFServiceTimeout: getEnvDuration("PDF_SERVICE_TIMEOUT", 30*time.Second),
+		PDFHealthTimeout:  getEnvDuration("PDF_HEALTH_TIMEOUT", 2*time.Second),
+
+		DBHealthTimeout: getEnvDuration("DB_HEALTH_TIMEOUT", 2*time.Second),
+
+		StatsCacheTTL: getEnvDuration("STATS_CACHE_TTL", 5*time.Minute),
+
+		RuleLoadBatchSize:  getEnvInt("RULE_LOAD_BATCH_SIZE", 500),
+		RuleFullReload:     getEnvDuration("RULE_FULL_RELOAD_INTERVAL", time.Hour),
+		RuleCacheBroadcast: getEnvBool("RULE_CACHE_BROADCAST", false),
+
+		DescriptionPrefixes: getEnvList("DESCRIPTION_PREFIXES"),
+
+		MonthNames: getEnvList("MONTH_NAMES"),
+
+		StripHeaderPeriods:   getEnvBool("STRIP_HEADER_PERIODS", true),
+		StrictKotakDetection: getEnvBool("STRICT_KOTAK_DETECTION", true),
+
+		MaxPageSize:             getEnvInt("MAX_PAGE_SIZE", 100),
+		MaxPageSizeTransactions: getEnvInt("MAX_PAGE_SIZE_TRANSACTIONS", 0),
+		MaxPageSizeRules:        getEnvInt("MAX_PAGE_SIZE_RULES", 0),
+		MaxPageSizeUploads:      getEnvInt("MAX_PAGE_SIZE_UPLOADS", 0),
+
+		MerchantDictionaryFallback: getEnvBool("MERCHANT_DICTIONARY_FALLBACK", false),
+		ReportMatchedKeyword:       getEnvBool("REPORT_MATCHED_KEYWORD", true),
+
+		AllowedCategories: getEnvList("ALLOWED_CATEGORIES"),
 	}
 
 	// Validate required fields
@@ -57,6 +184,12 @@ func LoadFromEnv() (*Config, error) {
 	if cfg.S3Bucket == "" && cfg.Environment == "production" {
 		return nil, fmt.Errorf("S3_BUCKET is required in production")
 	}
+	if cfg.PDFEnabled && cfg.PDFServiceURL == "" && cfg.Environment == "production" {
+		return nil, fmt.Errorf("PDF_SERVICE_URL is required in production (set PDF_ENABLED=false to disable PDF uploads)")
+	}
+	if cfg.MaxUploadBytes <= 0 {
+		return nil, fmt.Errorf("MAX_UPLOAD_BYTES must be positive")
+	}
 
 	return cfg, nil
 }
@@ -68,6 +201,17 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvList splits a comma-separated value, dropping blank entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -77,6 +221,24 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
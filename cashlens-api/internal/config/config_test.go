@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFromEnv_MaxUploadBytes(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://localhost/cashlens_test")
+
+	cfg, err := LoadFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(10*1024*1024), cfg.MaxUploadBytes)
+
+	t.Setenv("MAX_UPLOAD_BYTES", "52428800")
+	cfg, err = LoadFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(50*1024*1024), cfg.MaxUploadBytes)
+
+	// Unparseable values fall back to the default
+	t.Setenv("MAX_UPLOAD_BYTES", "50MB")
+	cfg, err = LoadFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(10*1024*1024), cfg.MaxUploadBytes)
+
+	t.Setenv("MAX_UPLOAD_BYTES", "0")
+	_, err = LoadFromEnv()
+	assert.Error(t, err)
+}
@@ -51,9 +51,9 @@ func (q *Queries) CreateGlobalRule(ctx context.Context, arg CreateGlobalRulePara
 }
 
 const createUserRule = `-- name: CreateUserRule :one
-INSERT INTO user_categorization_rules (user_id, keyword, category, priority, match_type, similarity_threshold, is_active)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, user_id, keyword, category, priority, match_type, similarity_threshold, is_active, created_at, updated_at
+INSERT INTO user_categorization_rules (user_id, keyword, category, priority, match_type, similarity_threshold, is_active, min_amount, max_amount, tags, direction)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, user_id, keyword, category, priority, match_type, similarity_threshold, is_active, created_at, updated_at, min_amount, max_amount, tags, direction
 `
 
 type CreateUserRuleParams struct {
@@ -64,6 +64,10 @@ type CreateUserRuleParams struct {
 	MatchType           pgtype.Text    `json:"match_type"`
 	SimilarityThreshold pgtype.Numeric `json:"similarity_threshold"`
 	IsActive            pgtype.Bool    `json:"is_active"`
+	MinAmount           pgtype.Numeric `json:"min_amount"`
+	MaxAmount           pgtype.Numeric `json:"max_amount"`
+	Tags                []string       `json:"tags"`
+	Direction           pgtype.Text    `json:"direction"`
 }
 
 func (q *Queries) CreateUserRule(ctx context.Context, arg CreateUserRuleParams) (UserCategorizationRule, error) {
@@ -75,6 +79,10 @@ func (q *Queries) CreateUserRule(ctx context.Context, arg CreateUserRuleParams)
 		arg.MatchType,
 		arg.SimilarityThreshold,
 		arg.IsActive,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Tags,
+		arg.Direction,
 	)
 	var i UserCategorizationRule
 	err := row.Scan(
@@ -88,6 +96,10 @@ func (q *Queries) CreateUserRule(ctx context.Context, arg CreateUserRuleParams)
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.MinAmount,
+		&i.MaxAmount,
+		&i.Tags,
+		&i.Direction,
 	)
 	return i, err
 }
@@ -119,6 +131,20 @@ func (q *Queries) DeactivateUserRule(ctx context.Context, arg DeactivateUserRule
 	return err
 }
 
+const deleteAllUserRules = `-- name: DeleteAllUserRules :execrows
+DELETE FROM user_categorization_rules
+WHERE user_id = $1
+`
+
+// Delete every rule a user created (account deletion)
+func (q *Queries) DeleteAllUserRules(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteAllUserRules, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteGlobalRule = `-- name: DeleteGlobalRule :exec
 DELETE FROM global_categorization_rules
 WHERE id = $1
@@ -304,6 +330,51 @@ func (q *Queries) GetGlobalRuleByKeyword(ctx context.Context, keyword string) (G
 	return i, err
 }
 
+const getGlobalRulesChangedSince = `-- name: GetGlobalRulesChangedSince :many
+SELECT id, keyword, category, priority, match_type, similarity_threshold, is_active, created_at, updated_at FROM global_categorization_rules
+WHERE updated_at > $1 OR (updated_at = $1 AND id > $2)
+ORDER BY updated_at ASC, id ASC
+LIMIT $3
+`
+
+type GetGlobalRulesChangedSinceParams struct {
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	ID        pgtype.UUID        `json:"id"`
+	Limit     int32              `json:"limit"`
+}
+
+// Rules updated after the (updated_at, id) watermark, oldest first, for batched
+// incremental cache loads. Deactivated rules are included so caches can drop them.
+func (q *Queries) GetGlobalRulesChangedSince(ctx context.Context, arg GetGlobalRulesChangedSinceParams) ([]GlobalCategorizationRule, error) {
+	rows, err := q.db.Query(ctx, getGlobalRulesChangedSince, arg.UpdatedAt, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GlobalCategorizationRule{}
+	for rows.Next() {
+		var i GlobalCategorizationRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Keyword,
+			&i.Category,
+			&i.Priority,
+			&i.MatchType,
+			&i.SimilarityThreshold,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getRuleStats = `-- name: GetRuleStats :one
 SELECT
     (SELECT COUNT(*) FROM global_categorization_rules g WHERE g.is_active = TRUE) as global_rules_count,
@@ -332,7 +403,7 @@ func (q *Queries) GetRuleStats(ctx context.Context, userID pgtype.UUID) (GetRule
 }
 
 const getUserRuleByKeyword = `-- name: GetUserRuleByKeyword :one
-SELECT id, user_id, keyword, category, priority, match_type, similarity_threshold, is_active, created_at, updated_at FROM user_categorization_rules
+SELECT id, user_id, keyword, category, priority, match_type, similarity_threshold, is_active, created_at, updated_at, min_amount, max_amount, tags, direction FROM user_categorization_rules
 WHERE user_id = $1 AND keyword = $2 AND is_active = TRUE
 LIMIT 1
 `
@@ -356,12 +427,16 @@ func (q *Queries) GetUserRuleByKeyword(ctx context.Context, arg GetUserRuleByKey
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.MinAmount,
+		&i.MaxAmount,
+		&i.Tags,
+		&i.Direction,
 	)
 	return i, err
 }
 
 const getUserRules = `-- name: GetUserRules :many
-SELECT id, user_id, keyword, category, priority, match_type, similarity_threshold, is_active, created_at, updated_at FROM user_categorization_rules
+SELECT id, user_id, keyword, category, priority, match_type, similarity_threshold, is_active, created_at, updated_at, min_amount, max_amount, tags, direction FROM user_categorization_rules
 WHERE user_id = $1 AND is_active = TRUE
 ORDER BY priority DESC, keyword ASC
 `
@@ -386,6 +461,10 @@ func (q *Queries) GetUserRules(ctx context.Context, userID pgtype.UUID) ([]UserC
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.MinAmount,
+			&i.MaxAmount,
+			&i.Tags,
+			&i.Direction,
 		); err != nil {
 			return nil, err
 		}
@@ -397,6 +476,73 @@ func (q *Queries) GetUserRules(ctx context.Context, userID pgtype.UUID) ([]UserC
 	return items, nil
 }
 
+const getUserRulesChangedSince = `-- name: GetUserRulesChangedSince :many
+SELECT id, user_id, keyword, category, priority, match_type, similarity_threshold, is_active, created_at, updated_at, min_amount, max_amount, tags, direction FROM user_categorization_rules
+WHERE user_id = $1 AND (updated_at > $2 OR (updated_at = $2 AND id > $3))
+ORDER BY updated_at ASC, id ASC
+LIMIT $4
+`
+
+type GetUserRulesChangedSinceParams struct {
+	UserID    pgtype.UUID        `json:"user_id"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	ID        pgtype.UUID        `json:"id"`
+	Limit     int32              `json:"limit"`
+}
+
+// User rules updated after the (updated_at, id) watermark, oldest first, for batched
+// incremental cache loads. Deactivated rules are included so caches can drop them.
+func (q *Queries) GetUserRulesChangedSince(ctx context.Context, arg GetUserRulesChangedSinceParams) ([]UserCategorizationRule, error) {
+	rows, err := q.db.Query(ctx, getUserRulesChangedSince,
+		arg.UserID,
+		arg.UpdatedAt,
+		arg.ID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UserCategorizationRule{}
+	for rows.Next() {
+		var i UserCategorizationRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Keyword,
+			&i.Category,
+			&i.Priority,
+			&i.MatchType,
+			&i.SimilarityThreshold,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.MinAmount,
+			&i.MaxAmount,
+			&i.Tags,
+			&i.Direction,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const notifyRuleCacheInvalidation = `-- name: NotifyRuleCacheInvalidation :exec
+SELECT pg_notify('cashlens_rule_cache', $1::text)
+`
+
+// Tell every API instance LISTENing on cashlens_rule_cache to drop a cached rule set.
+// The payload is a user ID.
+func (q *Queries) NotifyRuleCacheInvalidation(ctx context.Context, payload string) error {
+	_, err := q.db.Exec(ctx, notifyRuleCacheInvalidation, payload)
+	return err
+}
+
 const searchRulesByKeyword = `-- name: SearchRulesByKeyword :many
 SELECT id, keyword, category, priority, match_type, similarity_threshold, is_active, created_at, updated_at FROM global_categorization_rules
 WHERE keyword ILIKE '%' || $1 || '%' AND is_active = TRUE
@@ -440,7 +586,7 @@ func (q *Queries) SearchRulesByKeyword(ctx context.Context, arg SearchRulesByKey
 }
 
 const searchUserRulesByKeyword = `-- name: SearchUserRulesByKeyword :many
-SELECT id, user_id, keyword, category, priority, match_type, similarity_threshold, is_active, created_at, updated_at FROM user_categorization_rules
+SELECT id, user_id, keyword, category, priority, match_type, similarity_threshold, is_active, created_at, updated_at, min_amount, max_amount, tags, direction FROM user_categorization_rules
 WHERE user_id = $1 AND keyword ILIKE '%' || $2 || '%' AND is_active = TRUE
 ORDER BY priority DESC, keyword ASC
 LIMIT $3
@@ -472,6 +618,10 @@ func (q *Queries) SearchUserRulesByKeyword(ctx context.Context, arg SearchUserRu
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.MinAmount,
+			&i.MaxAmount,
+			&i.Tags,
+			&i.Direction,
 		); err != nil {
 			return nil, err
 		}
@@ -525,9 +675,10 @@ func (q *Queries) UpdateGlobalRule(ctx context.Context, arg UpdateGlobalRulePara
 
 const updateUserRule = `-- name: UpdateUserRule :one
 UPDATE user_categorization_rules
-SET category = $2, priority = $3, match_type = $4, similarity_threshold = $5, is_active = $6, updated_at = NOW()
+SET category = $2, priority = $3, match_type = $4, similarity_threshold = $5, is_active = $6,
+    min_amount = $8, max_amount = $9, tags = $10, direction = $11, updated_at = NOW()
 WHERE id = $1 AND user_id = $7
-RETURNING id, user_id, keyword, category, priority, match_type, similarity_threshold, is_active, created_at, updated_at
+RETURNING id, user_id, keyword, category, priority, match_type, similarity_threshold, is_active, created_at, updated_at, min_amount, max_amount, tags, direction
 `
 
 type UpdateUserRuleParams struct {
@@ -538,6 +689,10 @@ type UpdateUserRuleParams struct {
 	SimilarityThreshold pgtype.Numeric `json:"similarity_threshold"`
 	IsActive            pgtype.Bool    `json:"is_active"`
 	UserID              pgtype.UUID    `json:"user_id"`
+	MinAmount           pgtype.Numeric `json:"min_amount"`
+	MaxAmount           pgtype.Numeric `json:"max_amount"`
+	Tags                []string       `json:"tags"`
+	Direction           pgtype.Text    `json:"direction"`
 }
 
 func (q *Queries) UpdateUserRule(ctx context.Context, arg UpdateUserRuleParams) (UserCategorizationRule, error) {
@@ -549,6 +704,10 @@ func (q *Queries) UpdateUserRule(ctx context.Context, arg UpdateUserRuleParams)
 		arg.SimilarityThreshold,
 		arg.IsActive,
 		arg.UserID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Tags,
+		arg.Direction,
 	)
 	var i UserCategorizationRule
 	err := row.Scan(
@@ -562,6 +721,10 @@ func (q *Queries) UpdateUserRule(ctx context.Context, arg UpdateUserRuleParams)
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.MinAmount,
+		&i.MaxAmount,
+		&i.Tags,
+		&i.Direction,
 	)
 	return i, err
 }
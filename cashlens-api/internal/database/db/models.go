@@ -56,6 +56,19 @@ func (ns NullUploadStatus) Value() (driver.Value, error) {
 	return string(ns.UploadStatus), nil
 }
 
+// Rule that categorized each imported transaction
+type CategorizationAudit struct {
+	ID            pgtype.UUID `json:"id"`
+	UserID        pgtype.UUID `json:"user_id"`
+	TransactionID pgtype.UUID `json:"transaction_id"`
+	RuleID        pgtype.UUID `json:"rule_id"`
+	RuleType      string      `json:"rule_type"`
+	// Match type of the winning rule: substring, regex, exact, fuzzy, any, all
+	MatchType string             `json:"match_type"`
+	Category  string             `json:"category"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 // Configurable rules for detecting duplicate transactions
 type DuplicateDetectionRule struct {
 	ID       pgtype.UUID `json:"id"`
@@ -97,6 +110,14 @@ type Transaction struct {
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 	UploadID  pgtype.UUID        `json:"upload_id"`
+	Source    pgtype.Text        `json:"source"`
+	Flags     []string           `json:"flags"`
+	// UPI/NEFT/IMPS/RTGS or cheque reference from the statement
+	ReferenceNo pgtype.Text `json:"reference_no"`
+	// Normalized 0-1 score of the rule match that set category (NULL when not auto-categorized)
+	CategoryScore pgtype.Float8 `json:"category_score"`
+	// Cross-cutting labels (e.g. reimbursable), independent of category
+	Tags []string `json:"tags"`
 }
 
 // Tracks all CSV file uploads with processing status and statistics
@@ -127,6 +148,17 @@ type UploadHistory struct {
 	UpdatedAt            pgtype.Timestamptz `json:"updated_at"`
 }
 
+// Summary and per-row warnings returned by each processed upload
+type UploadResult struct {
+	UploadID pgtype.UUID `json:"upload_id"`
+	Summary  []byte      `json:"summary"`
+	// Rows that were skipped or could not be saved, with the reason
+	Warnings  []string           `json:"warnings"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// Skipped rows, row errors, reconciliation warnings and flagged rows recorded during the import
+	Issues []byte `json:"issues"`
+}
+
 // User accounts synchronized from Clerk authentication
 type User struct {
 	ID pgtype.UUID `json:"id"`
@@ -152,6 +184,27 @@ type UserCategorizationRule struct {
 	IsActive            pgtype.Bool        `json:"is_active"`
 	CreatedAt           pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+	// Rule only matches transactions with an absolute amount at or above this (NULL = no minimum)
+	MinAmount pgtype.Numeric `json:"min_amount"`
+	// Rule only matches transactions with an absolute amount at or below this (NULL = no maximum)
+	MaxAmount pgtype.Numeric `json:"max_amount"`
+	// Tags added to matching transactions on import; a rule with tags and no category only tags
+	Tags []string `json:"tags"`
+	// Rule only matches credits or only debits (NULL = either)
+	Direction pgtype.Text `json:"direction"`
+}
+
+// Per-user currency, timezone and week start used when presenting summaries
+type UserPreference struct {
+	UserID pgtype.UUID `json:"user_id"`
+	// ISO 4217 code amounts are displayed in
+	Currency string `json:"currency"`
+	// IANA timezone used for dates relative to today
+	Timezone string `json:"timezone"`
+	// First day of the week when summaries group by week
+	WeekStart string             `json:"week_start"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
 type UserUploadStat struct {
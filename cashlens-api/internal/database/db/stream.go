@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Hand-written: sqlc only generates :many queries that collect every row into a
+// slice, which is too much memory for exporting a large account.
+
+// StreamAllTransactions runs GetAllTransactions and calls fn with each row as it
+// is read from the database cursor. An error from fn stops the stream and is returned.
+func (q *Queries) StreamAllTransactions(ctx context.Context, userID pgtype.UUID, fn func(Transaction) error) error {
+	rows, err := q.db.Query(ctx, getAllTransactions, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		// Scanning by column name keeps this in step with GetAllTransactions and the
+		// Transaction model when sqlc regenerates them
+		i, err := pgx.RowToStructByName[Transaction](rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
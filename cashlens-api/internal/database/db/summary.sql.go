@@ -13,22 +13,24 @@ import (
 
 const getCashFlowTrend = `-- name: GetCashFlowTrend :many
 SELECT
-    DATE_TRUNC($4::text, txn_date::timestamp)::timestamp AS period,
+    (DATE_TRUNC($4::text, txn_date::timestamp + make_interval(days => $5::int))
+        - make_interval(days => $5::int))::timestamp AS period,
     COALESCE(SUM(CASE WHEN txn_type = 'credit' THEN amount ELSE 0 END), 0) AS inflow,
     COALESCE(SUM(CASE WHEN txn_type = 'debit' THEN amount ELSE 0 END), 0) AS outflow,
     COALESCE(SUM(CASE WHEN txn_type = 'credit' THEN amount ELSE -amount END), 0) AS net_flow
 FROM transactions
 WHERE user_id = $1
   AND txn_date BETWEEN $2 AND $3
-GROUP BY DATE_TRUNC($4::text, txn_date::timestamp)
+GROUP BY 1
 ORDER BY period
 `
 
 type GetCashFlowTrendParams struct {
-	UserID    pgtype.UUID `json:"user_id"`
-	TxnDate   pgtype.Date `json:"txn_date"`
-	TxnDate_2 pgtype.Date `json:"txn_date_2"`
-	DateTrunc string      `json:"date_trunc"`
+	UserID     pgtype.UUID `json:"user_id"`
+	TxnDate    pgtype.Date `json:"txn_date"`
+	TxnDate_2  pgtype.Date `json:"txn_date_2"`
+	DateTrunc  string      `json:"date_trunc"`
+	WeekOffset int32       `json:"week_offset"`
 }
 
 type GetCashFlowTrendRow struct {
@@ -38,12 +40,15 @@ type GetCashFlowTrendRow struct {
 	NetFlow interface{}      `json:"net_flow"`
 }
 
+// DATE_TRUNC weeks start on Monday; week_offset shifts dates forward before
+// truncating and back after, so 1 starts weeks on Sunday, 2 on Saturday, and so on.
 func (q *Queries) GetCashFlowTrend(ctx context.Context, arg GetCashFlowTrendParams) ([]GetCashFlowTrendRow, error) {
 	rows, err := q.db.Query(ctx, getCashFlowTrend,
 		arg.UserID,
 		arg.TxnDate,
 		arg.TxnDate_2,
 		arg.DateTrunc,
+		arg.WeekOffset,
 	)
 	if err != nil {
 		return nil, err
@@ -68,6 +73,59 @@ func (q *Queries) GetCashFlowTrend(ctx context.Context, arg GetCashFlowTrendPara
 	return items, nil
 }
 
+const getCategoryBreakdown = `-- name: GetCategoryBreakdown :many
+SELECT
+    COALESCE(NULLIF(category, ''), 'Uncategorized')::text AS category,
+    COALESCE(SUM(CASE WHEN txn_type = 'debit' THEN ABS(amount) ELSE 0 END), 0) AS total_outflow,
+    COALESCE(SUM(CASE WHEN txn_type = 'credit' THEN ABS(amount) ELSE 0 END), 0) AS total_inflow,
+    COUNT(*) AS transaction_count
+FROM transactions
+WHERE user_id = $1
+  AND txn_date BETWEEN $2 AND $3
+GROUP BY COALESCE(NULLIF(category, ''), 'Uncategorized')
+ORDER BY total_outflow DESC, total_inflow DESC, category ASC
+`
+
+type GetCategoryBreakdownParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	TxnDate   pgtype.Date `json:"txn_date"`
+	TxnDate_2 pgtype.Date `json:"txn_date_2"`
+}
+
+type GetCategoryBreakdownRow struct {
+	Category         string      `json:"category"`
+	TotalOutflow     interface{} `json:"total_outflow"`
+	TotalInflow      interface{} `json:"total_inflow"`
+	TransactionCount int64       `json:"transaction_count"`
+}
+
+// Outflow and inflow per category over a date range, largest spend first.
+// NULL and empty categories are grouped as Uncategorized.
+func (q *Queries) GetCategoryBreakdown(ctx context.Context, arg GetCategoryBreakdownParams) ([]GetCategoryBreakdownRow, error) {
+	rows, err := q.db.Query(ctx, getCategoryBreakdown, arg.UserID, arg.TxnDate, arg.TxnDate_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetCategoryBreakdownRow{}
+	for rows.Next() {
+		var i GetCategoryBreakdownRow
+		if err := rows.Scan(
+			&i.Category,
+			&i.TotalOutflow,
+			&i.TotalInflow,
+			&i.TransactionCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getKPIs = `-- name: GetKPIs :one
 SELECT
     COALESCE(SUM(CASE WHEN txn_type = 'credit' THEN amount ELSE 0 END), 0) AS total_inflow,
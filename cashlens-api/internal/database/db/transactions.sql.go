@@ -11,6 +11,47 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addTransactionTags = `-- name: AddTransactionTags :execrows
+UPDATE transactions t
+SET tags = ARRAY(SELECT DISTINCT tag FROM unnest(t.tags || $6::text[]) AS tag ORDER BY tag),
+    updated_at = NOW()
+WHERE t.user_id = $1
+  AND t.description ILIKE '%' || $2::text || '%'
+  AND ($3::text = '' OR EXISTS (
+      SELECT 1 FROM upload_history u
+      WHERE u.id = t.upload_id AND UPPER(u.bank_type) = UPPER($3::text)
+  ))
+  AND t.txn_date BETWEEN $4 AND $5
+  AND NOT t.tags @> $6::text[]
+`
+
+type AddTransactionTagsParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	Column2   string      `json:"column_2"`
+	Column3   string      `json:"column_3"`
+	TxnDate   pgtype.Date `json:"txn_date"`
+	TxnDate_2 pgtype.Date `json:"txn_date_2"`
+	Column6   []string    `json:"column_6"`
+}
+
+// Adds tags to every transaction matching the filter. $2 is an escaped
+// description substring and an empty $3 matches every bank. Rows that already
+// carry all of the tags are left alone so the count reflects real changes.
+func (q *Queries) AddTransactionTags(ctx context.Context, arg AddTransactionTagsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addTransactionTags,
+		arg.UserID,
+		arg.Column2,
+		arg.Column3,
+		arg.TxnDate,
+		arg.TxnDate_2,
+		arg.Column6,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 type BulkCreateTransactionsParams struct {
 	UserID      pgtype.UUID    `json:"user_id"`
 	TxnDate     pgtype.Date    `json:"txn_date"`
@@ -22,6 +63,32 @@ type BulkCreateTransactionsParams struct {
 	RawData     pgtype.Text    `json:"raw_data"`
 }
 
+const clearTransactionCategories = `-- name: ClearTransactionCategories :execrows
+UPDATE transactions
+SET category = NULL,
+    category_score = NULL,
+    is_reviewed = FALSE,
+    updated_at = NOW()
+WHERE user_id = $1
+  AND category IS NOT NULL
+  AND ($2::boolean OR NOT is_reviewed)
+`
+
+type ClearTransactionCategoriesParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	Column2 bool        `json:"column_2"`
+}
+
+// Clears categories before a full recategorization. Reviewed transactions keep
+// theirs unless $2 is true, in which case they are un-reviewed as well.
+func (q *Queries) ClearTransactionCategories(ctx context.Context, arg ClearTransactionCategoriesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, clearTransactionCategories, arg.UserID, arg.Column2)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const countCategorizedTransactions = `-- name: CountCategorizedTransactions :one
 SELECT COUNT(*) FROM transactions
 WHERE user_id = $1
@@ -35,6 +102,43 @@ func (q *Queries) CountCategorizedTransactions(ctx context.Context, userID pgtyp
 	return count, err
 }
 
+const countFlaggedTransactions = `-- name: CountFlaggedTransactions :one
+SELECT COUNT(*) FROM transactions
+WHERE user_id = $1
+  AND cardinality(flags) > 0
+  AND ($2::text = '' OR $2::text = ANY(flags))
+`
+
+type CountFlaggedTransactionsParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	Column2 string      `json:"column_2"`
+}
+
+func (q *Queries) CountFlaggedTransactions(ctx context.Context, arg CountFlaggedTransactionsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countFlaggedTransactions, arg.UserID, arg.Column2)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchUserTransactions = `-- name: CountSearchUserTransactions :one
+SELECT COUNT(*) FROM transactions
+WHERE user_id = $1
+  AND description ILIKE '%' || $2::text || '%'
+`
+
+type CountSearchUserTransactionsParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	Column2 string      `json:"column_2"`
+}
+
+func (q *Queries) CountSearchUserTransactions(ctx context.Context, arg CountSearchUserTransactionsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchUserTransactions, arg.UserID, arg.Column2)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countUncategorizedTransactions = `-- name: CountUncategorizedTransactions :one
 SELECT COUNT(*) FROM transactions
 WHERE user_id = $1
@@ -60,6 +164,109 @@ func (q *Queries) CountUserTransactions(ctx context.Context, userID pgtype.UUID)
 	return count, err
 }
 
+const countUserTransactionsBySource = `-- name: CountUserTransactionsBySource :one
+SELECT COUNT(*) FROM transactions
+WHERE user_id = $1
+  AND source = $2
+  AND (
+    $3::text = 'all'
+    OR ($3::text = 'categorized' AND category IS NOT NULL)
+    OR ($3::text = 'uncategorized' AND category IS NULL)
+  )
+`
+
+type CountUserTransactionsBySourceParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	Source  pgtype.Text `json:"source"`
+	Column3 string      `json:"column_3"`
+}
+
+func (q *Queries) CountUserTransactionsBySource(ctx context.Context, arg CountUserTransactionsBySourceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUserTransactionsBySource, arg.UserID, arg.Source, arg.Column3)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserTransactionsFiltered = `-- name: CountUserTransactionsFiltered :one
+SELECT COUNT(*) FROM transactions
+WHERE user_id = $1
+  AND txn_date >= $2
+  AND txn_date <= $3
+  AND (
+    $4::text = 'all'
+    OR ($4::text = 'categorized' AND category IS NOT NULL)
+    OR ($4::text = 'uncategorized' AND category IS NULL)
+  )
+  AND ($5::text = '' OR source = $5::text)
+  AND ($6::text = '' OR category = $6::text)
+  AND (
+    ($7::float8 <= 0 AND $8::float8 >= 1)
+    OR category_score BETWEEN $7::float8 AND $8::float8
+  )
+`
+
+type CountUserTransactionsFilteredParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	TxnDate   pgtype.Date `json:"txn_date"`
+	TxnDate_2 pgtype.Date `json:"txn_date_2"`
+	Column4   string      `json:"column_4"`
+	Column5   string      `json:"column_5"`
+	Column6   string      `json:"column_6"`
+	Column7   float64     `json:"column_7"`
+	Column8   float64     `json:"column_8"`
+}
+
+// Dates are inclusive; status ($4) is one of: all, categorized, uncategorized.
+// An empty source ($5) or category ($6) matches any. Confidence bounds ($7, $8) are
+// inclusive; narrower than 0-1 they exclude rows without a category_score.
+func (q *Queries) CountUserTransactionsFiltered(ctx context.Context, arg CountUserTransactionsFilteredParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUserTransactionsFiltered,
+		arg.UserID,
+		arg.TxnDate,
+		arg.TxnDate_2,
+		arg.Column4,
+		arg.Column5,
+		arg.Column6,
+		arg.Column7,
+		arg.Column8,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserTransactionsInRange = `-- name: CountUserTransactionsInRange :one
+SELECT COUNT(*) FROM transactions
+WHERE user_id = $1
+  AND txn_date >= $2
+  AND txn_date <= $3
+  AND (
+    $4::text = 'all'
+    OR ($4::text = 'categorized' AND category IS NOT NULL)
+    OR ($4::text = 'uncategorized' AND category IS NULL)
+  )
+`
+
+type CountUserTransactionsInRangeParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	TxnDate   pgtype.Date `json:"txn_date"`
+	TxnDate_2 pgtype.Date `json:"txn_date_2"`
+	Column4   string      `json:"column_4"`
+}
+
+func (q *Queries) CountUserTransactionsInRange(ctx context.Context, arg CountUserTransactionsInRangeParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUserTransactionsInRange,
+		arg.UserID,
+		arg.TxnDate,
+		arg.TxnDate_2,
+		arg.Column4,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTransaction = `-- name: CreateTransaction :one
 INSERT INTO transactions (
     user_id,
@@ -69,22 +276,34 @@ INSERT INTO transactions (
     txn_type,
     category,
     is_reviewed,
-    raw_data
+    raw_data,
+    source,
+    flags,
+    reference_no,
+    category_score,
+    tags,
+    upload_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 )
-RETURNING id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id
+RETURNING id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id, source, flags, reference_no, category_score, tags
 `
 
 type CreateTransactionParams struct {
-	UserID      pgtype.UUID    `json:"user_id"`
-	TxnDate     pgtype.Date    `json:"txn_date"`
-	Description string         `json:"description"`
-	Amount      pgtype.Numeric `json:"amount"`
-	TxnType     string         `json:"txn_type"`
-	Category    pgtype.Text    `json:"category"`
-	IsReviewed  bool           `json:"is_reviewed"`
-	RawData     pgtype.Text    `json:"raw_data"`
+	UserID        pgtype.UUID    `json:"user_id"`
+	TxnDate       pgtype.Date    `json:"txn_date"`
+	Description   string         `json:"description"`
+	Amount        pgtype.Numeric `json:"amount"`
+	TxnType       string         `json:"txn_type"`
+	Category      pgtype.Text    `json:"category"`
+	IsReviewed    bool           `json:"is_reviewed"`
+	RawData       pgtype.Text    `json:"raw_data"`
+	Source        pgtype.Text    `json:"source"`
+	Flags         []string       `json:"flags"`
+	ReferenceNo   pgtype.Text    `json:"reference_no"`
+	CategoryScore pgtype.Float8  `json:"category_score"`
+	Tags          []string       `json:"tags"`
+	UploadID      pgtype.UUID    `json:"upload_id"`
 }
 
 func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error) {
@@ -97,6 +316,12 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		arg.Category,
 		arg.IsReviewed,
 		arg.RawData,
+		arg.Source,
+		arg.Flags,
+		arg.ReferenceNo,
+		arg.CategoryScore,
+		arg.Tags,
+		arg.UploadID,
 	)
 	var i Transaction
 	err := row.Scan(
@@ -112,6 +337,11 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.UploadID,
+		&i.Source,
+		&i.Flags,
+		&i.ReferenceNo,
+		&i.CategoryScore,
+		&i.Tags,
 	)
 	return i, err
 }
@@ -126,18 +356,75 @@ func (q *Queries) DeleteTransaction(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
-const deleteUserTransactions = `-- name: DeleteUserTransactions :exec
+const deleteUserTransactions = `-- name: DeleteUserTransactions :execrows
 DELETE FROM transactions
 WHERE user_id = $1
 `
 
-func (q *Queries) DeleteUserTransactions(ctx context.Context, userID pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, deleteUserTransactions, userID)
-	return err
+func (q *Queries) DeleteUserTransactions(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserTransactions, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const fixTransactionAmountSigns = `-- name: FixTransactionAmountSigns :execrows
+UPDATE transactions t
+SET amount = CASE WHEN t.txn_type = 'debit' THEN -ABS(t.amount) ELSE ABS(t.amount) END,
+    updated_at = NOW()
+WHERE t.user_id = $1
+  AND ($2::text = '' OR EXISTS (
+      SELECT 1 FROM upload_history u
+      WHERE u.id = t.upload_id AND UPPER(u.bank_type) = UPPER($2::text)
+  ))
+  AND ((t.txn_type = 'debit' AND t.amount > 0) OR (t.txn_type = 'credit' AND t.amount < 0))
+`
+
+type FixTransactionAmountSignsParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	Column2 string      `json:"column_2"`
+}
+
+// Makes amount signs agree with txn_type (debits negative, credits positive).
+// An empty $2 matches every bank; otherwise only uploads from that bank.
+func (q *Queries) FixTransactionAmountSigns(ctx context.Context, arg FixTransactionAmountSignsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, fixTransactionAmountSigns, arg.UserID, arg.Column2)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const fixTransactionTypes = `-- name: FixTransactionTypes :execrows
+UPDATE transactions t
+SET txn_type = CASE WHEN t.amount < 0 THEN 'debit' ELSE 'credit' END,
+    updated_at = NOW()
+WHERE t.user_id = $1
+  AND ($2::text = '' OR EXISTS (
+      SELECT 1 FROM upload_history u
+      WHERE u.id = t.upload_id AND UPPER(u.bank_type) = UPPER($2::text)
+  ))
+  AND ((t.amount < 0 AND t.txn_type <> 'debit') OR (t.amount > 0 AND t.txn_type <> 'credit'))
+`
+
+type FixTransactionTypesParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	Column2 string      `json:"column_2"`
+}
+
+// Makes txn_type agree with the amount sign; zero amounts are left alone.
+// An empty $2 matches every bank; otherwise only uploads from that bank.
+func (q *Queries) FixTransactionTypes(ctx context.Context, arg FixTransactionTypesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, fixTransactionTypes, arg.UserID, arg.Column2)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getAllTransactions = `-- name: GetAllTransactions :many
-SELECT id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id FROM transactions
+SELECT id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id, source, flags, reference_no, category_score, tags FROM transactions
 WHERE user_id = $1
 ORDER BY txn_date DESC
 `
@@ -164,6 +451,11 @@ func (q *Queries) GetAllTransactions(ctx context.Context, userID pgtype.UUID) ([
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.UploadID,
+			&i.Source,
+			&i.Flags,
+			&i.ReferenceNo,
+			&i.CategoryScore,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -177,8 +469,9 @@ func (q *Queries) GetAllTransactions(ctx context.Context, userID pgtype.UUID) ([
 
 const getCategorizedTransactions = `-- name: GetCategorizedTransactions :many
 SELECT
-    t.id, t.user_id, t.txn_date, t.description, t.amount, t.txn_type, t.category, t.is_reviewed, t.raw_data, t.created_at, t.updated_at, t.upload_id,
-    uh.bank_type
+    t.id, t.user_id, t.txn_date, t.description, t.amount, t.txn_type, t.category, t.is_reviewed, t.raw_data, t.created_at, t.updated_at, t.upload_id, t.source, t.flags, t.reference_no, t.category_score, t.tags,
+    uh.bank_type,
+    COUNT(*) OVER() AS total_count
 FROM transactions t
 LEFT JOIN upload_history uh ON t.upload_id = uh.id
 WHERE t.user_id = $1
@@ -194,19 +487,25 @@ type GetCategorizedTransactionsParams struct {
 }
 
 type GetCategorizedTransactionsRow struct {
-	ID          pgtype.UUID        `json:"id"`
-	UserID      pgtype.UUID        `json:"user_id"`
-	TxnDate     pgtype.Date        `json:"txn_date"`
-	Description string             `json:"description"`
-	Amount      pgtype.Numeric     `json:"amount"`
-	TxnType     string             `json:"txn_type"`
-	Category    pgtype.Text        `json:"category"`
-	IsReviewed  bool               `json:"is_reviewed"`
-	RawData     pgtype.Text        `json:"raw_data"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	UploadID    pgtype.UUID        `json:"upload_id"`
-	BankType    pgtype.Text        `json:"bank_type"`
+	ID            pgtype.UUID        `json:"id"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	TxnDate       pgtype.Date        `json:"txn_date"`
+	Description   string             `json:"description"`
+	Amount        pgtype.Numeric     `json:"amount"`
+	TxnType       string             `json:"txn_type"`
+	Category      pgtype.Text        `json:"category"`
+	IsReviewed    bool               `json:"is_reviewed"`
+	RawData       pgtype.Text        `json:"raw_data"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	UploadID      pgtype.UUID        `json:"upload_id"`
+	Source        pgtype.Text        `json:"source"`
+	Flags         []string           `json:"flags"`
+	ReferenceNo   pgtype.Text        `json:"reference_no"`
+	CategoryScore pgtype.Float8      `json:"category_score"`
+	Tags          []string           `json:"tags"`
+	BankType      pgtype.Text        `json:"bank_type"`
+	TotalCount    int64              `json:"total_count"`
 }
 
 func (q *Queries) GetCategorizedTransactions(ctx context.Context, arg GetCategorizedTransactionsParams) ([]GetCategorizedTransactionsRow, error) {
@@ -231,7 +530,73 @@ func (q *Queries) GetCategorizedTransactions(ctx context.Context, arg GetCategor
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.UploadID,
+			&i.Source,
+			&i.Flags,
+			&i.ReferenceNo,
+			&i.CategoryScore,
+			&i.Tags,
 			&i.BankType,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFlaggedTransactions = `-- name: GetFlaggedTransactions :many
+SELECT id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id, source, flags, reference_no, category_score, tags FROM transactions
+WHERE user_id = $1
+  AND cardinality(flags) > 0
+  AND ($2::text = '' OR $2::text = ANY(flags))
+ORDER BY txn_date DESC
+LIMIT $3 OFFSET $4
+`
+
+type GetFlaggedTransactionsParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	Column2 string      `json:"column_2"`
+	Limit   int32       `json:"limit"`
+	Offset  int32       `json:"offset"`
+}
+
+// Transactions flagged during import; flag filter ($2) is optional
+func (q *Queries) GetFlaggedTransactions(ctx context.Context, arg GetFlaggedTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, getFlaggedTransactions,
+		arg.UserID,
+		arg.Column2,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TxnDate,
+			&i.Description,
+			&i.Amount,
+			&i.TxnType,
+			&i.Category,
+			&i.IsReviewed,
+			&i.RawData,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UploadID,
+			&i.Source,
+			&i.Flags,
+			&i.ReferenceNo,
+			&i.CategoryScore,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -244,7 +609,7 @@ func (q *Queries) GetCategorizedTransactions(ctx context.Context, arg GetCategor
 }
 
 const getTransactionByID = `-- name: GetTransactionByID :one
-SELECT id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id FROM transactions
+SELECT id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id, source, flags, reference_no, category_score, tags FROM transactions
 WHERE id = $1
 LIMIT 1
 `
@@ -265,6 +630,11 @@ func (q *Queries) GetTransactionByID(ctx context.Context, id pgtype.UUID) (Trans
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.UploadID,
+		&i.Source,
+		&i.Flags,
+		&i.ReferenceNo,
+		&i.CategoryScore,
+		&i.Tags,
 	)
 	return i, err
 }
@@ -303,7 +673,7 @@ func (q *Queries) GetTransactionStats(ctx context.Context, userID pgtype.UUID) (
 }
 
 const getTransactionsByCategory = `-- name: GetTransactionsByCategory :many
-SELECT id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id FROM transactions
+SELECT id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id, source, flags, reference_no, category_score, tags FROM transactions
 WHERE user_id = $1
   AND category = $2
 ORDER BY txn_date DESC
@@ -344,6 +714,11 @@ func (q *Queries) GetTransactionsByCategory(ctx context.Context, arg GetTransact
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.UploadID,
+			&i.Source,
+			&i.Flags,
+			&i.ReferenceNo,
+			&i.CategoryScore,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -356,7 +731,7 @@ func (q *Queries) GetTransactionsByCategory(ctx context.Context, arg GetTransact
 }
 
 const getTransactionsByDateRange = `-- name: GetTransactionsByDateRange :many
-SELECT id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id FROM transactions
+SELECT id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id, source, flags, reference_no, category_score, tags FROM transactions
 WHERE user_id = $1
   AND txn_date BETWEEN $2 AND $3
 ORDER BY txn_date DESC
@@ -390,6 +765,11 @@ func (q *Queries) GetTransactionsByDateRange(ctx context.Context, arg GetTransac
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.UploadID,
+			&i.Source,
+			&i.Flags,
+			&i.ReferenceNo,
+			&i.CategoryScore,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -403,8 +783,9 @@ func (q *Queries) GetTransactionsByDateRange(ctx context.Context, arg GetTransac
 
 const getUncategorizedTransactions = `-- name: GetUncategorizedTransactions :many
 SELECT
-    t.id, t.user_id, t.txn_date, t.description, t.amount, t.txn_type, t.category, t.is_reviewed, t.raw_data, t.created_at, t.updated_at, t.upload_id,
-    uh.bank_type
+    t.id, t.user_id, t.txn_date, t.description, t.amount, t.txn_type, t.category, t.is_reviewed, t.raw_data, t.created_at, t.updated_at, t.upload_id, t.source, t.flags, t.reference_no, t.category_score, t.tags,
+    uh.bank_type,
+    COUNT(*) OVER() AS total_count
 FROM transactions t
 LEFT JOIN upload_history uh ON t.upload_id = uh.id
 WHERE t.user_id = $1
@@ -420,19 +801,25 @@ type GetUncategorizedTransactionsParams struct {
 }
 
 type GetUncategorizedTransactionsRow struct {
-	ID          pgtype.UUID        `json:"id"`
-	UserID      pgtype.UUID        `json:"user_id"`
-	TxnDate     pgtype.Date        `json:"txn_date"`
-	Description string             `json:"description"`
-	Amount      pgtype.Numeric     `json:"amount"`
-	TxnType     string             `json:"txn_type"`
-	Category    pgtype.Text        `json:"category"`
-	IsReviewed  bool               `json:"is_reviewed"`
-	RawData     pgtype.Text        `json:"raw_data"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	UploadID    pgtype.UUID        `json:"upload_id"`
-	BankType    pgtype.Text        `json:"bank_type"`
+	ID            pgtype.UUID        `json:"id"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	TxnDate       pgtype.Date        `json:"txn_date"`
+	Description   string             `json:"description"`
+	Amount        pgtype.Numeric     `json:"amount"`
+	TxnType       string             `json:"txn_type"`
+	Category      pgtype.Text        `json:"category"`
+	IsReviewed    bool               `json:"is_reviewed"`
+	RawData       pgtype.Text        `json:"raw_data"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	UploadID      pgtype.UUID        `json:"upload_id"`
+	Source        pgtype.Text        `json:"source"`
+	Flags         []string           `json:"flags"`
+	ReferenceNo   pgtype.Text        `json:"reference_no"`
+	CategoryScore pgtype.Float8      `json:"category_score"`
+	Tags          []string           `json:"tags"`
+	BankType      pgtype.Text        `json:"bank_type"`
+	TotalCount    int64              `json:"total_count"`
 }
 
 func (q *Queries) GetUncategorizedTransactions(ctx context.Context, arg GetUncategorizedTransactionsParams) ([]GetUncategorizedTransactionsRow, error) {
@@ -457,7 +844,60 @@ func (q *Queries) GetUncategorizedTransactions(ctx context.Context, arg GetUncat
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.UploadID,
+			&i.Source,
+			&i.Flags,
+			&i.ReferenceNo,
+			&i.CategoryScore,
+			&i.Tags,
 			&i.BankType,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserBanks = `-- name: GetUserBanks :many
+SELECT
+    COALESCE(uh.bank_type, 'UNKNOWN')::text AS bank,
+    COUNT(*) AS transaction_count,
+    MIN(t.txn_date)::date AS first_txn_date,
+    MAX(t.txn_date)::date AS last_txn_date
+FROM transactions t
+LEFT JOIN upload_history uh ON t.upload_id = uh.id
+WHERE t.user_id = $1
+GROUP BY COALESCE(uh.bank_type, 'UNKNOWN')
+ORDER BY transaction_count DESC, bank ASC
+`
+
+type GetUserBanksRow struct {
+	Bank             string      `json:"bank"`
+	TransactionCount int64       `json:"transaction_count"`
+	FirstTxnDate     pgtype.Date `json:"first_txn_date"`
+	LastTxnDate      pgtype.Date `json:"last_txn_date"`
+}
+
+// Banks the user's transactions were imported from, via the upload they came from.
+// Manual entries and uploads whose bank wasn't detected are grouped as UNKNOWN.
+func (q *Queries) GetUserBanks(ctx context.Context, userID pgtype.UUID) ([]GetUserBanksRow, error) {
+	rows, err := q.db.Query(ctx, getUserBanks, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetUserBanksRow{}
+	for rows.Next() {
+		var i GetUserBanksRow
+		if err := rows.Scan(
+			&i.Bank,
+			&i.TransactionCount,
+			&i.FirstTxnDate,
+			&i.LastTxnDate,
 		); err != nil {
 			return nil, err
 		}
@@ -471,8 +911,9 @@ func (q *Queries) GetUncategorizedTransactions(ctx context.Context, arg GetUncat
 
 const getUserTransactions = `-- name: GetUserTransactions :many
 SELECT
-    t.id, t.user_id, t.txn_date, t.description, t.amount, t.txn_type, t.category, t.is_reviewed, t.raw_data, t.created_at, t.updated_at, t.upload_id,
-    uh.bank_type
+    t.id, t.user_id, t.txn_date, t.description, t.amount, t.txn_type, t.category, t.is_reviewed, t.raw_data, t.created_at, t.updated_at, t.upload_id, t.source, t.flags, t.reference_no, t.category_score, t.tags,
+    uh.bank_type,
+    COUNT(*) OVER() AS total_count
 FROM transactions t
 LEFT JOIN upload_history uh ON t.upload_id = uh.id
 WHERE t.user_id = $1
@@ -487,19 +928,25 @@ type GetUserTransactionsParams struct {
 }
 
 type GetUserTransactionsRow struct {
-	ID          pgtype.UUID        `json:"id"`
-	UserID      pgtype.UUID        `json:"user_id"`
-	TxnDate     pgtype.Date        `json:"txn_date"`
-	Description string             `json:"description"`
-	Amount      pgtype.Numeric     `json:"amount"`
-	TxnType     string             `json:"txn_type"`
-	Category    pgtype.Text        `json:"category"`
-	IsReviewed  bool               `json:"is_reviewed"`
-	RawData     pgtype.Text        `json:"raw_data"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	UploadID    pgtype.UUID        `json:"upload_id"`
-	BankType    pgtype.Text        `json:"bank_type"`
+	ID            pgtype.UUID        `json:"id"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	TxnDate       pgtype.Date        `json:"txn_date"`
+	Description   string             `json:"description"`
+	Amount        pgtype.Numeric     `json:"amount"`
+	TxnType       string             `json:"txn_type"`
+	Category      pgtype.Text        `json:"category"`
+	IsReviewed    bool               `json:"is_reviewed"`
+	RawData       pgtype.Text        `json:"raw_data"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	UploadID      pgtype.UUID        `json:"upload_id"`
+	Source        pgtype.Text        `json:"source"`
+	Flags         []string           `json:"flags"`
+	ReferenceNo   pgtype.Text        `json:"reference_no"`
+	CategoryScore pgtype.Float8      `json:"category_score"`
+	Tags          []string           `json:"tags"`
+	BankType      pgtype.Text        `json:"bank_type"`
+	TotalCount    int64              `json:"total_count"`
 }
 
 func (q *Queries) GetUserTransactions(ctx context.Context, arg GetUserTransactionsParams) ([]GetUserTransactionsRow, error) {
@@ -524,7 +971,395 @@ func (q *Queries) GetUserTransactions(ctx context.Context, arg GetUserTransactio
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.UploadID,
+			&i.Source,
+			&i.Flags,
+			&i.ReferenceNo,
+			&i.CategoryScore,
+			&i.Tags,
+			&i.BankType,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserTransactionsBySource = `-- name: GetUserTransactionsBySource :many
+SELECT
+    t.id, t.user_id, t.txn_date, t.description, t.amount, t.txn_type, t.category, t.is_reviewed, t.raw_data, t.created_at, t.updated_at, t.upload_id, t.source, t.flags, t.reference_no, t.category_score, t.tags,
+    uh.bank_type,
+    COUNT(*) OVER() AS total_count
+FROM transactions t
+LEFT JOIN upload_history uh ON t.upload_id = uh.id
+WHERE t.user_id = $1
+  AND t.source = $2
+  AND (
+    $3::text = 'all'
+    OR ($3::text = 'categorized' AND t.category IS NOT NULL)
+    OR ($3::text = 'uncategorized' AND t.category IS NULL)
+  )
+ORDER BY t.txn_date DESC
+LIMIT $4 OFFSET $5
+`
+
+type GetUserTransactionsBySourceParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	Source  pgtype.Text `json:"source"`
+	Column3 string      `json:"column_3"`
+	Limit   int32       `json:"limit"`
+	Offset  int32       `json:"offset"`
+}
+
+type GetUserTransactionsBySourceRow struct {
+	ID            pgtype.UUID        `json:"id"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	TxnDate       pgtype.Date        `json:"txn_date"`
+	Description   string             `json:"description"`
+	Amount        pgtype.Numeric     `json:"amount"`
+	TxnType       string             `json:"txn_type"`
+	Category      pgtype.Text        `json:"category"`
+	IsReviewed    bool               `json:"is_reviewed"`
+	RawData       pgtype.Text        `json:"raw_data"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	UploadID      pgtype.UUID        `json:"upload_id"`
+	Source        pgtype.Text        `json:"source"`
+	Flags         []string           `json:"flags"`
+	ReferenceNo   pgtype.Text        `json:"reference_no"`
+	CategoryScore pgtype.Float8      `json:"category_score"`
+	Tags          []string           `json:"tags"`
+	BankType      pgtype.Text        `json:"bank_type"`
+	TotalCount    int64              `json:"total_count"`
+}
+
+// Status filter ($3) is one of: all, categorized, uncategorized
+func (q *Queries) GetUserTransactionsBySource(ctx context.Context, arg GetUserTransactionsBySourceParams) ([]GetUserTransactionsBySourceRow, error) {
+	rows, err := q.db.Query(ctx, getUserTransactionsBySource,
+		arg.UserID,
+		arg.Source,
+		arg.Column3,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetUserTransactionsBySourceRow{}
+	for rows.Next() {
+		var i GetUserTransactionsBySourceRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TxnDate,
+			&i.Description,
+			&i.Amount,
+			&i.TxnType,
+			&i.Category,
+			&i.IsReviewed,
+			&i.RawData,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UploadID,
+			&i.Source,
+			&i.Flags,
+			&i.ReferenceNo,
+			&i.CategoryScore,
+			&i.Tags,
+			&i.BankType,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserTransactionsFiltered = `-- name: GetUserTransactionsFiltered :many
+SELECT
+    t.id, t.user_id, t.txn_date, t.description, t.amount, t.txn_type, t.category, t.is_reviewed, t.raw_data, t.created_at, t.updated_at, t.upload_id, t.source, t.flags, t.reference_no, t.category_score, t.tags,
+    uh.bank_type,
+    COUNT(*) OVER() AS total_count
+FROM transactions t
+LEFT JOIN upload_history uh ON t.upload_id = uh.id
+WHERE t.user_id = $1
+  AND t.txn_date >= $2
+  AND t.txn_date <= $3
+  AND (
+    $4::text = 'all'
+    OR ($4::text = 'categorized' AND t.category IS NOT NULL)
+    OR ($4::text = 'uncategorized' AND t.category IS NULL)
+  )
+  AND ($5::text = '' OR t.source = $5::text)
+  AND ($6::text = '' OR t.category = $6::text)
+  AND (
+    ($7::float8 <= 0 AND $8::float8 >= 1)
+    OR t.category_score BETWEEN $7::float8 AND $8::float8
+  )
+ORDER BY t.txn_date DESC
+LIMIT $9 OFFSET $10
+`
+
+type GetUserTransactionsFilteredParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	TxnDate   pgtype.Date `json:"txn_date"`
+	TxnDate_2 pgtype.Date `json:"txn_date_2"`
+	Column4   string      `json:"column_4"`
+	Column5   string      `json:"column_5"`
+	Column6   string      `json:"column_6"`
+	Column7   float64     `json:"column_7"`
+	Column8   float64     `json:"column_8"`
+	Limit     int32       `json:"limit"`
+	Offset    int32       `json:"offset"`
+}
+
+type GetUserTransactionsFilteredRow struct {
+	ID            pgtype.UUID        `json:"id"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	TxnDate       pgtype.Date        `json:"txn_date"`
+	Description   string             `json:"description"`
+	Amount        pgtype.Numeric     `json:"amount"`
+	TxnType       string             `json:"txn_type"`
+	Category      pgtype.Text        `json:"category"`
+	IsReviewed    bool               `json:"is_reviewed"`
+	RawData       pgtype.Text        `json:"raw_data"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	UploadID      pgtype.UUID        `json:"upload_id"`
+	Source        pgtype.Text        `json:"source"`
+	Flags         []string           `json:"flags"`
+	ReferenceNo   pgtype.Text        `json:"reference_no"`
+	CategoryScore pgtype.Float8      `json:"category_score"`
+	Tags          []string           `json:"tags"`
+	BankType      pgtype.Text        `json:"bank_type"`
+	TotalCount    int64              `json:"total_count"`
+}
+
+// Dates are inclusive; status ($4) is one of: all, categorized, uncategorized.
+// An empty source ($5) or category ($6) matches any. Confidence bounds ($7, $8) are
+// inclusive; narrower than 0-1 they exclude rows without a category_score.
+func (q *Queries) GetUserTransactionsFiltered(ctx context.Context, arg GetUserTransactionsFilteredParams) ([]GetUserTransactionsFilteredRow, error) {
+	rows, err := q.db.Query(ctx, getUserTransactionsFiltered,
+		arg.UserID,
+		arg.TxnDate,
+		arg.TxnDate_2,
+		arg.Column4,
+		arg.Column5,
+		arg.Column6,
+		arg.Column7,
+		arg.Column8,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetUserTransactionsFilteredRow{}
+	for rows.Next() {
+		var i GetUserTransactionsFilteredRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TxnDate,
+			&i.Description,
+			&i.Amount,
+			&i.TxnType,
+			&i.Category,
+			&i.IsReviewed,
+			&i.RawData,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UploadID,
+			&i.Source,
+			&i.Flags,
+			&i.ReferenceNo,
+			&i.CategoryScore,
+			&i.Tags,
+			&i.BankType,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsForReparse = `-- name: ListTransactionsForReparse :many
+SELECT t.id, t.user_id, t.txn_date, t.description, t.amount, t.txn_type, t.category, t.is_reviewed, t.raw_data, t.created_at, t.updated_at, t.upload_id, t.source, t.flags, t.reference_no, t.category_score, t.tags FROM transactions t
+LEFT JOIN upload_history uh ON t.upload_id = uh.id
+WHERE t.id > $1
+  AND t.raw_data IS NOT NULL
+  AND ($2::text = '' OR uh.bank_type = $2::text)
+  AND ($3::date IS NULL OR t.txn_date >= $3::date)
+  AND ($4::date IS NULL OR t.txn_date <= $4::date)
+ORDER BY t.id
+LIMIT $5
+`
+
+type ListTransactionsForReparseParams struct {
+	ID      pgtype.UUID `json:"id"`
+	Column2 string      `json:"column_2"`
+	Column3 pgtype.Date `json:"column_3"`
+	Column4 pgtype.Date `json:"column_4"`
+	Limit   int32       `json:"limit"`
+}
+
+type ListTransactionsForReparseRow struct {
+	ID            pgtype.UUID        `json:"id"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	TxnDate       pgtype.Date        `json:"txn_date"`
+	Description   string             `json:"description"`
+	Amount        pgtype.Numeric     `json:"amount"`
+	TxnType       string             `json:"txn_type"`
+	Category      pgtype.Text        `json:"category"`
+	IsReviewed    bool               `json:"is_reviewed"`
+	RawData       pgtype.Text        `json:"raw_data"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	UploadID      pgtype.UUID        `json:"upload_id"`
+	Source        pgtype.Text        `json:"source"`
+	Flags         []string           `json:"flags"`
+	ReferenceNo   pgtype.Text        `json:"reference_no"`
+	CategoryScore pgtype.Float8      `json:"category_score"`
+	Tags          []string           `json:"tags"`
+}
+
+// Keyset-paginated by id so reparse runs can resume; bank ($2) and date bounds ($3, $4) are optional
+func (q *Queries) ListTransactionsForReparse(ctx context.Context, arg ListTransactionsForReparseParams) ([]ListTransactionsForReparseRow, error) {
+	rows, err := q.db.Query(ctx, listTransactionsForReparse,
+		arg.ID,
+		arg.Column2,
+		arg.Column3,
+		arg.Column4,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTransactionsForReparseRow{}
+	for rows.Next() {
+		var i ListTransactionsForReparseRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TxnDate,
+			&i.Description,
+			&i.Amount,
+			&i.TxnType,
+			&i.Category,
+			&i.IsReviewed,
+			&i.RawData,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UploadID,
+			&i.Source,
+			&i.Flags,
+			&i.ReferenceNo,
+			&i.CategoryScore,
+			&i.Tags,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchUserTransactions = `-- name: SearchUserTransactions :many
+SELECT
+    t.id, t.user_id, t.txn_date, t.description, t.amount, t.txn_type, t.category, t.is_reviewed, t.raw_data, t.created_at, t.updated_at, t.upload_id, t.source, t.flags, t.reference_no, t.category_score, t.tags,
+    uh.bank_type,
+    COUNT(*) OVER() AS total_count
+FROM transactions t
+LEFT JOIN upload_history uh ON t.upload_id = uh.id
+WHERE t.user_id = $1
+  AND t.description ILIKE '%' || $2::text || '%'
+ORDER BY t.txn_date DESC
+LIMIT $3 OFFSET $4
+`
+
+type SearchUserTransactionsParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	Column2 string      `json:"column_2"`
+	Limit   int32       `json:"limit"`
+	Offset  int32       `json:"offset"`
+}
+
+type SearchUserTransactionsRow struct {
+	ID            pgtype.UUID        `json:"id"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	TxnDate       pgtype.Date        `json:"txn_date"`
+	Description   string             `json:"description"`
+	Amount        pgtype.Numeric     `json:"amount"`
+	TxnType       string             `json:"txn_type"`
+	Category      pgtype.Text        `json:"category"`
+	IsReviewed    bool               `json:"is_reviewed"`
+	RawData       pgtype.Text        `json:"raw_data"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	UploadID      pgtype.UUID        `json:"upload_id"`
+	Source        pgtype.Text        `json:"source"`
+	Flags         []string           `json:"flags"`
+	ReferenceNo   pgtype.Text        `json:"reference_no"`
+	CategoryScore pgtype.Float8      `json:"category_score"`
+	Tags          []string           `json:"tags"`
+	BankType      pgtype.Text        `json:"bank_type"`
+	TotalCount    int64              `json:"total_count"`
+}
+
+// Case-insensitive substring match on description; the caller escapes LIKE wildcards in $2.
+func (q *Queries) SearchUserTransactions(ctx context.Context, arg SearchUserTransactionsParams) ([]SearchUserTransactionsRow, error) {
+	rows, err := q.db.Query(ctx, searchUserTransactions,
+		arg.UserID,
+		arg.Column2,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchUserTransactionsRow{}
+	for rows.Next() {
+		var i SearchUserTransactionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.TxnDate,
+			&i.Description,
+			&i.Amount,
+			&i.TxnType,
+			&i.Category,
+			&i.IsReviewed,
+			&i.RawData,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UploadID,
+			&i.Source,
+			&i.Flags,
+			&i.ReferenceNo,
+			&i.CategoryScore,
+			&i.Tags,
 			&i.BankType,
+			&i.TotalCount,
 		); err != nil {
 			return nil, err
 		}
@@ -545,7 +1380,7 @@ SET description = COALESCE($2, description),
     is_reviewed = COALESCE($6, is_reviewed),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id
+RETURNING id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id, source, flags, reference_no, category_score, tags
 `
 
 type UpdateTransactionParams struct {
@@ -580,6 +1415,11 @@ func (q *Queries) UpdateTransaction(ctx context.Context, arg UpdateTransactionPa
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.UploadID,
+		&i.Source,
+		&i.Flags,
+		&i.ReferenceNo,
+		&i.CategoryScore,
+		&i.Tags,
 	)
 	return i, err
 }
@@ -587,10 +1427,11 @@ func (q *Queries) UpdateTransaction(ctx context.Context, arg UpdateTransactionPa
 const updateTransactionCategory = `-- name: UpdateTransactionCategory :one
 UPDATE transactions
 SET category = $2,
+    category_score = NULL,
     is_reviewed = $3,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id
+RETURNING id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id, source, flags, reference_no, category_score, tags
 `
 
 type UpdateTransactionCategoryParams struct {
@@ -599,6 +1440,7 @@ type UpdateTransactionCategoryParams struct {
 	IsReviewed bool        `json:"is_reviewed"`
 }
 
+// Sets a category manually, dropping the rule-match confidence of the automatic one
 func (q *Queries) UpdateTransactionCategory(ctx context.Context, arg UpdateTransactionCategoryParams) (Transaction, error) {
 	row := q.db.QueryRow(ctx, updateTransactionCategory, arg.ID, arg.Category, arg.IsReviewed)
 	var i Transaction
@@ -615,6 +1457,118 @@ func (q *Queries) UpdateTransactionCategory(ctx context.Context, arg UpdateTrans
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.UploadID,
+		&i.Source,
+		&i.Flags,
+		&i.ReferenceNo,
+		&i.CategoryScore,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const updateTransactionParsedFields = `-- name: UpdateTransactionParsedFields :exec
+UPDATE transactions
+SET txn_date = $2,
+    description = $3,
+    amount = $4,
+    txn_type = $5,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateTransactionParsedFieldsParams struct {
+	ID          pgtype.UUID    `json:"id"`
+	TxnDate     pgtype.Date    `json:"txn_date"`
+	Description string         `json:"description"`
+	Amount      pgtype.Numeric `json:"amount"`
+	TxnType     string         `json:"txn_type"`
+}
+
+func (q *Queries) UpdateTransactionParsedFields(ctx context.Context, arg UpdateTransactionParsedFieldsParams) error {
+	_, err := q.db.Exec(ctx, updateTransactionParsedFields,
+		arg.ID,
+		arg.TxnDate,
+		arg.Description,
+		arg.Amount,
+		arg.TxnType,
+	)
+	return err
+}
+
+const upsertTransactionPreservingReview = `-- name: UpsertTransactionPreservingReview :one
+UPDATE transactions
+SET txn_date = $3,
+    description = $4,
+    amount = $5,
+    txn_type = $6,
+    raw_data = $7,
+    source = COALESCE($8, source),
+    flags = $9,
+    category = CASE WHEN is_reviewed THEN category ELSE NULLIF($10::text, '') END,
+    reference_no = $11,
+    category_score = CASE WHEN is_reviewed THEN category_score ELSE NULLIF($12::float8, 0) END,
+    tags = ARRAY(SELECT DISTINCT tag FROM unnest(tags || $13::text[]) AS tag ORDER BY tag),
+    upload_id = COALESCE($14, upload_id),
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id, source, flags, reference_no, category_score, tags
+`
+
+type UpsertTransactionPreservingReviewParams struct {
+	ID          pgtype.UUID    `json:"id"`
+	UserID      pgtype.UUID    `json:"user_id"`
+	TxnDate     pgtype.Date    `json:"txn_date"`
+	Description string         `json:"description"`
+	Amount      pgtype.Numeric `json:"amount"`
+	TxnType     string         `json:"txn_type"`
+	RawData     pgtype.Text    `json:"raw_data"`
+	Source      pgtype.Text    `json:"source"`
+	Flags       []string       `json:"flags"`
+	Column10    string         `json:"column_10"`
+	ReferenceNo pgtype.Text    `json:"reference_no"`
+	Column12    float64        `json:"column_12"`
+	Column13    []string       `json:"column_13"`
+	UploadID    pgtype.UUID    `json:"upload_id"`
+}
+
+// Refreshes the parse-derived fields of a re-imported transaction.
+// Reviewed transactions keep their manually set category; the row moves to the latest upload.
+func (q *Queries) UpsertTransactionPreservingReview(ctx context.Context, arg UpsertTransactionPreservingReviewParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, upsertTransactionPreservingReview,
+		arg.ID,
+		arg.UserID,
+		arg.TxnDate,
+		arg.Description,
+		arg.Amount,
+		arg.TxnType,
+		arg.RawData,
+		arg.Source,
+		arg.Flags,
+		arg.Column10,
+		arg.ReferenceNo,
+		arg.Column12,
+		arg.Column13,
+		arg.UploadID,
+	)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TxnDate,
+		&i.Description,
+		&i.Amount,
+		&i.TxnType,
+		&i.Category,
+		&i.IsReviewed,
+		&i.RawData,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UploadID,
+		&i.Source,
+		&i.Flags,
+		&i.ReferenceNo,
+		&i.CategoryScore,
+		&i.Tags,
 	)
 	return i, err
 }
@@ -34,7 +34,7 @@ INSERT INTO transactions (
     unnest($9::TEXT[])
 )
 ON CONFLICT (user_id, txn_date, description, amount) DO NOTHING
-RETURNING id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id
+RETURNING id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id, source, flags, reference_no, category_score, tags
 `
 
 type BatchInsertTransactionsParams struct {
@@ -82,6 +82,11 @@ func (q *Queries) BatchInsertTransactions(ctx context.Context, arg BatchInsertTr
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.UploadID,
+			&i.Source,
+			&i.Flags,
+			&i.ReferenceNo,
+			&i.CategoryScore,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -284,9 +289,11 @@ INSERT INTO upload_history (
     file_hash,
     bank_type,
     status,
-    total_rows
+    total_rows,
+    processing_started_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8,
+    CASE WHEN $7 = 'processing' THEN NOW() END
 )
 RETURNING id, user_id, filename, file_key, file_size_bytes, file_hash, bank_type, status, error_message, processing_started_at, processing_completed_at, total_rows, parsed_rows, categorized_rows, duplicate_rows, error_rows, accuracy_percent, processing_duration_ms, created_at, updated_at
 `
@@ -303,7 +310,7 @@ type CreateUploadHistoryParams struct {
 }
 
 // SQLC queries for upload_history table
-// Create a new upload history record
+// Create a new upload history record; records created as processing start the clock
 func (q *Queries) CreateUploadHistory(ctx context.Context, arg CreateUploadHistoryParams) (UploadHistory, error) {
 	row := q.db.QueryRow(ctx, createUploadHistory,
 		arg.UserID,
@@ -352,15 +359,45 @@ func (q *Queries) DeleteUploadHistory(ctx context.Context, id pgtype.UUID) error
 	return err
 }
 
-const deleteUserUploadHistory = `-- name: DeleteUserUploadHistory :exec
+const deleteUserUploadHistory = `-- name: DeleteUserUploadHistory :execrows
 DELETE FROM upload_history
 WHERE user_id = $1
 `
 
 // Delete all upload history for a user
-func (q *Queries) DeleteUserUploadHistory(ctx context.Context, userID pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, deleteUserUploadHistory, userID)
-	return err
+func (q *Queries) DeleteUserUploadHistory(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserUploadHistory, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getCompletedUploadByFileKey = `-- name: GetCompletedUploadByFileKey :one
+SELECT uh.id, ur.summary
+FROM upload_history uh
+JOIN upload_results ur ON ur.upload_id = uh.id
+WHERE uh.user_id = $1 AND uh.file_key = $2 AND uh.status = 'completed'
+ORDER BY uh.created_at DESC
+LIMIT 1
+`
+
+type GetCompletedUploadByFileKeyParams struct {
+	UserID  pgtype.UUID `json:"user_id"`
+	FileKey string      `json:"file_key"`
+}
+
+type GetCompletedUploadByFileKeyRow struct {
+	ID      pgtype.UUID `json:"id"`
+	Summary []byte      `json:"summary"`
+}
+
+// Get the stored result of the latest completed upload of a file (idempotent reprocessing)
+func (q *Queries) GetCompletedUploadByFileKey(ctx context.Context, arg GetCompletedUploadByFileKeyParams) (GetCompletedUploadByFileKeyRow, error) {
+	row := q.db.QueryRow(ctx, getCompletedUploadByFileKey, arg.UserID, arg.FileKey)
+	var i GetCompletedUploadByFileKeyRow
+	err := row.Scan(&i.ID, &i.Summary)
+	return i, err
 }
 
 const getDuplicateTransactions = `-- name: GetDuplicateTransactions :many
@@ -566,7 +603,7 @@ func (q *Queries) GetStuckUploads(ctx context.Context) ([]UploadHistory, error)
 }
 
 const getTransactionsByUpload = `-- name: GetTransactionsByUpload :many
-SELECT id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id FROM transactions
+SELECT id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id, source, flags, reference_no, category_score, tags FROM transactions
 WHERE upload_id = $1
 ORDER BY txn_date DESC, created_at DESC
 `
@@ -594,6 +631,11 @@ func (q *Queries) GetTransactionsByUpload(ctx context.Context, uploadID pgtype.U
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.UploadID,
+			&i.Source,
+			&i.Flags,
+			&i.ReferenceNo,
+			&i.CategoryScore,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -766,6 +808,26 @@ func (q *Queries) GetUploadPerformanceMetrics(ctx context.Context, userID pgtype
 	return i, err
 }
 
+const getUploadResult = `-- name: GetUploadResult :one
+SELECT upload_id, summary, warnings, created_at, issues FROM upload_results
+WHERE upload_id = $1
+LIMIT 1
+`
+
+// Get the stored summary, warnings and issues of a processed upload
+func (q *Queries) GetUploadResult(ctx context.Context, uploadID pgtype.UUID) (UploadResult, error) {
+	row := q.db.QueryRow(ctx, getUploadResult, uploadID)
+	var i UploadResult
+	err := row.Scan(
+		&i.UploadID,
+		&i.Summary,
+		&i.Warnings,
+		&i.CreatedAt,
+		&i.Issues,
+	)
+	return i, err
+}
+
 const getUploadStatsByUser = `-- name: GetUploadStatsByUser :one
 SELECT
     COUNT(*) as total_uploads,
@@ -991,7 +1053,7 @@ INSERT INTO transactions (
     $1, $2, $3, $4, $5, $6, $7, $8, $9
 )
 ON CONFLICT (user_id, txn_date, description, amount) DO NOTHING
-RETURNING id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id
+RETURNING id, user_id, txn_date, description, amount, txn_type, category, is_reviewed, raw_data, created_at, updated_at, upload_id, source, flags, reference_no, category_score, tags
 `
 
 type InsertTransactionWithDuplicateCheckParams struct {
@@ -1033,6 +1095,11 @@ func (q *Queries) InsertTransactionWithDuplicateCheck(ctx context.Context, arg I
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.UploadID,
+		&i.Source,
+		&i.Flags,
+		&i.ReferenceNo,
+		&i.CategoryScore,
+		&i.Tags,
 	)
 	return i, err
 }
@@ -1051,6 +1118,39 @@ func (q *Queries) RefreshUserUploadStats(ctx context.Context) error {
 	return err
 }
 
+const saveUploadResult = `-- name: SaveUploadResult :exec
+INSERT INTO upload_results (
+    upload_id,
+    summary,
+    warnings,
+    issues
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (upload_id) DO UPDATE
+SET summary = EXCLUDED.summary,
+    warnings = EXCLUDED.warnings,
+    issues = EXCLUDED.issues
+`
+
+type SaveUploadResultParams struct {
+	UploadID pgtype.UUID `json:"upload_id"`
+	Summary  []byte      `json:"summary"`
+	Warnings []string    `json:"warnings"`
+	Issues   []byte      `json:"issues"`
+}
+
+// Store the summary, warnings and issues of a processed upload
+func (q *Queries) SaveUploadResult(ctx context.Context, arg SaveUploadResultParams) error {
+	_, err := q.db.Exec(ctx, saveUploadResult,
+		arg.UploadID,
+		arg.Summary,
+		arg.Warnings,
+		arg.Issues,
+	)
+	return err
+}
+
 const startProcessingUpload = `-- name: StartProcessingUpload :one
 UPDATE upload_history
 SET
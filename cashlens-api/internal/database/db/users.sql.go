@@ -14,6 +14,10 @@ import (
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, clerk_user_id, email, full_name)
 VALUES ($1, $2, $3, $4)
+ON CONFLICT (clerk_user_id) DO UPDATE
+SET email = EXCLUDED.email,
+    full_name = EXCLUDED.full_name,
+    updated_at = NOW()
 RETURNING id, clerk_user_id, email, full_name, created_at, updated_at
 `
 
@@ -24,6 +28,8 @@ type CreateUserParams struct {
 	FullName    pgtype.Text `json:"full_name"`
 }
 
+// Creates a user, or refreshes the email and name of an existing Clerk user
+// (webhooks may be redelivered).
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
 	row := q.db.QueryRow(ctx, createUser,
 		arg.ID,
@@ -43,6 +49,20 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 	return i, err
 }
 
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1
+`
+
+// Rows in other tables referencing the user are removed by ON DELETE CASCADE
+func (q *Queries) DeleteUser(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getUserByClerkID = `-- name: GetUserByClerkID :one
 SELECT id, clerk_user_id, email, full_name, created_at, updated_at FROM users
 WHERE clerk_user_id = $1
@@ -83,7 +103,32 @@ func (q *Queries) GetUserByID(ctx context.Context, id pgtype.UUID) (User, error)
 	return i, err
 }
 
-const updateUser = `-- name: UpdateUser :one
+const listUserIDs = `-- name: ListUserIDs :many
+SELECT id FROM users
+ORDER BY created_at ASC
+`
+
+func (q *Queries) ListUserIDs(ctx context.Context) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listUserIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateUserByClerkID = `-- name: UpdateUserByClerkID :one
 UPDATE users
 SET email = $2,
     full_name = $3,
@@ -92,14 +137,14 @@ WHERE clerk_user_id = $1
 RETURNING id, clerk_user_id, email, full_name, created_at, updated_at
 `
 
-type UpdateUserParams struct {
+type UpdateUserByClerkIDParams struct {
 	ClerkUserID string      `json:"clerk_user_id"`
 	Email       string      `json:"email"`
 	FullName    pgtype.Text `json:"full_name"`
 }
 
-func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
-	row := q.db.QueryRow(ctx, updateUser, arg.ClerkUserID, arg.Email, arg.FullName)
+func (q *Queries) UpdateUserByClerkID(ctx context.Context, arg UpdateUserByClerkIDParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserByClerkID, arg.ClerkUserID, arg.Email, arg.FullName)
 	var i User
 	err := row.Scan(
 		&i.ID,
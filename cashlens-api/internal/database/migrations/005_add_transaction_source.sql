@@ -0,0 +1,10 @@
+-- Migration 005: Record how each transaction entered the system
+-- Existing rows predate source tracking and are left NULL
+
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS source VARCHAR(10)
+    CHECK (source IN ('csv', 'xlsx', 'xls', 'pdf', 'manual', 'api'));
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_source ON transactions(user_id, source);
+
+COMMENT ON COLUMN transactions.source IS 'Import source: csv, xlsx, xls, pdf, manual, or api';
@@ -0,0 +1,11 @@
+-- Migration 006: Flag suspicious transactions during import for later review
+-- Flags: bad_date, zero_amount, reconciliation_mismatch
+
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS flags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Partial index keeps the issues lookup cheap; most rows have no flags
+CREATE INDEX IF NOT EXISTS idx_transactions_user_flagged ON transactions(user_id, txn_date DESC)
+    WHERE cardinality(flags) > 0;
+
+COMMENT ON COLUMN transactions.flags IS 'Import issues: bad_date, zero_amount, reconciliation_mismatch';
@@ -0,0 +1,12 @@
+-- Migration 007: Keep the result of each import so users can revisit it later
+-- Stores the ProcessUpload summary and any per-row warnings alongside upload_history
+
+CREATE TABLE IF NOT EXISTS upload_results (
+    upload_id UUID PRIMARY KEY REFERENCES upload_history(id) ON DELETE CASCADE,
+    summary JSONB NOT NULL DEFAULT '{}',
+    warnings TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE upload_results IS 'Summary and per-row warnings returned by each processed upload';
+COMMENT ON COLUMN upload_results.warnings IS 'Rows that were skipped or could not be saved, with the reason';
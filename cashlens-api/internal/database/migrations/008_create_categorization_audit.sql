@@ -0,0 +1,18 @@
+-- Migration 008: Record which rule categorized each imported transaction
+-- Used to tune rules, e.g. comparing how often each match type fires
+
+CREATE TABLE IF NOT EXISTS categorization_audit (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id UUID REFERENCES transactions(id) ON DELETE CASCADE,
+    rule_id UUID, -- Global or user rule; not a foreign key since rules live in two tables
+    rule_type VARCHAR(10) NOT NULL, -- global or user
+    match_type VARCHAR(20) NOT NULL, -- substring, regex, exact, fuzzy, any, all
+    category VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_categorization_audit_user_created ON categorization_audit(user_id, created_at DESC);
+
+COMMENT ON TABLE categorization_audit IS 'Rule that categorized each imported transaction';
+COMMENT ON COLUMN categorization_audit.match_type IS 'Match type of the winning rule: substring, regex, exact, fuzzy, any, all';
@@ -0,0 +1,9 @@
+-- Migration 009: Store the payment reference (UPI/NEFT/IMPS/RTGS or cheque number) parsed from each row
+
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS reference_no TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_reference ON transactions(user_id, reference_no)
+    WHERE reference_no IS NOT NULL;
+
+COMMENT ON COLUMN transactions.reference_no IS 'UPI/NEFT/IMPS/RTGS or cheque reference from the statement';
@@ -0,0 +1,12 @@
+-- Migration 010: Optional amount range on user rules, e.g. "rent" only above 10,000
+
+ALTER TABLE user_categorization_rules
+ADD COLUMN IF NOT EXISTS min_amount DECIMAL(15, 2),
+ADD COLUMN IF NOT EXISTS max_amount DECIMAL(15, 2);
+
+ALTER TABLE user_categorization_rules
+ADD CONSTRAINT user_rules_amount_range_check
+    CHECK (min_amount IS NULL OR max_amount IS NULL OR min_amount <= max_amount);
+
+COMMENT ON COLUMN user_categorization_rules.min_amount IS 'Rule only matches transactions with an absolute amount at or above this (NULL = no minimum)';
+COMMENT ON COLUMN user_categorization_rules.max_amount IS 'Rule only matches transactions with an absolute amount at or below this (NULL = no maximum)';
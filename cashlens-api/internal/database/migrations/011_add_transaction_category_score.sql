@@ -0,0 +1,7 @@
+-- Migration 011: Store how confident the automatic categorization was, so low-confidence rows can be reviewed first
+
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS category_score DOUBLE PRECISION
+    CHECK (category_score IS NULL OR (category_score >= 0 AND category_score <= 1));
+
+COMMENT ON COLUMN transactions.category_score IS 'Normalized 0-1 score of the rule match that set category (NULL when not auto-categorized)';
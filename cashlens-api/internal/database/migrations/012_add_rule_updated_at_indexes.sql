@@ -0,0 +1,5 @@
+-- Migration 012: Index rule updated_at so the categorizer can fetch only rules changed since its last load
+
+CREATE INDEX IF NOT EXISTS idx_global_rules_updated_at ON global_categorization_rules(updated_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_user_rules_user_updated_at ON user_categorization_rules(user_id, updated_at, id);
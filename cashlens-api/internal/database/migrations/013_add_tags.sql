@@ -0,0 +1,12 @@
+-- Migration 013: Tags on transactions, applied on import by user rules with a tags action
+
+ALTER TABLE user_categorization_rules
+ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_transactions_tags ON transactions USING GIN (tags);
+
+COMMENT ON COLUMN user_categorization_rules.tags IS 'Tags added to matching transactions on import; a rule with tags and no category only tags';
+COMMENT ON COLUMN transactions.tags IS 'Cross-cutting labels (e.g. reimbursable), independent of category';
@@ -0,0 +1,10 @@
+-- Migration 014: Optional direction on user rules, e.g. "INT PD" only for credits
+
+ALTER TABLE user_categorization_rules
+ADD COLUMN IF NOT EXISTS direction VARCHAR(10);
+
+ALTER TABLE user_categorization_rules
+ADD CONSTRAINT user_rules_direction_check
+    CHECK (direction IS NULL OR direction IN ('credit', 'debit'));
+
+COMMENT ON COLUMN user_categorization_rules.direction IS 'Rule only matches credits or only debits (NULL = either)';
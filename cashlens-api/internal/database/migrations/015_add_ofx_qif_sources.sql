@@ -0,0 +1,10 @@
+-- Migration 015: Allow OFX and QIF imports as transaction sources
+
+ALTER TABLE transactions
+DROP CONSTRAINT IF EXISTS transactions_source_check;
+
+ALTER TABLE transactions
+ADD CONSTRAINT transactions_source_check
+    CHECK (source IN ('csv', 'xlsx', 'xls', 'pdf', 'ofx', 'qif', 'manual', 'api'));
+
+COMMENT ON COLUMN transactions.source IS 'Import source: csv, xlsx, xls, pdf, ofx, qif, manual, or api';
@@ -0,0 +1,21 @@
+-- Migration 016: One active upload per file
+-- ProcessUpload returns the stored result instead of reprocessing a completed file_key,
+-- and the unique index stops concurrent requests from importing the same file twice
+
+-- Earlier duplicates (imported before this check existed) keep only their latest row active
+UPDATE upload_history uh
+SET status = 'failed',
+    error_message = 'superseded by a later upload of the same file',
+    updated_at = NOW()
+WHERE uh.status IN ('processing', 'completed')
+  AND EXISTS (
+      SELECT 1 FROM upload_history later
+      WHERE later.user_id = uh.user_id
+        AND later.file_key = uh.file_key
+        AND later.status IN ('processing', 'completed')
+        AND (later.created_at, later.id) > (uh.created_at, uh.id)
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_upload_history_user_file_key_active
+    ON upload_history(user_id, file_key)
+    WHERE status IN ('processing', 'completed');
@@ -0,0 +1,17 @@
+-- Migration 017: Per-user display preferences
+-- Users without a row use the defaults (INR, Asia/Kolkata, weeks starting Monday)
+
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL DEFAULT 'INR',
+    timezone TEXT NOT NULL DEFAULT 'Asia/Kolkata',
+    week_start TEXT NOT NULL DEFAULT 'monday'
+        CHECK (week_start IN ('monday', 'tuesday', 'wednesday', 'thursday', 'friday', 'saturday', 'sunday')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE user_preferences IS 'Per-user currency, timezone and week start used when presenting summaries';
+COMMENT ON COLUMN user_preferences.currency IS 'ISO 4217 code amounts are displayed in';
+COMMENT ON COLUMN user_preferences.timezone IS 'IANA timezone used for dates relative to today';
+COMMENT ON COLUMN user_preferences.week_start IS 'First day of the week when summaries group by week';
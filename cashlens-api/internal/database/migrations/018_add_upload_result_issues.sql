@@ -0,0 +1,7 @@
+-- Migration 018: Keep each import's problems by kind
+-- GET /v1/uploads/:id/issues returns them so imports can be diagnosed later
+
+ALTER TABLE upload_results
+ADD COLUMN IF NOT EXISTS issues JSONB NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN upload_results.issues IS 'Skipped rows, row errors, reconciliation warnings and flagged rows recorded during the import';
@@ -0,0 +1,14 @@
+-- Migration 019: One audit row per transaction
+-- Re-importing a statement updates the existing row instead of adding another, so
+-- match type stats count each transaction once
+
+-- Keep the latest audit of transactions already audited more than once
+DELETE FROM categorization_audit ca
+WHERE EXISTS (
+    SELECT 1 FROM categorization_audit later
+    WHERE later.transaction_id = ca.transaction_id
+      AND (later.created_at, later.id) > (ca.created_at, ca.id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categorization_audit_transaction
+    ON categorization_audit(transaction_id);
@@ -0,0 +1,31 @@
+-- name: UpsertCategorizationAudit :exec
+-- Record the rule that categorized a transaction. A transaction keeps one audit row:
+-- re-imports replace it only when the match changed, so they aren't counted twice.
+INSERT INTO categorization_audit (
+    user_id,
+    transaction_id,
+    rule_id,
+    rule_type,
+    match_type,
+    category
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (transaction_id) DO UPDATE
+SET rule_id = EXCLUDED.rule_id,
+    rule_type = EXCLUDED.rule_type,
+    match_type = EXCLUDED.match_type,
+    category = EXCLUDED.category,
+    created_at = NOW()
+WHERE (categorization_audit.rule_id, categorization_audit.match_type, categorization_audit.category)
+    IS DISTINCT FROM (EXCLUDED.rule_id, EXCLUDED.match_type, EXCLUDED.category);
+
+-- name: CountCategorizationsByMatchType :many
+-- Categorizations per match type recorded in [$2, $3)
+SELECT match_type, COUNT(*) AS categorized_count
+FROM categorization_audit
+WHERE user_id = $1
+  AND created_at >= $2
+  AND created_at < $3
+GROUP BY match_type
+ORDER BY match_type;
@@ -3,6 +3,14 @@ SELECT * FROM global_categorization_rules
 WHERE is_active = TRUE
 ORDER BY priority DESC, keyword ASC;
 
+-- name: GetGlobalRulesChangedSince :many
+-- Rules updated after the (updated_at, id) watermark, oldest first, for batched
+-- incremental cache loads. Deactivated rules are included so caches can drop them.
+SELECT * FROM global_categorization_rules
+WHERE updated_at > $1 OR (updated_at = $1 AND id > $2)
+ORDER BY updated_at ASC, id ASC
+LIMIT $3;
+
 -- name: GetGlobalRuleByKeyword :one
 SELECT * FROM global_categorization_rules
 WHERE keyword = $1 AND is_active = TRUE
@@ -33,19 +41,28 @@ SELECT * FROM user_categorization_rules
 WHERE user_id = $1 AND is_active = TRUE
 ORDER BY priority DESC, keyword ASC;
 
+-- name: GetUserRulesChangedSince :many
+-- User rules updated after the (updated_at, id) watermark, oldest first, for batched
+-- incremental cache loads. Deactivated rules are included so caches can drop them.
+SELECT * FROM user_categorization_rules
+WHERE user_id = $1 AND (updated_at > $2 OR (updated_at = $2 AND id > $3))
+ORDER BY updated_at ASC, id ASC
+LIMIT $4;
+
 -- name: GetUserRuleByKeyword :one
 SELECT * FROM user_categorization_rules
 WHERE user_id = $1 AND keyword = $2 AND is_active = TRUE
 LIMIT 1;
 
 -- name: CreateUserRule :one
-INSERT INTO user_categorization_rules (user_id, keyword, category, priority, match_type, similarity_threshold, is_active)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO user_categorization_rules (user_id, keyword, category, priority, match_type, similarity_threshold, is_active, min_amount, max_amount, tags, direction)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 RETURNING *;
 
 -- name: UpdateUserRule :one
 UPDATE user_categorization_rules
-SET category = $2, priority = $3, match_type = $4, similarity_threshold = $5, is_active = $6, updated_at = NOW()
+SET category = $2, priority = $3, match_type = $4, similarity_threshold = $5, is_active = $6,
+    min_amount = $8, max_amount = $9, tags = $10, direction = $11, updated_at = NOW()
 WHERE id = $1 AND user_id = $7
 RETURNING *;
 
@@ -53,6 +70,11 @@ RETURNING *;
 DELETE FROM user_categorization_rules
 WHERE id = $1 AND user_id = $2;
 
+-- name: DeleteAllUserRules :execrows
+-- Delete every rule a user created (account deletion)
+DELETE FROM user_categorization_rules
+WHERE user_id = $1;
+
 -- name: DeactivateUserRule :exec
 UPDATE user_categorization_rules
 SET is_active = FALSE, updated_at = NOW()
@@ -116,3 +138,8 @@ SELECT
     (SELECT COUNT(*) FROM user_categorization_rules u WHERE u.user_id = $1 AND u.is_active = TRUE) as user_rules_count,
     (SELECT COUNT(DISTINCT g2.category) FROM global_categorization_rules g2 WHERE g2.is_active = TRUE) as global_categories_count,
     (SELECT COUNT(DISTINCT u2.category) FROM user_categorization_rules u2 WHERE u2.user_id = $1 AND u2.is_active = TRUE) as user_categories_count;
+
+-- name: NotifyRuleCacheInvalidation :exec
+-- Tell every API instance LISTENing on cashlens_rule_cache to drop a cached rule set.
+-- The payload is a user ID.
+SELECT pg_notify('cashlens_rule_cache', sqlc.arg(payload)::text);
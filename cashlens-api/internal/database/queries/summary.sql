@@ -19,13 +19,30 @@ GROUP BY DATE_TRUNC(sqlc.arg(date_trunc)::text, txn_date::timestamp)
 ORDER BY period;
 
 -- name: GetCashFlowTrend :many
+-- DATE_TRUNC weeks start on Monday; week_offset shifts dates forward before
+-- truncating and back after, so 1 starts weeks on Sunday, 2 on Saturday, and so on.
 SELECT
-    DATE_TRUNC(sqlc.arg(date_trunc)::text, txn_date::timestamp)::timestamp AS period,
+    (DATE_TRUNC(sqlc.arg(date_trunc)::text, txn_date::timestamp + make_interval(days => sqlc.arg(week_offset)::int))
+        - make_interval(days => sqlc.arg(week_offset)::int))::timestamp AS period,
     COALESCE(SUM(CASE WHEN txn_type = 'credit' THEN amount ELSE 0 END), 0) AS inflow,
     COALESCE(SUM(CASE WHEN txn_type = 'debit' THEN amount ELSE 0 END), 0) AS outflow,
     COALESCE(SUM(CASE WHEN txn_type = 'credit' THEN amount ELSE -amount END), 0) AS net_flow
 FROM transactions
 WHERE user_id = $1
   AND txn_date BETWEEN $2 AND $3
-GROUP BY DATE_TRUNC(sqlc.arg(date_trunc)::text, txn_date::timestamp)
+GROUP BY 1
 ORDER BY period;
+
+-- name: GetCategoryBreakdown :many
+-- Outflow and inflow per category over a date range, largest spend first.
+-- NULL and empty categories are grouped as Uncategorized.
+SELECT
+    COALESCE(NULLIF(category, ''), 'Uncategorized')::text AS category,
+    COALESCE(SUM(CASE WHEN txn_type = 'debit' THEN ABS(amount) ELSE 0 END), 0) AS total_outflow,
+    COALESCE(SUM(CASE WHEN txn_type = 'credit' THEN ABS(amount) ELSE 0 END), 0) AS total_inflow,
+    COUNT(*) AS transaction_count
+FROM transactions
+WHERE user_id = $1
+  AND txn_date BETWEEN $2 AND $3
+GROUP BY COALESCE(NULLIF(category, ''), 'Uncategorized')
+ORDER BY total_outflow DESC, total_inflow DESC, category ASC;
@@ -7,9 +7,15 @@ INSERT INTO transactions (
     txn_type,
     category,
     is_reviewed,
-    raw_data
+    raw_data,
+    source,
+    flags,
+    reference_no,
+    category_score,
+    tags,
+    upload_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 )
 RETURNING *;
 
@@ -35,13 +41,72 @@ LIMIT 1;
 -- name: GetUserTransactions :many
 SELECT
     t.*,
-    uh.bank_type
+    uh.bank_type,
+    COUNT(*) OVER() AS total_count
 FROM transactions t
 LEFT JOIN upload_history uh ON t.upload_id = uh.id
 WHERE t.user_id = $1
 ORDER BY t.txn_date DESC
 LIMIT $2 OFFSET $3;
 
+-- name: GetUserTransactionsBySource :many
+-- Status filter ($3) is one of: all, categorized, uncategorized
+SELECT
+    t.*,
+    uh.bank_type,
+    COUNT(*) OVER() AS total_count
+FROM transactions t
+LEFT JOIN upload_history uh ON t.upload_id = uh.id
+WHERE t.user_id = $1
+  AND t.source = $2
+  AND (
+    $3::text = 'all'
+    OR ($3::text = 'categorized' AND t.category IS NOT NULL)
+    OR ($3::text = 'uncategorized' AND t.category IS NULL)
+  )
+ORDER BY t.txn_date DESC
+LIMIT $4 OFFSET $5;
+
+-- name: GetUserTransactionsFiltered :many
+-- Dates are inclusive; status ($4) is one of: all, categorized, uncategorized.
+-- An empty source ($5) or category ($6) matches any. Confidence bounds ($7, $8) are
+-- inclusive; narrower than 0-1 they exclude rows without a category_score.
+SELECT
+    t.*,
+    uh.bank_type,
+    COUNT(*) OVER() AS total_count
+FROM transactions t
+LEFT JOIN upload_history uh ON t.upload_id = uh.id
+WHERE t.user_id = $1
+  AND t.txn_date >= $2
+  AND t.txn_date <= $3
+  AND (
+    $4::text = 'all'
+    OR ($4::text = 'categorized' AND t.category IS NOT NULL)
+    OR ($4::text = 'uncategorized' AND t.category IS NULL)
+  )
+  AND ($5::text = '' OR t.source = $5::text)
+  AND ($6::text = '' OR t.category = $6::text)
+  AND (
+    ($7::float8 <= 0 AND $8::float8 >= 1)
+    OR t.category_score BETWEEN $7::float8 AND $8::float8
+  )
+ORDER BY t.txn_date DESC
+LIMIT $9 OFFSET $10;
+
+-- name: SearchUserTransactions :many
+-- Case-insensitive substring match on description; the caller escapes LIKE wildcards in $2.
+SELECT
+    t.*,
+    uh.bank_type,
+    COUNT(*) OVER() AS total_count
+FROM transactions t
+LEFT JOIN upload_history uh ON t.upload_id = uh.id
+WHERE t.user_id = $1
+  AND t.description ILIKE '%' || $2::text || '%'
+ORDER BY t.txn_date DESC
+LIMIT $3 OFFSET $4;
+
 -- name: GetAllTransactions :many
 SELECT * FROM transactions
 WHERE user_id = $1
@@ -50,7 +115,8 @@ ORDER BY txn_date DESC;
 -- name: GetCategorizedTransactions :many
 SELECT
     t.*,
-    uh.bank_type
+    uh.bank_type,
+    COUNT(*) OVER() AS total_count
 FROM transactions t
 LEFT JOIN upload_history uh ON t.upload_id = uh.id
 WHERE t.user_id = $1
@@ -61,7 +127,8 @@ LIMIT $2 OFFSET $3;
 -- name: GetUncategorizedTransactions :many
 SELECT
     t.*,
-    uh.bank_type
+    uh.bank_type,
+    COUNT(*) OVER() AS total_count
 FROM transactions t
 LEFT JOIN upload_history uh ON t.upload_id = uh.id
 WHERE t.user_id = $1
@@ -83,8 +150,10 @@ ORDER BY txn_date DESC
 LIMIT $3 OFFSET $4;
 
 -- name: UpdateTransactionCategory :one
+-- Sets a category manually, dropping the rule-match confidence of the automatic one
 UPDATE transactions
 SET category = $2,
+    category_score = NULL,
     is_reviewed = $3,
     updated_at = NOW()
 WHERE id = $1
@@ -105,14 +174,114 @@ RETURNING *;
 DELETE FROM transactions
 WHERE id = $1;
 
--- name: DeleteUserTransactions :exec
+-- name: DeleteUserTransactions :execrows
 DELETE FROM transactions
 WHERE user_id = $1;
 
+-- name: ClearTransactionCategories :execrows
+-- Clears categories before a full recategorization. Reviewed transactions keep
+-- theirs unless $2 is true, in which case they are un-reviewed as well.
+UPDATE transactions
+SET category = NULL,
+    category_score = NULL,
+    is_reviewed = FALSE,
+    updated_at = NOW()
+WHERE user_id = $1
+  AND category IS NOT NULL
+  AND ($2::boolean OR NOT is_reviewed);
+
+-- name: FixTransactionAmountSigns :execrows
+-- Makes amount signs agree with txn_type (debits negative, credits positive).
+-- An empty $2 matches every bank; otherwise only uploads from that bank.
+UPDATE transactions t
+SET amount = CASE WHEN t.txn_type = 'debit' THEN -ABS(t.amount) ELSE ABS(t.amount) END,
+    updated_at = NOW()
+WHERE t.user_id = $1
+  AND ($2::text = '' OR EXISTS (
+      SELECT 1 FROM upload_history u
+      WHERE u.id = t.upload_id AND UPPER(u.bank_type) = UPPER($2::text)
+  ))
+  AND ((t.txn_type = 'debit' AND t.amount > 0) OR (t.txn_type = 'credit' AND t.amount < 0));
+
+-- name: FixTransactionTypes :execrows
+-- Makes txn_type agree with the amount sign; zero amounts are left alone.
+-- An empty $2 matches every bank; otherwise only uploads from that bank.
+UPDATE transactions t
+SET txn_type = CASE WHEN t.amount < 0 THEN 'debit' ELSE 'credit' END,
+    updated_at = NOW()
+WHERE t.user_id = $1
+  AND ($2::text = '' OR EXISTS (
+      SELECT 1 FROM upload_history u
+      WHERE u.id = t.upload_id AND UPPER(u.bank_type) = UPPER($2::text)
+  ))
+  AND ((t.amount < 0 AND t.txn_type <> 'debit') OR (t.amount > 0 AND t.txn_type <> 'credit'));
+
+-- name: AddTransactionTags :execrows
+-- Adds tags to every transaction matching the filter. $2 is an escaped
+-- description substring and an empty $3 matches every bank. Rows that already
+-- carry all of the tags are left alone so the count reflects real changes.
+UPDATE transactions t
+SET tags = ARRAY(SELECT DISTINCT tag FROM unnest(t.tags || $6::text[]) AS tag ORDER BY tag),
+    updated_at = NOW()
+WHERE t.user_id = $1
+  AND t.description ILIKE '%' || $2::text || '%'
+  AND ($3::text = '' OR EXISTS (
+      SELECT 1 FROM upload_history u
+      WHERE u.id = t.upload_id AND UPPER(u.bank_type) = UPPER($3::text)
+  ))
+  AND t.txn_date BETWEEN $4 AND $5
+  AND NOT t.tags @> $6::text[];
+
 -- name: CountUserTransactions :one
 SELECT COUNT(*) FROM transactions
 WHERE user_id = $1;
 
+-- name: CountUserTransactionsBySource :one
+SELECT COUNT(*) FROM transactions
+WHERE user_id = $1
+  AND source = $2
+  AND (
+    $3::text = 'all'
+    OR ($3::text = 'categorized' AND category IS NOT NULL)
+    OR ($3::text = 'uncategorized' AND category IS NULL)
+  );
+
+-- name: CountUserTransactionsFiltered :one
+-- Dates are inclusive; status ($4) is one of: all, categorized, uncategorized.
+-- An empty source ($5) or category ($6) matches any. Confidence bounds ($7, $8) are
+-- inclusive; narrower than 0-1 they exclude rows without a category_score.
+SELECT COUNT(*) FROM transactions
+WHERE user_id = $1
+  AND txn_date >= $2
+  AND txn_date <= $3
+  AND (
+    $4::text = 'all'
+    OR ($4::text = 'categorized' AND category IS NOT NULL)
+    OR ($4::text = 'uncategorized' AND category IS NULL)
+  )
+  AND ($5::text = '' OR source = $5::text)
+  AND ($6::text = '' OR category = $6::text)
+  AND (
+    ($7::float8 <= 0 AND $8::float8 >= 1)
+    OR category_score BETWEEN $7::float8 AND $8::float8
+  );
+
+-- name: CountUserTransactionsInRange :one
+SELECT COUNT(*) FROM transactions
+WHERE user_id = $1
+  AND txn_date >= $2
+  AND txn_date <= $3
+  AND (
+    $4::text = 'all'
+    OR ($4::text = 'categorized' AND category IS NOT NULL)
+    OR ($4::text = 'uncategorized' AND category IS NULL)
+  );
+
+-- name: CountSearchUserTransactions :one
+SELECT COUNT(*) FROM transactions
+WHERE user_id = $1
+  AND description ILIKE '%' || $2::text || '%';
+
 -- name: CountCategorizedTransactions :one
 SELECT COUNT(*) FROM transactions
 WHERE user_id = $1
@@ -135,3 +304,73 @@ SELECT
     ) AS accuracy_percent
 FROM transactions
 WHERE user_id = $1;
+
+-- name: GetUserBanks :many
+-- Banks the user's transactions were imported from, via the upload they came from.
+-- Manual entries and uploads whose bank wasn't detected are grouped as UNKNOWN.
+SELECT
+    COALESCE(uh.bank_type, 'UNKNOWN')::text AS bank,
+    COUNT(*) AS transaction_count,
+    MIN(t.txn_date)::date AS first_txn_date,
+    MAX(t.txn_date)::date AS last_txn_date
+FROM transactions t
+LEFT JOIN upload_history uh ON t.upload_id = uh.id
+WHERE t.user_id = $1
+GROUP BY COALESCE(uh.bank_type, 'UNKNOWN')
+ORDER BY transaction_count DESC, bank ASC;
+
+-- name: ListTransactionsForReparse :many
+-- Keyset-paginated by id so reparse runs can resume; bank ($2) and date bounds ($3, $4) are optional
+SELECT t.* FROM transactions t
+LEFT JOIN upload_history uh ON t.upload_id = uh.id
+WHERE t.id > $1
+  AND t.raw_data IS NOT NULL
+  AND ($2::text = '' OR uh.bank_type = $2::text)
+  AND ($3::date IS NULL OR t.txn_date >= $3::date)
+  AND ($4::date IS NULL OR t.txn_date <= $4::date)
+ORDER BY t.id
+LIMIT $5;
+
+-- name: UpdateTransactionParsedFields :exec
+UPDATE transactions
+SET txn_date = $2,
+    description = $3,
+    amount = $4,
+    txn_type = $5,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetFlaggedTransactions :many
+-- Transactions flagged during import; flag filter ($2) is optional
+SELECT * FROM transactions
+WHERE user_id = $1
+  AND cardinality(flags) > 0
+  AND ($2::text = '' OR $2::text = ANY(flags))
+ORDER BY txn_date DESC
+LIMIT $3 OFFSET $4;
+
+-- name: CountFlaggedTransactions :one
+SELECT COUNT(*) FROM transactions
+WHERE user_id = $1
+  AND cardinality(flags) > 0
+  AND ($2::text = '' OR $2::text = ANY(flags));
+
+-- name: UpsertTransactionPreservingReview :one
+-- Refreshes the parse-derived fields of a re-imported transaction.
+-- Reviewed transactions keep their manually set category; the row moves to the latest upload.
+UPDATE transactions
+SET txn_date = $3,
+    description = $4,
+    amount = $5,
+    txn_type = $6,
+    raw_data = $7,
+    source = COALESCE($8, source),
+    flags = $9,
+    category = CASE WHEN is_reviewed THEN category ELSE NULLIF($10::text, '') END,
+    reference_no = $11,
+    category_score = CASE WHEN is_reviewed THEN category_score ELSE NULLIF($12::float8, 0) END,
+    tags = ARRAY(SELECT DISTINCT tag FROM unnest(tags || $13::text[]) AS tag ORDER BY tag),
+    upload_id = COALESCE($14, upload_id),
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
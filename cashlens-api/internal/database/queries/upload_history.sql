@@ -1,7 +1,7 @@
 -- SQLC queries for upload_history table
 
 -- name: CreateUploadHistory :one
--- Create a new upload history record
+-- Create a new upload history record; records created as processing start the clock
 INSERT INTO upload_history (
     user_id,
     filename,
@@ -10,9 +10,11 @@ INSERT INTO upload_history (
     file_hash,
     bank_type,
     status,
-    total_rows
+    total_rows,
+    processing_started_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8,
+    CASE WHEN $7 = 'processing' THEN NOW() END
 )
 RETURNING *;
 
@@ -28,6 +30,27 @@ SELECT * FROM upload_history
 WHERE id = $1 AND user_id = $2
 LIMIT 1;
 
+-- name: SaveUploadResult :exec
+-- Store the summary, warnings and issues of a processed upload
+INSERT INTO upload_results (
+    upload_id,
+    summary,
+    warnings,
+    issues
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (upload_id) DO UPDATE
+SET summary = EXCLUDED.summary,
+    warnings = EXCLUDED.warnings,
+    issues = EXCLUDED.issues;
+
+-- name: GetUploadResult :one
+-- Get the stored summary, warnings and issues of a processed upload
+SELECT * FROM upload_results
+WHERE upload_id = $1
+LIMIT 1;
+
 -- name: GetUserUploadHistory :many
 -- Get paginated upload history for a user
 SELECT * FROM upload_history
@@ -61,6 +84,15 @@ WHERE user_id = $1 AND file_hash = $2
 ORDER BY created_at DESC
 LIMIT 1;
 
+-- name: GetCompletedUploadByFileKey :one
+-- Get the stored result of the latest completed upload of a file (idempotent reprocessing)
+SELECT uh.id, ur.summary
+FROM upload_history uh
+JOIN upload_results ur ON ur.upload_id = uh.id
+WHERE uh.user_id = $1 AND uh.file_key = $2 AND uh.status = 'completed'
+ORDER BY uh.created_at DESC
+LIMIT 1;
+
 -- name: UpdateUploadStatus :one
 -- Update upload status (for state transitions)
 UPDATE upload_history
@@ -142,7 +174,7 @@ ORDER BY processing_started_at ASC;
 DELETE FROM upload_history
 WHERE id = $1;
 
--- name: DeleteUserUploadHistory :exec
+-- name: DeleteUserUploadHistory :execrows
 -- Delete all upload history for a user
 DELETE FROM upload_history
 WHERE user_id = $1;
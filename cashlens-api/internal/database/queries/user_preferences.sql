@@ -0,0 +1,13 @@
+-- name: GetUserPreferences :one
+SELECT * FROM user_preferences
+WHERE user_id = $1;
+
+-- name: UpsertUserPreferences :one
+INSERT INTO user_preferences (user_id, currency, timezone, week_start)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id) DO UPDATE
+SET currency = EXCLUDED.currency,
+    timezone = EXCLUDED.timezone,
+    week_start = EXCLUDED.week_start,
+    updated_at = NOW()
+RETURNING *;
@@ -9,14 +9,29 @@ WHERE id = $1
 LIMIT 1;
 
 -- name: CreateUser :one
+-- Creates a user, or refreshes the email and name of an existing Clerk user
+-- (webhooks may be redelivered).
 INSERT INTO users (id, clerk_user_id, email, full_name)
 VALUES ($1, $2, $3, $4)
+ON CONFLICT (clerk_user_id) DO UPDATE
+SET email = EXCLUDED.email,
+    full_name = EXCLUDED.full_name,
+    updated_at = NOW()
 RETURNING *;
 
--- name: UpdateUser :one
+-- name: UpdateUserByClerkID :one
 UPDATE users
 SET email = $2,
     full_name = $3,
     updated_at = NOW()
 WHERE clerk_user_id = $1
 RETURNING *;
+
+-- name: DeleteUser :execrows
+-- Rows in other tables referencing the user are removed by ON DELETE CASCADE
+DELETE FROM users
+WHERE id = $1;
+
+-- name: ListUserIDs :many
+SELECT id FROM users
+ORDER BY created_at ASC;
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// Reparser interface defines methods for re-parsing stored transactions in batches
+type Reparser interface {
+	ReparseBatch(ctx context.Context, opts models.ReparseOptions) (models.ReparseResult, error)
+}
+
+// DigestGenerator interface defines methods for composing periodic user digests
+type DigestGenerator interface {
+	GenerateAllDigests(ctx context.Context, period string) ([]models.Digest, error)
+}
+
+// AdminHandler handles maintenance operations that span all users
+type AdminHandler struct {
+	stats   StatsService
+	reparse Reparser
+	digests DigestGenerator
+}
+
+// NewAdminHandler creates a new admin handler instance
+func NewAdminHandler(stats StatsService) *AdminHandler {
+	return &AdminHandler{
+		stats: stats,
+	}
+}
+
+// SetReparseService enables the transaction reparse endpoint
+func (h *AdminHandler) SetReparseService(reparse Reparser) {
+	h.reparse = reparse
+}
+
+// SetDigestService enables the digest generation endpoint
+func (h *AdminHandler) SetDigestService(digests DigestGenerator) {
+	h.digests = digests
+}
+
+// RecomputeAllStats recomputes cached stats for every user
+// POST /v1/internal/stats/recompute
+func (h *AdminHandler) RecomputeAllStats(c fiber.Ctx) error {
+	recomputed, err := h.stats.RecomputeAllUserStats(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":            "failed to recompute stats",
+			"details":          err.Error(),
+			"users_recomputed": recomputed,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"users_recomputed": recomputed,
+		"message":          "stats recomputed successfully",
+	})
+}
+
+// ReparseTransactionsRequest represents the request body for ReparseTransactions
+type ReparseTransactionsRequest struct {
+	Bank      string   `json:"bank"`
+	Headers   []string `json:"headers"`
+	FromDate  string   `json:"from_date"`
+	ToDate    string   `json:"to_date"`
+	Cursor    string   `json:"cursor"`
+	BatchSize int      `json:"batch_size"`
+}
+
+// ReparseTransactions re-parses one batch of stored raw_data and corrects parsed fields
+// POST /v1/internal/transactions/reparse
+// Body: bank and headers (required), from_date/to_date (YYYY-MM-DD), cursor, batch_size
+// Returns progress for the batch; repeat with next_cursor until done is true
+func (h *AdminHandler) ReparseTransactions(c fiber.Ctx) error {
+	if h.reparse == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "reparse service not available",
+		})
+	}
+
+	// 1. Parse request body
+	var req ReparseTransactionsRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	// 2. Validate required fields
+	if req.Bank == "" || len(req.Headers) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "bank and headers are required",
+		})
+	}
+
+	opts := models.ReparseOptions{
+		Bank:      req.Bank,
+		Headers:   req.Headers,
+		BatchSize: req.BatchSize,
+	}
+
+	// 3. Parse optional date range and resume cursor
+	if req.FromDate != "" {
+		from, err := time.Parse("2006-01-02", req.FromDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "invalid from_date format (expected YYYY-MM-DD)",
+				"details": err.Error(),
+			})
+		}
+		opts.From = &from
+	}
+	if req.ToDate != "" {
+		to, err := time.Parse("2006-01-02", req.ToDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "invalid to_date format (expected YYYY-MM-DD)",
+				"details": err.Error(),
+			})
+		}
+		opts.To = &to
+	}
+	if req.Cursor != "" {
+		cursor, err := uuid.Parse(req.Cursor)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "invalid cursor",
+				"details": err.Error(),
+			})
+		}
+		opts.AfterID = cursor
+	}
+
+	// 4. Run the batch
+	result, err := h.reparse.ReparseBatch(c.Context(), opts)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to reparse transactions",
+			"details": err.Error(),
+		})
+	}
+
+	// Reparsed rows may belong to any user, so drop every cached stats entry
+	if result.Updated > 0 && h.stats != nil {
+		h.stats.InvalidateAllUserStats()
+	}
+
+	fmt.Printf("Reparse batch: scanned=%d updated=%d failed=%d next_cursor=%s done=%v\n",
+		result.Scanned, result.Updated, result.Failed, result.NextCursor, result.Done)
+
+	return c.JSON(result)
+}
+
+// GenerateDigests composes the weekly or monthly digest for every user. Nothing is
+// emailed; the payloads are returned for a mailer (or cron job) to send.
+// POST /v1/internal/digests?period=weekly|monthly
+func (h *AdminHandler) GenerateDigests(c fiber.Ctx) error {
+	if h.digests == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "digest service not available",
+		})
+	}
+
+	// 1. Validate period
+	period := c.Query("period", models.DigestPeriodWeekly)
+	if !models.ValidDigestPeriod(period) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid period - must be 'weekly' or 'monthly'",
+		})
+	}
+
+	// 2. Generate digests
+	digests, err := h.digests.GenerateAllDigests(c.Context(), period)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to generate digests",
+			"details": err.Error(),
+		})
+	}
+
+	// 3. Return response
+	return c.JSON(fiber.Map{
+		"period":  period,
+		"count":   len(digests),
+		"digests": digests,
+	})
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// DefaultPDFHealthTimeout bounds the PDF service probe so readiness stays fast
+const DefaultPDFHealthTimeout = 2 * time.Second
+
+// DefaultDatabaseHealthTimeout bounds the database ping, so a hung connection attempt
+// reports unhealthy instead of stalling the load balancer's probe
+const DefaultDatabaseHealthTimeout = 2 * time.Second
+
+// Readiness statuses reported by /health/ready
+const (
+	ReadinessOK        = "ok"
+	ReadinessDegraded  = "degraded"
+	ReadinessUnhealthy = "unhealthy"
+)
+
+// Pinger interface defines the database connectivity check (satisfied by *pgxpool.Pool)
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// HealthHandler reports whether the API and its dependencies can serve traffic
+type HealthHandler struct {
+	db            Pinger
+	dbTimeout     time.Duration
+	pdfServiceURL string
+	httpClient    *http.Client
+}
+
+// NewHealthHandler creates a new health handler instance
+func NewHealthHandler(db Pinger) *HealthHandler {
+	return &HealthHandler{
+		db:        db,
+		dbTimeout: DefaultDatabaseHealthTimeout,
+	}
+}
+
+// SetDatabaseTimeout sets how long the database ping may take before it counts as down
+func (h *HealthHandler) SetDatabaseTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultDatabaseHealthTimeout
+	}
+	h.dbTimeout = timeout
+}
+
+// SetPDFService enables the PDF microservice probe; an empty URL leaves it unreported
+func (h *HealthHandler) SetPDFService(url string, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultPDFHealthTimeout
+	}
+	h.pdfServiceURL = strings.TrimRight(url, "/")
+	h.httpClient = &http.Client{Timeout: timeout}
+}
+
+// GetReadiness checks each dependency and reports overall readiness
+// GET /health/ready
+//
+// The database is required, so a failed or timed-out ping returns 503. The PDF service only
+// backs PDF uploads (CSV/XLSX still work without it), so when it is down the
+// status is "degraded" with 200.
+func (h *HealthHandler) GetReadiness(c fiber.Ctx) error {
+	dependencies := fiber.Map{}
+	status := ReadinessOK
+
+	// 1. Database
+	if err := h.pingDatabase(c.Context()); err != nil {
+		dependencies["database"] = fiber.Map{"status": "down", "details": err.Error()}
+		status = ReadinessUnhealthy
+	} else {
+		dependencies["database"] = fiber.Map{"status": "up"}
+	}
+
+	// 2. PDF service (only when configured)
+	if h.pdfServiceURL != "" {
+		if err := h.pingPDFService(c.Context()); err != nil {
+			dependencies["pdf_service"] = fiber.Map{"status": "down", "details": err.Error()}
+			if status == ReadinessOK {
+				status = ReadinessDegraded
+			}
+		} else {
+			dependencies["pdf_service"] = fiber.Map{"status": "up"}
+		}
+	}
+
+	code := fiber.StatusOK
+	if status == ReadinessUnhealthy {
+		code = fiber.StatusServiceUnavailable
+	}
+
+	return c.Status(code).JSON(fiber.Map{
+		"status":       status,
+		"service":      "cashlens-api",
+		"dependencies": dependencies,
+	})
+}
+
+// pingDatabase pings the database within the configured timeout
+func (h *HealthHandler) pingDatabase(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, h.dbTimeout)
+	defer cancel()
+	return h.db.Ping(ctx)
+}
+
+// pingPDFService calls GET <pdfServiceURL>/health and expects a 2xx response
+func (h *HealthHandler) pingPDFService(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.pdfServiceURL+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePinger struct {
+	err error
+}
+
+func (p fakePinger) Ping(ctx context.Context) error {
+	return p.err
+}
+
+// hangingPinger blocks until the ping's context is done, like an unreachable database host
+type hangingPinger struct{}
+
+func (hangingPinger) Ping(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// TestGetReadiness tests dependency reporting for the database and the optional PDF service
+func TestGetReadiness(t *testing.T) {
+	healthyPDF := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/health", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthyPDF.Close()
+
+	unhealthyPDF := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer unhealthyPDF.Close()
+
+	slowPDF := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slowPDF.Close()
+
+	testCases := []struct {
+		name         string
+		dbErr        error
+		pdfURL       string
+		expectedCode int
+		expected     string
+		database     string
+		pdf          string // empty means not reported
+	}{
+		{"No PDF configured", nil, "", fiber.StatusOK, ReadinessOK, "up", ""},
+		{"PDF healthy", nil, healthyPDF.URL, fiber.StatusOK, ReadinessOK, "up", "up"},
+		{"PDF unhealthy", nil, unhealthyPDF.URL, fiber.StatusOK, ReadinessDegraded, "up", "down"},
+		{"PDF timeout", nil, slowPDF.URL, fiber.StatusOK, ReadinessDegraded, "up", "down"},
+		{"PDF unreachable", nil, "http://127.0.0.1:1", fiber.StatusOK, ReadinessDegraded, "up", "down"},
+		{"Database down", errors.New("connection refused"), healthyPDF.URL, fiber.StatusServiceUnavailable, ReadinessUnhealthy, "down", "up"},
+		{"Database and PDF down", errors.New("connection refused"), unhealthyPDF.URL, fiber.StatusServiceUnavailable, ReadinessUnhealthy, "down", "down"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewHealthHandler(fakePinger{err: tc.dbErr})
+			handler.SetPDFService(tc.pdfURL, 50*time.Millisecond)
+
+			app := fiber.New()
+			app.Get("/health/ready", handler.GetReadiness)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/health/ready", nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			var result struct {
+				Status       string `json:"status"`
+				Dependencies map[string]struct {
+					Status string `json:"status"`
+				} `json:"dependencies"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+			assert.Equal(t, tc.expected, result.Status)
+			assert.Equal(t, tc.database, result.Dependencies["database"].Status)
+			pdf, reported := result.Dependencies["pdf_service"]
+			if tc.pdf == "" {
+				assert.False(t, reported)
+			} else {
+				require.True(t, reported)
+				assert.Equal(t, tc.pdf, pdf.Status)
+			}
+		})
+	}
+}
+
+// TestGetReadiness_DatabaseTimeout tests that a hung database ping is cut short and reported as down
+func TestGetReadiness_DatabaseTimeout(t *testing.T) {
+	handler := NewHealthHandler(hangingPinger{})
+	handler.SetDatabaseTimeout(50 * time.Millisecond)
+
+	app := fiber.New()
+	app.Get("/health/ready", handler.GetReadiness)
+
+	start := time.Now()
+	resp, err := app.Test(httptest.NewRequest("GET", "/health/ready", nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+
+	var result struct {
+		Status       string `json:"status"`
+		Dependencies map[string]struct {
+			Status  string `json:"status"`
+			Details string `json:"details"`
+		} `json:"dependencies"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, ReadinessUnhealthy, result.Status)
+	assert.Equal(t, "down", result.Dependencies["database"].Status)
+	assert.Contains(t, result.Dependencies["database"].Details, "deadline exceeded")
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// DefaultMaxPageSize caps the limit query parameter of list endpoints unless configured otherwise
+const DefaultMaxPageSize = 100
+
+// pageLimit reads the limit query parameter. A missing, non-numeric or non-positive
+// limit uses defaultLimit; larger limits are clamped to maxLimit (DefaultMaxPageSize
+// when maxLimit is not positive), so every list endpoint treats them the same way.
+func pageLimit(c fiber.Ctx, defaultLimit, maxLimit int) int {
+	if maxLimit <= 0 {
+		maxLimit = DefaultMaxPageSize
+	}
+	limit := defaultLimit
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return limit
+}
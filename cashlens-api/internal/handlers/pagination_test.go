@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPageLimit tests defaults and clamping of the limit query parameter
+func TestPageLimit(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    string
+		maxLimit int
+		expected int
+	}{
+		{"Missing uses default", "", 100, 20},
+		{"Within range", "?limit=30", 100, 30},
+		{"Clamped to max", "?limit=500", 100, 100},
+		{"Clamped to configured max", "?limit=30", 25, 25},
+		{"Default clamped to configured max", "", 10, 10},
+		{"Zero uses default", "?limit=0", 100, 20},
+		{"Negative uses default", "?limit=-5", 100, 20},
+		{"Non-numeric uses default", "?limit=all", 100, 20},
+		{"Unset max uses DefaultMaxPageSize", "?limit=500", 0, DefaultMaxPageSize},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := fiber.New()
+			var got int
+			app.Get("/", func(c fiber.Ctx) error {
+				got = pageLimit(c, 20, tc.maxLimit)
+				return nil
+			})
+
+			_, err := app.Test(httptest.NewRequest("GET", "/"+tc.query, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+// TestMaxPageSize_EnforcedUniformly tests that every list endpoint clamps limit to its configured max
+func TestMaxPageSize_EnforcedUniformly(t *testing.T) {
+	userID := uuid.New()
+	const maxPageSize = 5
+
+	// Each list query records the limit it was called with (its only int32 argument before offset)
+	limits := make(map[string]int32)
+	recordLimit := func(query string) func(args []interface{}) [][]interface{} {
+		return func(args []interface{}) [][]interface{} {
+			for _, arg := range args {
+				if limit, ok := arg.(int32); ok {
+					limits[query] = limit
+					break
+				}
+			}
+			return [][]interface{}{}
+		}
+	}
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetUserTransactions":      recordLimit("GetUserTransactions"),
+		"SearchUserTransactions":   recordLimit("SearchUserTransactions"),
+		"GetFlaggedTransactions":   recordLimit("GetFlaggedTransactions"),
+		"SearchUserRulesByKeyword": recordLimit("SearchUserRulesByKeyword"),
+		"SearchRulesByKeyword":     recordLimit("SearchRulesByKeyword"),
+		"GetUserUploadHistory":     recordLimit("GetUserUploadHistory"),
+		"CountUserTransactions":    func(args []interface{}) [][]interface{} { return [][]interface{}{{int64(0)}} },
+		"CountFlaggedTransactions": func(args []interface{}) [][]interface{} { return [][]interface{}{{int64(0)}} },
+		"CountSearchUserTransactions": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{{int64(0)}}
+		},
+	}}
+	queries := db.New(fake)
+
+	transactionHandler := NewTransactionHandler(queries, nil)
+	transactionHandler.SetMaxPageSize(maxPageSize)
+	rulesHandler := NewRulesHandler(queries, nil)
+	rulesHandler.SetMaxPageSize(maxPageSize)
+	uploadHandler := NewUploadHandlerFull(nil, nil, nil, queries)
+	uploadHandler.SetMaxPageSize(maxPageSize)
+
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		c.Locals("user_id", userID.String())
+		return c.Next()
+	})
+	app.Get("/transactions", transactionHandler.GetTransactions)
+	app.Get("/transactions/search", transactionHandler.SearchTransactions)
+	app.Get("/transactions/issues", transactionHandler.GetTransactionIssues)
+	app.Get("/rules/search", rulesHandler.SearchRules)
+	app.Get("/upload/history", uploadHandler.GetUploadHistory)
+
+	testCases := []struct {
+		name    string
+		path    string
+		queries []string
+	}{
+		{"Transactions", "/transactions?limit=50", []string{"GetUserTransactions"}},
+		{"Transaction search", "/transactions/search?q=aws&limit=50", []string{"SearchUserTransactions"}},
+		{"Transaction issues", "/transactions/issues?limit=50", []string{"GetFlaggedTransactions"}},
+		{"Rule search", "/rules/search?q=aws&limit=50", []string{"SearchUserRulesByKeyword", "SearchRulesByKeyword"}},
+		{"Upload history", "/upload/history?limit=50", []string{"GetUserUploadHistory"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clear(limits)
+
+			resp, err := app.Test(httptest.NewRequest("GET", tc.path, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			for _, query := range tc.queries {
+				assert.Equal(t, int32(maxPageSize), limits[query], query)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/gofiber/fiber/v3"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// weekdays maps week_start values to time.Weekday
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// currencyCodePattern matches ISO 4217 currency codes
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// PreferencesHandler handles a user's display preferences
+type PreferencesHandler struct {
+	db *db.Queries
+}
+
+// NewPreferencesHandler creates a new preferences handler instance
+func NewPreferencesHandler(database *db.Queries) *PreferencesHandler {
+	return &PreferencesHandler{db: database}
+}
+
+// UpdatePreferencesRequest represents the request body for UpdatePreferences.
+// Omitted fields keep their current value.
+type UpdatePreferencesRequest struct {
+	Currency  *string `json:"currency"`
+	Timezone  *string `json:"timezone"`
+	WeekStart *string `json:"week_start"`
+}
+
+// loadUserPreferences returns a user's saved preferences, or the defaults when none are saved
+func loadUserPreferences(ctx context.Context, queries *db.Queries, userID pgtype.UUID) (models.UserPreferences, error) {
+	row, err := queries.GetUserPreferences(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.DefaultUserPreferences(), nil
+	}
+	if err != nil {
+		return models.UserPreferences{}, err
+	}
+	return models.UserPreferences{
+		Currency:  row.Currency,
+		Timezone:  row.Timezone,
+		WeekStart: row.WeekStart,
+	}, nil
+}
+
+// location returns the time zone of the preferences, falling back to the default zone
+// and then UTC if it cannot be loaded
+func location(prefs models.UserPreferences) *time.Location {
+	for _, name := range []string{prefs.Timezone, models.DefaultTimezone} {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// weekOffset returns the GetCashFlowTrend week_offset that starts weeks on weekStart
+// (Postgres weeks start on Monday)
+func weekOffset(weekStart string) int32 {
+	day, ok := weekdays[weekStart]
+	if !ok {
+		return 0
+	}
+	return int32((int(time.Monday) - int(day) + 7) % 7)
+}
+
+// GetPreferences returns the user's preferences, or the defaults when none are saved
+// GET /v1/user/preferences
+func (h *PreferencesHandler) GetPreferences(c fiber.Ctx) error {
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 2. Look up user
+	user, err := h.db.GetUserByClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	// 3. Load preferences
+	prefs, err := loadUserPreferences(c.Context(), h.db, user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to get preferences",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(prefs)
+}
+
+// UpdatePreferences saves the user's currency, timezone and week start
+// PUT /v1/user/preferences
+// Body: {"currency": "INR", "timezone": "Asia/Kolkata", "week_start": "monday"}
+func (h *PreferencesHandler) UpdatePreferences(c fiber.Ctx) error {
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 2. Parse request body
+	var req UpdatePreferencesRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	// 3. Look up user and current preferences
+	user, err := h.db.GetUserByClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+	prefs, err := loadUserPreferences(c.Context(), h.db, user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to get preferences",
+			"details": err.Error(),
+		})
+	}
+
+	// 4. Validate and apply the provided fields
+	if req.Currency != nil {
+		currency := strings.ToUpper(strings.TrimSpace(*req.Currency))
+		if !currencyCodePattern.MatchString(currency) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid currency - must be a 3-letter ISO 4217 code such as INR",
+			})
+		}
+		prefs.Currency = currency
+	}
+	if req.Timezone != nil {
+		timezone := strings.TrimSpace(*req.Timezone)
+		if _, err := time.LoadLocation(timezone); err != nil || timezone == "" || strings.EqualFold(timezone, "local") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid timezone - must be an IANA name such as Asia/Kolkata",
+			})
+		}
+		prefs.Timezone = timezone
+	}
+	if req.WeekStart != nil {
+		weekStart := strings.ToLower(strings.TrimSpace(*req.WeekStart))
+		if _, ok := weekdays[weekStart]; !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid week_start - must be a weekday name such as monday",
+			})
+		}
+		prefs.WeekStart = weekStart
+	}
+
+	// 5. Save preferences
+	saved, err := h.db.UpsertUserPreferences(c.Context(), db.UpsertUserPreferencesParams{
+		UserID:    user.ID,
+		Currency:  prefs.Currency,
+		Timezone:  prefs.Timezone,
+		WeekStart: prefs.WeekStart,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to save preferences",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(models.UserPreferences{
+		Currency:  saved.Currency,
+		Timezone:  saved.Timezone,
+		WeekStart: saved.WeekStart,
+	})
+}
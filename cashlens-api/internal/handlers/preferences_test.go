@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPreferencesTestApp serves the preferences routes over a single user's in-memory preferences row
+func newPreferencesTestApp(userID uuid.UUID, saved *db.UserPreference) *fiber.App {
+	row := func(prefs db.UserPreference) []interface{} {
+		return []interface{}{prefs.UserID, prefs.Currency, prefs.Timezone, prefs.WeekStart, prefs.CreatedAt, prefs.UpdatedAt}
+	}
+
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetUserPreferences": func(args []interface{}) [][]interface{} {
+			if saved == nil {
+				return nil
+			}
+			return [][]interface{}{row(*saved)}
+		},
+		"UpsertUserPreferences": func(args []interface{}) [][]interface{} {
+			saved = &db.UserPreference{
+				UserID:    args[0].(pgtype.UUID),
+				Currency:  args[1].(string),
+				Timezone:  args[2].(string),
+				WeekStart: args[3].(string),
+			}
+			return [][]interface{}{row(*saved)}
+		},
+	}}
+	handler := NewPreferencesHandler(db.New(fake))
+
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return c.Next()
+	})
+	app.Get("/user/preferences", handler.GetPreferences)
+	app.Put("/user/preferences", handler.UpdatePreferences)
+	return app
+}
+
+func preferencesRequest(t *testing.T, app *fiber.App, method, body string) (int, models.UserPreferences) {
+	req := httptest.NewRequest(method, "/user/preferences", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var prefs models.UserPreferences
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&prefs))
+	return resp.StatusCode, prefs
+}
+
+// TestPreferencesHandler tests the defaults, partial updates and validation
+func TestPreferencesHandler(t *testing.T) {
+	app := newPreferencesTestApp(uuid.New(), nil)
+
+	status, prefs := preferencesRequest(t, app, "GET", "")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, models.DefaultUserPreferences(), prefs)
+
+	// Omitted fields keep their current value
+	status, prefs = preferencesRequest(t, app, "PUT", `{"currency":"usd","week_start":"Sunday"}`)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, models.UserPreferences{Currency: "USD", Timezone: "Asia/Kolkata", WeekStart: "sunday"}, prefs)
+
+	status, prefs = preferencesRequest(t, app, "PUT", `{"timezone":"America/New_York"}`)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, models.UserPreferences{Currency: "USD", Timezone: "America/New_York", WeekStart: "sunday"}, prefs)
+
+	status, prefs = preferencesRequest(t, app, "GET", "")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "America/New_York", prefs.Timezone)
+
+	for _, body := range []string{
+		`{"currency":"RUPEES"}`,
+		`{"timezone":"Mars/Olympus"}`,
+		`{"timezone":"Local"}`,
+		`{"week_start":"funday"}`,
+	} {
+		status, _ := preferencesRequest(t, app, "PUT", body)
+		assert.Equal(t, fiber.StatusBadRequest, status, body)
+	}
+}
+
+func TestWeekOffset(t *testing.T) {
+	assert.Equal(t, int32(0), weekOffset("monday"))
+	assert.Equal(t, int32(1), weekOffset("sunday"))
+	assert.Equal(t, int32(2), weekOffset("saturday"))
+	assert.Equal(t, int32(6), weekOffset("tuesday"))
+	assert.Equal(t, int32(0), weekOffset("unknown"))
+}
+
+func TestFormatPeriodTimestamp_UsesTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// Truncated periods are wall-clock dates and must not shift a day when read in a zone behind UTC
+	ts := pgtype.Timestamp{Time: time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC), Valid: true}
+	assert.Equal(t, "2024-03-03", formatPeriodTimestamp(ts, "week", loc))
+	assert.Equal(t, "2024-03", formatPeriodTimestamp(ts, "month", loc))
+}
+
+// TestGetSummary_WeekStart tests that weekly trends are grouped from the saved week start
+func TestGetSummary_WeekStart(t *testing.T) {
+	userID := uuid.New()
+	var weekOffsetArg int32
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetUserPreferences": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{{args[0], "USD", "UTC", "sunday", pgtype.Timestamptz{}, pgtype.Timestamptz{}}}
+		},
+		"GetKPIs": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{{interface{}(0.0), interface{}(0.0), interface{}(0.0), int64(0)}}
+		},
+		"GetCashFlowTrend": func(args []interface{}) [][]interface{} {
+			weekOffsetArg = args[4].(int32)
+			return nil
+		},
+	}}
+	handler := NewSummaryHandler(db.New(fake))
+
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals("user_id", "user_test123")
+		return c.Next()
+	})
+	app.Get("/summary", handler.GetSummary)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/summary?from=2024-03-01&to=2024-03-31&group_by=week", nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(1), weekOffsetArg)
+
+	var summary SummaryResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&summary))
+	assert.Equal(t, "USD", summary.Currency)
+}
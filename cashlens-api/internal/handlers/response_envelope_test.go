@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/ashmitsharp/cashlens-api/internal/middleware"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEnvelopeTestApp routes the summary and issues endpoints through the response envelope
+func newEnvelopeTestApp(t *testing.T) *fiber.App {
+	userID := uuid.New()
+	flagged := newTestTransaction(t, "ZERO AMOUNT", 0, "zero_amount")
+
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetKPIs": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{{interface{}(50000.0), interface{}(3500.0), interface{}(46500.0), int64(2)}}
+		},
+		"GetCashFlowTrend": func(args []interface{}) [][]interface{} {
+			period := pgtype.Timestamp{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+			return [][]interface{}{{period, interface{}(50000.0), interface{}(3500.0), interface{}(46500.0)}}
+		},
+		"GetFlaggedTransactions": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{transactionRow(flagged)}
+		},
+		"CountFlaggedTransactions": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{{int64(1)}}
+		},
+	}}
+	queries := db.New(fake)
+	summaryHandler := NewSummaryHandler(queries)
+	transactionHandler := NewTransactionHandler(queries, nil)
+
+	app := fiber.New()
+	v1 := app.Group("/v1", middleware.ResponseEnvelope(), func(c fiber.Ctx) error {
+		c.Locals("user_id", "user_test123")
+		c.Locals("clerk_user_id", "user_test123")
+		return c.Next()
+	})
+	v1.Get("/summary", summaryHandler.GetSummary)
+	v1.Get("/transactions/issues", transactionHandler.GetTransactionIssues)
+	return app
+}
+
+// TestResponseEnvelope_WrapsSuccessfulResponses tests that bare objects and ad-hoc maps share the {success, data} shape
+func TestResponseEnvelope_WrapsSuccessfulResponses(t *testing.T) {
+	app := newEnvelopeTestApp(t)
+
+	t.Run("Summary", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/v1/summary?from=2024-01-01&to=2024-01-31", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var result struct {
+			Success bool            `json:"success"`
+			Data    SummaryResponse `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+		assert.True(t, result.Success)
+		assert.Equal(t, int64(2), result.Data.KPIs.TransactionCount)
+		assert.Equal(t, "2024-01-01", result.Data.FromDate.String())
+	})
+
+	t.Run("Issues", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/v1/transactions/issues", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var result struct {
+			Success bool `json:"success"`
+			Data    struct {
+				Transactions []db.Transaction `json:"transactions"`
+				Total        int64            `json:"total"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+		assert.True(t, result.Success)
+		assert.Equal(t, int64(1), result.Data.Total)
+		require.Len(t, result.Data.Transactions, 1)
+		assert.Equal(t, "ZERO AMOUNT", result.Data.Transactions[0].Description)
+	})
+}
+
+// TestResponseEnvelope_PassesErrorsThrough tests that error responses keep their original shape
+func TestResponseEnvelope_PassesErrorsThrough(t *testing.T) {
+	app := newEnvelopeTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/v1/transactions/issues?flag=bogus", nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.NotContains(t, result, "success")
+	assert.Contains(t, result, "error")
+}
@@ -1,18 +1,67 @@
 package handlers
 
 import (
-	"strconv"
+	"context"
+	"fmt"
+	"regexp"
+	"time"
 
 	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/ashmitsharp/cashlens-api/internal/models"
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// RulePreviewer interface defines methods for evaluating proposed rules without saving them
+type RulePreviewer interface {
+	PreviewRuleImpact(ctx context.Context, userID uuid.UUID, transactions []models.Transaction, proposed []models.ProposedRule) ([]models.CategoryChange, error)
+}
+
+// RuleRemovalPreviewer is implemented by rule previewers that can also simulate deleting a rule
+type RuleRemovalPreviewer interface {
+	PreviewRuleRemoval(ctx context.Context, userID, ruleID uuid.UUID, transactions []models.Transaction) ([]models.CategoryChange, bool, error)
+}
+
+// EffectiveRuleLister is implemented by categorizers that can list the ordered rules they evaluate for a user
+type EffectiveRuleLister interface {
+	EffectiveRules(ctx context.Context, userID uuid.UUID) ([]models.EffectiveRule, error)
+}
+
+// RuleTester interface defines methods for running a single unsaved rule against transactions
+type RuleTester interface {
+	TestRule(rule models.ProposedRule, transactions []models.Transaction) []models.RuleTestMatch
+}
+
+// Sample sizes for the rule test endpoint (most recent transactions first)
+const (
+	DefaultRuleTestSampleSize = 200
+	MaxRuleTestSampleSize     = 1000
+)
+
+// Default bounds for user rule priorities. The floor sits above the highest seeded
+// global rule priority (10) so user rules keep winning over global ones.
+const (
+	DefaultUserRulePriority    int32 = 100
+	DefaultUserRulePriorityMin int32 = 11
+	DefaultUserRulePriorityMax int32 = 1000
+)
+
+// Policies for user rule priorities outside the configured bounds
+const (
+	PriorityPolicyClamp  = "clamp"  // Move the priority to the nearest bound
+	PriorityPolicyReject = "reject" // Fail the request with 400
+)
+
 // RulesHandler handles categorization rule management
 type RulesHandler struct {
 	db          *db.Queries
 	categorizer Categorizer
+	previewer   RulePreviewer
+	tester      RuleTester
+	priorities  priorityBounds
+	categories  CategoryWhitelist
+	maxPageSize int // Largest limit the rule search returns (0 = DefaultMaxPageSize)
 }
 
 // NewRulesHandler creates a new rules handler instance
@@ -20,25 +69,171 @@ func NewRulesHandler(database *db.Queries, categorizer Categorizer) *RulesHandle
 	return &RulesHandler{
 		db:          database,
 		categorizer: categorizer,
+		priorities:  defaultPriorityBounds(),
+	}
+}
+
+// SetPriorityBounds sets the allowed user rule priority range and what happens to
+// priorities outside it (PriorityPolicyClamp or PriorityPolicyReject)
+func (h *RulesHandler) SetPriorityBounds(minPriority, maxPriority int32, policy string) {
+	h.priorities = newPriorityBounds(minPriority, maxPriority, policy)
+}
+
+// boundPriority applies the priority bounds to a user rule priority, defaulting 0
+// to DefaultUserRulePriority
+func (h *RulesHandler) boundPriority(priority int32) (int32, error) {
+	if priority == 0 {
+		priority = DefaultUserRulePriority
+	}
+	return h.priorities.bound(priority)
+}
+
+// priorityBounds is the allowed user rule priority range, shared by every handler
+// that creates user rules
+type priorityBounds struct {
+	min    int32
+	max    int32
+	policy string
+}
+
+// newPriorityBounds normalizes a configured range and policy
+func newPriorityBounds(minPriority, maxPriority int32, policy string) priorityBounds {
+	if minPriority > maxPriority {
+		minPriority, maxPriority = maxPriority, minPriority
 	}
+	if policy != PriorityPolicyReject {
+		policy = PriorityPolicyClamp
+	}
+	return priorityBounds{min: minPriority, max: maxPriority, policy: policy}
+}
+
+// defaultPriorityBounds returns the bounds used until SetPriorityBounds is called
+func defaultPriorityBounds() priorityBounds {
+	return newPriorityBounds(DefaultUserRulePriorityMin, DefaultUserRulePriorityMax, PriorityPolicyClamp)
+}
+
+// bound applies the policy to a requested priority
+func (b priorityBounds) bound(priority int32) (int32, error) {
+	if b.policy == PriorityPolicyReject && (priority < b.min || priority > b.max) {
+		return 0, fmt.Errorf("priority must be between %d and %d", b.min, b.max)
+	}
+	return b.clamp(priority), nil
+}
+
+// clamp moves a priority into the range regardless of policy. It is meant for
+// priorities the server picks itself, which the user cannot correct.
+func (b priorityBounds) clamp(priority int32) int32 {
+	if priority < b.min {
+		return b.min
+	}
+	if priority > b.max {
+		return b.max
+	}
+	return priority
+}
+
+// SetCategoryWhitelist restricts rule categories to a controlled taxonomy
+func (h *RulesHandler) SetCategoryWhitelist(categories CategoryWhitelist) {
+	h.categories = categories
+}
+
+// SetMaxPageSize sets the largest limit the rule search accepts; larger limits are clamped
+func (h *RulesHandler) SetMaxPageSize(size int) {
+	h.maxPageSize = size
+}
+
+// SetRulePreviewer enables the rule impact dry-run endpoint
+func (h *RulesHandler) SetRulePreviewer(previewer RulePreviewer) {
+	h.previewer = previewer
+}
+
+// SetRuleTester enables the single-rule test endpoint
+func (h *RulesHandler) SetRuleTester(tester RuleTester) {
+	h.tester = tester
+}
+
+// getUserUUIDFromClerkID looks up the user's database UUID from their Clerk ID
+func (h *RulesHandler) getUserUUIDFromClerkID(ctx context.Context, clerkUserID string) (uuid.UUID, error) {
+	user, err := h.db.GetUserByClerkID(ctx, clerkUserID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	var userUUID uuid.UUID
+	copy(userUUID[:], user.ID.Bytes[:])
+	return userUUID, nil
 }
 
 // CreateRuleRequest represents the request body for creating a rule
 type CreateRuleRequest struct {
-	Keyword             string  `json:"keyword" validate:"required"`
-	Category            string  `json:"category" validate:"required"`
-	Priority            int32   `json:"priority"`
-	MatchType           string  `json:"match_type"` // substring, regex, exact, fuzzy
-	SimilarityThreshold float64 `json:"similarity_threshold"`
+	Keyword             string   `json:"keyword" validate:"required"`
+	Keywords            []string `json:"keywords,omitempty"` // Multi-keyword rules; replaces keyword
+	Category            string   `json:"category" validate:"required"`
+	Priority            int32    `json:"priority"`
+	MatchType           string   `json:"match_type"` // substring, regex, exact, fuzzy, any, all
+	SimilarityThreshold float64  `json:"similarity_threshold"`
+	MinAmount           *float64 `json:"min_amount,omitempty"` // Only match absolute amounts at or above this
+	MaxAmount           *float64 `json:"max_amount,omitempty"` // Only match absolute amounts at or below this
+	Tags                []string `json:"tags,omitempty"`       // Added to matching transactions on import
+	Direction           string   `json:"direction,omitempty"`  // credit or debit; omitted matches either
+}
+
+// applyKeywords folds a keywords list into the stored keyword.
+// A list without an explicit match type matches any of its keywords.
+func (r *CreateRuleRequest) applyKeywords() {
+	if len(r.Keywords) == 0 {
+		return
+	}
+	r.Keyword = models.JoinKeywords(r.Keywords)
+	if r.MatchType == "" {
+		r.MatchType = models.MatchTypeAny
+	}
 }
 
 // UpdateRuleRequest represents the request body for updating a rule
 type UpdateRuleRequest struct {
-	Category            string  `json:"category"`
-	Priority            int32   `json:"priority"`
-	MatchType           string  `json:"match_type"`
-	SimilarityThreshold float64 `json:"similarity_threshold"`
-	IsActive            bool    `json:"is_active"`
+	Category            string   `json:"category"`
+	Priority            int32    `json:"priority"`
+	MatchType           string   `json:"match_type"`
+	SimilarityThreshold float64  `json:"similarity_threshold"`
+	IsActive            bool     `json:"is_active"`
+	MinAmount           *float64 `json:"min_amount,omitempty"` // Omitted bounds are cleared
+	MaxAmount           *float64 `json:"max_amount,omitempty"`
+	Tags                []string `json:"tags,omitempty"`      // Omitted tags are cleared
+	Direction           string   `json:"direction,omitempty"` // Omitted direction matches either
+}
+
+// validateAmountRange checks optional rule amount bounds
+func validateAmountRange(minAmount, maxAmount *float64) error {
+	if (minAmount != nil && *minAmount < 0) || (maxAmount != nil && *maxAmount < 0) {
+		return fmt.Errorf("min_amount and max_amount must not be negative")
+	}
+	if minAmount != nil && maxAmount != nil && *minAmount > *maxAmount {
+		return fmt.Errorf("min_amount must not be greater than max_amount")
+	}
+	return nil
+}
+
+// validateDirection checks an optional rule direction
+func validateDirection(direction string) error {
+	if !models.ValidDirection(direction) {
+		return fmt.Errorf("invalid direction - must be credit or debit")
+	}
+	return nil
+}
+
+// ruleDirection converts an optional rule direction to a nullable column value
+func ruleDirection(direction string) pgtype.Text {
+	return pgtype.Text{String: direction, Valid: direction != ""}
+}
+
+// amountBound converts an optional rule amount bound to a nullable NUMERIC
+func amountBound(amount *float64) pgtype.Numeric {
+	var n pgtype.Numeric
+	if amount != nil {
+		n.Scan(fmt.Sprintf("%.2f", *amount))
+	}
+	return n
 }
 
 // GetUserRules returns all active rules for the authenticated user
@@ -80,6 +275,57 @@ func (h *RulesHandler) GetUserRules(c fiber.Ctx) error {
 	})
 }
 
+// GetEffectiveRules returns the merged user and global rules in the order the
+// categorizer evaluates them, to explain why a transaction got its category
+// GET /v1/rules/effective
+func (h *RulesHandler) GetEffectiveRules(c fiber.Ctx) error {
+	lister, ok := h.categorizer.(EffectiveRuleLister)
+	if !ok {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "effective rules not available",
+		})
+	}
+
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 2. Look up user's UUID
+	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	// 3. Load the rules through the categorizer cache
+	rules, err := lister.EffectiveRules(c.Context(), userUUID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to load rules",
+			"details": err.Error(),
+		})
+	}
+
+	userCount := 0
+	for _, rule := range rules {
+		if rule.Source == "user" {
+			userCount++
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"rules":        rules,
+		"count":        len(rules),
+		"user_count":   userCount,
+		"global_count": len(rules) - userCount,
+	})
+}
+
 // GetGlobalRules returns all active global rules
 // GET /v1/rules/global
 func (h *RulesHandler) GetGlobalRules(c fiber.Ctx) error {
@@ -109,10 +355,29 @@ func (h *RulesHandler) CreateUserRule(c fiber.Ctx) error {
 		})
 	}
 
-	// Validate required fields
-	if req.Keyword == "" || req.Category == "" {
+	// Validate required fields; a rule with tags may leave category empty to only tag
+	req.applyKeywords()
+	req.Tags = models.NormalizeTags(req.Tags)
+	if req.Keyword == "" || (req.Category == "" && len(req.Tags) == 0) {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "keyword and category are required",
+			"error": "keyword and category (or tags) are required",
+		})
+	}
+	if req.Category != "" {
+		if err := checkCategory(h.categories, req.Category); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+	if err := validateAmountRange(req.MinAmount, req.MaxAmount); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err := validateDirection(req.Direction); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
@@ -138,9 +403,13 @@ func (h *RulesHandler) CreateUserRule(c fiber.Ctx) error {
 	pgUserID.Valid = true
 
 	// Set defaults
-	if req.Priority == 0 {
-		req.Priority = 100 // Default user rule priority
+	priority, err := h.boundPriority(req.Priority)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
 	}
+	req.Priority = priority
 	if req.MatchType == "" {
 		req.MatchType = "substring"
 	}
@@ -161,6 +430,10 @@ func (h *RulesHandler) CreateUserRule(c fiber.Ctx) error {
 		MatchType:           pgtype.Text{String: req.MatchType, Valid: true},
 		SimilarityThreshold: pgThreshold,
 		IsActive:            pgtype.Bool{Bool: true, Valid: true},
+		MinAmount:           amountBound(req.MinAmount),
+		MaxAmount:           amountBound(req.MaxAmount),
+		Tags:                req.Tags,
+		Direction:           ruleDirection(req.Direction),
 	})
 
 	if err != nil {
@@ -200,6 +473,23 @@ func (h *RulesHandler) UpdateUserRule(c fiber.Ctx) error {
 			"error": "invalid request body",
 		})
 	}
+	if req.Category != "" {
+		if err := checkCategory(h.categories, req.Category); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+	if err := validateAmountRange(req.MinAmount, req.MaxAmount); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err := validateDirection(req.Direction); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 
 	// Get user_id from context
 	userID, ok := c.Locals("user_id").(string)
@@ -229,6 +519,15 @@ func (h *RulesHandler) UpdateUserRule(c fiber.Ctx) error {
 	var pgThreshold pgtype.Numeric
 	pgThreshold.Scan(req.SimilarityThreshold)
 
+	// Keep the priority within the configured bounds, as on create
+	priority, err := h.boundPriority(req.Priority)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	req.Priority = priority
+
 	// Update rule in database
 	rule, err := h.db.UpdateUserRule(c.Context(), db.UpdateUserRuleParams{
 		ID:                  pgRuleID,
@@ -238,6 +537,10 @@ func (h *RulesHandler) UpdateUserRule(c fiber.Ctx) error {
 		SimilarityThreshold: pgThreshold,
 		IsActive:            pgtype.Bool{Bool: req.IsActive, Valid: true},
 		UserID:              pgUserID,
+		MinAmount:           amountBound(req.MinAmount),
+		MaxAmount:           amountBound(req.MaxAmount),
+		Tags:                models.NormalizeTags(req.Tags),
+		Direction:           ruleDirection(req.Direction),
 	})
 
 	if err != nil {
@@ -382,14 +685,7 @@ func (h *RulesHandler) SearchRules(c fiber.Ctx) error {
 	}
 
 	// Get limit (default 20)
-	limitStr := c.Query("limit", "20")
-	limit := 20
-	if l, err := strconv.Atoi(limitStr); err == nil {
-		limit = l
-	}
-	if limit > 100 {
-		limit = 100 // Max limit
-	}
+	limit := pageLimit(c, 20, h.maxPageSize)
 
 	// Get user_id from context
 	userID, ok := c.Locals("user_id").(string)
@@ -445,3 +741,444 @@ func (h *RulesHandler) SearchRules(c fiber.Ctx) error {
 		"query":        query,
 	})
 }
+
+// RuleImpactRequest represents the request body for PreviewRuleImpact
+type RuleImpactRequest struct {
+	Rules []CreateRuleRequest `json:"rules"`
+}
+
+// PreviewRuleImpact shows which transactions would change category if the proposed
+// rules were added, without saving anything
+// POST /v1/rules/impact
+func (h *RulesHandler) PreviewRuleImpact(c fiber.Ctx) error {
+	if h.previewer == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "rule impact preview not available",
+		})
+	}
+
+	// 1. Parse request body
+	var req RuleImpactRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	// 2. Validate proposed rules, applying the same defaults as CreateUserRule
+	if len(req.Rules) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "at least one rule is required",
+		})
+	}
+	proposed := make([]models.ProposedRule, 0, len(req.Rules))
+	for _, rule := range req.Rules {
+		rule.applyKeywords()
+		if rule.Keyword == "" || rule.Category == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "keyword and category are required",
+			})
+		}
+		if err := validateAmountRange(rule.MinAmount, rule.MaxAmount); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if err := validateDirection(rule.Direction); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		priority, err := h.boundPriority(rule.Priority)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		rule.Priority = priority
+		if rule.MatchType == "" {
+			rule.MatchType = "substring"
+		}
+		if rule.SimilarityThreshold == 0 {
+			rule.SimilarityThreshold = 0.3
+		}
+		proposed = append(proposed, models.ProposedRule{
+			Keyword:             rule.Keyword,
+			Category:            rule.Category,
+			Priority:            rule.Priority,
+			MatchType:           rule.MatchType,
+			SimilarityThreshold: rule.SimilarityThreshold,
+			MinAmount:           rule.MinAmount,
+			MaxAmount:           rule.MaxAmount,
+			Direction:           rule.Direction,
+		})
+	}
+
+	// 3. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 4. Look up user's UUID
+	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	// Convert to pgtype.UUID
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userUUID
+	pgUserID.Valid = true
+
+	// 5. Load the user's transactions
+	rows, err := h.db.GetAllTransactions(c.Context(), pgUserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to fetch transactions",
+			"details": err.Error(),
+		})
+	}
+	transactions := make([]models.Transaction, 0, len(rows))
+	for _, row := range rows {
+		transactions = append(transactions, toModelTransaction(row))
+	}
+
+	// 6. Compute the diff
+	changes, err := h.previewer.PreviewRuleImpact(c.Context(), userUUID, transactions, proposed)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to preview rule impact",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"changes":             changes,
+		"changed_count":       len(changes),
+		"transactions_tested": len(transactions),
+	})
+}
+
+// PreviewRuleDeletion shows which transactions would become uncategorized or change
+// category if the rule were deleted, without deleting it
+// GET /v1/rules/:id/impact
+func (h *RulesHandler) PreviewRuleDeletion(c fiber.Ctx) error {
+	remover, ok := h.previewer.(RuleRemovalPreviewer)
+	if !ok {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "rule impact preview not available",
+		})
+	}
+
+	// 1. Parse rule ID from URL parameter
+	ruleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid rule ID",
+		})
+	}
+
+	// 2. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 3. Look up user's UUID
+	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	// Convert to pgtype.UUID
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userUUID
+	pgUserID.Valid = true
+
+	// 4. Load the user's transactions
+	rows, err := h.db.GetAllTransactions(c.Context(), pgUserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to fetch transactions",
+			"details": err.Error(),
+		})
+	}
+	transactions := make([]models.Transaction, 0, len(rows))
+	for _, row := range rows {
+		transactions = append(transactions, toModelTransaction(row))
+	}
+
+	// 5. Recategorize with the rule removed
+	changes, found, err := remover.PreviewRuleRemoval(c.Context(), userUUID, ruleID, transactions)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to preview rule impact",
+			"details": err.Error(),
+		})
+	}
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "rule not found",
+		})
+	}
+
+	uncategorized := 0
+	for _, change := range changes {
+		if change.NewCategory == "" {
+			uncategorized++
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"rule_id":             ruleID,
+		"changes":             changes,
+		"changed_count":       len(changes),
+		"uncategorized_count": uncategorized,
+		"recategorized_count": len(changes) - uncategorized,
+		"transactions_tested": len(transactions),
+	})
+}
+
+// TestRuleRequest represents the request body for TestRule
+type TestRuleRequest struct {
+	CreateRuleRequest
+	SampleSize int `json:"sample_size"` // Recent transactions to test against (default 200, max 1000)
+}
+
+// validateMatchType checks a rule's match type and, for regex rules, that the pattern compiles
+func validateMatchType(matchType, keyword string) error {
+	if !containsMatchType(matchType) {
+		return fmt.Errorf("invalid match_type - must be one of: exact, substring, regex, fuzzy, any, all")
+	}
+	if matchType == "regex" {
+		if _, err := regexp.Compile(keyword); err != nil {
+			return fmt.Errorf("invalid regex pattern: %v", err)
+		}
+	}
+	return nil
+}
+
+// containsMatchType reports whether matchType is a supported rule match type
+func containsMatchType(matchType string) bool {
+	for _, supported := range models.MatchTypes {
+		if matchType == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// TestRule shows which of the user's recent transactions a rule would match,
+// without saving the rule or changing any transaction
+// POST /v1/rules/test
+func (h *RulesHandler) TestRule(c fiber.Ctx) error {
+	if h.tester == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "rule testing not available",
+		})
+	}
+
+	// 1. Parse request body
+	var req TestRuleRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	// 2. Validate the rule, applying the same defaults as CreateUserRule.
+	// Category is optional since nothing is saved.
+	req.applyKeywords()
+	if req.Keyword == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "keyword is required",
+		})
+	}
+	if req.MatchType == "" {
+		req.MatchType = "substring"
+	}
+	if req.SimilarityThreshold == 0 {
+		req.SimilarityThreshold = 0.3
+	}
+	if err := validateMatchType(req.MatchType, req.Keyword); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err := validateAmountRange(req.MinAmount, req.MaxAmount); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err := validateDirection(req.Direction); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if req.SampleSize < 0 || req.SampleSize > MaxRuleTestSampleSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("sample_size must be between 1 and %d", MaxRuleTestSampleSize),
+		})
+	}
+	if req.SampleSize == 0 {
+		req.SampleSize = DefaultRuleTestSampleSize
+	}
+
+	// 3. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 4. Look up user's UUID
+	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	// Convert to pgtype.UUID
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userUUID
+	pgUserID.Valid = true
+
+	// 5. Load the user's most recent transactions
+	rows, err := h.db.GetUserTransactions(c.Context(), db.GetUserTransactionsParams{
+		UserID: pgUserID,
+		Limit:  int32(req.SampleSize),
+		Offset: 0,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to fetch transactions",
+			"details": err.Error(),
+		})
+	}
+	transactions := make([]models.Transaction, 0, len(rows))
+	for _, row := range rows {
+		txn := models.Transaction{
+			ID:          row.ID.Bytes,
+			Description: row.Description,
+		}
+		if amount, err := row.Amount.Float64Value(); err == nil && amount.Valid {
+			txn.Amount = amount.Float64
+		}
+		if row.Category.Valid {
+			txn.Category = &row.Category.String
+		}
+		transactions = append(transactions, txn)
+	}
+
+	// 6. Run the rule
+	matches := h.tester.TestRule(models.ProposedRule{
+		Keyword:             req.Keyword,
+		Category:            req.Category,
+		Priority:            req.Priority,
+		MatchType:           req.MatchType,
+		SimilarityThreshold: req.SimilarityThreshold,
+		MinAmount:           req.MinAmount,
+		MaxAmount:           req.MaxAmount,
+		Direction:           req.Direction,
+	}, transactions)
+
+	return c.JSON(fiber.Map{
+		"matches":             matches,
+		"match_count":         len(matches),
+		"transactions_tested": len(transactions),
+	})
+}
+
+// GetRuleStatsByMatchType returns how many transactions each match type categorized
+// GET /v1/rules/stats/by-match-type?from=2024-01-01&to=2024-01-31
+// Dates are inclusive; the range defaults to the last 30 days.
+func (h *RulesHandler) GetRuleStatsByMatchType(c fiber.Ctx) error {
+	// 1. Parse date range
+	toDate := time.Now().UTC().Truncate(24 * time.Hour)
+	fromDate := toDate.AddDate(0, 0, -30)
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid from date, expected YYYY-MM-DD",
+			})
+		}
+		fromDate = parsed
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid to date, expected YYYY-MM-DD",
+			})
+		}
+		toDate = parsed
+	}
+	if toDate.Before(fromDate) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "to date must not be before from date",
+		})
+	}
+
+	// 2. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 3. Look up user's UUID
+	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userUUID
+	pgUserID.Valid = true
+
+	// 4. Count audit entries per match type (end of range is exclusive, so add a day)
+	rows, err := h.db.CountCategorizationsByMatchType(c.Context(), db.CountCategorizationsByMatchTypeParams{
+		UserID:      pgUserID,
+		CreatedAt:   pgtype.Timestamptz{Time: fromDate, Valid: true},
+		CreatedAt_2: pgtype.Timestamptz{Time: toDate.AddDate(0, 0, 1), Valid: true},
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to get match type stats",
+			"details": err.Error(),
+		})
+	}
+
+	// 5. Report every match type, including those that never fired
+	counts := make(map[string]int64, len(models.MatchTypes))
+	for _, matchType := range models.MatchTypes {
+		counts[matchType] = 0
+	}
+	var total int64
+	for _, row := range rows {
+		counts[row.MatchType] += row.CategorizedCount
+		total += row.CategorizedCount
+	}
+
+	return c.JSON(fiber.Map{
+		"counts": counts,
+		"total":  total,
+		"from":   fromDate.Format("2006-01-02"),
+		"to":     toDate.Format("2006-01-02"),
+	})
+}
@@ -0,0 +1,885 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/ashmitsharp/cashlens-api/internal/services"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPreviewRuleImpact tests that the diff reflects proposed rules layered over existing ones
+func TestPreviewRuleImpact(t *testing.T) {
+	userID := uuid.New()
+	cloud := "Cloud & Hosting"
+	food := "Food"
+
+	aws := newTestTransaction(t, "AWS SERVICES", -3500.00)
+	aws.Category = pgtype.Text{String: cloud, Valid: true}
+	swiggy := newTestTransaction(t, "SWIGGY ORDER 1234", -450.00)
+	zomato := newTestTransaction(t, "ZOMATO ORDER", -300.00)
+	zomato.Category = pgtype.Text{String: food, Valid: true}
+	zomato.IsReviewed = true
+	transactions := []db.Transaction{aws, swiggy, zomato}
+
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetAllTransactions": func(args []interface{}) [][]interface{} {
+			rows := [][]interface{}{}
+			for _, txn := range transactions {
+				rows = append(rows, transactionRow(txn))
+			}
+			return rows
+		},
+		"GetGlobalRulesChangedSince": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{{
+				pgtype.UUID{Bytes: uuid.New(), Valid: true}, "aws", cloud,
+				pgtype.Int4{Int32: 10, Valid: true}, pgtype.Text{String: "substring", Valid: true},
+				pgtype.Numeric{}, pgtype.Bool{Bool: true, Valid: true}, pgtype.Timestamptz{}, pgtype.Timestamptz{},
+			}}
+		},
+	}}
+	queries := db.New(fake)
+	categorizer := services.NewCategorizer(queries)
+
+	handler := NewRulesHandler(queries, categorizer)
+	handler.SetRulePreviewer(categorizer)
+
+	app := fiber.New()
+	app.Post("/rules/impact", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.PreviewRuleImpact(c)
+	})
+
+	body, _ := json.Marshal(RuleImpactRequest{Rules: []CreateRuleRequest{
+		{Keyword: "swiggy", Category: "Team Meals"},
+		{Keyword: "zomato", Category: "Team Meals"},
+	}})
+	req := httptest.NewRequest("POST", "/rules/impact", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Changes []struct {
+			TransactionID string `json:"transaction_id"`
+			OldCategory   string `json:"old_category"`
+			NewCategory   string `json:"new_category"`
+		} `json:"changes"`
+		ChangedCount       int `json:"changed_count"`
+		TransactionsTested int `json:"transactions_tested"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+	// AWS keeps its category and the reviewed Zomato transaction is left alone
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, uuid.UUID(swiggy.ID.Bytes).String(), result.Changes[0].TransactionID)
+	assert.Equal(t, "", result.Changes[0].OldCategory)
+	assert.Equal(t, "Team Meals", result.Changes[0].NewCategory)
+	assert.Equal(t, 1, result.ChangedCount)
+	assert.Equal(t, 3, result.TransactionsTested)
+}
+
+// TestPreviewRuleImpact_RequiresRules tests validation of the proposed rule set
+func TestPreviewRuleImpact_RequiresRules(t *testing.T) {
+	handler := NewRulesHandler(nil, nil)
+	handler.SetRulePreviewer(services.NewCategorizer(nil))
+
+	app := fiber.New()
+	app.Post("/rules/impact", handler.PreviewRuleImpact)
+
+	req := httptest.NewRequest("POST", "/rules/impact", bytes.NewReader([]byte(`{"rules": []}`)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+// TestPreviewRuleDeletion tests the reported impact of deleting a rule that categorized known transactions
+func TestPreviewRuleDeletion(t *testing.T) {
+	userID := uuid.New()
+	teamMealsRuleID := uuid.New()
+	userRule := func(id uuid.UUID, keyword, category string) []interface{} {
+		return []interface{}{
+			pgtype.UUID{Bytes: id, Valid: true}, pgtype.UUID{Bytes: userID, Valid: true}, keyword, category,
+			pgtype.Int4{Int32: 100, Valid: true}, pgtype.Text{String: "substring", Valid: true}, pgtype.Numeric{},
+			pgtype.Bool{Bool: true, Valid: true}, pgtype.Timestamptz{}, pgtype.Timestamptz{Valid: true},
+			pgtype.Numeric{}, pgtype.Numeric{}, []string{}, pgtype.Text{},
+		}
+	}
+
+	order := newTestTransaction(t, "SWIGGY ORDER 1234", -450.00)
+	order.Category = pgtype.Text{String: "Team Meals", Valid: true}
+	instamart := newTestTransaction(t, "SWIGGY INSTAMART", -900.00)
+	instamart.Category = pgtype.Text{String: "Team Meals", Valid: true}
+	uber := newTestTransaction(t, "UBER TRIP", -300.00)
+	uber.Category = pgtype.Text{String: "Travel", Valid: true}
+	reviewed := newTestTransaction(t, "SWIGGY ORDER 999", -200.00)
+	reviewed.Category = pgtype.Text{String: "Team Meals", Valid: true}
+	reviewed.IsReviewed = true
+	transactions := []db.Transaction{order, instamart, uber, reviewed}
+
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetAllTransactions": func(args []interface{}) [][]interface{} {
+			rows := [][]interface{}{}
+			for _, txn := range transactions {
+				rows = append(rows, transactionRow(txn))
+			}
+			return rows
+		},
+		"GetGlobalRulesChangedSince": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{{
+				pgtype.UUID{Bytes: uuid.New(), Valid: true}, "swiggy instamart", "Groceries",
+				pgtype.Int4{Int32: 10, Valid: true}, pgtype.Text{String: "substring", Valid: true},
+				pgtype.Numeric{}, pgtype.Bool{Bool: true, Valid: true}, pgtype.Timestamptz{}, pgtype.Timestamptz{},
+			}}
+		},
+		"GetUserRulesChangedSince": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{
+				userRule(teamMealsRuleID, "swiggy", "Team Meals"),
+				userRule(uuid.New(), "uber", "Travel"),
+			}
+		},
+	}}
+	queries := db.New(fake)
+	categorizer := services.NewCategorizer(queries)
+
+	handler := NewRulesHandler(queries, categorizer)
+	handler.SetRulePreviewer(categorizer)
+
+	app := fiber.New()
+	app.Get("/rules/:id/impact", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.PreviewRuleDeletion(c)
+	})
+
+	t.Run("Rule with categorized transactions", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/rules/"+teamMealsRuleID.String()+"/impact", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var result struct {
+			Changes []struct {
+				TransactionID string `json:"transaction_id"`
+				OldCategory   string `json:"old_category"`
+				NewCategory   string `json:"new_category"`
+			} `json:"changes"`
+			ChangedCount       int `json:"changed_count"`
+			UncategorizedCount int `json:"uncategorized_count"`
+			RecategorizedCount int `json:"recategorized_count"`
+			TransactionsTested int `json:"transactions_tested"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+		// Uber is categorized by another rule and the reviewed order is left alone
+		require.Len(t, result.Changes, 2)
+		assert.Equal(t, uuid.UUID(order.ID.Bytes).String(), result.Changes[0].TransactionID)
+		assert.Equal(t, "Team Meals", result.Changes[0].OldCategory)
+		assert.Equal(t, "", result.Changes[0].NewCategory)
+		assert.Equal(t, uuid.UUID(instamart.ID.Bytes).String(), result.Changes[1].TransactionID)
+		assert.Equal(t, "Team Meals", result.Changes[1].OldCategory)
+		assert.Equal(t, "Groceries", result.Changes[1].NewCategory)
+		assert.Equal(t, 2, result.ChangedCount)
+		assert.Equal(t, 1, result.UncategorizedCount)
+		assert.Equal(t, 1, result.RecategorizedCount)
+		assert.Equal(t, 4, result.TransactionsTested)
+	})
+
+	t.Run("Unknown rule", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/rules/"+uuid.New().String()+"/impact", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Invalid rule ID", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/rules/not-a-uuid/impact", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+// TestGetEffectiveRules tests that user rules precede global rules in evaluation order
+func TestGetEffectiveRules(t *testing.T) {
+	userID := uuid.New()
+	userRule := func(keyword, category, matchType string, priority int32, tags []string) []interface{} {
+		return []interface{}{
+			pgtype.UUID{Bytes: uuid.New(), Valid: true}, pgtype.UUID{Bytes: userID, Valid: true}, keyword, category,
+			pgtype.Int4{Int32: priority, Valid: true}, pgtype.Text{String: matchType, Valid: true}, pgtype.Numeric{},
+			pgtype.Bool{Bool: true, Valid: true}, pgtype.Timestamptz{}, pgtype.Timestamptz{Valid: true},
+			pgtype.Numeric{}, pgtype.Numeric{}, tags, pgtype.Text{},
+		}
+	}
+	globalRule := func(keyword, category, matchType string, priority int32) []interface{} {
+		return []interface{}{
+			pgtype.UUID{Bytes: uuid.New(), Valid: true}, keyword, category,
+			pgtype.Int4{Int32: priority, Valid: true}, pgtype.Text{String: matchType, Valid: true},
+			pgtype.Numeric{}, pgtype.Bool{Bool: true, Valid: true}, pgtype.Timestamptz{}, pgtype.Timestamptz{},
+		}
+	}
+
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetGlobalRulesChangedSince": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{
+				globalRule("aws", "Cloud & Hosting", "substring", 5),
+				globalRule("swiggy", "Food", "substring", 10),
+			}
+		},
+		"GetUserRulesChangedSince": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{
+				userRule("uber", "Travel", "exact", 10, []string{}),
+				userRule("swiggy", "Team Meals", "substring", 100, []string{}),
+				userRule("zomato", "", "substring", 100, []string{"food"}), // Tag-only
+			}
+		},
+	}}
+	queries := db.New(fake)
+	handler := NewRulesHandler(queries, services.NewCategorizer(queries))
+
+	app := fiber.New()
+	app.Get("/rules/effective", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.GetEffectiveRules(c)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/rules/effective", nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Rules []struct {
+			Position  int     `json:"position"`
+			Source    string  `json:"source"`
+			Keyword   string  `json:"keyword"`
+			Category  string  `json:"category"`
+			Priority  int32   `json:"priority"`
+			MatchType string  `json:"match_type"`
+			Weight    float64 `json:"match_weight"`
+		} `json:"rules"`
+		Count       int `json:"count"`
+		UserCount   int `json:"user_count"`
+		GlobalCount int `json:"global_count"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+	require.Len(t, result.Rules, 4)
+	order := make([]string, len(result.Rules))
+	for i, rule := range result.Rules {
+		assert.Equal(t, i+1, rule.Position)
+		order[i] = rule.Source + ":" + rule.Keyword
+	}
+	// Priority first; at equal priority the user rule precedes the global one
+	assert.Equal(t, []string{"user:swiggy", "user:uber", "global:swiggy", "global:aws"}, order)
+	assert.Equal(t, "exact", result.Rules[1].MatchType)
+	assert.Equal(t, 1.0, result.Rules[1].Weight)
+	assert.Equal(t, 4, result.Count)
+	assert.Equal(t, 2, result.UserCount)
+	assert.Equal(t, 2, result.GlobalCount)
+}
+
+// TestCreateUserRule_Keywords tests that a keywords list is stored as one multi-keyword rule
+func TestCreateUserRule_Keywords(t *testing.T) {
+	userID := uuid.New()
+
+	var created db.CreateUserRuleParams
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"CreateUserRule": func(args []interface{}) [][]interface{} {
+			created = db.CreateUserRuleParams{
+				Keyword:   args[1].(string),
+				Category:  args[2].(string),
+				MatchType: args[4].(pgtype.Text),
+			}
+			return [][]interface{}{{
+				pgtype.UUID{Bytes: uuid.New(), Valid: true}, args[0], args[1], args[2], args[3],
+				args[4], args[5], args[6], pgtype.Timestamptz{}, pgtype.Timestamptz{}, args[7], args[8], args[9], args[10],
+			}}
+		},
+	}}
+	handler := NewRulesHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Post("/rules", func(c fiber.Ctx) error {
+		c.Locals("user_id", userID.String())
+		return handler.CreateUserRule(c)
+	})
+
+	testCases := []struct {
+		name              string
+		body              string
+		expectedKeyword   string
+		expectedMatchType string
+	}{
+		{"Any by default", `{"keywords": ["swiggy", " zomato ", ""], "category": "Food"}`, "swiggy|zomato", "any"},
+		{"All", `{"keywords": ["aws", "invoice"], "match_type": "all", "category": "Food"}`, "aws|invoice", "all"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/rules", bytes.NewReader([]byte(tc.body)))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+			assert.Equal(t, tc.expectedKeyword, created.Keyword)
+			assert.Equal(t, "Food", created.Category)
+			assert.Equal(t, tc.expectedMatchType, created.MatchType.String)
+		})
+	}
+
+}
+
+// TestUserRule_CategoryWhitelist tests that rule categories are checked against the taxonomy on create and update
+func TestUserRule_CategoryWhitelist(t *testing.T) {
+	userID := uuid.New()
+
+	ruleRow := func(args []interface{}) [][]interface{} {
+		return [][]interface{}{{
+			pgtype.UUID{Bytes: uuid.New(), Valid: true}, pgtype.UUID{Bytes: userID, Valid: true}, "swiggy", "Food",
+			pgtype.Int4{Int32: 100, Valid: true}, pgtype.Text{String: "substring", Valid: true}, pgtype.Numeric{},
+			pgtype.Bool{Bool: true, Valid: true}, pgtype.Timestamptz{}, pgtype.Timestamptz{}, pgtype.Numeric{}, pgtype.Numeric{}, []string(nil), pgtype.Text{},
+		}}
+	}
+
+	testCases := []struct {
+		name         string
+		whitelist    []string
+		method       string
+		body         string
+		expectedCode int
+	}{
+		{"Create without whitelist", nil, "POST", `{"keyword": "swiggy", "category": "Crypto"}`, fiber.StatusCreated},
+		{"Create allowed", []string{"Food", "Travel"}, "POST", `{"keyword": "swiggy", "category": "Food"}`, fiber.StatusCreated},
+		{"Create unknown", []string{"Food", "Travel"}, "POST", `{"keyword": "swiggy", "category": "Crypto"}`, fiber.StatusBadRequest},
+		{"Create tag-only", []string{"Food", "Travel"}, "POST", `{"keyword": "swiggy", "tags": ["team-lunch"]}`, fiber.StatusCreated},
+		{"Update without whitelist", nil, "PUT", `{"category": "Crypto"}`, fiber.StatusOK},
+		{"Update allowed", []string{"Food", "Travel"}, "PUT", `{"category": "Travel"}`, fiber.StatusOK},
+		{"Update unknown", []string{"Food", "Travel"}, "PUT", `{"category": "Crypto"}`, fiber.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+				"CreateUserRule": ruleRow,
+				"UpdateUserRule": ruleRow,
+			}}
+			handler := NewRulesHandler(db.New(fake), nil)
+			if tc.whitelist != nil {
+				handler.SetCategoryWhitelist(services.NewCategoryWhitelist(tc.whitelist))
+			}
+
+			app := fiber.New()
+			app.Post("/rules", func(c fiber.Ctx) error {
+				c.Locals("user_id", userID.String())
+				return handler.CreateUserRule(c)
+			})
+			app.Put("/rules/:id", func(c fiber.Ctx) error {
+				c.Locals("user_id", userID.String())
+				return handler.UpdateUserRule(c)
+			})
+
+			path := "/rules"
+			if tc.method == "PUT" {
+				path += "/" + uuid.New().String()
+			}
+			req := httptest.NewRequest(tc.method, path, bytes.NewReader([]byte(tc.body)))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			if tc.expectedCode == fiber.StatusBadRequest {
+				assert.Empty(t, fake.calls)
+			}
+		})
+	}
+}
+
+// TestCreateUserRule_Tags tests that tags are normalized and allow tag-only rules without a category
+func TestCreateUserRule_Tags(t *testing.T) {
+	userID := uuid.New()
+
+	var tags []string
+	var category string
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"CreateUserRule": func(args []interface{}) [][]interface{} {
+			category, tags = args[2].(string), args[9].([]string)
+			return [][]interface{}{{
+				pgtype.UUID{Bytes: uuid.New(), Valid: true}, args[0], args[1], args[2], args[3],
+				args[4], args[5], args[6], pgtype.Timestamptz{}, pgtype.Timestamptz{}, args[7], args[8], args[9], args[10],
+			}}
+		},
+	}}
+	handler := NewRulesHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Post("/rules", func(c fiber.Ctx) error {
+		c.Locals("user_id", userID.String())
+		return handler.CreateUserRule(c)
+	})
+
+	testCases := []struct {
+		name             string
+		body             string
+		expectedCode     int
+		expectedCategory string
+		expectedTags     []string
+	}{
+		{"Category and tags", `{"keyword": "uber", "category": "Travel", "tags": [" Reimbursable ", "reimbursable", ""]}`, fiber.StatusCreated, "Travel", []string{"reimbursable"}},
+		{"Tag only", `{"keyword": "client", "tags": ["billable", "Client"]}`, fiber.StatusCreated, "", []string{"billable", "client"}},
+		{"No tags", `{"keyword": "aws", "category": "Cloud"}`, fiber.StatusCreated, "Cloud", []string{}},
+		{"Neither category nor tags", `{"keyword": "aws", "tags": [" "]}`, fiber.StatusBadRequest, "", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tags, category = nil, ""
+			req := httptest.NewRequest("POST", "/rules", bytes.NewReader([]byte(tc.body)))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedCategory, category)
+			assert.Equal(t, tc.expectedTags, tags)
+		})
+	}
+}
+
+// TestUserRule_AmountRange tests that amount bounds are validated and persisted on create and update
+func TestUserRule_AmountRange(t *testing.T) {
+	userID := uuid.New()
+
+	var minAmount, maxAmount pgtype.Numeric
+	ruleRow := func(args []interface{}, minArg, maxArg int) [][]interface{} {
+		minAmount, maxAmount = args[minArg].(pgtype.Numeric), args[maxArg].(pgtype.Numeric)
+		return [][]interface{}{{
+			pgtype.UUID{Bytes: uuid.New(), Valid: true}, pgtype.UUID{Bytes: userID, Valid: true}, "rent", "Rent",
+			pgtype.Int4{Int32: 100, Valid: true}, pgtype.Text{String: "substring", Valid: true}, pgtype.Numeric{},
+			pgtype.Bool{Bool: true, Valid: true}, pgtype.Timestamptz{}, pgtype.Timestamptz{}, minAmount, maxAmount, args[9], args[10],
+		}}
+	}
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"CreateUserRule": func(args []interface{}) [][]interface{} { return ruleRow(args, 7, 8) },
+		"UpdateUserRule": func(args []interface{}) [][]interface{} { return ruleRow(args, 7, 8) },
+	}}
+	handler := NewRulesHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Post("/rules", func(c fiber.Ctx) error {
+		c.Locals("user_id", userID.String())
+		return handler.CreateUserRule(c)
+	})
+	app.Put("/rules/:id", func(c fiber.Ctx) error {
+		c.Locals("user_id", userID.String())
+		return handler.UpdateUserRule(c)
+	})
+
+	send := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	bound := func(n pgtype.Numeric) interface{} {
+		if !n.Valid {
+			return nil
+		}
+		f, err := n.Float64Value()
+		require.NoError(t, err)
+		return f.Float64
+	}
+
+	// Create with a minimum only
+	require.Equal(t, fiber.StatusCreated, send("POST", "/rules", `{"keyword": "rent", "category": "Rent", "min_amount": 10000}`))
+	assert.Equal(t, 10000.0, bound(minAmount))
+	assert.Nil(t, bound(maxAmount))
+
+	// Update to a closed range, then clear it
+	rulePath := "/rules/" + uuid.New().String()
+	require.Equal(t, fiber.StatusOK, send("PUT", rulePath, `{"category": "Rent", "min_amount": 5000, "max_amount": 50000.5, "is_active": true}`))
+	assert.Equal(t, 5000.0, bound(minAmount))
+	assert.Equal(t, 50000.5, bound(maxAmount))
+	require.Equal(t, fiber.StatusOK, send("PUT", rulePath, `{"category": "Rent", "is_active": true}`))
+	assert.Nil(t, bound(minAmount))
+	assert.Nil(t, bound(maxAmount))
+
+	// Invalid ranges are rejected before touching the database
+	fake.calls = nil
+	assert.Equal(t, fiber.StatusBadRequest, send("POST", "/rules", `{"keyword": "rent", "category": "Rent", "min_amount": 500, "max_amount": 100}`))
+	assert.Equal(t, fiber.StatusBadRequest, send("PUT", rulePath, `{"category": "Rent", "min_amount": -1}`))
+	assert.Empty(t, fake.calls)
+}
+
+// TestUserRule_Direction tests that a credit/debit direction is validated and persisted on create and update
+func TestUserRule_Direction(t *testing.T) {
+	userID := uuid.New()
+
+	var direction pgtype.Text
+	ruleRow := func(args []interface{}) [][]interface{} {
+		direction = args[10].(pgtype.Text)
+		return [][]interface{}{{
+			pgtype.UUID{Bytes: uuid.New(), Valid: true}, pgtype.UUID{Bytes: userID, Valid: true}, "INT PD", "Interest Income",
+			pgtype.Int4{Int32: 100, Valid: true}, pgtype.Text{String: "regex", Valid: true}, pgtype.Numeric{},
+			pgtype.Bool{Bool: true, Valid: true}, pgtype.Timestamptz{}, pgtype.Timestamptz{}, args[7], args[8], args[9], direction,
+		}}
+	}
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"CreateUserRule": ruleRow,
+		"UpdateUserRule": ruleRow,
+	}}
+	handler := NewRulesHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Post("/rules", func(c fiber.Ctx) error {
+		c.Locals("user_id", userID.String())
+		return handler.CreateUserRule(c)
+	})
+	app.Put("/rules/:id", func(c fiber.Ctx) error {
+		c.Locals("user_id", userID.String())
+		return handler.UpdateUserRule(c)
+	})
+
+	send := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Create a credit-only rule, then clear the direction on update
+	require.Equal(t, fiber.StatusCreated, send("POST", "/rules", `{"keyword": "INT PD", "category": "Interest Income", "match_type": "regex", "direction": "credit"}`))
+	assert.Equal(t, pgtype.Text{String: "credit", Valid: true}, direction)
+	rulePath := "/rules/" + uuid.New().String()
+	require.Equal(t, fiber.StatusOK, send("PUT", rulePath, `{"category": "Interest Income", "match_type": "regex", "is_active": true}`))
+	assert.False(t, direction.Valid)
+
+	// Unknown directions are rejected before touching the database
+	fake.calls = nil
+	assert.Equal(t, fiber.StatusBadRequest, send("POST", "/rules", `{"keyword": "INT PD", "category": "Interest Income", "direction": "incoming"}`))
+	assert.Equal(t, fiber.StatusBadRequest, send("PUT", rulePath, `{"category": "Interest Income", "direction": "out"}`))
+	assert.Empty(t, fake.calls)
+}
+
+// TestGetRuleStatsByMatchType tests per-match-type counts over seeded audit entries
+func TestGetRuleStatsByMatchType(t *testing.T) {
+	userID := uuid.New()
+	day := func(d int) pgtype.Timestamptz {
+		return pgtype.Timestamptz{Time: time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC), Valid: true}
+	}
+	audit := []db.CategorizationAudit{
+		{MatchType: "substring", CreatedAt: day(2)},
+		{MatchType: "substring", CreatedAt: day(10)},
+		{MatchType: "regex", CreatedAt: day(15)},
+		{MatchType: "fuzzy", CreatedAt: day(31)},
+		{MatchType: "exact", CreatedAt: pgtype.Timestamptz{Time: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Valid: true}},
+	}
+
+	// Mirrors the SQL: entries in [from, to) grouped by match type
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"CountCategorizationsByMatchType": func(args []interface{}) [][]interface{} {
+			from, to := args[1].(pgtype.Timestamptz).Time, args[2].(pgtype.Timestamptz).Time
+			counts := map[string]int64{}
+			var order []string
+			for _, entry := range audit {
+				if entry.CreatedAt.Time.Before(from) || !entry.CreatedAt.Time.Before(to) {
+					continue
+				}
+				if counts[entry.MatchType] == 0 {
+					order = append(order, entry.MatchType)
+				}
+				counts[entry.MatchType]++
+			}
+			rows := [][]interface{}{}
+			for _, matchType := range order {
+				rows = append(rows, []interface{}{matchType, counts[matchType]})
+			}
+			return rows
+		},
+	}}
+	handler := NewRulesHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Get("/rules/stats/by-match-type", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.GetRuleStatsByMatchType(c)
+	})
+
+	testCases := []struct {
+		name           string
+		query          string
+		expectedCode   int
+		expectedCounts map[string]int64
+		expectedTotal  int64
+	}{
+		{
+			name:           "January",
+			query:          "?from=2024-01-01&to=2024-01-31",
+			expectedCode:   fiber.StatusOK,
+			expectedCounts: map[string]int64{"exact": 0, "substring": 2, "regex": 1, "fuzzy": 1, "any": 0, "all": 0},
+			expectedTotal:  4,
+		},
+		{
+			name:           "Narrow range",
+			query:          "?from=2024-01-10&to=2024-01-15",
+			expectedCode:   fiber.StatusOK,
+			expectedCounts: map[string]int64{"exact": 0, "substring": 1, "regex": 1, "fuzzy": 0, "any": 0, "all": 0},
+			expectedTotal:  2,
+		},
+		{name: "Invalid date", query: "?from=01-01-2024", expectedCode: fiber.StatusBadRequest},
+		{name: "Reversed range", query: "?from=2024-02-01&to=2024-01-01", expectedCode: fiber.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/rules/stats/by-match-type"+tc.query, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			if tc.expectedCode != fiber.StatusOK {
+				return
+			}
+
+			var result struct {
+				Counts map[string]int64 `json:"counts"`
+				Total  int64            `json:"total"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			assert.Equal(t, tc.expectedCounts, result.Counts)
+			assert.Equal(t, tc.expectedTotal, result.Total)
+		})
+	}
+}
+
+// TestUpdateUserRule_PriorityBounds tests that out-of-bounds priorities on update follow the configured policy
+func TestUpdateUserRule_PriorityBounds(t *testing.T) {
+	userID := uuid.New()
+
+	var savedPriority pgtype.Int4
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"UpdateUserRule": func(args []interface{}) [][]interface{} {
+			savedPriority = args[2].(pgtype.Int4)
+			return [][]interface{}{{
+				args[0], args[6], "rent", args[1], args[2], args[3], args[4], args[5],
+				pgtype.Timestamptz{}, pgtype.Timestamptz{}, args[7], args[8], args[9], args[10],
+			}}
+		},
+	}}
+
+	testCases := []struct {
+		name             string
+		policy           string
+		priority         int32
+		expectedStatus   int
+		expectedPriority int32
+	}{
+		{"Within bounds", PriorityPolicyClamp, 150, fiber.StatusOK, 150},
+		{"Zero uses the default", PriorityPolicyClamp, 0, fiber.StatusOK, DefaultUserRulePriority},
+		{"Clamped to floor", PriorityPolicyClamp, 5, fiber.StatusOK, 20},
+		{"Clamped to ceiling", PriorityPolicyClamp, 5000, fiber.StatusOK, 500},
+		{"Rejected below floor", PriorityPolicyReject, 5, fiber.StatusBadRequest, 0},
+		{"Rejected above ceiling", PriorityPolicyReject, 5000, fiber.StatusBadRequest, 0},
+		{"Reject policy allows bounds", PriorityPolicyReject, 500, fiber.StatusOK, 500},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			savedPriority = pgtype.Int4{}
+			handler := NewRulesHandler(db.New(fake), nil)
+			handler.SetPriorityBounds(20, 500, tc.policy)
+
+			app := fiber.New()
+			app.Put("/rules/:id", func(c fiber.Ctx) error {
+				c.Locals("user_id", userID.String())
+				return handler.UpdateUserRule(c)
+			})
+
+			body := fmt.Sprintf(`{"category": "Rent", "priority": %d, "match_type": "substring", "is_active": true}`, tc.priority)
+			req := httptest.NewRequest("PUT", "/rules/"+uuid.New().String(), bytes.NewReader([]byte(body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tc.expectedPriority, savedPriority.Int32)
+		})
+	}
+}
+
+func TestCreateUserRule_PriorityBoundsMatchUpdate(t *testing.T) {
+	var savedPriority int32
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"CreateUserRule": func(args []interface{}) [][]interface{} {
+			savedPriority = args[3].(pgtype.Int4).Int32
+			return [][]interface{}{{
+				pgtype.UUID{Bytes: uuid.New(), Valid: true}, args[0], args[1], args[2], args[3],
+				args[4], args[5], args[6], pgtype.Timestamptz{}, pgtype.Timestamptz{}, args[7], args[8], args[9], args[10],
+			}}
+		},
+	}}
+	handler := NewRulesHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Post("/rules", func(c fiber.Ctx) error {
+		c.Locals("user_id", uuid.New().String())
+		return handler.CreateUserRule(c)
+	})
+	create := func() int {
+		req := httptest.NewRequest("POST", "/rules", bytes.NewReader([]byte(`{"keyword": "rent", "category": "Rent", "priority": 1}`)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Default bounds keep user rules above global ones
+	assert.Equal(t, fiber.StatusCreated, create())
+	assert.Equal(t, DefaultUserRulePriorityMin, savedPriority)
+
+	handler.SetPriorityBounds(DefaultUserRulePriorityMin, DefaultUserRulePriorityMax, PriorityPolicyReject)
+	assert.Equal(t, fiber.StatusBadRequest, create())
+}
+
+// TestTestRule tests that a rule is run against recent transactions without writing anything
+func TestTestRule(t *testing.T) {
+	userID := uuid.New()
+	food := "Food"
+	zomato := newTestTransaction(t, "UPI/401234/ZOMATO ORDER", -450.00)
+	zomato.Category = pgtype.Text{String: food, Valid: true}
+	transactions := []db.Transaction{
+		newTestTransaction(t, "SWIGGY ORDER 1234", -300.00),
+		zomato,
+		newTestTransaction(t, "AWS SERVICES", -3500.00),
+	}
+
+	var limits []int32
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetUserTransactions": func(args []interface{}) [][]interface{} {
+			limits = append(limits, args[1].(int32))
+			rows := [][]interface{}{}
+			for _, txn := range transactions {
+				rows = append(rows, append(transactionRow(txn), pgtype.Text{}, int64(len(transactions))))
+			}
+			return rows
+		},
+	}}
+	handler := NewRulesHandler(db.New(fake), nil)
+	handler.SetRuleTester(services.NewCategorizer(nil))
+
+	app := fiber.New()
+	app.Post("/rules/test", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.TestRule(c)
+	})
+
+	testCases := []struct {
+		name         string
+		body         string
+		expectedCode int
+		expected     []string
+		limit        int32
+	}{
+		{"Substring", `{"keyword": "order"}`, fiber.StatusOK, []string{"SWIGGY ORDER 1234", "UPI/401234/ZOMATO ORDER"}, DefaultRuleTestSampleSize},
+		{"Regex", `{"keyword": "^UPI/\\d+/", "match_type": "regex", "sample_size": 50}`, fiber.StatusOK, []string{"UPI/401234/ZOMATO ORDER"}, 50},
+		{"Keywords list", `{"keywords": ["aws", "swiggy"]}`, fiber.StatusOK, []string{"SWIGGY ORDER 1234", "AWS SERVICES"}, DefaultRuleTestSampleSize},
+		{"Amount range", `{"keyword": "order", "min_amount": 400}`, fiber.StatusOK, []string{"UPI/401234/ZOMATO ORDER"}, DefaultRuleTestSampleSize},
+		{"No matches", `{"keyword": "netflix", "match_type": "exact"}`, fiber.StatusOK, []string{}, DefaultRuleTestSampleSize},
+		{"Invalid regex", `{"keyword": "([a-z", "match_type": "regex"}`, fiber.StatusBadRequest, nil, 0},
+		{"Invalid match type", `{"keyword": "aws", "match_type": "prefix"}`, fiber.StatusBadRequest, nil, 0},
+		{"Missing keyword", `{"category": "Food"}`, fiber.StatusBadRequest, nil, 0},
+		{"Sample size too large", `{"keyword": "aws", "sample_size": 5000}`, fiber.StatusBadRequest, nil, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake.calls = nil
+			limits = nil
+			req := httptest.NewRequest("POST", "/rules/test", bytes.NewReader([]byte(tc.body)))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			var result struct {
+				Error   string `json:"error"`
+				Matches []struct {
+					Description     string `json:"description"`
+					CurrentCategory string `json:"current_category"`
+				} `json:"matches"`
+				MatchCount         int `json:"match_count"`
+				TransactionsTested int `json:"transactions_tested"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			if tc.expectedCode != fiber.StatusOK {
+				assert.NotEmpty(t, result.Error)
+				assert.Empty(t, fake.calls)
+				return
+			}
+
+			descriptions := []string{}
+			for _, match := range result.Matches {
+				descriptions = append(descriptions, match.Description)
+			}
+			assert.Equal(t, tc.expected, descriptions)
+			assert.Equal(t, len(tc.expected), result.MatchCount)
+			assert.Equal(t, len(transactions), result.TransactionsTested)
+			assert.Equal(t, []int32{tc.limit}, limits)
+			// Read-only: only the user lookup and transaction fetch run
+			assert.Equal(t, []string{"GetUserByClerkID", "GetUserTransactions"}, fake.calls)
+		})
+	}
+
+	// Invalid regex errors explain the pattern problem
+	req := httptest.NewRequest("POST", "/rules/test", bytes.NewReader([]byte(`{"keyword": "([a-z", "match_type": "regex"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var errResult map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResult))
+	assert.Contains(t, errResult["error"], "invalid regex pattern")
+}
@@ -1,15 +1,26 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/csv"
 	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/ashmitsharp/cashlens-api/internal/models"
 	"github.com/gofiber/fiber/v3"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// validGroupBy lists the trend periods accepted by group_by
+var validGroupBy = map[string]bool{
+	"day":   true,
+	"week":  true,
+	"month": true,
+	"year":  true,
+}
+
 type SummaryHandler struct {
 	queries *db.Queries
 }
@@ -37,9 +48,10 @@ type NetFlowTrendPoint struct {
 type SummaryResponse struct {
 	KPIs          KPIsResponse        `json:"kpis"`
 	NetFlowTrend  []NetFlowTrendPoint `json:"net_flow_trend"`
-	FromDate      string              `json:"from_date"`
-	ToDate        string              `json:"to_date"`
+	FromDate      models.Date         `json:"from_date"`
+	ToDate        models.Date         `json:"to_date"`
 	GroupBy       string              `json:"group_by"`
+	Currency      string              `json:"currency"`
 }
 
 // GetSummary handles GET /v1/summary
@@ -61,38 +73,29 @@ func (h *SummaryHandler) GetSummary(c fiber.Ctx) error {
 		})
 	}
 
+	// Timezone, week start and currency come from the user's preferences
+	prefs, err := loadUserPreferences(c.Context(), h.queries, user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": fmt.Sprintf("Failed to fetch preferences: %s", err.Error()),
+		})
+	}
+	loc := location(prefs)
+
 	// Parse query parameters
 	fromStr := c.Query("from")
 	toStr := c.Query("to")
 	groupBy := c.Query("group_by", "month")
 
 	// Default to last 12 months if not provided
-	var fromDate, toDate time.Time
-	if fromStr == "" || toStr == "" {
-		toDate = time.Now()
-		fromDate = toDate.AddDate(-1, 0, 0) // 1 year ago
-	} else {
-		fromDate, err = time.Parse("2006-01-02", fromStr)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": fmt.Sprintf("Invalid from date format: %s", err.Error()),
-			})
-		}
-		toDate, err = time.Parse("2006-01-02", toStr)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": fmt.Sprintf("Invalid to date format: %s", err.Error()),
-			})
-		}
+	fromDate, toDate, err := summaryDateRange(fromStr, toStr, loc)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
 	}
 
 	// Validate groupBy parameter
-	validGroupBy := map[string]bool{
-		"day":   true,
-		"week":  true,
-		"month": true,
-		"year":  true,
-	}
 	if !validGroupBy[groupBy] {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid group_by parameter. Must be one of: day, week, month, year",
@@ -131,10 +134,11 @@ func (h *SummaryHandler) GetSummary(c fiber.Ctx) error {
 
 	// Get cash flow trend (inflow, outflow, net)
 	trendRows, err := h.queries.GetCashFlowTrend(c.Context(), db.GetCashFlowTrendParams{
-		UserID:    user.ID,
-		TxnDate:   fromPgDate,
-		TxnDate_2: toPgDate,
-		DateTrunc: groupBy,
+		UserID:     user.ID,
+		TxnDate:    fromPgDate,
+		TxnDate_2:  toPgDate,
+		DateTrunc:  groupBy,
+		WeekOffset: trendWeekOffset(groupBy, prefs),
 	})
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -142,10 +146,32 @@ func (h *SummaryHandler) GetSummary(c fiber.Ctx) error {
 		})
 	}
 
-	// Convert trend to response format
-	trend := make([]NetFlowTrendPoint, 0, len(trendRows))
-	for _, row := range trendRows {
-		periodStr := formatPeriodTimestamp(row.Period, groupBy)
+	response := SummaryResponse{
+		KPIs:         kpis,
+		NetFlowTrend: trendPoints(trendRows, groupBy, loc),
+		FromDate:     models.NewDate(fromDate),
+		ToDate:       models.NewDate(toDate),
+		GroupBy:      groupBy,
+		Currency:     prefs.Currency,
+	}
+
+	return c.JSON(response)
+}
+
+// trendWeekOffset returns the GetCashFlowTrend week_offset for the user's week start;
+// other groupings are not shifted
+func trendWeekOffset(groupBy string, prefs models.UserPreferences) int32 {
+	if groupBy != "week" {
+		return 0
+	}
+	return weekOffset(prefs.WeekStart)
+}
+
+// trendPoints converts cash flow trend rows to response format
+func trendPoints(rows []db.GetCashFlowTrendRow, groupBy string, loc *time.Location) []NetFlowTrendPoint {
+	trend := make([]NetFlowTrendPoint, 0, len(rows))
+	for _, row := range rows {
+		periodStr := formatPeriodTimestamp(row.Period, groupBy, loc)
 
 		trend = append(trend, NetFlowTrendPoint{
 			Period:  periodStr,
@@ -154,16 +180,183 @@ func (h *SummaryHandler) GetSummary(c fiber.Ctx) error {
 			NetFlow: convertToFloat64(row.NetFlow),
 		})
 	}
+	return trend
+}
 
-	response := SummaryResponse{
-		KPIs:         kpis,
-		NetFlowTrend: trend,
-		FromDate:     fromDate.Format("2006-01-02"),
-		ToDate:       toDate.Format("2006-01-02"),
-		GroupBy:      groupBy,
+// ExportTrend handles GET /v1/summary/trend/export
+// Query params: from (date), to (date), group_by (day|week|month|year), format (csv)
+// Returns the same trend as GetSummary as a CSV download with a
+// period,inflow,outflow,net_flow header row.
+func (h *SummaryHandler) ExportTrend(c fiber.Ctx) error {
+	// Extract user ID from Clerk auth middleware
+	clerkUserID, ok := c.Locals("user_id").(string)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
 	}
 
-	return c.JSON(response)
+	// Get user from database using Clerk ID
+	user, err := h.queries.GetUserByClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	prefs, err := loadUserPreferences(c.Context(), h.queries, user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": fmt.Sprintf("Failed to fetch preferences: %s", err.Error()),
+		})
+	}
+	loc := location(prefs)
+
+	// Parse query parameters
+	if format := c.Query("format", "csv"); format != "csv" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid format parameter. Must be: csv",
+		})
+	}
+	groupBy := c.Query("group_by", "month")
+	if !validGroupBy[groupBy] {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid group_by parameter. Must be one of: day, week, month, year",
+		})
+	}
+	fromDate, toDate, err := summaryDateRange(c.Query("from"), c.Query("to"), loc)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	trendRows, err := h.queries.GetCashFlowTrend(c.Context(), db.GetCashFlowTrendParams{
+		UserID:     user.ID,
+		TxnDate:    pgtype.Date{Time: fromDate, Valid: true},
+		TxnDate_2:  pgtype.Date{Time: toDate, Valid: true},
+		DateTrunc:  groupBy,
+		WeekOffset: trendWeekOffset(groupBy, prefs),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": fmt.Sprintf("Failed to fetch cash flow trend: %s", err.Error()),
+		})
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"period", "inflow", "outflow", "net_flow"})
+	for _, point := range trendPoints(trendRows, groupBy, loc) {
+		writer.Write([]string{
+			point.Period,
+			strconv.FormatFloat(point.Inflow, 'f', 2, 64),
+			strconv.FormatFloat(point.Outflow, 'f', 2, 64),
+			strconv.FormatFloat(point.NetFlow, 'f', 2, 64),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": fmt.Sprintf("Failed to write CSV: %s", err.Error()),
+		})
+	}
+
+	filename := fmt.Sprintf("net-flow-trend_%s_%s_%s.csv",
+		fromDate.Format(models.DateLayout), toDate.Format(models.DateLayout), groupBy)
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Send(buf.Bytes())
+}
+
+// CategoryBreakdownItem is one category's share of the cash flow in a date range
+type CategoryBreakdownItem struct {
+	Category     string  `json:"category"`
+	TotalOutflow float64 `json:"total_outflow"`
+	TotalInflow  float64 `json:"total_inflow"`
+	Count        int64   `json:"count"`
+}
+
+// GetCategoryBreakdown handles GET /v1/summary/categories
+// Query params: from (date), to (date); same defaults as GetSummary.
+// Categories are sorted by outflow, largest first; uncategorized transactions
+// are reported as "Uncategorized".
+func (h *SummaryHandler) GetCategoryBreakdown(c fiber.Ctx) error {
+	// Extract user ID from Clerk auth middleware
+	clerkUserID, ok := c.Locals("user_id").(string)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	// Get user from database using Clerk ID
+	user, err := h.queries.GetUserByClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	prefs, err := loadUserPreferences(c.Context(), h.queries, user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": fmt.Sprintf("Failed to fetch preferences: %s", err.Error()),
+		})
+	}
+
+	fromDate, toDate, err := summaryDateRange(c.Query("from"), c.Query("to"), location(prefs))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	rows, err := h.queries.GetCategoryBreakdown(c.Context(), db.GetCategoryBreakdownParams{
+		UserID:    user.ID,
+		TxnDate:   pgtype.Date{Time: fromDate, Valid: true},
+		TxnDate_2: pgtype.Date{Time: toDate, Valid: true},
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": fmt.Sprintf("Failed to fetch category breakdown: %s", err.Error()),
+		})
+	}
+
+	categories := make([]CategoryBreakdownItem, 0, len(rows))
+	for _, row := range rows {
+		categories = append(categories, CategoryBreakdownItem{
+			Category:     row.Category,
+			TotalOutflow: convertToFloat64(row.TotalOutflow),
+			TotalInflow:  convertToFloat64(row.TotalInflow),
+			Count:        row.TransactionCount,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"categories": categories,
+		"from_date":  models.NewDate(fromDate),
+		"to_date":    models.NewDate(toDate),
+	})
+}
+
+// summaryDateRange parses the from/to query parameters, defaulting to the last
+// 12 months up to today in loc when either is missing
+func summaryDateRange(fromStr, toStr string, loc *time.Location) (time.Time, time.Time, error) {
+	if fromStr == "" || toStr == "" {
+		now := time.Now().In(loc)
+		toDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		return toDate.AddDate(-1, 0, 0), toDate, nil // 1 year ago
+	}
+	fromDate, err := time.Parse(models.DateLayout, fromStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("Invalid from date format: %s", err.Error())
+	}
+	toDate, err := time.Parse(models.DateLayout, toStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("Invalid to date format: %s", err.Error())
+	}
+	return fromDate, toDate, nil
 }
 
 // convertToFloat64 converts pgtype.Numeric or interface{} to float64
@@ -200,11 +393,15 @@ func convertToFloat64(val interface{}) float64 {
 	}
 }
 
-// formatPeriodTimestamp formats the period timestamp based on groupBy parameter
-func formatPeriodTimestamp(ts pgtype.Timestamp, groupBy string) string {
+// formatPeriodTimestamp formats the period timestamp based on groupBy parameter.
+// Periods are truncated transaction dates without a zone, so they are read as
+// wall-clock times in the user's time zone loc rather than converted.
+func formatPeriodTimestamp(ts pgtype.Timestamp, groupBy string, loc *time.Location) string {
 	if !ts.Valid {
 		return ""
 	}
+	ts.Time = time.Date(ts.Time.Year(), ts.Time.Month(), ts.Time.Day(),
+		ts.Time.Hour(), ts.Time.Minute(), ts.Time.Second(), 0, loc)
 
 	// Format based on groupBy
 	switch groupBy {
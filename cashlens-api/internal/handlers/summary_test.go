@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetCategoryBreakdown tests the per-category totals and the date range passed to the query
+func TestGetCategoryBreakdown(t *testing.T) {
+	userID := uuid.New()
+
+	var queried []interface{}
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetCategoryBreakdown": func(args []interface{}) [][]interface{} {
+			queried = args
+			return [][]interface{}{
+				{"Cloud & Hosting", interface{}(18500.0), interface{}(0.0), int64(3)},
+				{"Uncategorized", interface{}(1200.0), interface{}(250.0), int64(2)},
+				{"Salary", interface{}(0.0), interface{}(50000.0), int64(1)},
+			}
+		},
+	}}
+	handler := NewSummaryHandler(db.New(fake))
+
+	app := fiber.New()
+	app.Get("/summary/categories", func(c fiber.Ctx) error {
+		c.Locals("user_id", "user_test123")
+		return handler.GetCategoryBreakdown(c)
+	})
+
+	t.Run("Explicit range", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/summary/categories?from=2024-01-01&to=2024-01-31", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var result struct {
+			Categories []CategoryBreakdownItem `json:"categories"`
+			FromDate   string                  `json:"from_date"`
+			ToDate     string                  `json:"to_date"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+		assert.Equal(t, []CategoryBreakdownItem{
+			{Category: "Cloud & Hosting", TotalOutflow: 18500, TotalInflow: 0, Count: 3},
+			{Category: "Uncategorized", TotalOutflow: 1200, TotalInflow: 250, Count: 2},
+			{Category: "Salary", TotalOutflow: 0, TotalInflow: 50000, Count: 1},
+		}, result.Categories)
+		assert.Equal(t, "2024-01-01", result.FromDate)
+		assert.Equal(t, "2024-01-31", result.ToDate)
+
+		require.Len(t, queried, 3)
+		assert.Equal(t, pgtype.UUID{Bytes: userID, Valid: true}, queried[0])
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), queried[1].(pgtype.Date).Time)
+		assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), queried[2].(pgtype.Date).Time)
+	})
+
+	t.Run("Defaults to the last 12 months", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/summary/categories", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		from := queried[1].(pgtype.Date).Time
+		to := queried[2].(pgtype.Date).Time
+		assert.Equal(t, to.AddDate(-1, 0, 0), from)
+	})
+
+	t.Run("Invalid date", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/summary/categories?from=01-01-2024&to=2024-01-31", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+// TestExportTrend tests that the CSV export carries the same rows as the JSON trend
+func TestExportTrend(t *testing.T) {
+	userID := uuid.New()
+	month := func(m time.Month) pgtype.Timestamp {
+		return pgtype.Timestamp{Time: time.Date(2024, m, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	}
+
+	var dateTrunc string
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetKPIs": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{{interface{}(100000.0), interface{}(21500.5), interface{}(78499.5), int64(5)}}
+		},
+		"GetCashFlowTrend": func(args []interface{}) [][]interface{} {
+			dateTrunc = args[3].(string)
+			return [][]interface{}{
+				{month(time.January), interface{}(50000.0), interface{}(3500.0), interface{}(46500.0)},
+				{month(time.February), interface{}(50000.0), interface{}(18000.5), interface{}(31999.5)},
+			}
+		},
+	}}
+	handler := NewSummaryHandler(db.New(fake))
+
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals("user_id", "user_test123")
+		return c.Next()
+	})
+	app.Get("/summary", handler.GetSummary)
+	app.Get("/summary/trend/export", handler.ExportTrend)
+
+	query := "?from=2024-01-01&to=2024-02-29&group_by=month"
+	resp, err := app.Test(httptest.NewRequest("GET", "/summary/trend/export"+query+"&format=csv", nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="net-flow-trend_2024-01-01_2024-02-29_month.csv"`, resp.Header.Get("Content-Disposition"))
+	assert.Equal(t, "month", dateTrunc)
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	require.NoError(t, err)
+
+	// Every CSV row matches the JSON trend point for the same period
+	summaryResp, err := app.Test(httptest.NewRequest("GET", "/summary"+query, nil))
+	require.NoError(t, err)
+	defer summaryResp.Body.Close()
+	var summary SummaryResponse
+	require.NoError(t, json.NewDecoder(summaryResp.Body).Decode(&summary))
+
+	require.Len(t, records, len(summary.NetFlowTrend)+1)
+	assert.Equal(t, []string{"period", "inflow", "outflow", "net_flow"}, records[0])
+	for i, point := range summary.NetFlowTrend {
+		assert.Equal(t, []string{
+			point.Period,
+			strconv.FormatFloat(point.Inflow, 'f', 2, 64),
+			strconv.FormatFloat(point.Outflow, 'f', 2, 64),
+			strconv.FormatFloat(point.NetFlow, 'f', 2, 64),
+		}, records[i+1])
+	}
+	assert.Equal(t, []string{"2024-02", "50000.00", "18000.50", "31999.50"}, records[2])
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/summary/trend/export"+query+"&format=xlsx", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Invalid group_by", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/summary/trend/export?group_by=hour", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
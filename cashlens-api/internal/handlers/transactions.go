@@ -1,20 +1,74 @@
 package handlers
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/ashmitsharp/cashlens-api/internal/models"
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// RuleSuggester interface defines methods for suggesting user rules from a user's transactions
+type RuleSuggester interface {
+	Suggest(transactions []models.Transaction) []models.RuleSuggestion
+}
+
+// namedSuggester is a RuleSuggester served at /v1/transactions/suggestions/:kind
+type namedSuggester struct {
+	kind      string
+	suggester RuleSuggester
+}
+
+// RecurringDetector interface defines methods for finding recurring charges such as subscriptions
+type RecurringDetector interface {
+	Detect(transactions []models.Transaction) []models.RecurringGroup
+}
+
+// RuleLearner interface defines how a rule keyword is derived from a corrected transaction
+type RuleLearner interface {
+	Keyword(description string) string
+}
+
+// LearnedRulePriority ranks rules learned from category corrections above the default
+// user rule priority, since the user explicitly corrected that merchant. It is clamped
+// into the configured user rule priority bounds.
+const LearnedRulePriority int32 = 500
+
+// CategoryWhitelist interface defines the check for a controlled category taxonomy
+type CategoryWhitelist interface {
+	Allows(category string) bool
+}
+
+// checkCategory rejects categories outside the whitelist; a nil whitelist allows any category
+func checkCategory(whitelist CategoryWhitelist, category string) error {
+	if whitelist == nil || whitelist.Allows(category) {
+		return nil
+	}
+	return fmt.Errorf("category %q is not in the allowed category list", category)
+}
+
 // TransactionHandler handles transaction-related requests
 type TransactionHandler struct {
 	db          *db.Queries
 	categorizer Categorizer
+	stats       StatsService
+	suggesters  []namedSuggester
+	categories  CategoryWhitelist
+	learner     RuleLearner
+	recurring   RecurringDetector
+	priorities  priorityBounds // Bounds for the priority of learned rules
+	maxPageSize int            // Largest limit list endpoints return (0 = DefaultMaxPageSize)
 }
 
 // NewTransactionHandler creates a new transaction handler
@@ -22,6 +76,61 @@ func NewTransactionHandler(database *db.Queries, categorizer Categorizer) *Trans
 	return &TransactionHandler{
 		db:          database,
 		categorizer: categorizer,
+		priorities:  defaultPriorityBounds(),
+	}
+}
+
+// SetPriorityBounds sets the user rule priority range learned rules are clamped into,
+// matching RulesHandler.SetPriorityBounds
+func (h *TransactionHandler) SetPriorityBounds(minPriority, maxPriority int32, policy string) {
+	h.priorities = newPriorityBounds(minPriority, maxPriority, policy)
+}
+
+// SetStatsService enables cached stats, recomputed after bulk operations
+func (h *TransactionHandler) SetStatsService(stats StatsService) {
+	h.stats = stats
+}
+
+// AddRuleSuggester enables rule suggestions of the given kind (e.g. "salary");
+// adding a kind again replaces its suggester
+func (h *TransactionHandler) AddRuleSuggester(kind string, suggester RuleSuggester) {
+	for i := range h.suggesters {
+		if h.suggesters[i].kind == kind {
+			h.suggesters[i].suggester = suggester
+			return
+		}
+	}
+	h.suggesters = append(h.suggesters, namedSuggester{kind: kind, suggester: suggester})
+}
+
+// SetCategoryWhitelist restricts category updates to a controlled taxonomy
+func (h *TransactionHandler) SetCategoryWhitelist(categories CategoryWhitelist) {
+	h.categories = categories
+}
+
+// SetRuleLearner enables create_rule on category updates
+func (h *TransactionHandler) SetRuleLearner(learner RuleLearner) {
+	h.learner = learner
+}
+
+// SetMaxPageSize sets the largest limit the transaction list, search and issues endpoints accept;
+// larger limits are clamped
+func (h *TransactionHandler) SetMaxPageSize(size int) {
+	h.maxPageSize = size
+}
+
+// SetRecurringDetector enables recurring transaction detection
+func (h *TransactionHandler) SetRecurringDetector(recurring RecurringDetector) {
+	h.recurring = recurring
+}
+
+// recomputeStats refreshes the user's cached stats after a data change
+func (h *TransactionHandler) recomputeStats(ctx context.Context, userID uuid.UUID) {
+	if h.stats == nil {
+		return
+	}
+	if _, err := h.stats.RecomputeUserStats(ctx, userID); err != nil {
+		fmt.Printf("Failed to recompute stats: %v\n", err)
 	}
 }
 
@@ -37,8 +146,15 @@ func (h *TransactionHandler) getUserUUIDFromClerkID(ctx context.Context, clerkUs
 	return userUUID, nil
 }
 
-// GetTransactions returns transactions with optional filtering
-// GET /v1/transactions?status=all|categorized|uncategorized&limit=50&offset=0
+// MIMEApplicationNDJSON is the Accept type that selects streaming transaction lists
+const MIMEApplicationNDJSON = "application/x-ndjson"
+
+// GetTransactions returns transactions with optional filtering. With
+// "Accept: application/x-ndjson" every transaction is streamed instead, one JSON
+// object per line, without pagination or filters. min_confidence and max_confidence
+// (0-1, inclusive) keep auto-categorized transactions within that category_score range,
+// e.g. max_confidence=0.5 to review uncertain categorizations.
+// GET /v1/transactions?status=all|categorized|uncategorized&source=csv|xlsx|xls|pdf|manual|api&from=YYYY-MM-DD&to=YYYY-MM-DD&category=Travel&min_confidence=0&max_confidence=1&limit=50&offset=0
 func (h *TransactionHandler) GetTransactions(c fiber.Ctx) error {
 	// 1. Get clerk_user_id from context
 	clerkUserID, ok := c.Locals("clerk_user_id").(string)
@@ -56,33 +172,157 @@ func (h *TransactionHandler) GetTransactions(c fiber.Ctx) error {
 		})
 	}
 
+	if strings.Contains(c.Get(fiber.HeaderAccept), MIMEApplicationNDJSON) {
+		return h.streamTransactions(c, userUUID)
+	}
+
 	// 3. Parse query parameters
 	status := c.Query("status", "all")
-	limitStr := c.Query("limit", "50")
+	source := c.Query("source")
 	offsetStr := c.Query("offset", "0")
 
-	limit, err := strconv.ParseInt(limitStr, 10, 32)
-	if err != nil || limit < 1 || limit > 100 {
-		limit = 50
-	}
+	limit := pageLimit(c, 50, h.maxPageSize)
 
 	offset, err := strconv.ParseInt(offsetStr, 10, 32)
 	if err != nil || offset < 0 {
 		offset = 0
 	}
 
+	if source != "" && !models.ValidSources[source] {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid source - must be one of: csv, xlsx, xls, pdf, ofx, qif, manual, api",
+		})
+	}
+
+	// Optional date range (inclusive) and category filters
+	fromStr := c.Query("from")
+	toStr := c.Query("to")
+	category := strings.TrimSpace(c.Query("category"))
+	fromDate := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	if fromStr != "" {
+		parsed, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid from date, expected YYYY-MM-DD",
+			})
+		}
+		fromDate = parsed
+	}
+	if toStr != "" {
+		parsed, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid to date, expected YYYY-MM-DD",
+			})
+		}
+		toDate = parsed
+	}
+	if fromDate.After(toDate) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "from date must not be after to date",
+		})
+	}
+	minConfidence, err := parseConfidence(c.Query("min_confidence"), 0)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid min_confidence",
+			"details": err.Error(),
+		})
+	}
+	maxConfidence, err := parseConfidence(c.Query("max_confidence"), 1)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid max_confidence",
+			"details": err.Error(),
+		})
+	}
+	if minConfidence > maxConfidence {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "min_confidence must not be greater than max_confidence",
+		})
+	}
+	confidenceFiltered := minConfidence > 0 || maxConfidence < 1
+	filtered := fromStr != "" || toStr != "" || category != "" || confidenceFiltered
+
 	// 4. Convert to pgtype.UUID
 	var pgUserID pgtype.UUID
 	pgUserID.Bytes = userUUID
 	pgUserID.Valid = true
 
-	// 4. Query transactions based on status filter
+	// 4. Query transactions based on status filter. The list queries return the
+	// full match count with each row, so a separate count query is only needed
+	// when the requested page is past the end.
 	var transactions interface{}
 	var totalCount int64
 
-	switch status {
-	case "uncategorized":
-		transactions, err = h.db.GetUncategorizedTransactions(c.Context(), db.GetUncategorizedTransactionsParams{
+	switch {
+	case filtered:
+		if status != "categorized" && status != "uncategorized" {
+			status = "all"
+		}
+		rows, err := h.db.GetUserTransactionsFiltered(c.Context(), db.GetUserTransactionsFilteredParams{
+			UserID:    pgUserID,
+			TxnDate:   pgtype.Date{Time: fromDate, Valid: true},
+			TxnDate_2: pgtype.Date{Time: toDate, Valid: true},
+			Column4:   status,
+			Column5:   source,
+			Column6:   category,
+			Column7:   minConfidence,
+			Column8:   maxConfidence,
+			Limit:     int32(limit),
+			Offset:    int32(offset),
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch transactions",
+			})
+		}
+		transactions = rows
+		if len(rows) > 0 {
+			totalCount = rows[0].TotalCount
+		} else if offset > 0 {
+			totalCount, _ = h.db.CountUserTransactionsFiltered(c.Context(), db.CountUserTransactionsFilteredParams{
+				UserID:    pgUserID,
+				TxnDate:   pgtype.Date{Time: fromDate, Valid: true},
+				TxnDate_2: pgtype.Date{Time: toDate, Valid: true},
+				Column4:   status,
+				Column5:   source,
+				Column6:   category,
+				Column7:   minConfidence,
+				Column8:   maxConfidence,
+			})
+		}
+
+	case source != "":
+		if status != "categorized" && status != "uncategorized" {
+			status = "all"
+		}
+		rows, err := h.db.GetUserTransactionsBySource(c.Context(), db.GetUserTransactionsBySourceParams{
+			UserID:  pgUserID,
+			Source:  pgtype.Text{String: source, Valid: true},
+			Column3: status,
+			Limit:   int32(limit),
+			Offset:  int32(offset),
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch transactions",
+			})
+		}
+		transactions = rows
+		if len(rows) > 0 {
+			totalCount = rows[0].TotalCount
+		} else if offset > 0 {
+			totalCount, _ = h.db.CountUserTransactionsBySource(c.Context(), db.CountUserTransactionsBySourceParams{
+				UserID:  pgUserID,
+				Source:  pgtype.Text{String: source, Valid: true},
+				Column3: status,
+			})
+		}
+
+	case status == "uncategorized":
+		rows, err := h.db.GetUncategorizedTransactions(c.Context(), db.GetUncategorizedTransactionsParams{
 			UserID: pgUserID,
 			Limit:  int32(limit),
 			Offset: int32(offset),
@@ -92,10 +332,15 @@ func (h *TransactionHandler) GetTransactions(c fiber.Ctx) error {
 				"error": "failed to fetch uncategorized transactions",
 			})
 		}
-		totalCount, _ = h.db.CountUncategorizedTransactions(c.Context(), pgUserID)
+		transactions = rows
+		if len(rows) > 0 {
+			totalCount = rows[0].TotalCount
+		} else if offset > 0 {
+			totalCount, _ = h.db.CountUncategorizedTransactions(c.Context(), pgUserID)
+		}
 
-	case "categorized":
-		transactions, err = h.db.GetCategorizedTransactions(c.Context(), db.GetCategorizedTransactionsParams{
+	case status == "categorized":
+		rows, err := h.db.GetCategorizedTransactions(c.Context(), db.GetCategorizedTransactionsParams{
 			UserID: pgUserID,
 			Limit:  int32(limit),
 			Offset: int32(offset),
@@ -105,10 +350,15 @@ func (h *TransactionHandler) GetTransactions(c fiber.Ctx) error {
 				"error": "failed to fetch categorized transactions",
 			})
 		}
-		totalCount, _ = h.db.CountCategorizedTransactions(c.Context(), pgUserID)
+		transactions = rows
+		if len(rows) > 0 {
+			totalCount = rows[0].TotalCount
+		} else if offset > 0 {
+			totalCount, _ = h.db.CountCategorizedTransactions(c.Context(), pgUserID)
+		}
 
 	default: // "all"
-		transactions, err = h.db.GetUserTransactions(c.Context(), db.GetUserTransactionsParams{
+		rows, err := h.db.GetUserTransactions(c.Context(), db.GetUserTransactionsParams{
 			UserID: pgUserID,
 			Limit:  int32(limit),
 			Offset: int32(offset),
@@ -118,7 +368,119 @@ func (h *TransactionHandler) GetTransactions(c fiber.Ctx) error {
 				"error": "failed to fetch transactions",
 			})
 		}
-		totalCount, _ = h.db.CountUserTransactions(c.Context(), pgUserID)
+		transactions = rows
+		if len(rows) > 0 {
+			totalCount = rows[0].TotalCount
+		} else if offset > 0 {
+			totalCount, _ = h.db.CountUserTransactions(c.Context(), pgUserID)
+		}
+	}
+
+	// 5. Return response
+	return c.JSON(fiber.Map{
+		"transactions": transactions,
+		"total":        totalCount,
+		"limit":        limit,
+		"offset":       offset,
+	})
+}
+
+// streamTransactions writes all of a user's transactions as newline-delimited JSON,
+// encoding each row as it is read from the database so memory stays flat however
+// many transactions the user has
+func (h *TransactionHandler) streamTransactions(c fiber.Ctx, userUUID uuid.UUID) error {
+	for _, param := range []string{"status", "source", "from", "to", "category", "min_confidence", "max_confidence"} {
+		if c.Query(param) != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("%s filter is not supported when streaming transactions", param),
+			})
+		}
+	}
+
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userUUID
+	pgUserID.Valid = true
+
+	// The writer runs after the handler returns, so it must not touch c
+	ctx := c.Context()
+	c.Set(fiber.HeaderContentType, MIMEApplicationNDJSON)
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		encoder := json.NewEncoder(w)
+		err := h.db.StreamAllTransactions(ctx, pgUserID, func(txn db.Transaction) error {
+			return encoder.Encode(txn)
+		})
+		if err != nil {
+			fmt.Printf("Failed to stream transactions: %v\n", err)
+		}
+		w.Flush()
+	})
+}
+
+// likeEscaper escapes LIKE/ILIKE wildcards so search terms match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchTransactions finds transactions whose description contains the query (case-insensitive)
+// GET /v1/transactions/search?q=aws&limit=20&offset=0
+func (h *TransactionHandler) SearchTransactions(c fiber.Ctx) error {
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 2. Look up user's UUID
+	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	// 3. Parse query parameters
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "search query (q) is required",
+		})
+	}
+
+	limit := pageLimit(c, 20, h.maxPageSize)
+
+	offset, err := strconv.ParseInt(c.Query("offset", "0"), 10, 32)
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	// Convert to pgtype.UUID
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userUUID
+	pgUserID.Valid = true
+
+	// 4. Search descriptions; the count query is only needed past the last page
+	pattern := likeEscaper.Replace(query)
+	transactions, err := h.db.SearchUserTransactions(c.Context(), db.SearchUserTransactionsParams{
+		UserID:  pgUserID,
+		Column2: pattern,
+		Limit:   int32(limit),
+		Offset:  int32(offset),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to search transactions",
+			"details": err.Error(),
+		})
+	}
+
+	var totalCount int64
+	if len(transactions) > 0 {
+		totalCount = transactions[0].TotalCount
+	} else if offset > 0 {
+		totalCount, _ = h.db.CountSearchUserTransactions(c.Context(), db.CountSearchUserTransactionsParams{
+			UserID:  pgUserID,
+			Column2: pattern,
+		})
 	}
 
 	// 5. Return response
@@ -127,12 +489,103 @@ func (h *TransactionHandler) GetTransactions(c fiber.Ctx) error {
 		"total":        totalCount,
 		"limit":        limit,
 		"offset":       offset,
+		"query":        query,
+	})
+}
+
+// GetTransactionCount returns only the number of matching transactions
+// GET /v1/transactions/count?status=all|categorized|uncategorized&from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *TransactionHandler) GetTransactionCount(c fiber.Ctx) error {
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 2. Parse query parameters
+	status := c.Query("status", "all")
+	if status != "all" && status != "categorized" && status != "uncategorized" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid status - must be one of: all, categorized, uncategorized",
+		})
+	}
+
+	fromStr := c.Query("from")
+	toStr := c.Query("to")
+	fromDate := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	if fromStr != "" {
+		parsed, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid from date, expected YYYY-MM-DD",
+			})
+		}
+		fromDate = parsed
+	}
+	if toStr != "" {
+		parsed, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid to date, expected YYYY-MM-DD",
+			})
+		}
+		toDate = parsed
+	}
+	if toDate.Before(fromDate) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "to date must not be before from date",
+		})
+	}
+
+	// 3. Look up user's UUID from clerk_user_id
+	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userUUID
+	pgUserID.Valid = true
+
+	// 4. Count with the cheapest query for the filters given
+	var count int64
+	switch {
+	case fromStr != "" || toStr != "":
+		count, err = h.db.CountUserTransactionsInRange(c.Context(), db.CountUserTransactionsInRangeParams{
+			UserID:    pgUserID,
+			TxnDate:   pgtype.Date{Time: fromDate, Valid: true},
+			TxnDate_2: pgtype.Date{Time: toDate, Valid: true},
+			Column4:   status,
+		})
+	case status == "categorized":
+		count, err = h.db.CountCategorizedTransactions(c.Context(), pgUserID)
+	case status == "uncategorized":
+		count, err = h.db.CountUncategorizedTransactions(c.Context(), pgUserID)
+	default:
+		count, err = h.db.CountUserTransactions(c.Context(), pgUserID)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to count transactions",
+			"details": err.Error(),
+		})
+	}
+
+	// 5. Return response
+	return c.JSON(fiber.Map{
+		"count": count,
 	})
 }
 
 // UpdateTransactionRequest represents the request body for updating a transaction
 type UpdateTransactionRequest struct {
-	Category string `json:"category"`
+	Category   string `json:"category"`
+	CreateRule bool   `json:"create_rule"` // Also learn a user rule so similar transactions get this category
 }
 
 // UpdateTransaction updates a transaction's category and marks it as reviewed
@@ -177,6 +630,11 @@ func (h *TransactionHandler) UpdateTransaction(c fiber.Ctx) error {
 			"error": "category is required",
 		})
 	}
+	if err := checkCategory(h.categories, req.Category); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 
 	// 5. Convert to pgtype.UUID
 	var pgTxnID pgtype.UUID
@@ -212,27 +670,88 @@ func (h *TransactionHandler) UpdateTransaction(c fiber.Ctx) error {
 		})
 	}
 
-	// 9. Invalidate user cache to force reload with new rule
+	// 9. Optionally learn a rule from the correction
+	response := fiber.Map{
+		"transaction": updated,
+		"message":     "Transaction updated successfully",
+	}
+	if req.CreateRule {
+		rule, conflict, err := h.learnRule(c.Context(), transaction.UserID, transaction.Description, req.Category)
+		if err != nil {
+			// The category is already saved, so report the rule failure without failing the update
+			response["rule_error"] = err.Error()
+		}
+		response["rule_created"] = rule != nil
+		if rule != nil {
+			response["rule"] = rule
+		}
+		if conflict != nil {
+			// Left unchanged; the user decides whether to edit or replace it
+			response["rule_conflict"] = conflict
+		}
+	}
+
+	// 10. Invalidate user cache to force reload with new rule
 	if h.categorizer != nil {
 		h.categorizer.InvalidateUserCache(userUUID)
 	}
+	h.recomputeStats(c.Context(), userUUID)
 
-	// 10. Return updated transaction
-	return c.JSON(fiber.Map{
-		"transaction": updated,
-		"message":     "Transaction updated successfully",
-	})
+	// 11. Return updated transaction
+	return c.JSON(response)
 }
 
-// BulkUpdateRequest represents the request body for bulk updating transactions
-type BulkUpdateRequest struct {
-	TransactionIDs []string `json:"transaction_ids"`
-	Category       string   `json:"category"`
+// learnRule creates a substring user rule for the merchant keyword of a corrected
+// transaction. Existing rules are never changed: when the user already has an active
+// rule with that keyword pointing at another category, it is returned as a conflict
+// instead. Both results are nil when no keyword can be derived or the existing rule
+// already maps to the category.
+func (h *TransactionHandler) learnRule(ctx context.Context, userID pgtype.UUID, description, category string) (created, conflict *db.UserCategorizationRule, err error) {
+	if h.learner == nil {
+		return nil, nil, nil
+	}
+	keyword := h.learner.Keyword(description)
+	if keyword == "" {
+		return nil, nil, nil
+	}
+
+	existing, err := h.db.GetUserRuleByKeyword(ctx, db.GetUserRuleByKeywordParams{
+		UserID:  userID,
+		Keyword: keyword,
+	})
+	if err == nil {
+		if existing.Category == category {
+			return nil, nil, nil
+		}
+		return nil, &existing, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, fmt.Errorf("failed to check existing rules: %w", err)
+	}
+
+	var threshold pgtype.Numeric
+	if err := threshold.Scan("0.3"); err != nil {
+		return nil, nil, fmt.Errorf("invalid similarity threshold: %w", err)
+	}
+	rule, err := h.db.CreateUserRule(ctx, db.CreateUserRuleParams{
+		UserID:              userID,
+		Keyword:             keyword,
+		Category:            category,
+		Priority:            pgtype.Int4{Int32: h.priorities.clamp(LearnedRulePriority), Valid: true},
+		MatchType:           pgtype.Text{String: "substring", Valid: true},
+		SimilarityThreshold: threshold,
+		IsActive:            pgtype.Bool{Bool: true, Valid: true},
+		Tags:                []string{},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create rule: %w", err)
+	}
+	return &rule, nil, nil
 }
 
-// BulkUpdateTransactions updates multiple transactions at once
-// PUT /v1/transactions/bulk
-func (h *TransactionHandler) BulkUpdateTransactions(c fiber.Ctx) error {
+// DeleteTransaction removes a transaction that was parsed in error or duplicated
+// DELETE /v1/transactions/:id
+func (h *TransactionHandler) DeleteTransaction(c fiber.Ctx) error {
 	// 1. Get clerk_user_id from context
 	clerkUserID, ok := c.Locals("clerk_user_id").(string)
 	if !ok || clerkUserID == "" {
@@ -241,7 +760,7 @@ func (h *TransactionHandler) BulkUpdateTransactions(c fiber.Ctx) error {
 		})
 	}
 
-	// 1.5. Look up user's UUID
+	// 2. Look up user's UUID
 	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -249,26 +768,329 @@ func (h *TransactionHandler) BulkUpdateTransactions(c fiber.Ctx) error {
 		})
 	}
 
-	// 2. Parse request body
-	var req BulkUpdateRequest
-	if err := c.Bind().JSON(&req); err != nil {
+	// 3. Get transaction ID from URL
+	txnID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
+			"error": "invalid transaction ID",
 		})
 	}
 
-	// 3. Validate request
-	if len(req.TransactionIDs) == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "transaction_ids cannot be empty",
-		})
-	}
+	var pgTxnID pgtype.UUID
+	pgTxnID.Bytes = txnID
+	pgTxnID.Valid = true
 
-	if req.Category == "" {
+	// 4. Get transaction to verify ownership
+	transaction, err := h.db.GetTransactionByID(c.Context(), pgTxnID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "transaction not found",
+		})
+	}
+
+	// 5. Verify user owns this transaction
+	var transactionUserID uuid.UUID
+	copy(transactionUserID[:], transaction.UserID.Bytes[:])
+	if transactionUserID != userUUID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "forbidden - cannot delete this transaction",
+		})
+	}
+
+	// 6. Delete transaction
+	if err := h.db.DeleteTransaction(c.Context(), pgTxnID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to delete transaction",
+		})
+	}
+
+	// Rules are unaffected, so only the cached stats need refreshing
+	h.recomputeStats(c.Context(), userUUID)
+
+	return c.JSON(fiber.Map{
+		"message": "Transaction deleted successfully",
+	})
+}
+
+// ClearCategories removes categories so transactions can be recategorized from scratch.
+// Manually reviewed transactions are kept unless include_reviewed=true.
+// POST /v1/transactions/clear-categories?include_reviewed=true
+func (h *TransactionHandler) ClearCategories(c fiber.Ctx) error {
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 2. Parse query parameters
+	includeReviewed := false
+	if includeStr := c.Query("include_reviewed"); includeStr != "" {
+		parsed, err := strconv.ParseBool(includeStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid include_reviewed - must be true or false",
+			})
+		}
+		includeReviewed = parsed
+	}
+
+	// 3. Look up user's UUID
+	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userUUID
+	pgUserID.Valid = true
+
+	// 4. Clear categories
+	cleared, err := h.db.ClearTransactionCategories(c.Context(), db.ClearTransactionCategoriesParams{
+		UserID:  pgUserID,
+		Column2: includeReviewed,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to clear categories",
+			"details": err.Error(),
+		})
+	}
+	h.recomputeStats(c.Context(), userUUID)
+
+	// 5. Return response
+	return c.JSON(fiber.Map{
+		"cleared_count":    cleared,
+		"include_reviewed": includeReviewed,
+		"message":          fmt.Sprintf("Cleared categories on %d transactions", cleared),
+	})
+}
+
+// FixSigns repairs transactions whose amount sign disagrees with txn_type, e.g. after
+// a parser bug stored a bank's debits as positive amounts. By default the amount sign
+// is recomputed from txn_type; from=amount recomputes txn_type from the sign instead.
+// POST /v1/transactions/fix-signs?bank=HDFC&from=type
+func (h *TransactionHandler) FixSigns(c fiber.Ctx) error {
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 2. Parse query parameters
+	bank := strings.TrimSpace(c.Query("bank"))
+	from := c.Query("from", "type")
+	if from != "type" && from != "amount" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid from - must be 'type' or 'amount'",
+		})
+	}
+
+	// 3. Look up user's UUID
+	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userUUID
+	pgUserID.Valid = true
+
+	// 4. Correct whichever side disagrees with the trusted one
+	var fixed int64
+	if from == "amount" {
+		fixed, err = h.db.FixTransactionTypes(c.Context(), db.FixTransactionTypesParams{
+			UserID:  pgUserID,
+			Column2: bank,
+		})
+	} else {
+		fixed, err = h.db.FixTransactionAmountSigns(c.Context(), db.FixTransactionAmountSignsParams{
+			UserID:  pgUserID,
+			Column2: bank,
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to fix transaction signs",
+			"details": err.Error(),
+		})
+	}
+	if fixed > 0 {
+		h.recomputeStats(c.Context(), userUUID)
+	}
+
+	// 5. Return response
+	return c.JSON(fiber.Map{
+		"fixed_count": fixed,
+		"bank":        bank,
+		"from":        from,
+		"message":     fmt.Sprintf("Fixed %d transactions", fixed),
+	})
+}
+
+// BulkTagRequest represents the request body for tagging transactions by filter
+type BulkTagRequest struct {
+	Description string   `json:"description"`
+	Bank        string   `json:"bank"`
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	Tags        []string `json:"tags"`
+}
+
+// BulkTagTransactions adds tags to every transaction matching a filter
+// POST /v1/transactions/bulk-tag
+func (h *TransactionHandler) BulkTagTransactions(c fiber.Ctx) error {
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 2. Parse request body
+	var req BulkTagRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	// 3. Validate request; an empty filter would tag every transaction
+	tags := models.NormalizeTags(req.Tags)
+	if len(tags) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "at least one tag is required",
+		})
+	}
+	description := strings.TrimSpace(req.Description)
+	bank := strings.TrimSpace(req.Bank)
+	if description == "" && bank == "" && req.From == "" && req.To == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "at least one filter (description, bank, from, to) is required",
+		})
+	}
+
+	fromDate := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	if req.From != "" {
+		parsed, err := time.Parse("2006-01-02", req.From)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid from date, expected YYYY-MM-DD",
+			})
+		}
+		fromDate = parsed
+	}
+	if req.To != "" {
+		parsed, err := time.Parse("2006-01-02", req.To)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid to date, expected YYYY-MM-DD",
+			})
+		}
+		toDate = parsed
+	}
+	if fromDate.After(toDate) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "from date must not be after to date",
+		})
+	}
+
+	// 4. Look up user's UUID
+	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userUUID
+	pgUserID.Valid = true
+
+	// 5. Tag all matching transactions in one update
+	tagged, err := h.db.AddTransactionTags(c.Context(), db.AddTransactionTagsParams{
+		UserID:    pgUserID,
+		Column2:   likeEscaper.Replace(description),
+		Column3:   bank,
+		TxnDate:   pgtype.Date{Time: fromDate, Valid: true},
+		TxnDate_2: pgtype.Date{Time: toDate, Valid: true},
+		Column6:   tags,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to tag transactions",
+			"details": err.Error(),
+		})
+	}
+
+	// 6. Return response
+	return c.JSON(fiber.Map{
+		"tagged_count": tagged,
+		"tags":         tags,
+		"message":      fmt.Sprintf("Tagged %d transactions", tagged),
+	})
+}
+
+// BulkUpdateRequest represents the request body for bulk updating transactions
+type BulkUpdateRequest struct {
+	TransactionIDs []string `json:"transaction_ids"`
+	Category       string   `json:"category"`
+}
+
+// BulkUpdateTransactions updates multiple transactions at once
+// PUT /v1/transactions/bulk
+func (h *TransactionHandler) BulkUpdateTransactions(c fiber.Ctx) error {
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 1.5. Look up user's UUID
+	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	// 2. Parse request body
+	var req BulkUpdateRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	// 3. Validate request
+	if len(req.TransactionIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "transaction_ids cannot be empty",
+		})
+	}
+
+	if req.Category == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "category is required",
 		})
 	}
+	if err := checkCategory(h.categories, req.Category); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 
 	// 4. Update each transaction
 	updatedCount := 0
@@ -315,10 +1137,11 @@ func (h *TransactionHandler) BulkUpdateTransactions(c fiber.Ctx) error {
 		updatedCount++
 	}
 
-	// 5. Invalidate user cache
+	// 5. Invalidate user cache and recompute cached stats
 	if h.categorizer != nil {
 		h.categorizer.InvalidateUserCache(userUUID)
 	}
+	h.recomputeStats(c.Context(), userUUID)
 
 	// 6. Return response
 	response := fiber.Map{
@@ -354,12 +1177,23 @@ func (h *TransactionHandler) GetTransactionStats(c fiber.Ctx) error {
 		})
 	}
 
+	// 3. Serve from the stats cache when available
+	if h.stats != nil {
+		cached, err := h.stats.GetUserStats(c.Context(), userUUID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to fetch transaction statistics",
+			})
+		}
+		return c.JSON(cached)
+	}
+
 	// Convert to pgtype.UUID
 	var pgUserID pgtype.UUID
 	pgUserID.Bytes = userUUID
 	pgUserID.Valid = true
 
-	// 3. Get stats
+	// 4. Get stats
 	stats, err := h.db.GetTransactionStats(c.Context(), pgUserID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -367,7 +1201,7 @@ func (h *TransactionHandler) GetTransactionStats(c fiber.Ctx) error {
 		})
 	}
 
-	// 4. Return stats
+	// 5. Return stats
 	return c.JSON(fiber.Map{
 		"total_transactions":      stats.TotalCount,
 		"categorized_count":       stats.CategorizedCount,
@@ -375,3 +1209,289 @@ func (h *TransactionHandler) GetTransactionStats(c fiber.Ctx) error {
 		"accuracy_percent":        stats.AccuracyPercent,
 	})
 }
+
+// BankCoverage summarizes the transactions imported from one bank
+type BankCoverage struct {
+	Bank             string `json:"bank"`
+	TransactionCount int64  `json:"transaction_count"`
+	FirstTxnDate     string `json:"first_txn_date"`
+	LastTxnDate      string `json:"last_txn_date"`
+}
+
+// GetBanks lists the banks the user has imported transactions from, with the number
+// of transactions and the date range covered for each, most transactions first
+// GET /v1/me/banks
+func (h *TransactionHandler) GetBanks(c fiber.Ctx) error {
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 2. Look up user's UUID
+	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userUUID
+	pgUserID.Valid = true
+
+	// 3. Group transactions by the bank of their upload
+	rows, err := h.db.GetUserBanks(c.Context(), pgUserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to fetch banks",
+			"details": err.Error(),
+		})
+	}
+
+	banks := make([]BankCoverage, 0, len(rows))
+	for _, row := range rows {
+		banks = append(banks, BankCoverage{
+			Bank:             row.Bank,
+			TransactionCount: row.TransactionCount,
+			FirstTxnDate:     formatDate(row.FirstTxnDate),
+			LastTxnDate:      formatDate(row.LastTxnDate),
+		})
+	}
+
+	// 4. Return banks
+	return c.JSON(fiber.Map{
+		"banks": banks,
+		"total": len(banks),
+	})
+}
+
+// parseConfidence parses a category confidence bound between 0 and 1; empty uses fallback
+func parseConfidence(value string, fallback float64) (float64, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	confidence, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(confidence) || confidence < 0 || confidence > 1 {
+		return 0, fmt.Errorf("must be a number between 0 and 1, got %q", value)
+	}
+	return confidence, nil
+}
+
+// formatDate formats a date column as YYYY-MM-DD, or "" when it is NULL
+func formatDate(d pgtype.Date) string {
+	if !d.Valid {
+		return ""
+	}
+	return d.Time.Format("2006-01-02")
+}
+
+// GetTransactionIssues returns transactions flagged during import for review
+// GET /v1/transactions/issues?flag=bad_date|zero_amount|reconciliation_mismatch&limit=50&offset=0
+func (h *TransactionHandler) GetTransactionIssues(c fiber.Ctx) error {
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 2. Look up user's UUID
+	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	// 3. Parse query parameters
+	flag := c.Query("flag")
+	if flag != "" && !models.ValidFlags[flag] {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid flag - must be one of: bad_date, zero_amount, reconciliation_mismatch",
+		})
+	}
+
+	limit := pageLimit(c, 50, h.maxPageSize)
+
+	offset, err := strconv.ParseInt(c.Query("offset", "0"), 10, 32)
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	// Convert to pgtype.UUID
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userUUID
+	pgUserID.Valid = true
+
+	// 4. Get flagged transactions
+	transactions, err := h.db.GetFlaggedTransactions(c.Context(), db.GetFlaggedTransactionsParams{
+		UserID:  pgUserID,
+		Column2: flag,
+		Limit:   int32(limit),
+		Offset:  int32(offset),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to fetch flagged transactions",
+		})
+	}
+	totalCount, _ := h.db.CountFlaggedTransactions(c.Context(), db.CountFlaggedTransactionsParams{
+		UserID:  pgUserID,
+		Column2: flag,
+	})
+
+	// 5. Return response
+	return c.JSON(fiber.Map{
+		"transactions": transactions,
+		"total":        totalCount,
+		"limit":        limit,
+		"offset":       offset,
+	})
+}
+
+// GetRuleSuggestions runs the suggester added for the kind in the URL, e.g. salary,
+// cash, income or emi
+// GET /v1/transactions/suggestions/:kind
+// Each suggestion carries a keyword, category, match_type and, for direction-only rules,
+// a direction that can be posted to /v1/rules
+func (h *TransactionHandler) GetRuleSuggestions(c fiber.Ctx) error {
+	kind := c.Params("kind")
+	for _, named := range h.suggesters {
+		if named.kind == kind {
+			return h.suggestRules(c, named.suggester.Suggest)
+		}
+	}
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+		"error": fmt.Sprintf("%s suggestions not available", kind),
+	})
+}
+
+// suggestRules runs a rule suggester over all of the caller's transactions
+func (h *TransactionHandler) suggestRules(c fiber.Ctx, suggest func([]models.Transaction) []models.RuleSuggestion) error {
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 2. Look up user's UUID
+	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	// Convert to pgtype.UUID
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userUUID
+	pgUserID.Valid = true
+
+	// 3. Load all of the user's transactions
+	rows, err := h.db.GetAllTransactions(c.Context(), pgUserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to fetch transactions",
+			"details": err.Error(),
+		})
+	}
+
+	transactions := make([]models.Transaction, 0, len(rows))
+	for _, row := range rows {
+		transactions = append(transactions, toModelTransaction(row))
+	}
+
+	// 4. Return suggestions
+	suggestions := suggest(transactions)
+	return c.JSON(fiber.Map{
+		"suggestions": suggestions,
+		"count":       len(suggestions),
+	})
+}
+
+// GetRecurringTransactions lists groups of transactions that repeat monthly or quarterly
+// with a similar description and amount, with the date the next one is expected
+// GET /v1/transactions/recurring
+func (h *TransactionHandler) GetRecurringTransactions(c fiber.Ctx) error {
+	if h.recurring == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "recurring transaction detection not available",
+		})
+	}
+
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 2. Look up user's UUID
+	userUUID, err := h.getUserUUIDFromClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	// Convert to pgtype.UUID
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userUUID
+	pgUserID.Valid = true
+
+	// 3. Load all of the user's transactions
+	rows, err := h.db.GetAllTransactions(c.Context(), pgUserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to fetch transactions",
+			"details": err.Error(),
+		})
+	}
+
+	transactions := make([]models.Transaction, 0, len(rows))
+	for _, row := range rows {
+		transactions = append(transactions, toModelTransaction(row))
+	}
+
+	// 4. Return recurring groups
+	groups := h.recurring.Detect(transactions)
+	return c.JSON(fiber.Map{
+		"recurring": groups,
+		"count":     len(groups),
+	})
+}
+
+// toModelTransaction converts a database transaction row to the API model
+func toModelTransaction(row db.Transaction) models.Transaction {
+	txn := models.Transaction{
+		ID:          row.ID.Bytes,
+		UserID:      row.UserID.Bytes,
+		TxnDate:     models.NewDate(row.TxnDate.Time),
+		Description: row.Description,
+		TxnType:     row.TxnType,
+		IsReviewed:  row.IsReviewed,
+		Flags:       row.Flags,
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
+	}
+	if amount, err := row.Amount.Float64Value(); err == nil && amount.Valid {
+		txn.Amount = amount.Float64
+	}
+	if row.Category.Valid {
+		txn.Category = &row.Category.String
+	}
+	if row.RawData.Valid {
+		txn.RawData = &row.RawData.String
+	}
+	if row.Source.Valid {
+		txn.Source = &row.Source.String
+	}
+	return txn
+}
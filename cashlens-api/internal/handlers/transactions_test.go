@@ -0,0 +1,1588 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/ashmitsharp/cashlens-api/internal/services"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDBTX answers sqlc queries by name ("-- name: GetUserByClerkID :one") with canned rows
+type fakeDBTX struct {
+	results map[string]func(args []interface{}) [][]interface{}
+	errs    map[string]error // QueryRow errors returned instead of results
+	calls   []string         // Names of the queries executed, in order
+}
+
+func queryName(sql string) string {
+	return strings.Fields(strings.TrimPrefix(sql, "-- name: "))[0]
+}
+
+func (f *fakeDBTX) rowsFor(sql string, args []interface{}) [][]interface{} {
+	name := queryName(sql)
+	f.calls = append(f.calls, name)
+	result, ok := f.results[name]
+	if !ok {
+		return nil
+	}
+	return result(args)
+}
+
+// Exec runs the canned result for the query, if any; a single int64 value is the affected row count
+func (f *fakeDBTX) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	rows := f.rowsFor(sql, args)
+	if len(rows) == 1 && len(rows[0]) == 1 {
+		if affected, ok := rows[0][0].(int64); ok {
+			return pgconn.NewCommandTag(fmt.Sprintf("UPDATE %d", affected)), nil
+		}
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+func (f *fakeDBTX) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return &fakeRows{sql: sql, rows: f.rowsFor(sql, args), pos: -1}, nil
+}
+
+func (f *fakeDBTX) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	if err, ok := f.errs[queryName(sql)]; ok {
+		f.calls = append(f.calls, queryName(sql))
+		return &fakeRow{err: err}
+	}
+	rows := f.rowsFor(sql, args)
+	if len(rows) == 0 {
+		return &fakeRow{err: pgx.ErrNoRows}
+	}
+	return &fakeRow{values: rows[0]}
+}
+
+func (f *fakeDBTX) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return 0, nil
+}
+
+// scanValues copies canned values into Scan destinations of the same type
+func scanValues(values []interface{}, dest []interface{}) error {
+	if len(values) != len(dest) {
+		return fmt.Errorf("fake row has %d values, scan expects %d", len(values), len(dest))
+	}
+	for i, value := range values {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+type fakeRow struct {
+	values []interface{}
+	err    error
+}
+
+func (r *fakeRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	return scanValues(r.values, dest)
+}
+
+type fakeRows struct {
+	pgx.Rows
+	sql  string
+	rows [][]interface{}
+	pos  int
+}
+
+// selectList matches the column list of a SELECT query
+var selectList = regexp.MustCompile(`(?is)\bSELECT\s+(.*?)\s+FROM\s`)
+
+// FieldDescriptions names the columns of the query's SELECT list, for scanning by name
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
+	match := selectList.FindStringSubmatch(r.sql)
+	if match == nil {
+		return nil
+	}
+	var fields []pgconn.FieldDescription
+	for _, column := range strings.Split(match[1], ",") {
+		fields = append(fields, pgconn.FieldDescription{Name: strings.TrimSpace(column)})
+	}
+	return fields
+}
+
+func (r *fakeRows) Next() bool {
+	r.pos++
+	return r.pos < len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	// pgx.RowToStructByName passes a RowScanner that scans the fields itself
+	if len(dest) == 1 {
+		if scanner, ok := dest[0].(pgx.RowScanner); ok {
+			return scanner.ScanRow(r)
+		}
+	}
+	return scanValues(r.rows[r.pos], dest)
+}
+
+func (r *fakeRows) Close() {}
+
+func (r *fakeRows) Err() error { return nil }
+
+// userRow returns a users row in GetUserByClerkID column order
+func userRow(id uuid.UUID) []interface{} {
+	return []interface{}{
+		pgtype.UUID{Bytes: id, Valid: true},
+		"user_test123",
+		"test@example.com",
+		pgtype.Text{},
+		pgtype.Timestamptz{},
+		pgtype.Timestamptz{},
+	}
+}
+
+// transactionRow returns a transactions row in SELECT * column order
+func transactionRow(txn db.Transaction) []interface{} {
+	return []interface{}{
+		txn.ID, txn.UserID, txn.TxnDate, txn.Description, txn.Amount, txn.TxnType,
+		txn.Category, txn.IsReviewed, txn.RawData, txn.CreatedAt, txn.UpdatedAt,
+		txn.UploadID, txn.Source, txn.Flags, txn.ReferenceNo, txn.CategoryScore, txn.Tags,
+	}
+}
+
+func newTestTransaction(t *testing.T, description string, amount float64, flags ...string) db.Transaction {
+	var pgAmount pgtype.Numeric
+	require.NoError(t, pgAmount.Scan(fmt.Sprintf("%.2f", amount)))
+	return db.Transaction{
+		ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		TxnDate:     pgtype.Date{Time: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), Valid: true},
+		Description: description,
+		Amount:      pgAmount,
+		TxnType:     "debit",
+		Flags:       append([]string{}, flags...),
+	}
+}
+
+// TestGetTransactionIssues tests that only flagged transactions are returned, optionally filtered by flag
+func TestGetTransactionIssues(t *testing.T) {
+	userID := uuid.New()
+	transactions := []db.Transaction{
+		newTestTransaction(t, "AWS SERVICES", -3500.00),
+		newTestTransaction(t, "FUTURE DATED", -100.00, "bad_date"),
+		newTestTransaction(t, "ZERO AMOUNT", 0, "zero_amount"),
+		newTestTransaction(t, "BOTH", 0, "bad_date", "zero_amount"),
+	}
+
+	// Mirrors the SQL: only rows with flags, optionally containing the requested flag
+	flagged := func(args []interface{}) []db.Transaction {
+		flag := args[1].(string)
+		matched := []db.Transaction{}
+		for _, txn := range transactions {
+			for _, f := range txn.Flags {
+				if flag == "" || f == flag {
+					matched = append(matched, txn)
+					break
+				}
+			}
+		}
+		return matched
+	}
+
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetFlaggedTransactions": func(args []interface{}) [][]interface{} {
+			rows := [][]interface{}{}
+			for _, txn := range flagged(args) {
+				rows = append(rows, transactionRow(txn))
+			}
+			return rows
+		},
+		"CountFlaggedTransactions": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{{int64(len(flagged(args)))}}
+		},
+	}}
+	handler := NewTransactionHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Get("/transactions/issues", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.GetTransactionIssues(c)
+	})
+
+	testCases := []struct {
+		name         string
+		query        string
+		expectedCode int
+		expected     []string
+	}{
+		{"All flagged", "", fiber.StatusOK, []string{"FUTURE DATED", "ZERO AMOUNT", "BOTH"}},
+		{"Filter by flag", "?flag=bad_date", fiber.StatusOK, []string{"FUTURE DATED", "BOTH"}},
+		{"Invalid flag", "?flag=bogus", fiber.StatusBadRequest, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/transactions/issues"+tc.query, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			if tc.expectedCode != fiber.StatusOK {
+				return
+			}
+
+			var result struct {
+				Transactions []db.Transaction `json:"transactions"`
+				Total        int64            `json:"total"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+			descriptions := []string{}
+			for _, txn := range result.Transactions {
+				descriptions = append(descriptions, txn.Description)
+				assert.NotEmpty(t, txn.Flags)
+			}
+			assert.Equal(t, tc.expected, descriptions)
+			assert.Equal(t, int64(len(tc.expected)), result.Total)
+		})
+	}
+}
+
+// TestGetTransactions_TotalFromListQuery tests that the total comes from the list query without a separate count
+func TestGetTransactions_TotalFromListQuery(t *testing.T) {
+	userID := uuid.New()
+	var all []db.Transaction
+	for i := 0; i < 7; i++ {
+		all = append(all, newTestTransaction(t, fmt.Sprintf("TXN %d", i), -100.00))
+	}
+
+	// Mirrors COUNT(*) OVER(): every row carries the size of the full result set
+	listRows := func(args []interface{}) [][]interface{} {
+		limit, offset := int(args[1].(int32)), int(args[2].(int32))
+		rows := [][]interface{}{}
+		for i := offset; i < len(all) && i < offset+limit; i++ {
+			rows = append(rows, append(transactionRow(all[i]), pgtype.Text{}, int64(len(all))))
+		}
+		return rows
+	}
+
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetUserTransactions": listRows,
+		"CountUserTransactions": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{{int64(len(all))}}
+		},
+	}}
+	handler := NewTransactionHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Get("/transactions", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.GetTransactions(c)
+	})
+
+	testCases := []struct {
+		name          string
+		query         string
+		expectedRows  int
+		expectedCalls []string
+	}{
+		{"First page", "?limit=3", 3, []string{"GetUserByClerkID", "GetUserTransactions"}},
+		{"Last partial page", "?limit=3&offset=6", 1, []string{"GetUserByClerkID", "GetUserTransactions"}},
+		{"Past the end falls back to count", "?limit=3&offset=9", 0, []string{"GetUserByClerkID", "GetUserTransactions", "CountUserTransactions"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake.calls = nil
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/transactions"+tc.query, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			var result struct {
+				Transactions []db.GetUserTransactionsRow `json:"transactions"`
+				Total        int64                       `json:"total"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+			assert.Len(t, result.Transactions, tc.expectedRows)
+			assert.Equal(t, int64(len(all)), result.Total)
+			assert.Equal(t, tc.expectedCalls, fake.calls)
+		})
+	}
+}
+
+// TestGetTransactions_DateAndCategoryFilters tests the optional from/to/category filters
+func TestGetTransactions_DateAndCategoryFilters(t *testing.T) {
+	userID := uuid.New()
+	newTxn := func(description string, date time.Time, category string) db.Transaction {
+		txn := newTestTransaction(t, description, -100.00)
+		txn.TxnDate = pgtype.Date{Time: date, Valid: true}
+		txn.Category = pgtype.Text{String: category, Valid: category != ""}
+		return txn
+	}
+	all := []db.Transaction{
+		newTxn("UBER MARCH", time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC), "Travel"),
+		newTxn("SWIGGY MARCH", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), "Food"),
+		newTxn("OLA MARCH", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), "Travel"),
+		newTxn("UBER FEB", time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC), "Travel"),
+		newTxn("UNKNOWN MARCH", time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), ""),
+	}
+
+	// Mirrors GetUserTransactionsFiltered (status, source and paging are not exercised here)
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetUserTransactions": func(args []interface{}) [][]interface{} {
+			rows := [][]interface{}{}
+			for _, txn := range all {
+				rows = append(rows, append(transactionRow(txn), pgtype.Text{}, int64(len(all))))
+			}
+			return rows
+		},
+		"GetUserTransactionsFiltered": func(args []interface{}) [][]interface{} {
+			from, to := args[1].(pgtype.Date).Time, args[2].(pgtype.Date).Time
+			category := args[5].(string)
+			matched := []db.Transaction{}
+			for _, txn := range all {
+				if txn.TxnDate.Time.Before(from) || txn.TxnDate.Time.After(to) {
+					continue
+				}
+				if category != "" && txn.Category.String != category {
+					continue
+				}
+				matched = append(matched, txn)
+			}
+			rows := [][]interface{}{}
+			for _, txn := range matched {
+				rows = append(rows, append(transactionRow(txn), pgtype.Text{}, int64(len(matched))))
+			}
+			return rows
+		},
+	}}
+	handler := NewTransactionHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Get("/transactions", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.GetTransactions(c)
+	})
+
+	testCases := []struct {
+		name         string
+		query        string
+		expectedCode int
+		expected     []string
+		expectedCall string
+	}{
+		{"No filters keeps the unfiltered query", "", fiber.StatusOK, []string{"UBER MARCH", "SWIGGY MARCH", "OLA MARCH", "UBER FEB", "UNKNOWN MARCH"}, "GetUserTransactions"},
+		{"Travel in March", "?category=Travel&from=2024-03-01&to=2024-03-31", fiber.StatusOK, []string{"UBER MARCH", "OLA MARCH"}, "GetUserTransactionsFiltered"},
+		{"From only", "?from=2024-03-10", fiber.StatusOK, []string{"UBER MARCH", "SWIGGY MARCH"}, "GetUserTransactionsFiltered"},
+		{"To only", "?to=2024-03-01", fiber.StatusOK, []string{"OLA MARCH", "UBER FEB"}, "GetUserTransactionsFiltered"},
+		{"Category only", "?category=Food", fiber.StatusOK, []string{"SWIGGY MARCH"}, "GetUserTransactionsFiltered"},
+		{"Malformed from", "?from=03/01/2024", fiber.StatusBadRequest, nil, ""},
+		{"Malformed to", "?to=2024-13-01", fiber.StatusBadRequest, nil, ""},
+		{"From after to", "?from=2024-04-01&to=2024-03-01", fiber.StatusBadRequest, nil, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake.calls = nil
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/transactions"+tc.query, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			if tc.expectedCode != fiber.StatusOK {
+				assert.Equal(t, []string{"GetUserByClerkID"}, fake.calls)
+				return
+			}
+
+			var result struct {
+				Transactions []db.GetUserTransactionsFilteredRow `json:"transactions"`
+				Total        int64                               `json:"total"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+			descriptions := []string{}
+			for _, txn := range result.Transactions {
+				descriptions = append(descriptions, txn.Description)
+			}
+			assert.Equal(t, tc.expected, descriptions)
+			assert.Equal(t, int64(len(tc.expected)), result.Total)
+			assert.Equal(t, []string{"GetUserByClerkID", tc.expectedCall}, fake.calls)
+		})
+	}
+}
+
+// TestGetTransactions_ConfidenceFilter tests filtering by category confidence, e.g. to
+// review uncertain categorizations
+func TestGetTransactions_ConfidenceFilter(t *testing.T) {
+	userID := uuid.New()
+	newTxn := func(description string, score float64, scored bool) db.Transaction {
+		txn := newTestTransaction(t, description, -100.00)
+		txn.Category = pgtype.Text{String: "Food", Valid: true}
+		txn.CategoryScore = pgtype.Float8{Float64: score, Valid: scored}
+		return txn
+	}
+	all := []db.Transaction{
+		newTxn("SWIGGY", 0.9, true),
+		newTxn("ZOMATO", 0.5, true),
+		newTxn("CAFE", 0.2, true),
+		newTxn("MANUAL LUNCH", 0, false), // Categorized by hand, no score
+	}
+
+	// Mirrors the confidence condition of GetUserTransactionsFiltered
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetUserTransactionsFiltered": func(args []interface{}) [][]interface{} {
+			minConfidence, maxConfidence := args[6].(float64), args[7].(float64)
+			matched := []db.Transaction{}
+			for _, txn := range all {
+				unbounded := minConfidence <= 0 && maxConfidence >= 1
+				score := txn.CategoryScore
+				if unbounded || (score.Valid && score.Float64 >= minConfidence && score.Float64 <= maxConfidence) {
+					matched = append(matched, txn)
+				}
+			}
+			rows := [][]interface{}{}
+			for _, txn := range matched {
+				rows = append(rows, append(transactionRow(txn), pgtype.Text{}, int64(len(matched))))
+			}
+			return rows
+		},
+	}}
+	handler := NewTransactionHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Get("/transactions", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.GetTransactions(c)
+	})
+
+	testCases := []struct {
+		name         string
+		query        string
+		expectedCode int
+		expected     []string
+	}{
+		{"Below a confidence level", "?max_confidence=0.5", fiber.StatusOK, []string{"ZOMATO", "CAFE"}},
+		{"Above a confidence level", "?min_confidence=0.6", fiber.StatusOK, []string{"SWIGGY"}},
+		{"Between two levels", "?min_confidence=0.3&max_confidence=0.8", fiber.StatusOK, []string{"ZOMATO"}},
+		{"Full range keeps unscored rows", "?min_confidence=0&max_confidence=1", fiber.StatusOK, nil},
+		{"Out of range", "?min_confidence=1.5", fiber.StatusBadRequest, nil},
+		{"Not a number", "?max_confidence=low", fiber.StatusBadRequest, nil},
+		{"Min above max", "?min_confidence=0.8&max_confidence=0.2", fiber.StatusBadRequest, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake.calls = nil
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/transactions"+tc.query, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			if tc.expectedCode != fiber.StatusOK {
+				assert.Equal(t, []string{"GetUserByClerkID"}, fake.calls)
+				return
+			}
+			if tc.expected == nil {
+				// Without a bound the unfiltered query is used
+				assert.Equal(t, []string{"GetUserByClerkID", "GetUserTransactions"}, fake.calls)
+				return
+			}
+
+			var result struct {
+				Transactions []db.GetUserTransactionsFilteredRow `json:"transactions"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+			descriptions := []string{}
+			for _, txn := range result.Transactions {
+				descriptions = append(descriptions, txn.Description)
+			}
+			assert.Equal(t, tc.expected, descriptions)
+			assert.Equal(t, []string{"GetUserByClerkID", "GetUserTransactionsFiltered"}, fake.calls)
+		})
+	}
+}
+
+// TestGetTransactions_NDJSONStream tests that Accept: application/x-ndjson streams one line per transaction
+func TestGetTransactions_NDJSONStream(t *testing.T) {
+	userID := uuid.New()
+	const total = 250
+
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetAllTransactions": func(args []interface{}) [][]interface{} {
+			rows := make([][]interface{}, 0, total)
+			for i := 0; i < total; i++ {
+				rows = append(rows, transactionRow(newTestTransaction(t, fmt.Sprintf("TXN %d", i), -10.00)))
+			}
+			return rows
+		},
+	}}
+	handler := NewTransactionHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Get("/transactions", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.GetTransactions(c)
+	})
+
+	t.Run("Streams every transaction", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/transactions?limit=10", nil)
+		req.Header.Set("Accept", MIMEApplicationNDJSON)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, MIMEApplicationNDJSON, resp.Header.Get("Content-Type"))
+
+		// Pagination does not apply to the stream
+		scanner := bufio.NewScanner(resp.Body)
+		lines := 0
+		for scanner.Scan() {
+			var txn db.Transaction
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &txn))
+			assert.Equal(t, fmt.Sprintf("TXN %d", lines), txn.Description)
+			lines++
+		}
+		require.NoError(t, scanner.Err())
+		assert.Equal(t, total, lines)
+		assert.NotContains(t, fake.calls, "GetUserTransactions")
+	})
+
+	t.Run("Filters are rejected", func(t *testing.T) {
+		for _, query := range []string{"category=Travel", "min_confidence=0.5", "max_confidence=0.5"} {
+			req := httptest.NewRequest("GET", "/transactions?"+query, nil)
+			req.Header.Set("Accept", MIMEApplicationNDJSON)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, query)
+		}
+	})
+}
+
+// TestSearchTransactions tests description search, wildcard escaping and the limit cap
+func TestSearchTransactions(t *testing.T) {
+	userID := uuid.New()
+	all := []db.Transaction{
+		newTestTransaction(t, "AWS EMEA SARL", -1500.00),
+		newTestTransaction(t, "SWIGGY ORDER", -350.00),
+		newTestTransaction(t, "aws marketplace", -200.00),
+		newTestTransaction(t, "UPI 50% CASHBACK", 25.00),
+	}
+
+	// Mirrors SearchUserTransactions: unescapes the pattern and matches case-insensitively
+	unescape := strings.NewReplacer(`\%`, `%`, `\_`, `_`, `\\`, `\`)
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"SearchUserTransactions": func(args []interface{}) [][]interface{} {
+			needle := strings.ToLower(unescape.Replace(args[1].(string)))
+			matched := []db.Transaction{}
+			for _, txn := range all {
+				if strings.Contains(strings.ToLower(txn.Description), needle) {
+					matched = append(matched, txn)
+				}
+			}
+			rows := [][]interface{}{}
+			for _, txn := range matched {
+				rows = append(rows, append(transactionRow(txn), pgtype.Text{}, int64(len(matched))))
+			}
+			return rows
+		},
+	}}
+	handler := NewTransactionHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Get("/transactions/search", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.SearchTransactions(c)
+	})
+
+	testCases := []struct {
+		name            string
+		query           string
+		expectedCode    int
+		expected        []string
+		expectedPattern string
+		expectedLimit   int32
+	}{
+		{"Case-insensitive match", "?q=aws", fiber.StatusOK, []string{"AWS EMEA SARL", "aws marketplace"}, "aws", 20},
+		{"Wildcards are escaped", "?q=50%25", fiber.StatusOK, []string{"UPI 50% CASHBACK"}, `50\%`, 20},
+		{"Limit is capped", "?q=swiggy&limit=500", fiber.StatusOK, []string{"SWIGGY ORDER"}, "swiggy", 100},
+		{"No matches", "?q=netflix", fiber.StatusOK, []string{}, "netflix", 20},
+		{"Empty query", "?q=", fiber.StatusBadRequest, nil, "", 0},
+		{"Blank query", "?q=%20%20", fiber.StatusBadRequest, nil, "", 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake.calls = nil
+			var gotArgs []interface{}
+			search := fake.results["SearchUserTransactions"]
+			fake.results["SearchUserTransactions"] = func(args []interface{}) [][]interface{} {
+				gotArgs = args
+				return search(args)
+			}
+			defer func() { fake.results["SearchUserTransactions"] = search }()
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/transactions/search"+tc.query, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			if tc.expectedCode != fiber.StatusOK {
+				assert.Equal(t, []string{"GetUserByClerkID"}, fake.calls)
+				return
+			}
+
+			var result struct {
+				Transactions []db.SearchUserTransactionsRow `json:"transactions"`
+				Total        int64                          `json:"total"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+			descriptions := []string{}
+			for _, txn := range result.Transactions {
+				descriptions = append(descriptions, txn.Description)
+			}
+			assert.Equal(t, tc.expected, descriptions)
+			assert.Equal(t, int64(len(tc.expected)), result.Total)
+			require.Len(t, gotArgs, 4)
+			assert.Equal(t, tc.expectedPattern, gotArgs[1])
+			assert.Equal(t, tc.expectedLimit, gotArgs[2])
+		})
+	}
+}
+
+// TestGetTransactionCount tests that only the count is returned, with and without filters
+func TestGetTransactionCount(t *testing.T) {
+	userID := uuid.New()
+	type txn struct {
+		date        time.Time
+		categorized bool
+	}
+	all := []txn{
+		{time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), false},
+		{time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), false},
+	}
+
+	// Mirrors the SQL count queries
+	count := func(from, to time.Time, status string) [][]interface{} {
+		n := int64(0)
+		for _, tx := range all {
+			if tx.date.Before(from) || tx.date.After(to) {
+				continue
+			}
+			if (status == "categorized" && !tx.categorized) || (status == "uncategorized" && tx.categorized) {
+				continue
+			}
+			n++
+		}
+		return [][]interface{}{{n}}
+	}
+	minDate := time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+	maxDate := time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"CountUserTransactions": func(args []interface{}) [][]interface{} {
+			return count(minDate, maxDate, "all")
+		},
+		"CountCategorizedTransactions": func(args []interface{}) [][]interface{} {
+			return count(minDate, maxDate, "categorized")
+		},
+		"CountUncategorizedTransactions": func(args []interface{}) [][]interface{} {
+			return count(minDate, maxDate, "uncategorized")
+		},
+		"CountUserTransactionsInRange": func(args []interface{}) [][]interface{} {
+			return count(args[1].(pgtype.Date).Time, args[2].(pgtype.Date).Time, args[3].(string))
+		},
+	}}
+	handler := NewTransactionHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Get("/transactions/count", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.GetTransactionCount(c)
+	})
+
+	testCases := []struct {
+		name          string
+		query         string
+		expectedCount int64
+		expectedQuery string
+	}{
+		{"No filters", "", 5, "CountUserTransactions"},
+		{"Categorized", "?status=categorized", 2, "CountCategorizedTransactions"},
+		{"Uncategorized", "?status=uncategorized", 3, "CountUncategorizedTransactions"},
+		{"Date range", "?from=2024-01-20&to=2024-03-01", 3, "CountUserTransactionsInRange"},
+		{"Date range and status", "?from=2024-02-01&status=uncategorized", 2, "CountUserTransactionsInRange"},
+		{"Only to date", "?to=2024-01-31", 2, "CountUserTransactionsInRange"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake.calls = nil
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/transactions/count"+tc.query, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			var result map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			assert.Equal(t, map[string]interface{}{"count": float64(tc.expectedCount)}, result)
+			assert.Equal(t, []string{"GetUserByClerkID", tc.expectedQuery}, fake.calls)
+		})
+	}
+
+	for _, query := range []string{"?status=pending", "?from=01-02-2024", "?from=2024-03-01&to=2024-01-01"} {
+		resp, err := app.Test(httptest.NewRequest("GET", "/transactions/count"+query, nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, query)
+	}
+}
+
+// TestDeleteTransaction tests ownership checks before a transaction is deleted
+func TestDeleteTransaction(t *testing.T) {
+	userID := uuid.New()
+	owned := newTestTransaction(t, "DUPLICATE UPI PAYMENT", -500.00)
+	owned.UserID = pgtype.UUID{Bytes: userID, Valid: true}
+	foreign := newTestTransaction(t, "SOMEONE ELSE", -100.00)
+	foreign.UserID = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	testCases := []struct {
+		name          string
+		id            string
+		expectedCode  int
+		expectedCalls []string
+	}{
+		{"Owner deletes", uuid.UUID(owned.ID.Bytes).String(), fiber.StatusOK, []string{"GetUserByClerkID", "GetTransactionByID", "DeleteTransaction"}},
+		{"Other user's transaction", uuid.UUID(foreign.ID.Bytes).String(), fiber.StatusForbidden, []string{"GetUserByClerkID", "GetTransactionByID"}},
+		{"Missing transaction", uuid.New().String(), fiber.StatusNotFound, []string{"GetUserByClerkID", "GetTransactionByID"}},
+		{"Invalid ID", "not-a-uuid", fiber.StatusBadRequest, []string{"GetUserByClerkID"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var deleted pgtype.UUID
+			fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+				"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+					return [][]interface{}{userRow(userID)}
+				},
+				"GetTransactionByID": func(args []interface{}) [][]interface{} {
+					for _, txn := range []db.Transaction{owned, foreign} {
+						if txn.ID == args[0].(pgtype.UUID) {
+							return [][]interface{}{transactionRow(txn)}
+						}
+					}
+					return nil
+				},
+				"DeleteTransaction": func(args []interface{}) [][]interface{} {
+					deleted = args[0].(pgtype.UUID)
+					return nil
+				},
+			}}
+			handler := NewTransactionHandler(db.New(fake), nil)
+
+			app := fiber.New()
+			app.Delete("/transactions/:id", func(c fiber.Ctx) error {
+				c.Locals("clerk_user_id", "user_test123")
+				return handler.DeleteTransaction(c)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("DELETE", "/transactions/"+tc.id, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedCalls, fake.calls)
+			if tc.expectedCode == fiber.StatusOK {
+				assert.Equal(t, owned.ID, deleted)
+			}
+		})
+	}
+}
+
+// TestUpdateTransaction_CategoryWhitelist tests that categories outside a configured taxonomy are rejected
+func TestUpdateTransaction_CategoryWhitelist(t *testing.T) {
+	userID := uuid.New()
+	txn := newTestTransaction(t, "MAKEMYTRIP FLIGHT", -8500.00)
+	txn.UserID = pgtype.UUID{Bytes: userID, Valid: true}
+
+	testCases := []struct {
+		name         string
+		whitelist    []string
+		category     string
+		expectedCode int
+	}{
+		{"Disabled allows any category", nil, "Crypto", fiber.StatusOK},
+		{"Allowed category", []string{"Travel", "Salary"}, "Travel", fiber.StatusOK},
+		{"Unknown category", []string{"Travel", "Salary"}, "Crypto", fiber.StatusBadRequest},
+		{"Whitelist is case-sensitive", []string{"Travel", "Salary"}, "travel", fiber.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var updated string
+			fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+				"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+					return [][]interface{}{userRow(userID)}
+				},
+				"GetTransactionByID": func(args []interface{}) [][]interface{} {
+					return [][]interface{}{transactionRow(txn)}
+				},
+				"UpdateTransactionCategory": func(args []interface{}) [][]interface{} {
+					updated = args[1].(pgtype.Text).String
+					return [][]interface{}{transactionRow(txn)}
+				},
+			}}
+			handler := NewTransactionHandler(db.New(fake), nil)
+			if tc.whitelist != nil {
+				handler.SetCategoryWhitelist(services.NewCategoryWhitelist(tc.whitelist))
+			}
+
+			app := fiber.New()
+			app.Put("/transactions/:id", func(c fiber.Ctx) error {
+				c.Locals("clerk_user_id", "user_test123")
+				return handler.UpdateTransaction(c)
+			})
+
+			body := fmt.Sprintf(`{"category": %q}`, tc.category)
+			req := httptest.NewRequest("PUT", "/transactions/"+uuid.UUID(txn.ID.Bytes).String(), strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			if tc.expectedCode == fiber.StatusOK {
+				assert.Equal(t, tc.category, updated)
+			} else {
+				assert.NotContains(t, fake.calls, "UpdateTransactionCategory")
+
+				var result map[string]interface{}
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+				assert.Contains(t, result["error"], tc.category)
+			}
+		})
+	}
+}
+
+// TestUpdateTransaction_CreateRule tests that create_rule learns a merchant rule once and
+// reports, without changing, an existing keyword rule for another category
+func TestUpdateTransaction_CreateRule(t *testing.T) {
+	userID := uuid.New()
+	txn := newTestTransaction(t, "UPI/SWIGGY/401234567890/PAYMENT", -450.00)
+	txn.UserID = pgtype.UUID{Bytes: userID, Valid: true}
+	unnamed := newTestTransaction(t, "IMPS/P2A/401234567890", -1000.00)
+	unnamed.UserID = pgtype.UUID{Bytes: userID, Valid: true}
+
+	testCases := []struct {
+		name             string
+		txn              db.Transaction
+		body             string
+		existingRule     string // Category of an existing "swiggy" rule ("" = none)
+		reportsRule      bool   // Whether rule_created is in the response
+		expectedCreated  bool
+		expectedConflict bool
+		expectedCalls    []string
+	}{
+		{"Without flag", txn, `{"category": "Team Meals"}`, "", false, false, false,
+			[]string{"GetUserByClerkID", "GetTransactionByID", "UpdateTransactionCategory"}},
+		{"Creates rule", txn, `{"category": "Team Meals", "create_rule": true}`, "", true, true, false,
+			[]string{"GetUserByClerkID", "GetTransactionByID", "UpdateTransactionCategory", "GetUserRuleByKeyword", "CreateUserRule"}},
+		{"Skips identical rule", txn, `{"category": "Team Meals", "create_rule": true}`, "Team Meals", true, false, false,
+			[]string{"GetUserByClerkID", "GetTransactionByID", "UpdateTransactionCategory", "GetUserRuleByKeyword"}},
+		{"Reports conflicting rule", txn, `{"category": "Team Meals", "create_rule": true}`, "Food Delivery", true, false, true,
+			[]string{"GetUserByClerkID", "GetTransactionByID", "UpdateTransactionCategory", "GetUserRuleByKeyword"}},
+		{"No merchant keyword", unnamed, `{"category": "Transfers", "create_rule": true}`, "", true, false, false,
+			[]string{"GetUserByClerkID", "GetTransactionByID", "UpdateTransactionCategory"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var created db.CreateUserRuleParams
+			existing := []interface{}{
+				pgtype.UUID{Bytes: uuid.New(), Valid: true}, pgtype.UUID{Bytes: userID, Valid: true}, "swiggy", tc.existingRule,
+				pgtype.Int4{Int32: 500, Valid: true}, pgtype.Text{String: "substring", Valid: true}, pgtype.Numeric{},
+				pgtype.Bool{Bool: true, Valid: true}, pgtype.Timestamptz{}, pgtype.Timestamptz{}, pgtype.Numeric{}, pgtype.Numeric{}, []string{}, pgtype.Text{},
+			}
+			fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+				"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+					return [][]interface{}{userRow(userID)}
+				},
+				"GetTransactionByID": func(args []interface{}) [][]interface{} {
+					return [][]interface{}{transactionRow(tc.txn)}
+				},
+				"UpdateTransactionCategory": func(args []interface{}) [][]interface{} {
+					return [][]interface{}{transactionRow(tc.txn)}
+				},
+				"GetUserRuleByKeyword": func(args []interface{}) [][]interface{} {
+					if tc.existingRule != "" {
+						return [][]interface{}{existing}
+					}
+					return nil
+				},
+				"CreateUserRule": func(args []interface{}) [][]interface{} {
+					created = db.CreateUserRuleParams{
+						Keyword:   args[1].(string),
+						Category:  args[2].(string),
+						Priority:  args[3].(pgtype.Int4),
+						MatchType: args[4].(pgtype.Text),
+					}
+					return [][]interface{}{{
+						pgtype.UUID{Bytes: uuid.New(), Valid: true}, args[0], args[1], args[2], args[3],
+						args[4], args[5], args[6], pgtype.Timestamptz{}, pgtype.Timestamptz{}, args[7], args[8], args[9], args[10],
+					}}
+				},
+			}}
+			handler := NewTransactionHandler(db.New(fake), nil)
+			handler.SetRuleLearner(services.NewRuleLearner())
+
+			app := fiber.New()
+			app.Put("/transactions/:id", func(c fiber.Ctx) error {
+				c.Locals("clerk_user_id", "user_test123")
+				return handler.UpdateTransaction(c)
+			})
+
+			req := httptest.NewRequest("PUT", "/transactions/"+uuid.UUID(tc.txn.ID.Bytes).String(), strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			var result map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			if tc.reportsRule {
+				assert.Equal(t, tc.expectedCreated, result["rule_created"])
+			} else {
+				assert.NotContains(t, result, "rule_created")
+			}
+			assert.Equal(t, tc.expectedCalls, fake.calls)
+
+			if tc.expectedCreated {
+				assert.Equal(t, "swiggy", created.Keyword)
+				assert.Equal(t, "Team Meals", created.Category)
+				assert.Equal(t, LearnedRulePriority, created.Priority.Int32)
+				assert.Equal(t, "substring", created.MatchType.String)
+				assert.Contains(t, result, "rule")
+			}
+			if tc.expectedConflict {
+				require.Contains(t, result, "rule_conflict")
+				conflict := result["rule_conflict"].(map[string]interface{})
+				assert.Equal(t, "swiggy", conflict["keyword"])
+				assert.Equal(t, tc.existingRule, conflict["category"])
+				assert.NotContains(t, result, "rule")
+			} else {
+				assert.NotContains(t, result, "rule_conflict")
+			}
+		})
+	}
+}
+
+// TestUpdateTransaction_CreateRulePriorityBounds tests that learned rules are clamped
+// into the configured user rule priority bounds, whatever the policy
+func TestUpdateTransaction_CreateRulePriorityBounds(t *testing.T) {
+	userID := uuid.New()
+	txn := newTestTransaction(t, "UPI/SWIGGY/401234567890/PAYMENT", -450.00)
+	txn.UserID = pgtype.UUID{Bytes: userID, Valid: true}
+
+	for _, policy := range []string{PriorityPolicyClamp, PriorityPolicyReject} {
+		t.Run(policy, func(t *testing.T) {
+			var priority pgtype.Int4
+			fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+				"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+					return [][]interface{}{userRow(userID)}
+				},
+				"GetTransactionByID": func(args []interface{}) [][]interface{} {
+					return [][]interface{}{transactionRow(txn)}
+				},
+				"UpdateTransactionCategory": func(args []interface{}) [][]interface{} {
+					return [][]interface{}{transactionRow(txn)}
+				},
+				"GetUserRuleByKeyword": func(args []interface{}) [][]interface{} {
+					return nil
+				},
+				"CreateUserRule": func(args []interface{}) [][]interface{} {
+					priority = args[3].(pgtype.Int4)
+					return [][]interface{}{{
+						pgtype.UUID{Bytes: uuid.New(), Valid: true}, args[0], args[1], args[2], args[3],
+						args[4], args[5], args[6], pgtype.Timestamptz{}, pgtype.Timestamptz{}, args[7], args[8], args[9], args[10],
+					}}
+				},
+			}}
+			handler := NewTransactionHandler(db.New(fake), nil)
+			handler.SetRuleLearner(services.NewRuleLearner())
+			handler.SetPriorityBounds(11, 200, policy)
+
+			app := fiber.New()
+			app.Put("/transactions/:id", func(c fiber.Ctx) error {
+				c.Locals("clerk_user_id", "user_test123")
+				return handler.UpdateTransaction(c)
+			})
+
+			body := `{"category": "Team Meals", "create_rule": true}`
+			req := httptest.NewRequest("PUT", "/transactions/"+uuid.UUID(txn.ID.Bytes).String(), strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			var result map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			assert.Equal(t, true, result["rule_created"])
+			assert.Equal(t, int32(200), priority.Int32)
+		})
+	}
+}
+
+// TestClearCategories tests that reviewed transactions keep their category unless include_reviewed is set
+func TestClearCategories(t *testing.T) {
+	userID := uuid.New()
+
+	testCases := []struct {
+		name               string
+		query              string
+		expectedCleared    int64
+		expectedCategories []string
+	}{
+		{"Default keeps reviewed", "", 2, []string{"", "", "Office Supplies", ""}},
+		{"Include reviewed", "?include_reviewed=true", 3, []string{"", "", "", ""}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stored := []db.Transaction{
+				newTestTransaction(t, "AMAZON PAY", -1200.00),
+				newTestTransaction(t, "UBER TRIP", -350.00),
+				newTestTransaction(t, "STAPLES", -800.00),
+				newTestTransaction(t, "UNKNOWN PAYEE", -50.00),
+			}
+			stored[0].Category = pgtype.Text{String: "Shopping", Valid: true}
+			stored[1].Category = pgtype.Text{String: "Travel", Valid: true}
+			stored[2].Category = pgtype.Text{String: "Office Supplies", Valid: true}
+			stored[2].IsReviewed = true
+
+			// Mirrors the SQL update
+			fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+				"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+					return [][]interface{}{userRow(userID)}
+				},
+				"ClearTransactionCategories": func(args []interface{}) [][]interface{} {
+					includeReviewed := args[1].(bool)
+					cleared := int64(0)
+					for i := range stored {
+						if stored[i].Category.Valid && (includeReviewed || !stored[i].IsReviewed) {
+							stored[i].Category = pgtype.Text{}
+							stored[i].IsReviewed = false
+							cleared++
+						}
+					}
+					return [][]interface{}{{cleared}}
+				},
+			}}
+			handler := NewTransactionHandler(db.New(fake), nil)
+
+			app := fiber.New()
+			app.Post("/transactions/clear-categories", func(c fiber.Ctx) error {
+				c.Locals("clerk_user_id", "user_test123")
+				return handler.ClearCategories(c)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("POST", "/transactions/clear-categories"+tc.query, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			var result map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			assert.Equal(t, float64(tc.expectedCleared), result["cleared_count"])
+
+			for i, txn := range stored {
+				assert.Equal(t, tc.expectedCategories[i], txn.Category.String, txn.Description)
+			}
+		})
+	}
+
+	t.Run("Invalid include_reviewed", func(t *testing.T) {
+		handler := NewTransactionHandler(db.New(&fakeDBTX{}), nil)
+		app := fiber.New()
+		app.Post("/transactions/clear-categories", func(c fiber.Ctx) error {
+			c.Locals("clerk_user_id", "user_test123")
+			return handler.ClearCategories(c)
+		})
+
+		resp, err := app.Test(httptest.NewRequest("POST", "/transactions/clear-categories?include_reviewed=maybe", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+// TestFixSigns tests that mis-signed rows are corrected from txn_type, or txn_type from the sign
+func TestFixSigns(t *testing.T) {
+	userID := uuid.New()
+
+	type storedTxn struct {
+		txn  db.Transaction
+		bank string
+	}
+	newStored := func(description string, amount float64, txnType, bank string) *storedTxn {
+		txn := newTestTransaction(t, description, amount)
+		txn.TxnType = txnType
+		return &storedTxn{txn: txn, bank: bank}
+	}
+	matchesBank := func(stored *storedTxn, bank string) bool {
+		return bank == "" || strings.EqualFold(stored.bank, bank)
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		expectedFixed int64
+		expectedSigns []int
+		expectedTypes []string
+	}{
+		{"Amount from type for one bank", "?bank=hdfc", 2, []int{-1, -1, 1, 1, 1}, []string{"debit", "debit", "credit", "debit", "credit"}},
+		{"Amount from type for all banks", "", 3, []int{-1, -1, 1, -1, 1}, []string{"debit", "debit", "credit", "debit", "credit"}},
+		{"Type from amount", "?bank=HDFC&from=amount", 2, []int{1, -1, 1, 1, -1}, []string{"credit", "debit", "credit", "debit", "debit"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stored := []*storedTxn{
+				newStored("AMAZON PAY", 1200.00, "debit", "HDFC"),
+				newStored("UBER TRIP", -350.00, "debit", "HDFC"),
+				newStored("SALARY CREDIT", 50000.00, "credit", "HDFC"),
+				newStored("SWIGGY", 450.00, "debit", "ICICI"),
+				newStored("REFUND", -99.00, "credit", "HDFC"),
+			}
+
+			// Mirror the SQL updates
+			fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+				"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+					return [][]interface{}{userRow(userID)}
+				},
+				"FixTransactionAmountSigns": func(args []interface{}) [][]interface{} {
+					fixed := int64(0)
+					for _, s := range stored {
+						sign := s.txn.Amount.Int.Sign()
+						if matchesBank(s, args[1].(string)) &&
+							((s.txn.TxnType == "debit" && sign > 0) || (s.txn.TxnType == "credit" && sign < 0)) {
+							s.txn.Amount.Int = new(big.Int).Neg(s.txn.Amount.Int)
+							fixed++
+						}
+					}
+					return [][]interface{}{{fixed}}
+				},
+				"FixTransactionTypes": func(args []interface{}) [][]interface{} {
+					fixed := int64(0)
+					for _, s := range stored {
+						sign := s.txn.Amount.Int.Sign()
+						if !matchesBank(s, args[1].(string)) {
+							continue
+						}
+						if sign < 0 && s.txn.TxnType != "debit" {
+							s.txn.TxnType = "debit"
+							fixed++
+						} else if sign > 0 && s.txn.TxnType != "credit" {
+							s.txn.TxnType = "credit"
+							fixed++
+						}
+					}
+					return [][]interface{}{{fixed}}
+				},
+			}}
+			handler := NewTransactionHandler(db.New(fake), nil)
+
+			app := fiber.New()
+			app.Post("/transactions/fix-signs", func(c fiber.Ctx) error {
+				c.Locals("clerk_user_id", "user_test123")
+				return handler.FixSigns(c)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("POST", "/transactions/fix-signs"+tc.query, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			var result map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			assert.Equal(t, float64(tc.expectedFixed), result["fixed_count"])
+
+			for i, s := range stored {
+				assert.Equal(t, tc.expectedSigns[i], s.txn.Amount.Int.Sign(), s.txn.Description)
+				assert.Equal(t, tc.expectedTypes[i], s.txn.TxnType, s.txn.Description)
+			}
+		})
+	}
+
+	t.Run("Invalid from", func(t *testing.T) {
+		handler := NewTransactionHandler(db.New(&fakeDBTX{}), nil)
+		app := fiber.New()
+		app.Post("/transactions/fix-signs", func(c fiber.Ctx) error {
+			c.Locals("clerk_user_id", "user_test123")
+			return handler.FixSigns(c)
+		})
+
+		resp, err := app.Test(httptest.NewRequest("POST", "/transactions/fix-signs?from=sign", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+// TestBulkTagTransactions tests that tags are added to every transaction matching the filter
+func TestBulkTagTransactions(t *testing.T) {
+	userID := uuid.New()
+
+	type storedTxn struct {
+		txn  db.Transaction
+		bank string
+	}
+	newStored := func(description string, day int, bank string, tags ...string) *storedTxn {
+		txn := newTestTransaction(t, description, -500.00)
+		txn.TxnDate = pgtype.Date{Time: time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC), Valid: true}
+		txn.Tags = append([]string{}, tags...)
+		return &storedTxn{txn: txn, bank: bank}
+	}
+	stored := []*storedTxn{
+		newStored("UBER TRIP MUMBAI", 2, "HDFC"),
+		newStored("UBER TRIP PUNE", 10, "HDFC"),
+		newStored("UBER EATS ORDER", 12, "HDFC", "travel"),
+		newStored("UBER TRIP DELHI", 28, "HDFC"),
+		newStored("UBER TRIP BANGALORE", 15, "ICICI"),
+		newStored("SWIGGY ORDER", 11, "HDFC"),
+	}
+
+	// Mirror the SQL update: substring, bank, inclusive date range, and skip rows already tagged
+	var captured []interface{}
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"AddTransactionTags": func(args []interface{}) [][]interface{} {
+			captured = args
+			from := args[3].(pgtype.Date).Time
+			to := args[4].(pgtype.Date).Time
+			tags := args[5].([]string)
+			tagged := int64(0)
+			for _, s := range stored {
+				date := s.txn.TxnDate.Time
+				if !strings.Contains(strings.ToLower(s.txn.Description), strings.ToLower(args[1].(string))) ||
+					(args[2].(string) != "" && !strings.EqualFold(s.bank, args[2].(string))) ||
+					date.Before(from) || date.After(to) {
+					continue
+				}
+				changed := false
+				for _, tag := range tags {
+					if !slices.Contains(s.txn.Tags, tag) {
+						s.txn.Tags = append(s.txn.Tags, tag)
+						changed = true
+					}
+				}
+				if changed {
+					slices.Sort(s.txn.Tags)
+					tagged++
+				}
+			}
+			return [][]interface{}{{tagged}}
+		},
+	}}
+	handler := NewTransactionHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Post("/transactions/bulk-tag", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.BulkTagTransactions(c)
+	})
+
+	body := `{"description":"uber trip","bank":"hdfc","from":"2024-03-01","to":"2024-03-15","tags":[" Travel ","reimbursable","travel"]}`
+	req := httptest.NewRequest("POST", "/transactions/bulk-tag", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, float64(2), result["tagged_count"])
+	assert.Equal(t, []string{"reimbursable", "travel"}, captured[5])
+
+	expectedTags := [][]string{
+		{"reimbursable", "travel"},
+		{"reimbursable", "travel"},
+		{"travel"},
+		{},
+		{},
+		{},
+	}
+	for i, s := range stored {
+		assert.Equal(t, expectedTags[i], s.txn.Tags, s.txn.Description)
+	}
+
+	invalidCases := []struct {
+		name string
+		body string
+	}{
+		{"No tags", `{"description":"uber","tags":[" "]}`},
+		{"No filter", `{"tags":["travel"]}`},
+		{"Invalid date", `{"from":"03/01/2024","tags":["travel"]}`},
+		{"Reversed range", `{"from":"2024-03-15","to":"2024-03-01","tags":["travel"]}`},
+	}
+	for _, tc := range invalidCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/transactions/bulk-tag", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
+
+// TestGetRecurringTransactions tests that monthly charges are grouped and one-off spend is ignored
+func TestGetRecurringTransactions(t *testing.T) {
+	userID := uuid.New()
+	var rows [][]interface{}
+	for i := 0; i < 3; i++ {
+		txn := newTestTransaction(t, fmt.Sprintf("NETFLIX.COM REF%04d", i), -649.00)
+		txn.TxnDate.Time = time.Date(2024, time.Month(1+i), 7, 0, 0, 0, 0, time.UTC)
+		rows = append(rows, transactionRow(txn))
+	}
+	rows = append(rows, transactionRow(newTestTransaction(t, "AMAZON ORDER 1234", -1299.00)))
+
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetAllTransactions": func(args []interface{}) [][]interface{} {
+			return rows
+		},
+	}}
+	handler := NewTransactionHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Get("/transactions/recurring", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.GetRecurringTransactions(c)
+	})
+
+	t.Run("Unavailable without a detector", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/transactions/recurring", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+	})
+
+	t.Run("Lists recurring groups", func(t *testing.T) {
+		handler.SetRecurringDetector(services.NewRecurringDetector(0, 0))
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/transactions/recurring", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var result struct {
+			Recurring []struct {
+				Counterparty     string  `json:"counterparty"`
+				Cadence          string  `json:"cadence"`
+				Occurrences      int     `json:"occurrences"`
+				AverageAmount    float64 `json:"average_amount"`
+				NextExpectedDate string  `json:"next_expected_date"`
+			} `json:"recurring"`
+			Count int `json:"count"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+		require.Equal(t, 1, result.Count)
+		group := result.Recurring[0]
+		assert.Equal(t, "NETFLIX COM", group.Counterparty)
+		assert.Equal(t, "monthly", group.Cadence)
+		assert.Equal(t, 3, group.Occurrences)
+		assert.Equal(t, -649.00, group.AverageAmount)
+		assert.Equal(t, "2024-04-07", group.NextExpectedDate)
+	})
+}
+
+// TestGetRuleSuggestions tests that each kind is served by the suggester added for it
+func TestGetRuleSuggestions(t *testing.T) {
+	userID := uuid.New()
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetAllTransactions": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{
+				transactionRow(newTestTransaction(t, "ATM WDL MG ROAD", -5000.00)),
+				transactionRow(newTestTransaction(t, "ACH DIVIDEND INFOSYS", 120.00)),
+			}
+		},
+	}}
+	handler := NewTransactionHandler(db.New(fake), nil)
+	handler.AddRuleSuggester("cash", services.NewCashWithdrawalSuggester())
+	handler.AddRuleSuggester("income", services.NewIncomeSuggester())
+
+	app := fiber.New()
+	app.Get("/transactions/suggestions/:kind", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.GetRuleSuggestions(c)
+	})
+
+	for _, kind := range []string{"cash", "income"} {
+		t.Run(kind, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/transactions/suggestions/"+kind, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			var result struct {
+				Suggestions []models.RuleSuggestion `json:"suggestions"`
+				Count       int                     `json:"count"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			require.Equal(t, 1, result.Count)
+			assert.Equal(t, "regex", result.Suggestions[0].MatchType)
+		})
+	}
+
+	t.Run("Unknown kind", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/transactions/suggestions/salary", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
+
+// TestGetBanks tests per-bank transaction counts and date ranges over a multi-bank set
+func TestGetBanks(t *testing.T) {
+	userID := uuid.New()
+
+	type storedTxn struct {
+		date time.Time
+		bank string // Bank of the upload; empty for manual entries
+	}
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	stored := []storedTxn{
+		{day(15), "HDFC"}, {day(3), "HDFC"}, {day(28), "HDFC"},
+		{day(10), "ICICI"}, {day(12), "ICICI"},
+		{day(20), ""},
+		{day(5), "Axis"}, {day(6), "Axis"},
+	}
+
+	// Mirror the SQL grouping: count and date range per bank, most transactions first
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetUserBanks": func(args []interface{}) [][]interface{} {
+			type group struct {
+				count       int64
+				first, last time.Time
+			}
+			groups := map[string]*group{}
+			var order []string
+			for _, s := range stored {
+				bank := s.bank
+				if bank == "" {
+					bank = "UNKNOWN"
+				}
+				g, ok := groups[bank]
+				if !ok {
+					g = &group{first: s.date, last: s.date}
+					groups[bank] = g
+					order = append(order, bank)
+				}
+				g.count++
+				if s.date.Before(g.first) {
+					g.first = s.date
+				}
+				if s.date.After(g.last) {
+					g.last = s.date
+				}
+			}
+			sort.SliceStable(order, func(i, j int) bool {
+				if groups[order[i]].count != groups[order[j]].count {
+					return groups[order[i]].count > groups[order[j]].count
+				}
+				return order[i] < order[j]
+			})
+			var rows [][]interface{}
+			for _, bank := range order {
+				g := groups[bank]
+				rows = append(rows, []interface{}{
+					bank, g.count,
+					pgtype.Date{Time: g.first, Valid: true},
+					pgtype.Date{Time: g.last, Valid: true},
+				})
+			}
+			return rows
+		},
+	}}
+	handler := NewTransactionHandler(db.New(fake), nil)
+
+	app := fiber.New()
+	app.Get("/me/banks", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.GetBanks(c)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/me/banks", nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Banks []BankCoverage `json:"banks"`
+		Total int            `json:"total"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+	assert.Equal(t, 4, result.Total)
+	assert.Equal(t, []BankCoverage{
+		{Bank: "HDFC", TransactionCount: 3, FirstTxnDate: "2024-01-03", LastTxnDate: "2024-01-28"},
+		{Bank: "Axis", TransactionCount: 2, FirstTxnDate: "2024-01-05", LastTxnDate: "2024-01-06"},
+		{Bank: "ICICI", TransactionCount: 2, FirstTxnDate: "2024-01-10", LastTxnDate: "2024-01-12"},
+		{Bank: "UNKNOWN", TransactionCount: 1, FirstTxnDate: "2024-01-20", LastTxnDate: "2024-01-20"},
+	}, result.Banks)
+	assert.Contains(t, fake.calls, "GetUserBanks")
+}
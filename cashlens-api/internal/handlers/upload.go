@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -12,6 +17,8 @@ import (
 	"github.com/ashmitsharp/cashlens-api/internal/models"
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -20,15 +27,22 @@ const (
 	PresignedURLExpiryMinutes = 15
 	// PresignedURLExpirySeconds is the expiry time for presigned URLs in seconds
 	PresignedURLExpirySeconds = PresignedURLExpiryMinutes * 60
+	// MaxSkipRows is the largest number of leading rows a ProcessUpload request may skip
+	MaxSkipRows = 100
+	// uploadRecordTimeout bounds upload_history writes made after the request may be gone
+	uploadRecordTimeout = 5 * time.Second
 )
 
 var (
 	// AllowedContentTypes defines the content types that are allowed for upload
 	AllowedContentTypes = map[string]bool{
 		"text/csv":                 true,
+		"text/plain":               true,
 		"application/vnd.ms-excel": true,
 		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": true,
-		"application/pdf": true,
+		"application/pdf":   true,
+		"application/x-ofx": true,
+		"application/x-qif": true,
 	}
 )
 
@@ -36,7 +50,10 @@ var (
 type StorageService interface {
 	GenerateUploadKey(userID, filename string) (string, error)
 	GeneratePresignedURL(key, contentType string, expiryMinutes int) (string, error)
+	GeneratePresignedDownloadURL(key string, expiryMinutes int) (string, error)
 	DownloadFile(key string) (io.ReadCloser, error)
+	ListUserFiles(userID string) ([]models.FileInfo, error)
+	DeleteFile(key string) error
 }
 
 // Parser interface defines methods for parsing bank statement files
@@ -44,6 +61,27 @@ type Parser interface {
 	ParseFile(file io.Reader, filename string) ([]models.ParsedTransaction, error)
 }
 
+// RowSkippingParser is implemented by parsers that can skip a fixed number of leading rows
+type RowSkippingParser interface {
+	ParseFileSkippingRows(file io.Reader, filename string, skipRows int) ([]models.ParsedTransaction, error)
+}
+
+// SheetParser is implemented by parsers that can read a named sheet of a multi-sheet workbook
+type SheetParser interface {
+	ParseXLSXSheet(file io.Reader, sheetName string, skipRows int) ([]models.ParsedTransaction, error)
+}
+
+// SkipReportingParser is implemented by parsers that report the rows they had to skip,
+// so the problems can be surfaced in the upload summary
+type SkipReportingParser interface {
+	ParseFileReportingSkips(file io.Reader, filename string, skipRows int, sheet string) ([]models.ParsedTransaction, []string, error)
+}
+
+// PDFPageChecker is implemented by parsers that can warn when a PDF was only partially processed
+type PDFPageChecker interface {
+	ParsePDFCheckingPages(ctx context.Context, file io.Reader, expectedPages int) ([]models.ParsedTransaction, string, error)
+}
+
 // Categorizer interface defines methods for categorizing transactions
 type Categorizer interface {
 	Categorize(ctx context.Context, description string, userID uuid.UUID) (string, error)
@@ -52,12 +90,71 @@ type Categorizer interface {
 	GetStats(ctx context.Context, userID uuid.UUID) (map[string]interface{}, error)
 }
 
+// MatchCategorizer is implemented by categorizers that report which rule matched
+type MatchCategorizer interface {
+	CategorizeMatch(ctx context.Context, description string, userID uuid.UUID) (models.CategoryMatch, error)
+}
+
+// AmountCategorizer is implemented by categorizers that support amount-range rules
+type AmountCategorizer interface {
+	CategorizeMatchWithAmount(ctx context.Context, description string, amount float64, userID uuid.UUID) (models.CategoryMatch, error)
+}
+
+// AmountRuleChecker is implemented by categorizers that can tell whether a user's
+// rules depend on the amount, not just the description
+type AmountRuleChecker interface {
+	HasAmountRules(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+// TagMatcher is implemented by categorizers whose rules can also tag transactions
+type TagMatcher interface {
+	MatchTags(ctx context.Context, description string, amount float64, userID uuid.UUID) ([]string, error)
+}
+
+// StatsService interface defines methods for cached per-user statistics
+type StatsService interface {
+	GetUserStats(ctx context.Context, userID uuid.UUID) (models.UserStats, error)
+	RecomputeUserStats(ctx context.Context, userID uuid.UUID) (models.UserStats, error)
+	RecomputeAllUserStats(ctx context.Context) (int, error)
+	InvalidateAllUserStats()
+}
+
+// DuplicateDetector interface defines methods for flagging already-imported transactions
+type DuplicateDetector interface {
+	ToleranceDays() int
+	FindMatches(incoming, existing []models.ParsedTransaction) []int
+}
+
+// ReversalDetector interface defines methods for pairing debits with their reversing credits
+type ReversalDetector interface {
+	FindReversals(transactions []models.ParsedTransaction) []bool
+}
+
+// Reconciler interface defines methods for comparing imported totals against statement figures
+type Reconciler interface {
+	Discrepancy(expected, actual float64) (float64, bool)
+	Tolerance() float64
+}
+
+// FileValidator interface defines methods for enforcing the upload size limit
+type FileValidator interface {
+	MaxSize() int64
+}
+
 // UploadHandler handles file upload-related requests
 type UploadHandler struct {
 	storage     StorageService
 	parser      Parser
 	categorizer Categorizer
 	db          *db.Queries
+	stats       StatsService
+	dedup       DuplicateDetector
+	reversals   ReversalDetector
+	reconciler  Reconciler
+	validator   FileValidator
+	omitRawData bool // Store NULL raw_data instead of the original row
+	noMemo      bool // Categorize every row instead of once per repeated description
+	maxPageSize int  // Largest limit the upload history returns (0 = DefaultMaxPageSize)
 }
 
 // NewUploadHandler creates a new upload handler instance (backward compatible)
@@ -90,9 +187,54 @@ func NewUploadHandlerFull(storage StorageService, parser Parser, categorizer Cat
 	}
 }
 
+// SetStatsService enables recomputing cached stats after an upload is processed
+func (h *UploadHandler) SetStatsService(stats StatsService) {
+	h.stats = stats
+}
+
+// SetDuplicateDetector enables skipping transactions that duplicate already-imported ones
+func (h *UploadHandler) SetDuplicateDetector(dedup DuplicateDetector) {
+	h.dedup = dedup
+}
+
+// SetReversalDetector enables categorizing reversal pairs so they net to zero in reports
+func (h *UploadHandler) SetReversalDetector(reversals ReversalDetector) {
+	h.reversals = reversals
+}
+
+// SetReconciler enables checking an upload's imported totals against its statement
+func (h *UploadHandler) SetReconciler(reconciler Reconciler) {
+	h.reconciler = reconciler
+}
+
+// SetFileValidator enables the upload size limit. The limit is returned with presigned
+// URLs, and processing rejects larger files and deletes them from storage.
+func (h *UploadHandler) SetFileValidator(validator FileValidator) {
+	h.validator = validator
+}
+
+// SetStoreRawData controls whether the original row is kept in raw_data.
+// Disabling it saves storage and avoids keeping sensitive data, but
+// transactions without raw_data are skipped by the reparse endpoint.
+func (h *UploadHandler) SetStoreRawData(store bool) {
+	h.omitRawData = !store
+}
+
+// SetCategoryMemo controls whether an upload categorizes each distinct description
+// once and reuses the result for its repeats (enabled by default). Results are only
+// kept for the duration of one ProcessUpload request.
+func (h *UploadHandler) SetCategoryMemo(enabled bool) {
+	h.noMemo = !enabled
+}
+
+// SetMaxPageSize sets the largest limit the upload history accepts; larger limits are clamped
+func (h *UploadHandler) SetMaxPageSize(size int) {
+	h.maxPageSize = size
+}
+
 // GetPresignedURL generates a presigned URL for file upload
 // Query params: filename (required), content_type (required)
-// Returns: upload_url, file_key, expires_in
+// Returns: upload_url, file_key, expires_in, max_size (when a size limit is set)
 func (h *UploadHandler) GetPresignedURL(c fiber.Ctx) error {
 	// 1. Get query parameters
 	filename := c.Query("filename")
@@ -145,22 +287,78 @@ func (h *UploadHandler) GetPresignedURL(c fiber.Ctx) error {
 		})
 	}
 
-	// 8. Return successful response
-	return c.JSON(fiber.Map{
+	// 8. Return successful response, with the size limit so clients can check before uploading
+	resp := fiber.Map{
 		"upload_url": url,
 		"file_key":   key,
 		"expires_in": PresignedURLExpirySeconds,
+	}
+	if h.validator != nil {
+		resp["max_size"] = h.validator.MaxSize()
+	}
+	return c.JSON(resp)
+}
+
+// GetDownloadURL generates a presigned URL for re-downloading an uploaded statement
+// GET /v1/upload/download-url?file_key=uploads/user123/1699564800-uuid-statement.csv
+func (h *UploadHandler) GetDownloadURL(c fiber.Ctx) error {
+	// 1. Validate file_key
+	fileKey := c.Query("file_key")
+	if fileKey == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "file_key is required",
+		})
+	}
+
+	// 2. Get clerk_user_id from context (set by auth middleware)
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 3. Security check: Verify file belongs to user
+	if !isFileOwnedByUser(fileKey, clerkUserID) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "forbidden - cannot access this file",
+		})
+	}
+
+	// 4. Generate presigned URL
+	url, err := h.storage.GeneratePresignedDownloadURL(fileKey, PresignedURLExpiryMinutes)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to generate presigned URL",
+			"details": err.Error(),
+		})
+	}
+
+	// 5. Return successful response
+	return c.JSON(fiber.Map{
+		"download_url": url,
+		"file_key":     fileKey,
+		"expires_in":   PresignedURLExpirySeconds,
 	})
 }
 
 // ProcessUploadRequest represents the request body for ProcessUpload
 type ProcessUploadRequest struct {
 	FileKey string `json:"file_key"`
+	// SkipRows overrides header detection by skipping this many leading rows
+	SkipRows int `json:"skip_rows,omitempty"`
+	// ExpectedPages is the PDF statement's page count; when omitted it is detected from the file
+	ExpectedPages int `json:"expected_pages,omitempty"`
+	// Sheet names the XLSX sheet holding the transactions; by default the first
+	// sheet with recognized headers is used
+	Sheet string `json:"sheet,omitempty"`
 }
 
 // ProcessUpload processes an uploaded file from S3 and returns summary statistics
 // POST /v1/upload/process
-// Body: {"file_key": "uploads/user123/1699564800-uuid-statement.csv"}
+// Body: {"file_key": "uploads/user123/1699564800-uuid-statement.csv", "skip_rows": 0, "sheet": "Account Statement"}
+// Processing a file_key that already completed returns the stored summary, with
+// already_processed set, instead of importing the file again.
 func (h *UploadHandler) ProcessUpload(c fiber.Ctx) error {
 	// 1. Parse request body
 	var req ProcessUploadRequest
@@ -176,6 +374,16 @@ func (h *UploadHandler) ProcessUpload(c fiber.Ctx) error {
 			"error": "file_key is required",
 		})
 	}
+	if req.ExpectedPages < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "expected_pages must not be negative",
+		})
+	}
+	if req.SkipRows < 0 || req.SkipRows > MaxSkipRows {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("skip_rows must be between 0 and %d", MaxSkipRows),
+		})
+	}
 
 	// 3. Authenticate and authorize
 	clerkUserID, ok := c.Locals("clerk_user_id").(string)
@@ -203,47 +411,119 @@ func (h *UploadHandler) ProcessUpload(c fiber.Ctx) error {
 		})
 	}
 
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userUUID
+	pgUserID.Valid = true
+
+	// 4.5. A file that was already processed returns its stored result instead of being imported again
+	previous, err := h.db.GetCompletedUploadByFileKey(c.Context(), db.GetCompletedUploadByFileKeyParams{
+		UserID:  pgUserID,
+		FileKey: req.FileKey,
+	})
+	if err == nil {
+		var summary fiber.Map
+		if err := json.Unmarshal(previous.Summary, &summary); err == nil {
+			summary["already_processed"] = true
+			return c.JSON(summary)
+		}
+		fmt.Printf("Failed to decode stored upload summary: %v\n", err)
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		fmt.Printf("Failed to look up earlier upload: %v\n", err)
+	}
+
+	// 4.6. Record the upload before processing so failed attempts are kept too. Only one
+	// processing or completed record may exist per file, so a concurrent request for the
+	// same file_key fails here instead of importing it a second time.
+	// A record left processing by a crash or cancelled request is reclaimed once it is stale.
+	filename := filepath.Base(req.FileKey)
+	bankType := detectBankFromFilename(filename)
+	record := db.CreateUploadHistoryParams{
+		UserID:    pgUserID,
+		Filename:  filename,
+		FileKey:   req.FileKey,
+		BankType:  pgtype.Text{String: bankType, Valid: bankType != "UNKNOWN"},
+		Status:    "processing",
+		TotalRows: pgtype.Int4{Int32: 0, Valid: true},
+	}
+	uploadHistory, err := h.db.CreateUploadHistory(c.Context(), record)
+	if isUniqueViolation(err) && h.reclaimStaleUploads(c.Context(), pgUserID, req.FileKey) {
+		uploadHistory, err = h.db.CreateUploadHistory(c.Context(), record)
+	}
+	if isUniqueViolation(err) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "file is already being processed",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to record upload",
+			"details": err.Error(),
+		})
+	}
+
 	// 5. Download file from S3
 	reader, err := h.storage.DownloadFile(req.FileKey)
 	if err != nil {
+		h.failUpload(c.Context(), uploadHistory.ID, "file not found in storage")
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "file not found in storage",
 		})
 	}
 	defer reader.Close()
 
+	// 5.1. Enforce the size limit; presigned uploads aren't size-checked by S3, so an
+	// oversized object is deleted here rather than left orphaned in the bucket
+	var file io.Reader = reader
+	if h.validator != nil {
+		data, err := io.ReadAll(io.LimitReader(reader, h.validator.MaxSize()+1))
+		if err != nil {
+			h.failUpload(c.Context(), uploadHistory.ID, "failed to read file from storage")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "failed to read file from storage",
+				"details": err.Error(),
+			})
+		}
+		if int64(len(data)) > h.validator.MaxSize() {
+			if err := h.storage.DeleteFile(req.FileKey); err != nil {
+				fmt.Printf("Failed to delete oversized upload %s: %v\n", req.FileKey, err)
+			}
+			h.failUpload(c.Context(), uploadHistory.ID, "file too large")
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"error":    "file too large",
+				"details":  fmt.Sprintf("file exceeds maximum allowed size (%d bytes)", h.validator.MaxSize()),
+				"max_size": h.validator.MaxSize(),
+			})
+		}
+		file = bytes.NewReader(data)
+	}
+
 	// 6. Parse file and extract transactions
-	filename := filepath.Base(req.FileKey)
-	transactions, err := h.parser.ParseFile(reader, filename)
+	transactions, pagesWarning, parseWarnings, err := h.parseFile(c.Context(), file, filename, req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		h.failUpload(c.Context(), uploadHistory.ID, err.Error())
+		// The user's file is fine when a parsing dependency is down, so report it as a gateway error
+		var unavailableErr interface{ ServiceUnavailable() bool }
+		if errors.As(err, &unavailableErr) && unavailableErr.ServiceUnavailable() {
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+				"error":   "PDF processing temporarily unavailable, please retry",
+				"details": err.Error(),
+			})
+		}
+
+		resp := fiber.Map{
 			"error":   "failed to parse file",
 			"details": err.Error(),
-		})
+		}
+		// Unknown formats report the headers that were seen so users can map a schema
+		var formatErr interface{ UnmatchedHeaders() []string }
+		if errors.As(err, &formatErr) {
+			resp["unmatched_headers"] = formatErr.UnmatchedHeaders()
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(resp)
 	}
 
-	// 7. Create upload history record
-	var pgUserID pgtype.UUID
-	pgUserID.Bytes = userUUID
-	pgUserID.Valid = true
-
-	// Detect bank type from filename
-	bankType := detectBankFromFilename(filename)
-
-	uploadHistory, err := h.db.CreateUploadHistory(c.Context(), db.CreateUploadHistoryParams{
-		UserID:   pgUserID,
-		Filename: filename,
-		FileKey:  req.FileKey,
-		BankType: pgtype.Text{String: bankType, Valid: bankType != "UNKNOWN"},
-		Status:   "processing",
-		TotalRows: pgtype.Int4{
-			Int32: int32(len(transactions)),
-			Valid: true,
-		},
-	})
-	if err != nil {
-		fmt.Printf("Failed to create upload history: %v\n", err)
-	}
+	// 7. Link transactions to the upload record, tagged with the import source
+	source := sourceFromFilename(filename)
 
 	var uploadID pgtype.UUID
 	if uploadHistory.ID.Valid {
@@ -253,26 +533,59 @@ func (h *UploadHandler) ProcessUpload(c fiber.Ctx) error {
 
 	// 8. Categorize and save transactions if categorizer and db are available
 	var categorizedCount int
+	var duplicateCount int
+	var errorCount int
 	var accuracyPercent float64
+	warnings := parseWarnings // Per-row problems, stored with the upload result
 
 	if h.categorizer != nil && h.db != nil {
 
 		// Ensure categorizer has loaded global rules
 		if err := h.categorizer.LoadGlobalRules(c.Context()); err != nil {
+			h.failUpload(c.Context(), uploadHistory.ID, "failed to load categorization rules")
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error":   "failed to load categorization rules",
 				"details": err.Error(),
 			})
 		}
 
+		// Match transactions that were already imported (within the tolerance window)
+		matches := h.findExistingMatches(c.Context(), pgUserID, transactions)
+
+		// Pair debits with the credits that reversed them
+		reversals := make([]bool, len(transactions))
+		if h.reversals != nil {
+			reversals = h.reversals.FindReversals(transactions)
+		}
+
+		// Repeated descriptions are categorized once per upload
+		memo := h.newCategoryMemo(c.Context(), userUUID)
+
 		// Categorize and save each transaction
-		for _, txn := range transactions {
-			// Categorize transaction
-			category, err := h.categorizer.Categorize(c.Context(), txn.Description, userUUID)
-			if err != nil {
-				// Log error but continue processing
-				fmt.Printf("Failed to categorize transaction: %v\n", err)
-				category = ""
+		for i, txn := range transactions {
+			// Categorize transaction (reversal pairs skip the rules)
+			category := models.CategoryReversal
+			var match models.CategoryMatch
+			if !reversals[i] {
+				match, err = memo.categorize(c.Context(), txn.Description, txn.Amount, userUUID)
+				if err != nil {
+					// Log error but continue processing
+					fmt.Printf("Failed to categorize transaction: %v\n", err)
+					warnings = append(warnings, fmt.Sprintf("row %d: failed to categorize: %v", i+1, err))
+				}
+				category = match.Category
+			}
+
+			// Tag rules apply alongside categorization
+			tags := []string{} // Column is NOT NULL
+			if matcher, ok := h.categorizer.(TagMatcher); ok && !reversals[i] {
+				matched, err := matcher.MatchTags(c.Context(), txn.Description, txn.Amount, userUUID)
+				if err != nil {
+					fmt.Printf("Failed to tag transaction: %v\n", err)
+					warnings = append(warnings, fmt.Sprintf("row %d: failed to tag: %v", i+1, err))
+				} else {
+					tags = append(tags, matched...)
+				}
 			}
 
 			if category != "" {
@@ -283,6 +596,8 @@ func (h *UploadHandler) ProcessUpload(c fiber.Ctx) error {
 			var pgAmount pgtype.Numeric
 			if err := pgAmount.Scan(fmt.Sprintf("%.2f", txn.Amount)); err != nil {
 				fmt.Printf("Failed to convert amount to numeric: %v\n", err)
+				warnings = append(warnings, fmt.Sprintf("row %d: invalid amount: %v", i+1, err))
+				errorCount++
 				continue
 			}
 
@@ -296,22 +611,61 @@ func (h *UploadHandler) ProcessUpload(c fiber.Ctx) error {
 				}
 			}
 
+			// Re-imported rows refresh the existing transaction, keeping reviewed categories
+			if matches[i].Valid {
+				duplicateCount++
+				saved, err := h.db.UpsertTransactionPreservingReview(c.Context(), db.UpsertTransactionPreservingReviewParams{
+					ID:          matches[i],
+					UserID:      pgUserID,
+					TxnDate:     pgtype.Date{Time: txn.TxnDate, Valid: true},
+					Description: txn.Description,
+					Amount:      pgAmount,
+					TxnType:     txnType,
+					RawData:     rawDataText(txn.RawData, !h.omitRawData),
+					Source:      pgtype.Text{String: source, Valid: source != ""},
+					Flags:       append([]string{}, txn.Flags...),
+					Column10:    category,
+					ReferenceNo: pgtype.Text{String: txn.ReferenceNo, Valid: txn.ReferenceNo != ""},
+					Column12:    match.Score, // 0 stores NULL
+					Column13:    tags,        // Merged with existing tags
+					UploadID:    uploadID,
+				})
+				if err != nil {
+					fmt.Printf("Failed to update re-imported transaction: %v\n", err)
+					warnings = append(warnings, fmt.Sprintf("row %d: failed to update re-imported transaction: %v", i+1, err))
+					errorCount++
+				} else if saved.Category.String == match.Category {
+					h.recordCategorization(c.Context(), saved, match)
+				}
+				continue
+			}
+
 			// Save transaction to database
-			_, err = h.db.CreateTransaction(c.Context(), db.CreateTransactionParams{
-				UserID:      pgUserID,
-				TxnDate:     pgtype.Date{Time: txn.TxnDate, Valid: true},
-				Description: txn.Description,
-				Amount:      pgAmount,
-				TxnType:     txnType,
-				Category:    pgtype.Text{String: category, Valid: category != ""},
-				IsReviewed:  false,
-				RawData:     pgtype.Text{String: txn.RawData, Valid: txn.RawData != ""},
+			saved, err := h.db.CreateTransaction(c.Context(), db.CreateTransactionParams{
+				UserID:        pgUserID,
+				TxnDate:       pgtype.Date{Time: txn.TxnDate, Valid: true},
+				Description:   txn.Description,
+				Amount:        pgAmount,
+				TxnType:       txnType,
+				Category:      pgtype.Text{String: category, Valid: category != ""},
+				IsReviewed:    false,
+				RawData:       rawDataText(txn.RawData, !h.omitRawData),
+				Source:        pgtype.Text{String: source, Valid: source != ""},
+				Flags:         append([]string{}, txn.Flags...), // Column is NOT NULL
+				ReferenceNo:   pgtype.Text{String: txn.ReferenceNo, Valid: txn.ReferenceNo != ""},
+				CategoryScore: pgtype.Float8{Float64: match.Score, Valid: match.Score > 0},
+				Tags:          tags,
+				UploadID:      uploadID,
 			})
 
 			if err != nil {
 				// Log error but continue processing other transactions
 				fmt.Printf("Failed to save transaction: %v\n", err)
+				warnings = append(warnings, fmt.Sprintf("row %d: failed to save: %v", i+1, err))
+				errorCount++
+				continue
 			}
+			h.recordCategorization(c.Context(), saved, match)
 		}
 
 		// Calculate accuracy
@@ -320,9 +674,12 @@ func (h *UploadHandler) ProcessUpload(c fiber.Ctx) error {
 		}
 	}
 
-	// 9. Update upload history with completion status
+	// 9. Update upload history with completion status; the import is done, so this is
+	// recorded even if the client has gone
 	if uploadHistory.ID.Valid {
-		_, err = h.db.CompleteUploadProcessing(c.Context(), db.CompleteUploadProcessingParams{
+		recordCtx, cancel := uploadRecordContext(c.Context())
+		defer cancel()
+		_, err = h.db.CompleteUploadProcessing(recordCtx, db.CompleteUploadProcessingParams{
 			ID:           uploadHistory.ID,
 			Status:       "completed",
 			ErrorMessage: pgtype.Text{Valid: false},
@@ -339,11 +696,11 @@ func (h *UploadHandler) ProcessUpload(c fiber.Ctx) error {
 				Valid: true,
 			},
 			DuplicateRows: pgtype.Int4{
-				Int32: 0,
+				Int32: int32(duplicateCount),
 				Valid: true,
 			},
 			ErrorRows: pgtype.Int4{
-				Int32: 0,
+				Int32: int32(errorCount),
 				Valid: true,
 			},
 		})
@@ -352,11 +709,355 @@ func (h *UploadHandler) ProcessUpload(c fiber.Ctx) error {
 		}
 	}
 
+	// 9.5. Refresh cached stats now that new transactions were inserted
+	if h.stats != nil {
+		if _, err := h.stats.RecomputeUserStats(c.Context(), userUUID); err != nil {
+			fmt.Printf("Failed to recompute stats: %v\n", err)
+		}
+	}
+
 	// 10. Build and return summary response
-	summary := buildProcessSummaryWithCategorization(req.FileKey, filename, transactions, categorizedCount, accuracyPercent)
+	summary := buildProcessSummaryWithCategorization(req.FileKey, filename, transactions, categorizedCount, duplicateCount, accuracyPercent)
+	if accounts := accountBreakdown(transactions); len(accounts) > 0 {
+		summary["accounts"] = accounts
+	}
+	issues := uploadIssues(transactions, parseWarnings, warnings[len(parseWarnings):], pagesWarning)
+	warnings = append(warnings, issues.ReconciliationWarnings...)
+	if len(warnings) > 0 {
+		summary["warnings"] = warnings
+	}
+	if pagesWarning != "" {
+		summary["pages_warning"] = pagesWarning
+	}
+
+	// 11. Keep the result so it can be revisited via GET /v1/uploads/:id and /issues
+	if uploadHistory.ID.Valid {
+		recordCtx, cancel := uploadRecordContext(c.Context())
+		defer cancel()
+		h.saveUploadResult(recordCtx, uploadHistory.ID, summary, warnings, issues)
+	}
 	return c.JSON(summary)
 }
 
+// failUpload marks an upload record as failed with the reason, so the attempt stays auditable.
+// Failures are logged; the request already reports the error.
+func (h *UploadHandler) failUpload(ctx context.Context, uploadID pgtype.UUID, reason string) {
+	if !uploadID.Valid {
+		return
+	}
+	// Failures are often caused by the request being cancelled, which must not leave
+	// the record processing
+	ctx, cancel := uploadRecordContext(ctx)
+	defer cancel()
+	zero := pgtype.Int4{Int32: 0, Valid: true}
+	_, err := h.db.CompleteUploadProcessing(ctx, db.CompleteUploadProcessingParams{
+		ID:              uploadID,
+		Status:          "failed",
+		ErrorMessage:    pgtype.Text{String: reason, Valid: true},
+		TotalRows:       zero,
+		ParsedRows:      zero,
+		CategorizedRows: zero,
+		DuplicateRows:   zero,
+		ErrorRows:       zero,
+	})
+	if err != nil {
+		fmt.Printf("Failed to mark upload as failed: %v\n", err)
+	}
+}
+
+// uploadRecordContext returns a context for upload_history writes that must land even
+// when the request context is cancelled, bounded by uploadRecordTimeout
+func uploadRecordContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), uploadRecordTimeout)
+}
+
+// reclaimStaleUploads marks the user's records of fileKey that GetStuckUploads reports
+// (processing for over 5 minutes, e.g. after a crash) as failed, so the file can be
+// retried. It reports whether any record was reclaimed.
+func (h *UploadHandler) reclaimStaleUploads(ctx context.Context, userID pgtype.UUID, fileKey string) bool {
+	stuck, err := h.db.GetStuckUploads(ctx)
+	if err != nil {
+		fmt.Printf("Failed to look up stuck uploads: %v\n", err)
+		return false
+	}
+	reclaimed := false
+	for _, upload := range stuck {
+		if upload.UserID == userID && upload.FileKey == fileKey {
+			h.failUpload(ctx, upload.ID, "processing did not finish")
+			reclaimed = true
+		}
+	}
+	return reclaimed
+}
+
+// saveUploadResult stores the summary, warnings and issues of a processed upload.
+// Failures are logged; the import itself already succeeded.
+func (h *UploadHandler) saveUploadResult(ctx context.Context, uploadID pgtype.UUID, summary fiber.Map, warnings []string, issues models.UploadIssues) {
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		fmt.Printf("Failed to encode upload summary: %v\n", err)
+		return
+	}
+	issuesJSON, err := json.Marshal(issues)
+	if err != nil {
+		fmt.Printf("Failed to encode upload issues: %v\n", err)
+		return
+	}
+	err = h.db.SaveUploadResult(ctx, db.SaveUploadResultParams{
+		UploadID: uploadID,
+		Summary:  summaryJSON,
+		Warnings: append([]string{}, warnings...), // Column is NOT NULL
+		Issues:   issuesJSON,
+	})
+	if err != nil {
+		fmt.Printf("Failed to save upload result: %v\n", err)
+	}
+}
+
+// categorize runs the categorizer, reporting the matched rule when the categorizer supports it
+func (h *UploadHandler) categorize(ctx context.Context, description string, amount float64, userID uuid.UUID) (models.CategoryMatch, error) {
+	if matcher, ok := h.categorizer.(AmountCategorizer); ok {
+		return matcher.CategorizeMatchWithAmount(ctx, description, amount, userID)
+	}
+	if matcher, ok := h.categorizer.(MatchCategorizer); ok {
+		return matcher.CategorizeMatch(ctx, description, userID)
+	}
+	category, err := h.categorizer.Categorize(ctx, description, userID)
+	return models.CategoryMatch{Category: category}, err
+}
+
+// categoryMemo reuses categorization results within a single upload, where the same
+// merchant description often repeats many times. Without a map it categorizes every call.
+type categoryMemo struct {
+	handler  *UploadHandler
+	byAmount bool // Rules depend on the amount, so it is part of the key
+	matches  map[string]models.CategoryMatch
+}
+
+// newCategoryMemo creates the memo for one upload; it caches nothing when memoization is off.
+// The amount is part of the key unless the categorizer reports that none of the
+// user's rules depend on it.
+func (h *UploadHandler) newCategoryMemo(ctx context.Context, userID uuid.UUID) *categoryMemo {
+	if h.noMemo {
+		return &categoryMemo{handler: h}
+	}
+	byAmount := false
+	if _, ok := h.categorizer.(AmountCategorizer); ok {
+		byAmount = true
+		if checker, ok := h.categorizer.(AmountRuleChecker); ok {
+			if hasAmountRules, err := checker.HasAmountRules(ctx, userID); err == nil {
+				byAmount = hasAmountRules
+			}
+		}
+	}
+	return &categoryMemo{
+		handler:  h,
+		byAmount: byAmount,
+		matches:  make(map[string]models.CategoryMatch),
+	}
+}
+
+// categorize returns the cached match for a repeated description, categorizing and
+// caching it on first sight. Failed categorizations are not cached.
+func (m *categoryMemo) categorize(ctx context.Context, description string, amount float64, userID uuid.UUID) (models.CategoryMatch, error) {
+	if m.matches == nil {
+		return m.handler.categorize(ctx, description, amount, userID)
+	}
+
+	// Rules match case-insensitively on the trimmed description
+	key := strings.ToUpper(strings.TrimSpace(description))
+	if m.byAmount {
+		key = fmt.Sprintf("%s|%.2f", key, amount)
+	}
+	if match, ok := m.matches[key]; ok {
+		return match, nil
+	}
+
+	match, err := m.handler.categorize(ctx, description, amount, userID)
+	if err != nil {
+		return match, err
+	}
+	m.matches[key] = match
+	return match, nil
+}
+
+// recordCategorization stores which rule categorized a saved transaction, replacing
+// its earlier audit when a re-import matched differently. Matches without rule
+// details (or no match) are not recorded.
+func (h *UploadHandler) recordCategorization(ctx context.Context, txn db.Transaction, match models.CategoryMatch) {
+	if match.MatchType == "" {
+		return
+	}
+	err := h.db.UpsertCategorizationAudit(ctx, db.UpsertCategorizationAuditParams{
+		UserID:        txn.UserID,
+		TransactionID: txn.ID,
+		RuleID:        pgtype.UUID{Bytes: match.RuleID, Valid: match.RuleID != uuid.Nil},
+		RuleType:      match.RuleType,
+		MatchType:     match.MatchType,
+		Category:      match.Category,
+	})
+	if err != nil {
+		fmt.Printf("Failed to record categorization: %v\n", err)
+	}
+}
+
+// parseFile parses the downloaded file, skipping leading rows and selecting the
+// XLSX sheet when requested. For PDFs it also returns a warning when fewer pages
+// were processed than expected; for other files, warnings for rows that were skipped.
+func (h *UploadHandler) parseFile(ctx context.Context, file io.Reader, filename string, req ProcessUploadRequest) ([]models.ParsedTransaction, string, []string, error) {
+	skipRows := req.SkipRows
+	ext := strings.ToLower(filepath.Ext(filename))
+	if checker, ok := h.parser.(PDFPageChecker); ok && ext == ".pdf" {
+		transactions, pagesWarning, err := checker.ParsePDFCheckingPages(ctx, file, req.ExpectedPages)
+		return transactions, pagesWarning, nil, err
+	}
+	if reporter, ok := h.parser.(SkipReportingParser); ok {
+		transactions, skipped, err := reporter.ParseFileReportingSkips(file, filename, skipRows, req.Sheet)
+		return transactions, "", skipped, err
+	}
+	if req.Sheet != "" && (ext == ".xlsx" || ext == ".xls") {
+		sheetParser, ok := h.parser.(SheetParser)
+		if !ok {
+			return nil, "", nil, fmt.Errorf("parser does not support sheet selection")
+		}
+		transactions, err := sheetParser.ParseXLSXSheet(file, req.Sheet, skipRows)
+		return transactions, "", nil, err
+	}
+	if skipRows == 0 {
+		transactions, err := h.parser.ParseFile(file, filename)
+		return transactions, "", nil, err
+	}
+	skipper, ok := h.parser.(RowSkippingParser)
+	if !ok {
+		return nil, "", nil, fmt.Errorf("parser does not support skip_rows")
+	}
+	transactions, err := skipper.ParseFileSkippingRows(file, filename, skipRows)
+	return transactions, "", nil, err
+}
+
+// findExistingMatches returns, for each parsed transaction, the ID of the existing
+// transaction it duplicates; IDs are invalid for new transactions.
+// Without a configured detector nothing is matched.
+func (h *UploadHandler) findExistingMatches(ctx context.Context, userID pgtype.UUID, transactions []models.ParsedTransaction) []pgtype.UUID {
+	matches := make([]pgtype.UUID, len(transactions))
+	if h.dedup == nil || len(transactions) == 0 {
+		return matches
+	}
+
+	// Only load existing transactions in the date span of the upload, widened by the tolerance
+	from, to := transactions[0].TxnDate, transactions[0].TxnDate
+	for _, txn := range transactions[1:] {
+		if txn.TxnDate.Before(from) {
+			from = txn.TxnDate
+		}
+		if txn.TxnDate.After(to) {
+			to = txn.TxnDate
+		}
+	}
+	tolerance := h.dedup.ToleranceDays()
+
+	rows, err := h.db.GetTransactionsByDateRange(ctx, db.GetTransactionsByDateRangeParams{
+		UserID:    userID,
+		TxnDate:   pgtype.Date{Time: from.AddDate(0, 0, -tolerance), Valid: true},
+		TxnDate_2: pgtype.Date{Time: to.AddDate(0, 0, tolerance), Valid: true},
+	})
+	if err != nil {
+		fmt.Printf("Failed to load existing transactions for dedup: %v\n", err)
+		return matches
+	}
+
+	existing := make([]models.ParsedTransaction, 0, len(rows))
+	existingIDs := make([]pgtype.UUID, 0, len(rows))
+	for _, row := range rows {
+		amount, err := row.Amount.Float64Value()
+		if err != nil || !amount.Valid {
+			continue
+		}
+		existing = append(existing, models.ParsedTransaction{
+			TxnDate:     row.TxnDate.Time,
+			Description: row.Description,
+			Amount:      amount.Float64,
+			ReferenceNo: row.ReferenceNo.String,
+		})
+		existingIDs = append(existingIDs, row.ID)
+	}
+
+	for i, idx := range h.dedup.FindMatches(transactions, existing) {
+		if idx >= 0 {
+			matches[i] = existingIDs[idx]
+		}
+	}
+	return matches
+}
+
+// accountBreakdown counts transactions per account for combined multi-account statements.
+// Returns nil when no account sections were found.
+func accountBreakdown(transactions []models.ParsedTransaction) map[string]int {
+	var accounts map[string]int
+	for _, txn := range transactions {
+		if txn.Account == "" {
+			continue
+		}
+		if accounts == nil {
+			accounts = make(map[string]int)
+		}
+		accounts[txn.Account]++
+	}
+	return accounts
+}
+
+// balanceWarnings describes the transactions whose running balance didn't reconcile
+// with the previous row, which points at a misparsed amount or a skipped row
+func balanceWarnings(transactions []models.ParsedTransaction) []string {
+	var warnings []string
+	for i, txn := range transactions {
+		if slices.Contains(txn.Flags, models.FlagReconciliationMismatch) {
+			warnings = append(warnings, fmt.Sprintf(
+				"row %d: balance %.2f does not match the previous balance plus amount %.2f; a row may be misparsed or missing",
+				i+1, txn.Balance, txn.Amount))
+		}
+	}
+	return warnings
+}
+
+// uploadIssues groups the problems found while importing: rows the parser skipped,
+// rows that failed to categorize or save, balance mismatches, and flagged rows
+func uploadIssues(transactions []models.ParsedTransaction, skipped, rowErrors []string, pagesWarning string) models.UploadIssues {
+	issues := models.NewUploadIssues()
+	issues.SkippedRows = append(issues.SkippedRows, skipped...)
+	issues.RowErrors = append(issues.RowErrors, rowErrors...)
+	issues.ReconciliationWarnings = append(issues.ReconciliationWarnings, balanceWarnings(transactions)...)
+	issues.PagesWarning = pagesWarning
+	for i, txn := range transactions {
+		if len(txn.Flags) == 0 {
+			continue
+		}
+		issues.FlaggedRows = append(issues.FlaggedRows, models.FlaggedRow{
+			Row:         i + 1,
+			TxnDate:     models.NewDate(txn.TxnDate),
+			Description: txn.Description,
+			Amount:      txn.Amount,
+			Flags:       txn.Flags,
+		})
+	}
+	return issues
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" // unique_violation
+}
+
+// rawDataText converts a transaction's original row to a nullable column value,
+// returning NULL when raw data storage is disabled
+func rawDataText(rawData string, store bool) pgtype.Text {
+	if !store {
+		return pgtype.Text{Valid: false}
+	}
+	return pgtype.Text{String: rawData, Valid: rawData != ""}
+}
+
 // isFileOwnedByUser checks if a file key belongs to the specified user
 func isFileOwnedByUser(fileKey, userID string) bool {
 	expectedPrefix := fmt.Sprintf("uploads/%s/", userID)
@@ -365,11 +1066,13 @@ func isFileOwnedByUser(fileKey, userID string) bool {
 
 // buildProcessSummary creates the summary response from parsed transactions (deprecated)
 func buildProcessSummary(fileKey, filename string, transactions []models.ParsedTransaction) fiber.Map {
-	return buildProcessSummaryWithCategorization(fileKey, filename, transactions, 0, 0.0)
+	return buildProcessSummaryWithCategorization(fileKey, filename, transactions, 0, 0, 0.0)
 }
 
-// buildProcessSummaryWithCategorization creates the summary response with categorization stats
-func buildProcessSummaryWithCategorization(fileKey, filename string, transactions []models.ParsedTransaction, categorizedCount int, accuracyPercent float64) fiber.Map {
+// buildProcessSummaryWithCategorization creates the summary response with categorization stats.
+// Duplicates are rows already imported by an earlier upload; they refresh the existing
+// transaction instead of being inserted again.
+func buildProcessSummaryWithCategorization(fileKey, filename string, transactions []models.ParsedTransaction, categorizedCount, duplicateCount int, accuracyPercent float64) fiber.Map {
 	totalRows := len(transactions)
 
 	// Calculate date range
@@ -381,38 +1084,44 @@ func buildProcessSummaryWithCategorization(fileKey, filename string, transaction
 	uncategorizedCount := totalRows - categorizedCount
 
 	return fiber.Map{
-		"upload_id":           fileKey, // Use file_key as upload_id for now
-		"total_transactions":  totalRows,
-		"categorized_count":   categorizedCount,
-		"uncategorized_count": uncategorizedCount,
-		"accuracy_percent":    accuracyPercent,
-		"bank_detected":       bank,
-		"date_range":          dateRange,
-		"status":              "success",
+		"upload_id":            fileKey, // Use file_key as upload_id for now
+		"total_transactions":   totalRows,
+		"categorized_count":    categorizedCount,
+		"uncategorized_count":  uncategorizedCount,
+		"duplicates_refreshed": duplicateCount,
+		"accuracy_percent":     accuracyPercent,
+		"bank_detected":        bank,
+		"date_range":           dateRange,
+		"status":               "success",
 	}
 }
 
-// calculateDateRange finds the earliest and latest transaction dates
+// calculateDateRange finds the earliest and latest transaction dates (YYYY-MM-DD).
+// Both are null when there are no transactions.
 func calculateDateRange(transactions []models.ParsedTransaction) fiber.Map {
-	var minDate, maxDate time.Time
+	// An empty file has no range; never report the zero time
+	if len(transactions) == 0 {
+		return fiber.Map{
+			"from": nil,
+			"to":   nil,
+		}
+	}
 
-	if len(transactions) > 0 {
-		minDate = transactions[0].TxnDate
-		maxDate = transactions[0].TxnDate
+	minDate := transactions[0].TxnDate
+	maxDate := transactions[0].TxnDate
 
-		for _, txn := range transactions {
-			if txn.TxnDate.Before(minDate) {
-				minDate = txn.TxnDate
-			}
-			if txn.TxnDate.After(maxDate) {
-				maxDate = txn.TxnDate
-			}
+	for _, txn := range transactions {
+		if txn.TxnDate.Before(minDate) {
+			minDate = txn.TxnDate
+		}
+		if txn.TxnDate.After(maxDate) {
+			maxDate = txn.TxnDate
 		}
 	}
 
 	return fiber.Map{
-		"from": minDate,
-		"to":   maxDate,
+		"from": models.NewDate(minDate),
+		"to":   models.NewDate(maxDate),
 	}
 }
 
@@ -421,11 +1130,14 @@ func detectBankFromFilename(filename string) string {
 	lowerFilename := strings.ToLower(filename)
 
 	bankKeywords := map[string]string{
-		"hdfc":  "HDFC",
-		"icici": "ICICI",
-		"sbi":   "SBI",
-		"axis":  "Axis",
-		"kotak": "Kotak",
+		"hdfc":     "HDFC",
+		"icici":    "ICICI",
+		"sbi":      "SBI",
+		"axis":     "Axis",
+		"kotak":    "Kotak",
+		"yesbank":  "Yes Bank",
+		"yes_bank": "Yes Bank",
+		"idfc":     "IDFC First",
 	}
 
 	for keyword, bankName := range bankKeywords {
@@ -438,6 +1150,27 @@ func detectBankFromFilename(filename string) string {
 	return "UNKNOWN"
 }
 
+// sourceFromFilename maps a file extension to the transaction import source
+// Returns an empty string for unrecognized extensions
+func sourceFromFilename(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		return models.SourceCSV
+	case ".xlsx":
+		return models.SourceXLSX
+	case ".xls":
+		return models.SourceXLS
+	case ".pdf":
+		return models.SourcePDF
+	case ".ofx":
+		return models.SourceOFX
+	case ".qif":
+		return models.SourceQIF
+	default:
+		return ""
+	}
+}
+
 // GetUploadHistory returns the upload history for the authenticated user
 // GET /v1/upload/history?limit=10&offset=0
 func (h *UploadHandler) GetUploadHistory(c fiber.Ctx) error {
@@ -461,20 +1194,9 @@ func (h *UploadHandler) GetUploadHistory(c fiber.Ctx) error {
 	copy(userUUID[:], user.ID.Bytes[:])
 
 	// 3. Parse query parameters
-	limit := int32(10)
+	limit := int32(pageLimit(c, 10, h.maxPageSize))
 	offset := int32(0)
 
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if parsedLimit, err := fmt.Sscanf(limitStr, "%d", &limit); err == nil && parsedLimit == 1 {
-			if limit < 1 {
-				limit = 10
-			}
-			if limit > 100 {
-				limit = 100
-			}
-		}
-	}
-
 	if offsetStr := c.Query("offset"); offsetStr != "" {
 		fmt.Sscanf(offsetStr, "%d", &offset)
 		if offset < 0 {
@@ -506,3 +1228,297 @@ func (h *UploadHandler) GetUploadHistory(c fiber.Ctx) error {
 		"offset":  offset,
 	})
 }
+
+// GetUploadDetail returns the stored result of a single past import
+// GET /v1/uploads/:id
+func (h *UploadHandler) GetUploadDetail(c fiber.Ctx) error {
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 2. Look up user's UUID from clerk_user_id
+	user, err := h.db.GetUserByClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	// 3. Get upload ID from URL
+	uploadUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid upload ID",
+		})
+	}
+
+	var pgUploadID pgtype.UUID
+	pgUploadID.Bytes = uploadUUID
+	pgUploadID.Valid = true
+
+	// 4. Get the upload and verify the user owns it
+	upload, err := h.db.GetUploadHistoryByID(c.Context(), pgUploadID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "upload not found",
+		})
+	}
+	if upload.UserID.Bytes != user.ID.Bytes {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "forbidden - cannot access this upload",
+		})
+	}
+
+	// 5. Get the stored result; uploads that never finished processing have none
+	var summary json.RawMessage
+	warnings := []string{}
+	result, err := h.db.GetUploadResult(c.Context(), pgUploadID)
+	if err == nil {
+		summary = result.Summary
+		warnings = append(warnings, result.Warnings...)
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to fetch upload result",
+			"details": err.Error(),
+		})
+	}
+
+	// 6. Return upload with its result
+	return c.JSON(fiber.Map{
+		"upload":   upload,
+		"summary":  summary,
+		"warnings": warnings,
+	})
+}
+
+// GetUploadIssues returns the problems recorded while a past upload was imported:
+// skipped rows, row errors, reconciliation warnings and rows with date/amount flags
+// GET /v1/uploads/:id/issues
+func (h *UploadHandler) GetUploadIssues(c fiber.Ctx) error {
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 2. Look up user's UUID from clerk_user_id
+	user, err := h.db.GetUserByClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	// 3. Get upload ID from URL
+	uploadUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid upload ID",
+		})
+	}
+
+	var pgUploadID pgtype.UUID
+	pgUploadID.Bytes = uploadUUID
+	pgUploadID.Valid = true
+
+	// 4. Get the upload and verify the user owns it
+	upload, err := h.db.GetUploadHistoryByID(c.Context(), pgUploadID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "upload not found",
+		})
+	}
+	if upload.UserID.Bytes != user.ID.Bytes {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "forbidden - cannot access this upload",
+		})
+	}
+
+	// 5. Get the stored issues; uploads that never finished processing have none
+	issues := models.NewUploadIssues()
+	result, err := h.db.GetUploadResult(c.Context(), pgUploadID)
+	if err == nil {
+		if err := json.Unmarshal(result.Issues, &issues); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "failed to decode upload issues",
+				"details": err.Error(),
+			})
+		}
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to fetch upload result",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"upload_id": uploadUUID,
+		"status":    upload.Status,
+		"issues":    issues,
+	})
+}
+
+// ReconcileRequest holds the figures printed on a bank statement. Every field is
+// optional, but opening and closing balances must be given together.
+type ReconcileRequest struct {
+	OpeningBalance *float64 `json:"opening_balance"`
+	ClosingBalance *float64 `json:"closing_balance"`
+	TotalDebits    *float64 `json:"total_debits"`
+	TotalCredits   *float64 `json:"total_credits"`
+}
+
+// ReconcileCheck compares one statement figure against the imported transactions
+type ReconcileCheck struct {
+	Field      string  `json:"field"`
+	Expected   float64 `json:"expected"`
+	Actual     float64 `json:"actual"`
+	Difference float64 `json:"difference"` // Actual minus expected
+	Matches    bool    `json:"matches"`
+}
+
+// ReconcileUpload handles POST /v1/uploads/:id/reconcile
+// Compares the sum of an upload's imported transactions against the totals stated
+// on the statement, so silently skipped or misparsed rows show up as discrepancies.
+// The closing balance is checked as opening balance plus the imported net flow.
+// Body: opening_balance, closing_balance, total_debits, total_credits
+// Returns: the imported totals and one check per provided figure
+func (h *UploadHandler) ReconcileUpload(c fiber.Ctx) error {
+	if h.reconciler == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "reconciliation is not available",
+		})
+	}
+
+	// 1. Get clerk_user_id from context
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
+
+	// 2. Parse and validate the statement figures
+	var req ReconcileRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+	}
+	if (req.OpeningBalance == nil) != (req.ClosingBalance == nil) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "opening_balance and closing_balance must be provided together",
+		})
+	}
+	if req.OpeningBalance == nil && req.TotalDebits == nil && req.TotalCredits == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "provide opening and closing balances, total_debits or total_credits",
+		})
+	}
+
+	// 3. Look up user's UUID from clerk_user_id
+	user, err := h.db.GetUserByClerkID(c.Context(), clerkUserID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found in database",
+		})
+	}
+
+	// 4. Get upload ID from URL
+	uploadUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid upload ID",
+		})
+	}
+
+	var pgUploadID pgtype.UUID
+	pgUploadID.Bytes = uploadUUID
+	pgUploadID.Valid = true
+
+	// 5. Get the upload and verify the user owns it
+	upload, err := h.db.GetUploadHistoryByID(c.Context(), pgUploadID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "upload not found",
+		})
+	}
+	if upload.UserID.Bytes != user.ID.Bytes {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "forbidden - cannot access this upload",
+		})
+	}
+
+	// 6. Sum the upload's imported transactions
+	transactions, err := h.db.GetTransactionsByUpload(c.Context(), pgUploadID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "failed to fetch upload transactions",
+			"details": err.Error(),
+		})
+	}
+
+	var totalDebits, totalCredits float64
+	for _, txn := range transactions {
+		amount, err := txn.Amount.Float64Value()
+		if err != nil || !amount.Valid {
+			continue
+		}
+		if txn.TxnType == "credit" {
+			totalCredits += math.Abs(amount.Float64)
+		} else {
+			totalDebits += math.Abs(amount.Float64)
+		}
+	}
+	netFlow := totalCredits - totalDebits
+
+	// 7. Compare each provided figure
+	checks := []ReconcileCheck{}
+	check := func(field string, expected, actual float64) {
+		diff, exceeds := h.reconciler.Discrepancy(expected, actual)
+		checks = append(checks, ReconcileCheck{
+			Field:      field,
+			Expected:   expected,
+			Actual:     math.Round(actual*100) / 100,
+			Difference: diff,
+			Matches:    !exceeds,
+		})
+	}
+	if req.TotalDebits != nil {
+		check("total_debits", *req.TotalDebits, totalDebits)
+	}
+	if req.TotalCredits != nil {
+		check("total_credits", *req.TotalCredits, totalCredits)
+	}
+	if req.OpeningBalance != nil {
+		check("closing_balance", *req.ClosingBalance, *req.OpeningBalance+netFlow)
+	}
+
+	discrepancies := 0
+	for _, ch := range checks {
+		if !ch.Matches {
+			discrepancies++
+		}
+	}
+
+	// 8. Return the comparison
+	return c.JSON(fiber.Map{
+		"upload_id":         uploadUUID.String(),
+		"transaction_count": len(transactions),
+		"imported": fiber.Map{
+			"total_debits":  math.Round(totalDebits*100) / 100,
+			"total_credits": math.Round(totalCredits*100) / 100,
+			"net_flow":      math.Round(netFlow*100) / 100,
+		},
+		"checks":        checks,
+		"discrepancies": discrepancies,
+		"reconciled":    discrepancies == 0,
+		"tolerance":     h.reconciler.Tolerance(),
+	})
+}
@@ -2,25 +2,39 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
 	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/ashmitsharp/cashlens-api/internal/services"
 	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 // MockStorageService is a mock implementation of StorageService for testing
 type MockStorageService struct {
-	GenerateUploadKeyFunc    func(userID, filename string) (string, error)
-	GeneratePresignedURLFunc func(key, contentType string, expiryMinutes int) (string, error)
-	DownloadFileFunc         func(key string) (io.ReadCloser, error)
+	GenerateUploadKeyFunc            func(userID, filename string) (string, error)
+	GeneratePresignedURLFunc         func(key, contentType string, expiryMinutes int) (string, error)
+	GeneratePresignedDownloadURLFunc func(key string, expiryMinutes int) (string, error)
+	DownloadFileFunc                 func(key string) (io.ReadCloser, error)
+	ListUserFilesFunc                func(userID string) ([]models.FileInfo, error)
+	DeleteFileFunc                   func(key string) error
 }
 
 func (m *MockStorageService) GenerateUploadKey(userID, filename string) (string, error) {
@@ -37,6 +51,13 @@ func (m *MockStorageService) GeneratePresignedURL(key, contentType string, expir
 	return fmt.Sprintf("https://s3.amazonaws.com/bucket/%s?signature=mock", key), nil
 }
 
+func (m *MockStorageService) GeneratePresignedDownloadURL(key string, expiryMinutes int) (string, error) {
+	if m.GeneratePresignedDownloadURLFunc != nil {
+		return m.GeneratePresignedDownloadURLFunc(key, expiryMinutes)
+	}
+	return fmt.Sprintf("https://s3.amazonaws.com/bucket/%s?signature=mock", key), nil
+}
+
 func (m *MockStorageService) DownloadFile(key string) (io.ReadCloser, error) {
 	if m.DownloadFileFunc != nil {
 		return m.DownloadFileFunc(key)
@@ -44,6 +65,20 @@ func (m *MockStorageService) DownloadFile(key string) (io.ReadCloser, error) {
 	return nil, fmt.Errorf("file not found")
 }
 
+func (m *MockStorageService) ListUserFiles(userID string) ([]models.FileInfo, error) {
+	if m.ListUserFilesFunc != nil {
+		return m.ListUserFilesFunc(userID)
+	}
+	return []models.FileInfo{}, nil
+}
+
+func (m *MockStorageService) DeleteFile(key string) error {
+	if m.DeleteFileFunc != nil {
+		return m.DeleteFileFunc(key)
+	}
+	return nil
+}
+
 // MockParser is a mock implementation of Parser for testing
 type MockParser struct {
 	ParseFileFunc func(file io.Reader, filename string) ([]models.ParsedTransaction, error)
@@ -204,7 +239,6 @@ func TestGetPresignedURL_InvalidContentType(t *testing.T) {
 	invalidTypes := []string{
 		"image/jpeg",
 		"application/json",
-		"text/plain",
 		"video/mp4",
 		"application/zip",
 	}
@@ -249,6 +283,7 @@ func TestGetPresignedURL_ValidContentTypes(t *testing.T) {
 		filename    string
 	}{
 		{"text/csv", "test.csv"},
+		{"text/plain", "test.txt"},
 		{"application/vnd.ms-excel", "test.xls"},
 		{"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "test.xlsx"},
 		{"application/pdf", "test.pdf"},
@@ -580,7 +615,7 @@ func TestProcessUpload_Success_PDF(t *testing.T) {
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	require.NoError(t, err)
 
-	assert.Equal(t, float64(1), result["transactions_parsed"])
+	assert.Equal(t, float64(1), result["total_transactions"])
 }
 
 // TestProcessUpload_MissingFileKey tests error when file_key is missing
@@ -790,6 +825,150 @@ func TestProcessUpload_ParseError(t *testing.T) {
 	assert.Contains(t, result["error"].(string), "parse")
 }
 
+// TestProcessUpload_PDFServiceDown tests that an unreachable PDF service is reported as 502, not a parse error
+func TestProcessUpload_PDFServiceDown(t *testing.T) {
+	pdfService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	pdfService.Close()
+
+	mockStorage := &MockStorageService{
+		DownloadFileFunc: func(key string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader([]byte("%PDF-1.4 mock"))), nil
+		},
+	}
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"CreateUploadHistory": createdUploadHistory,
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(uuid.New())}
+		},
+	}}
+	parser := services.NewParserWithPDFClient(pdfService.URL)
+	parser.SetPDFRetry(1, 0)
+	handler := NewUploadHandlerFull(mockStorage, parser, nil, db.New(fake))
+
+	app := fiber.New()
+	app.Post("/process", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.ProcessUpload(c)
+	})
+
+	bodyBytes, _ := json.Marshal(map[string]string{
+		"file_key": "uploads/user_test123/1699564800-uuid-statement.pdf",
+	})
+	req := httptest.NewRequest("POST", "/process", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, fiber.StatusBadGateway, resp.StatusCode)
+
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, "PDF processing temporarily unavailable, please retry", result["error"])
+}
+
+// TestGetPresignedURL_MaxSize tests that the configured size limit is returned with the URL
+func TestGetPresignedURL_MaxSize(t *testing.T) {
+	mockStorage := &MockStorageService{
+		GenerateUploadKeyFunc: func(userID, filename string) (string, error) {
+			return fmt.Sprintf("uploads/%s/1699564800-uuid-%s", userID, filename), nil
+		},
+		GeneratePresignedURLFunc: func(key, contentType string, expiryMinutes int) (string, error) {
+			return "https://s3.amazonaws.com/bucket/" + key, nil
+		},
+	}
+	handler := NewUploadHandler(mockStorage)
+	handler.SetFileValidator(services.NewFileValidator(5 * 1024 * 1024))
+
+	app := fiber.New()
+	app.Get("/presigned-url", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.GetPresignedURL(c)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/presigned-url?filename=test.csv&content_type=text/csv", nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, float64(5*1024*1024), result["max_size"])
+}
+
+// TestProcessUpload_Oversized tests that files over the size limit are rejected and deleted from storage
+func TestProcessUpload_Oversized(t *testing.T) {
+	fileKey := "uploads/user_test123/1699564800-uuid-statement.csv"
+	content := "Date,Narration,Withdrawal Amt.,Deposit Amt.,Closing Balance\n" +
+		strings.Repeat("01/01/24,UPI/ZOMATO/ORDER,350.00,,10000.00\n", 100)
+
+	var deleted []string
+	mockStorage := &MockStorageService{
+		DownloadFileFunc: func(key string) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(content)), nil
+		},
+		DeleteFileFunc: func(key string) error {
+			deleted = append(deleted, key)
+			return nil
+		},
+	}
+	mockParser := &MockParser{
+		ParseFileFunc: func(file io.Reader, filename string) ([]models.ParsedTransaction, error) {
+			t.Fatal("oversized file must not be parsed")
+			return nil, nil
+		},
+	}
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"CreateUploadHistory": createdUploadHistory,
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(uuid.New())}
+		},
+	}}
+	handler := NewUploadHandlerFull(mockStorage, mockParser, nil, db.New(fake))
+	handler.SetFileValidator(services.NewFileValidator(1024))
+
+	app := fiber.New()
+	app.Post("/process", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.ProcessUpload(c)
+	})
+
+	bodyBytes, _ := json.Marshal(map[string]string{"file_key": fileKey})
+	req := httptest.NewRequest("POST", "/process", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Equal(t, []string{fileKey}, deleted)
+
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, "file too large", result["error"])
+	assert.Equal(t, float64(1024), result["max_size"])
+
+	// Files within the limit are still parsed
+	handler.SetFileValidator(services.NewFileValidator(int64(len(content))))
+	parsed := false
+	mockParser.ParseFileFunc = func(file io.Reader, filename string) ([]models.ParsedTransaction, error) {
+		data, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+		parsed = true
+		return nil, fmt.Errorf("unknown bank format")
+	}
+	req = httptest.NewRequest("POST", "/process", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.True(t, parsed)
+	assert.Len(t, deleted, 1)
+}
+
 // TestProcessUpload_EmptyFile tests error when file has no transactions
 func TestProcessUpload_EmptyFile(t *testing.T) {
 	mockStorage := &MockStorageService{
@@ -831,6 +1010,12 @@ func TestProcessUpload_EmptyFile(t *testing.T) {
 
 	assert.Equal(t, float64(0), result["total_rows"])
 	assert.Equal(t, float64(0), result["transactions_parsed"])
+
+	// No transactions means no date range, not the zero time
+	require.Contains(t, result, "date_range")
+	dateRange := result["date_range"].(map[string]interface{})
+	assert.Nil(t, dateRange["from"])
+	assert.Nil(t, dateRange["to"])
 }
 
 // TestProcessUpload_BankDetection tests bank detection from filename
@@ -967,3 +1152,1536 @@ func TestProcessUpload_DateRangeCalculation(t *testing.T) {
 		})
 	}
 }
+
+// TestCalculateDateRange_SerializesDateOnly tests that the upload summary date range has no time/zone component
+func TestCalculateDateRange_SerializesDateOnly(t *testing.T) {
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+
+	testCases := []struct {
+		name         string
+		transactions []models.ParsedTransaction
+		expected     string
+	}{
+		{
+			name: "Multiple transactions",
+			transactions: []models.ParsedTransaction{
+				{Description: "Txn1", TxnDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+				{Description: "Txn2", TxnDate: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+				{Description: "Txn3", TxnDate: time.Date(2024, 1, 25, 0, 0, 0, 0, time.UTC)},
+			},
+			expected: `{"from":"2024-01-05","to":"2024-01-25"}`,
+		},
+		{
+			name: "Non-UTC dates keep their calendar day",
+			transactions: []models.ParsedTransaction{
+				{Description: "Txn1", TxnDate: time.Date(2024, 3, 1, 0, 30, 0, 0, ist)},
+			},
+			expected: `{"from":"2024-03-01","to":"2024-03-01"}`,
+		},
+		{
+			name:         "No transactions",
+			transactions: []models.ParsedTransaction{},
+			expected:     `{"from":null,"to":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			summary := buildProcessSummary("uploads/user123/test.csv", "test.csv", tc.transactions)
+
+			encoded, err := json.Marshal(summary["date_range"])
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(encoded))
+		})
+	}
+}
+
+// TestGetDownloadURL tests presigned download URL generation with the ownership check
+func TestGetDownloadURL(t *testing.T) {
+	testCases := []struct {
+		name         string
+		query        string
+		clerkUserID  string
+		storageErr   error
+		expectedCode int
+	}{
+		{"Own file", "?file_key=uploads/user_test123/1699564800-uuid-statement.csv", "user_test123", nil, fiber.StatusOK},
+		{"Missing file_key", "", "user_test123", nil, fiber.StatusBadRequest},
+		{"Another user's file", "?file_key=uploads/user_other/1699564800-uuid-statement.csv", "user_test123", nil, fiber.StatusForbidden},
+		{"Unauthenticated", "?file_key=uploads/user_test123/statement.csv", "", nil, fiber.StatusUnauthorized},
+		{"Storage error", "?file_key=uploads/user_test123/statement.csv", "user_test123", fmt.Errorf("s3 unavailable"), fiber.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requestedKey string
+			var requestedExpiry int
+			mockStorage := &MockStorageService{
+				GeneratePresignedDownloadURLFunc: func(key string, expiryMinutes int) (string, error) {
+					requestedKey, requestedExpiry = key, expiryMinutes
+					if tc.storageErr != nil {
+						return "", tc.storageErr
+					}
+					return "https://s3.amazonaws.com/bucket/" + key + "?X-Amz-Signature=mock", nil
+				},
+			}
+			handler := NewUploadHandler(mockStorage)
+
+			app := fiber.New()
+			app.Get("/upload/download-url", func(c fiber.Ctx) error {
+				if tc.clerkUserID != "" {
+					c.Locals("clerk_user_id", tc.clerkUserID)
+				}
+				return handler.GetDownloadURL(c)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/upload/download-url"+tc.query, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+
+			if tc.expectedCode == fiber.StatusOK {
+				var result map[string]interface{}
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+				assert.Equal(t, "uploads/user_test123/1699564800-uuid-statement.csv", requestedKey)
+				assert.Equal(t, PresignedURLExpiryMinutes, requestedExpiry)
+				assert.Contains(t, result["download_url"], requestedKey)
+				assert.Equal(t, requestedKey, result["file_key"])
+				assert.Equal(t, float64(PresignedURLExpirySeconds), result["expires_in"])
+			} else if tc.expectedCode != fiber.StatusInternalServerError {
+				assert.Empty(t, requestedKey, "no URL should be signed")
+			}
+		})
+	}
+}
+
+// TestSourceFromFilename tests that the import source is derived from the file extension
+func TestSourceFromFilename(t *testing.T) {
+	testCases := []struct {
+		name           string
+		filename       string
+		expectedSource string
+	}{
+		{"CSV file", "hdfc_statement.csv", models.SourceCSV},
+		{"XLSX file", "icici_statement.xlsx", models.SourceXLSX},
+		{"Legacy XLS file", "sbi_statement.xls", models.SourceXLS},
+		{"PDF file", "axis_statement.pdf", models.SourcePDF},
+		{"OFX file", "quicken_export.ofx", models.SourceOFX},
+		{"QIF file", "quicken_export.qif", models.SourceQIF},
+		{"Uppercase extension", "KOTAK.CSV", models.SourceCSV},
+		{"Delimited text file", "statement.txt", models.SourceCSV},
+		{"Unsupported extension", "statement.docx", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedSource, sourceFromFilename(tc.filename))
+		})
+	}
+}
+
+// TestRawDataText tests that raw_data is omitted when storage is disabled
+func TestRawDataText(t *testing.T) {
+	row := "15/01/2024,AWS SERVICES,3500.00"
+
+	stored := rawDataText(row, true)
+	assert.True(t, stored.Valid)
+	assert.Equal(t, row, stored.String)
+
+	omitted := rawDataText(row, false)
+	assert.False(t, omitted.Valid)
+	assert.Empty(t, omitted.String)
+
+	assert.False(t, rawDataText("", true).Valid)
+}
+
+// TestAccountBreakdown tests per-account counts for combined statements
+func TestAccountBreakdown(t *testing.T) {
+	transactions := []models.ParsedTransaction{
+		{Description: "AWS SERVICES", Account: "XXXX6789"},
+		{Description: "SALARY CREDIT", Account: "XXXX6789"},
+		{Description: "GST PAYMENT", Account: "XXXX4321"},
+	}
+	assert.Equal(t, map[string]int{"XXXX6789": 2, "XXXX4321": 1}, accountBreakdown(transactions))
+
+	single := []models.ParsedTransaction{{Description: "AWS SERVICES"}}
+	assert.Nil(t, accountBreakdown(single))
+}
+
+func TestBalanceWarnings(t *testing.T) {
+	transactions := []models.ParsedTransaction{
+		{Description: "AWS SERVICES", Amount: -3500, Balance: 450000},
+		{Description: "SWIGGY", Amount: -250, Balance: 494300, Flags: []string{models.FlagReconciliationMismatch}},
+		{Description: "ZOMATO", Amount: 0, Flags: []string{models.FlagZeroAmount}},
+	}
+	assert.Equal(t, []string{
+		"row 2: balance 494300.00 does not match the previous balance plus amount -250.00; a row may be misparsed or missing",
+	}, balanceWarnings(transactions))
+
+	assert.Nil(t, balanceWarnings(transactions[:1]))
+}
+
+// TestProcessUpload_ReportsSkippedRows tests that rows the parser had to skip are
+// listed in the summary warnings
+func TestProcessUpload_ReportsSkippedRows(t *testing.T) {
+	userID := uuid.New()
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"CreateUploadHistory": createdUploadHistory,
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"CreateTransaction": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{transactionRow(newTestTransaction(t, args[2].(string), -3500))}
+		},
+	}}
+
+	csvData := "Date,Narration,Chq./Ref.No.,Value Dt,Withdrawal Amt.,Deposit Amt.\n" +
+		"15/01/2024,AWS SERVICES,,15/01/2024,3500.00,\n" +
+		"16/01/2024,DECLINED POS AMAZON,,16/01/2024,,\n"
+	mockStorage := &MockStorageService{
+		DownloadFileFunc: func(key string) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(csvData)), nil
+		},
+	}
+
+	handler := NewUploadHandlerFull(mockStorage, services.NewParser(), &MockCategorizer{}, db.New(fake))
+	app := fiber.New()
+	app.Post("/process", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.ProcessUpload(c)
+	})
+
+	body := `{"file_key": "uploads/user_test123/1699564800-uuid-statement.csv"}`
+	req := httptest.NewRequest("POST", "/process", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, float64(1), result["total_transactions"])
+	assert.Equal(t, []interface{}{"line 3: skipped row: both debit and credit are zero"}, result["warnings"])
+}
+
+// MockCategorizer categorizes descriptions from a fixed map
+type MockCategorizer struct {
+	Categories map[string]string
+	LoadErr    error // Returned by LoadGlobalRules
+}
+
+func (m *MockCategorizer) Categorize(ctx context.Context, description string, userID uuid.UUID) (string, error) {
+	return m.Categories[description], nil
+}
+
+func (m *MockCategorizer) LoadGlobalRules(ctx context.Context) error { return m.LoadErr }
+
+func (m *MockCategorizer) InvalidateUserCache(userID uuid.UUID) {}
+
+func (m *MockCategorizer) GetStats(ctx context.Context, userID uuid.UUID) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+// TestProcessUpload_ReimportPreservesManualCategory tests that re-importing a statement
+// refreshes matched transactions without overwriting manually reviewed categories
+func TestProcessUpload_ReimportPreservesManualCategory(t *testing.T) {
+	userID := uuid.New()
+	pgUserID := pgtype.UUID{Bytes: userID, Valid: true}
+
+	// In-memory transactions table emulating the queries used by the upload and update flows
+	var stored []db.Transaction
+	find := func(id interface{}) int {
+		for i := range stored {
+			if stored[i].ID == id.(pgtype.UUID) {
+				return i
+			}
+		}
+		return -1
+	}
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"CreateUploadHistory": createdUploadHistory,
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetTransactionsByDateRange": func(args []interface{}) [][]interface{} {
+			rows := [][]interface{}{}
+			for _, txn := range stored {
+				rows = append(rows, transactionRow(txn))
+			}
+			return rows
+		},
+		"CreateTransaction": func(args []interface{}) [][]interface{} {
+			txn := db.Transaction{
+				ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+				UserID:      args[0].(pgtype.UUID),
+				TxnDate:     args[1].(pgtype.Date),
+				Description: args[2].(string),
+				Amount:      args[3].(pgtype.Numeric),
+				TxnType:     args[4].(string),
+				Category:    args[5].(pgtype.Text),
+				IsReviewed:  args[6].(bool),
+				RawData:     args[7].(pgtype.Text),
+				Source:      args[8].(pgtype.Text),
+				Flags:       args[9].([]string),
+				ReferenceNo: args[10].(pgtype.Text),
+			}
+			stored = append(stored, txn)
+			return [][]interface{}{transactionRow(txn)}
+		},
+		"GetTransactionByID": func(args []interface{}) [][]interface{} {
+			if i := find(args[0]); i >= 0 {
+				return [][]interface{}{transactionRow(stored[i])}
+			}
+			return nil
+		},
+		"UpdateTransactionCategory": func(args []interface{}) [][]interface{} {
+			i := find(args[0])
+			stored[i].Category = args[1].(pgtype.Text)
+			stored[i].IsReviewed = args[2].(bool)
+			return [][]interface{}{transactionRow(stored[i])}
+		},
+		"UpsertTransactionPreservingReview": func(args []interface{}) [][]interface{} {
+			i := find(args[0])
+			stored[i].RawData = args[6].(pgtype.Text)
+			stored[i].Flags = args[8].([]string)
+			stored[i].ReferenceNo = args[10].(pgtype.Text)
+			if !stored[i].IsReviewed {
+				category := args[9].(string)
+				stored[i].Category = pgtype.Text{String: category, Valid: category != ""}
+			}
+			return [][]interface{}{transactionRow(stored[i])}
+		},
+	}}
+	queries := db.New(fake)
+
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	parsed := []models.ParsedTransaction{
+		{TxnDate: day, Description: "AMAZON PAY", Amount: -1200.00, TxnType: "debit", RawData: "v1", ReferenceNo: "401234567890"},
+		{TxnDate: day, Description: "UBER TRIP", Amount: -350.00, TxnType: "debit", RawData: "v1"},
+	}
+	mockStorage := &MockStorageService{
+		DownloadFileFunc: func(key string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(nil)), nil
+		},
+	}
+	mockParser := &MockParser{
+		ParseFileFunc: func(file io.Reader, filename string) ([]models.ParsedTransaction, error) {
+			return parsed, nil
+		},
+	}
+	categorizer := &MockCategorizer{Categories: map[string]string{
+		"AMAZON PAY": "Shopping",
+		"UBER TRIP":  "Travel",
+	}}
+
+	uploadHandler := NewUploadHandlerFull(mockStorage, mockParser, categorizer, queries)
+	uploadHandler.SetDuplicateDetector(services.NewDuplicateDetector(0))
+	txnHandler := NewTransactionHandler(queries, nil)
+
+	app := fiber.New()
+	app.Post("/process", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return uploadHandler.ProcessUpload(c)
+	})
+	app.Put("/transactions/:id", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return txnHandler.UpdateTransaction(c)
+	})
+
+	process := func() map[string]interface{} {
+		body := `{"file_key": "uploads/user_test123/1699564800-uuid-statement.csv"}`
+		req := httptest.NewRequest("POST", "/process", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var summary map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&summary))
+		return summary
+	}
+
+	// 1. Initial import
+	summary := process()
+	assert.Equal(t, float64(0), summary["duplicates_refreshed"])
+	require.Len(t, stored, 2)
+	assert.Equal(t, pgUserID, stored[0].UserID)
+	assert.Equal(t, pgtype.Text{String: "401234567890", Valid: true}, stored[0].ReferenceNo)
+	assert.False(t, stored[1].ReferenceNo.Valid)
+
+	// 2. Manually recategorize the Amazon purchase
+	amazonID := uuid.UUID(stored[0].ID.Bytes).String()
+	req := httptest.NewRequest("PUT", "/transactions/"+amazonID, bytes.NewReader([]byte(`{"category": "Office Supplies"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// 3. Re-import an updated statement where the rules now categorize differently
+	categorizer.Categories = map[string]string{
+		"AMAZON PAY": "Shopping",
+		"UBER TRIP":  "Commute",
+	}
+	for i := range parsed {
+		parsed[i].RawData = "v2"
+	}
+	summary = process()
+
+	assert.Equal(t, float64(2), summary["duplicates_refreshed"])
+	require.Len(t, stored, 2, "re-import should not create new transactions")
+
+	assert.Equal(t, "Office Supplies", stored[0].Category.String, "manual category should survive re-import")
+	assert.True(t, stored[0].IsReviewed)
+	assert.Equal(t, "Commute", stored[1].Category.String, "unreviewed category should follow the rules")
+	for _, txn := range stored {
+		assert.Equal(t, "v2", txn.RawData.String)
+	}
+}
+
+// TestProcessUpload_SkipsOverlappingTransactions tests that overlapping statements uploaded
+// one after the other don't insert the shared rows twice
+func TestProcessUpload_SkipsOverlappingTransactions(t *testing.T) {
+	userID := uuid.New()
+
+	// In-memory transactions table emulating the queries used by the upload flow
+	var stored []db.Transaction
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"CreateUploadHistory": createdUploadHistory,
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetTransactionsByDateRange": func(args []interface{}) [][]interface{} {
+			rows := [][]interface{}{}
+			for _, txn := range stored {
+				rows = append(rows, transactionRow(txn))
+			}
+			return rows
+		},
+		"CreateTransaction": func(args []interface{}) [][]interface{} {
+			txn := db.Transaction{
+				ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+				UserID:      args[0].(pgtype.UUID),
+				TxnDate:     args[1].(pgtype.Date),
+				Description: args[2].(string),
+				Amount:      args[3].(pgtype.Numeric),
+				TxnType:     args[4].(string),
+				ReferenceNo: args[10].(pgtype.Text),
+				Tags:        args[12].([]string),
+			}
+			stored = append(stored, txn)
+			return [][]interface{}{transactionRow(txn)}
+		},
+		"UpsertTransactionPreservingReview": func(args []interface{}) [][]interface{} {
+			for _, txn := range stored {
+				if txn.ID == args[0].(pgtype.UUID) {
+					return [][]interface{}{transactionRow(txn)}
+				}
+			}
+			return nil
+		},
+	}}
+	queries := db.New(fake)
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	firstStatement := []models.ParsedTransaction{
+		{TxnDate: day(5), Description: "UPI/SWIGGY/ORDER", Amount: -300.00, ReferenceNo: "401100000001"},
+		{TxnDate: day(10), Description: "RENT PAYMENT", Amount: -25000.00},
+		{TxnDate: day(12), Description: "CAFE COFFEE DAY", Amount: -150.00},
+		{TxnDate: day(12), Description: "CAFE COFFEE DAY", Amount: -150.00}, // Two identical coffees
+		{TxnDate: day(14), Description: "UPI/ZOMATO/ORDER", Amount: -300.00, ReferenceNo: "401100000002"},
+	}
+	secondStatement := []models.ParsedTransaction{
+		{TxnDate: day(10), Description: "rent  payment", Amount: -25000.00}, // Normalized description
+		{TxnDate: day(12), Description: "CAFE COFFEE DAY", Amount: -150.00},
+		{TxnDate: day(12), Description: "CAFE COFFEE DAY", Amount: -150.00},
+		{TxnDate: day(15), Description: "UPI/ZOMATO/ORDER/REF", Amount: -300.00, ReferenceNo: "401100000002"}, // Same reference
+		{TxnDate: day(14), Description: "UPI/ZOMATO/ORDER", Amount: -300.00, ReferenceNo: "401100000003"},     // New reference
+		{TxnDate: day(18), Description: "UBER TRIP", Amount: -200.00},
+	}
+
+	parsed := firstStatement
+	mockStorage := &MockStorageService{
+		DownloadFileFunc: func(key string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(nil)), nil
+		},
+	}
+	mockParser := &MockParser{
+		ParseFileFunc: func(file io.Reader, filename string) ([]models.ParsedTransaction, error) {
+			return parsed, nil
+		},
+	}
+	handler := NewUploadHandlerFull(mockStorage, mockParser, &MockCategorizer{}, queries)
+	handler.SetDuplicateDetector(services.NewDuplicateDetector(0))
+
+	app := fiber.New()
+	app.Post("/process", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.ProcessUpload(c)
+	})
+
+	process := func() map[string]interface{} {
+		body := `{"file_key": "uploads/user_test123/1699564800-uuid-statement.csv"}`
+		req := httptest.NewRequest("POST", "/process", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var summary map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&summary))
+		return summary
+	}
+
+	summary := process()
+	assert.Equal(t, float64(0), summary["duplicates_refreshed"])
+	require.Len(t, stored, 5)
+
+	parsed = secondStatement
+	summary = process()
+	assert.Equal(t, float64(4), summary["duplicates_refreshed"])
+	require.Len(t, stored, 7, "only the new reference and the new trip should be inserted")
+	assert.Equal(t, pgtype.Text{String: "401100000003", Valid: true}, stored[5].ReferenceNo)
+	assert.Equal(t, "UBER TRIP", stored[6].Description)
+}
+
+// uploadHistoryRow returns an upload_history row in SELECT * column order
+func uploadHistoryRow(u db.UploadHistory) []interface{} {
+	return []interface{}{
+		u.ID, u.UserID, u.Filename, u.FileKey, u.FileSizeBytes, u.FileHash, u.BankType,
+		u.Status, u.ErrorMessage, u.ProcessingStartedAt, u.ProcessingCompletedAt,
+		u.TotalRows, u.ParsedRows, u.CategorizedRows, u.DuplicateRows, u.ErrorRows,
+		u.AccuracyPercent, u.ProcessingDurationMs, u.CreatedAt, u.UpdatedAt,
+	}
+}
+
+// createdUploadHistory answers CreateUploadHistory with a new record
+func createdUploadHistory(args []interface{}) [][]interface{} {
+	return [][]interface{}{uploadHistoryRow(db.UploadHistory{
+		ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		UserID:  args[0].(pgtype.UUID),
+		FileKey: args[2].(string),
+		Status:  args[6].(db.UploadStatus),
+	})}
+}
+
+// TestGetUploadHistory tests that past uploads are listed for the requesting user only, paginated
+func TestGetUploadHistory(t *testing.T) {
+	userID, otherUserID := uuid.New(), uuid.New()
+	completedAt := pgtype.Timestamptz{Time: time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC), Valid: true}
+	newUpload := func(owner uuid.UUID, filename string) db.UploadHistory {
+		return db.UploadHistory{
+			ID:                    pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			UserID:                pgtype.UUID{Bytes: owner, Valid: true},
+			Filename:              filename,
+			FileKey:               "uploads/user_test123/1699564800-uuid-" + filename,
+			BankType:              pgtype.Text{String: "HDFC", Valid: true},
+			Status:                db.UploadStatusCompleted,
+			TotalRows:             pgtype.Int4{Int32: 42, Valid: true},
+			ProcessingCompletedAt: completedAt,
+		}
+	}
+	uploads := []db.UploadHistory{
+		newUpload(userID, "jan.csv"),
+		newUpload(otherUserID, "other.csv"),
+		newUpload(userID, "feb.csv"),
+		newUpload(userID, "mar.csv"),
+	}
+
+	// Mirror the SQL: the user's uploads, newest (last) first, then LIMIT/OFFSET
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetUserUploadHistory": func(args []interface{}) [][]interface{} {
+			var rows [][]interface{}
+			for i := len(uploads) - 1; i >= 0; i-- {
+				if uploads[i].UserID == args[0].(pgtype.UUID) {
+					rows = append(rows, uploadHistoryRow(uploads[i]))
+				}
+			}
+			limit, offset := int(args[1].(int32)), int(args[2].(int32))
+			rows = rows[min(offset, len(rows)):]
+			return rows[:min(limit, len(rows))]
+		},
+	}}
+	handler := NewUploadHandlerFull(&MockStorageService{}, &MockParser{}, nil, db.New(fake))
+
+	app := fiber.New()
+	app.Get("/upload/history", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.GetUploadHistory(c)
+	})
+
+	var result struct {
+		Uploads []db.UploadHistory `json:"uploads"`
+		Limit   int32              `json:"limit"`
+		Offset  int32              `json:"offset"`
+	}
+	resp, err := app.Test(httptest.NewRequest("GET", "/upload/history?limit=2&offset=1", nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+	assert.Equal(t, int32(2), result.Limit)
+	assert.Equal(t, int32(1), result.Offset)
+	require.Len(t, result.Uploads, 2)
+	assert.Equal(t, "feb.csv", result.Uploads[0].Filename)
+	assert.Equal(t, "jan.csv", result.Uploads[1].Filename)
+	for _, u := range result.Uploads {
+		assert.Equal(t, userID, uuid.UUID(u.UserID.Bytes), "only the requesting user's uploads")
+		assert.Equal(t, "uploads/user_test123/1699564800-uuid-"+u.Filename, u.FileKey)
+		assert.Equal(t, "HDFC", u.BankType.String)
+		assert.Equal(t, int32(42), u.TotalRows.Int32)
+		assert.Equal(t, db.UploadStatusCompleted, u.Status)
+		assert.True(t, completedAt.Time.Equal(u.ProcessingCompletedAt.Time))
+	}
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		app := fiber.New()
+		app.Get("/upload/history", handler.GetUploadHistory)
+		resp, err := app.Test(httptest.NewRequest("GET", "/upload/history", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+// TestGetUploadDetail tests fetching a past import's stored result, scoped to its owner
+func TestGetUploadDetail(t *testing.T) {
+	userID := uuid.New()
+	ownUpload := db.UploadHistory{
+		ID:            pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		UserID:        pgtype.UUID{Bytes: userID, Valid: true},
+		Filename:      "statement.csv",
+		BankType:      pgtype.Text{String: "HDFC", Valid: true},
+		Status:        db.UploadStatusCompleted,
+		TotalRows:     pgtype.Int4{Int32: 3, Valid: true},
+		DuplicateRows: pgtype.Int4{Int32: 1, Valid: true},
+		ErrorRows:     pgtype.Int4{Int32: 1, Valid: true},
+	}
+	otherUpload := ownUpload
+	otherUpload.ID = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	otherUpload.UserID = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	uploads := []db.UploadHistory{ownUpload, otherUpload}
+
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetUploadHistoryByID": func(args []interface{}) [][]interface{} {
+			for _, u := range uploads {
+				if u.ID == args[0].(pgtype.UUID) {
+					return [][]interface{}{uploadHistoryRow(u)}
+				}
+			}
+			return nil
+		},
+		"GetUploadResult": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{{
+				args[0].(pgtype.UUID),
+				[]byte(`{"total_rows": 3, "duplicates_refreshed": 1}`),
+				[]string{"row 2: failed to save: boom"},
+				pgtype.Timestamptz{},
+				[]byte(`{}`),
+			}}
+		},
+	}}
+	handler := NewUploadHandlerFull(&MockStorageService{}, &MockParser{}, nil, db.New(fake))
+
+	app := fiber.New()
+	app.Get("/uploads/:id", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.GetUploadDetail(c)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		id := uuid.UUID(ownUpload.ID.Bytes).String()
+		resp, err := app.Test(httptest.NewRequest("GET", "/uploads/"+id, nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var result struct {
+			Upload   db.UploadHistory       `json:"upload"`
+			Summary  map[string]interface{} `json:"summary"`
+			Warnings []string               `json:"warnings"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+		assert.Equal(t, "HDFC", result.Upload.BankType.String)
+		assert.Equal(t, db.UploadStatusCompleted, result.Upload.Status)
+		assert.Equal(t, int32(1), result.Upload.DuplicateRows.Int32)
+		assert.Equal(t, float64(3), result.Summary["total_rows"])
+		assert.Equal(t, []string{"row 2: failed to save: boom"}, result.Warnings)
+	})
+
+	t.Run("Not owned", func(t *testing.T) {
+		id := uuid.UUID(otherUpload.ID.Bytes).String()
+		resp, err := app.Test(httptest.NewRequest("GET", "/uploads/"+id, nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/uploads/"+uuid.New().String(), nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
+
+// TestGetUploadIssues tests that the issues recorded during an import are returned to its owner
+func TestGetUploadIssues(t *testing.T) {
+	userID := uuid.New()
+	ownUpload := db.UploadHistory{
+		ID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		UserID:   pgtype.UUID{Bytes: userID, Valid: true},
+		Filename: "statement.csv",
+		Status:   db.UploadStatusCompleted,
+	}
+	otherUpload := ownUpload
+	otherUpload.ID = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	otherUpload.UserID = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	unprocessed := ownUpload
+	unprocessed.ID = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	unprocessed.Status = db.UploadStatusFailed
+	uploads := []db.UploadHistory{ownUpload, otherUpload, unprocessed}
+
+	// Issues as ProcessUpload records them
+	parsed := []models.ParsedTransaction{
+		{TxnDate: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), Description: "SWIGGY", Amount: -450},
+		{TxnDate: time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC), Description: "REFUND", Amount: 0,
+			Flags: []string{models.FlagZeroAmount}},
+		{TxnDate: time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC), Description: "ATM", Amount: -2000, Balance: 5000,
+			Flags: []string{models.FlagReconciliationMismatch}},
+	}
+	recorded := uploadIssues(parsed,
+		[]string{"row 4: invalid date \"31/02/2024\""},
+		[]string{"row 1: failed to save: boom"}, "")
+	issuesJSON, err := json.Marshal(recorded)
+	require.NoError(t, err)
+
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetUploadHistoryByID": func(args []interface{}) [][]interface{} {
+			for _, u := range uploads {
+				if u.ID == args[0].(pgtype.UUID) {
+					return [][]interface{}{uploadHistoryRow(u)}
+				}
+			}
+			return nil
+		},
+		"GetUploadResult": func(args []interface{}) [][]interface{} {
+			if args[0].(pgtype.UUID) != ownUpload.ID {
+				return nil
+			}
+			return [][]interface{}{{
+				args[0].(pgtype.UUID),
+				[]byte(`{}`),
+				[]string{},
+				pgtype.Timestamptz{},
+				issuesJSON,
+			}}
+		},
+	}}
+	handler := NewUploadHandlerFull(&MockStorageService{}, &MockParser{}, nil, db.New(fake))
+
+	app := fiber.New()
+	app.Get("/uploads/:id/issues", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.GetUploadIssues(c)
+	})
+
+	get := func(upload db.UploadHistory) (int, models.UploadIssues) {
+		id := uuid.UUID(upload.ID.Bytes).String()
+		resp, err := app.Test(httptest.NewRequest("GET", "/uploads/"+id+"/issues", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var result struct {
+			Issues models.UploadIssues `json:"issues"`
+		}
+		if resp.StatusCode == fiber.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		}
+		return resp.StatusCode, result.Issues
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		status, issues := get(ownUpload)
+		require.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, []string{`row 4: invalid date "31/02/2024"`}, issues.SkippedRows)
+		assert.Equal(t, []string{"row 1: failed to save: boom"}, issues.RowErrors)
+		require.Len(t, issues.ReconciliationWarnings, 1)
+		assert.True(t, strings.HasPrefix(issues.ReconciliationWarnings[0], "row 3: balance 5000.00"))
+		assert.Equal(t, []models.FlaggedRow{
+			{Row: 2, TxnDate: models.NewDate(parsed[1].TxnDate), Description: "REFUND", Amount: 0, Flags: []string{models.FlagZeroAmount}},
+			{Row: 3, TxnDate: models.NewDate(parsed[2].TxnDate), Description: "ATM", Amount: -2000, Flags: []string{models.FlagReconciliationMismatch}},
+		}, issues.FlaggedRows)
+	})
+
+	t.Run("No stored result", func(t *testing.T) {
+		status, issues := get(unprocessed)
+		require.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, models.NewUploadIssues(), issues)
+	})
+
+	t.Run("Not owned", func(t *testing.T) {
+		status, _ := get(otherUpload)
+		assert.Equal(t, fiber.StatusForbidden, status)
+	})
+}
+
+// MockMatchCategorizer reports fixed rule matches with scores
+type MockMatchCategorizer struct {
+	MockCategorizer
+	Matches map[string]models.CategoryMatch
+}
+
+func (m *MockMatchCategorizer) CategorizeMatch(ctx context.Context, description string, userID uuid.UUID) (models.CategoryMatch, error) {
+	return m.Matches[description], nil
+}
+
+// TestReconcileUpload tests that a statement total the imported rows don't add up to is reported
+func TestReconcileUpload(t *testing.T) {
+	userID := uuid.New()
+	upload := db.UploadHistory{
+		ID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		UserID:   pgtype.UUID{Bytes: userID, Valid: true},
+		Filename: "statement.csv",
+		Status:   db.UploadStatusCompleted,
+	}
+
+	// The statement lists a 500.00 debit the import skipped
+	salary := newTestTransaction(t, "SALARY CREDIT", 50000)
+	salary.TxnType = "credit"
+	rent := newTestTransaction(t, "RENT PAYMENT", -20000)
+	coffee := newTestTransaction(t, "CAFE COFFEE DAY", -250.50)
+
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetUploadHistoryByID": func(args []interface{}) [][]interface{} {
+			if args[0].(pgtype.UUID) != upload.ID {
+				return nil
+			}
+			return [][]interface{}{uploadHistoryRow(upload)}
+		},
+		"GetTransactionsByUpload": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{transactionRow(salary), transactionRow(rent), transactionRow(coffee)}
+		},
+	}}
+	handler := NewUploadHandlerFull(&MockStorageService{}, &MockParser{}, nil, db.New(fake))
+	handler.SetReconciler(services.NewBalanceReconciler(services.DefaultBalanceTolerance))
+
+	app := fiber.New()
+	app.Post("/uploads/:id/reconcile", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.ReconcileUpload(c)
+	})
+
+	reconcile := func(t *testing.T, id, body string) *http.Response {
+		req := httptest.NewRequest("POST", "/uploads/"+id+"/reconcile", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+	id := uuid.UUID(upload.ID.Bytes).String()
+
+	t.Run("Reports discrepancy", func(t *testing.T) {
+		resp := reconcile(t, id, `{
+			"opening_balance": 10000,
+			"closing_balance": 39249.50,
+			"total_debits": 20750.50,
+			"total_credits": 50000
+		}`)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var result struct {
+			TransactionCount int                `json:"transaction_count"`
+			Imported         map[string]float64 `json:"imported"`
+			Checks           []ReconcileCheck   `json:"checks"`
+			Discrepancies    int                `json:"discrepancies"`
+			Reconciled       bool               `json:"reconciled"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+		assert.Equal(t, 3, result.TransactionCount)
+		assert.Equal(t, 20250.50, result.Imported["total_debits"])
+		assert.Equal(t, 50000.0, result.Imported["total_credits"])
+		assert.False(t, result.Reconciled)
+		assert.Equal(t, 2, result.Discrepancies)
+		assert.Equal(t, []ReconcileCheck{
+			{Field: "total_debits", Expected: 20750.50, Actual: 20250.50, Difference: -500, Matches: false},
+			{Field: "total_credits", Expected: 50000, Actual: 50000, Difference: 0, Matches: true},
+			{Field: "closing_balance", Expected: 39249.50, Actual: 39749.50, Difference: 500, Matches: false},
+		}, result.Checks)
+	})
+
+	t.Run("Within tolerance", func(t *testing.T) {
+		resp := reconcile(t, id, `{"total_debits": 20251}`)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var result map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, true, result["reconciled"])
+		assert.Equal(t, float64(0), result["discrepancies"])
+	})
+
+	t.Run("Unpaired balance", func(t *testing.T) {
+		resp := reconcile(t, id, `{"opening_balance": 10000}`)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("No figures", func(t *testing.T) {
+		resp := reconcile(t, id, `{}`)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Unknown upload", func(t *testing.T) {
+		resp := reconcile(t, uuid.New().String(), `{"total_debits": 100}`)
+		defer resp.Body.Close()
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
+
+// TestReconcileUpload_OverlappingReimport tests that rows refreshed by a later upload
+// count towards that upload's totals
+func TestReconcileUpload_OverlappingReimport(t *testing.T) {
+	userID := uuid.New()
+
+	// In-memory uploads and transactions emulating the queries used by both flows
+	var uploads []db.UploadHistory
+	var stored []db.Transaction
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"CreateUploadHistory": func(args []interface{}) [][]interface{} {
+			row := createdUploadHistory(args)
+			uploads = append(uploads, db.UploadHistory{
+				ID:     row[0][0].(pgtype.UUID),
+				UserID: args[0].(pgtype.UUID),
+				Status: db.UploadStatusCompleted,
+			})
+			return row
+		},
+		"GetUploadHistoryByID": func(args []interface{}) [][]interface{} {
+			for _, u := range uploads {
+				if u.ID == args[0].(pgtype.UUID) {
+					return [][]interface{}{uploadHistoryRow(u)}
+				}
+			}
+			return nil
+		},
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetTransactionsByDateRange": func(args []interface{}) [][]interface{} {
+			rows := [][]interface{}{}
+			for _, txn := range stored {
+				rows = append(rows, transactionRow(txn))
+			}
+			return rows
+		},
+		"GetTransactionsByUpload": func(args []interface{}) [][]interface{} {
+			rows := [][]interface{}{}
+			for _, txn := range stored {
+				if txn.UploadID == args[0].(pgtype.UUID) {
+					rows = append(rows, transactionRow(txn))
+				}
+			}
+			return rows
+		},
+		"CreateTransaction": func(args []interface{}) [][]interface{} {
+			txn := db.Transaction{
+				ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+				UserID:      args[0].(pgtype.UUID),
+				TxnDate:     args[1].(pgtype.Date),
+				Description: args[2].(string),
+				Amount:      args[3].(pgtype.Numeric),
+				TxnType:     args[4].(string),
+				ReferenceNo: args[10].(pgtype.Text),
+				UploadID:    args[13].(pgtype.UUID),
+			}
+			stored = append(stored, txn)
+			return [][]interface{}{transactionRow(txn)}
+		},
+		"UpsertTransactionPreservingReview": func(args []interface{}) [][]interface{} {
+			for i, txn := range stored {
+				if txn.ID == args[0].(pgtype.UUID) {
+					if uploadID := args[13].(pgtype.UUID); uploadID.Valid {
+						stored[i].UploadID = uploadID
+					}
+					return [][]interface{}{transactionRow(stored[i])}
+				}
+			}
+			return nil
+		},
+	}}
+	handler := NewUploadHandlerFull(&MockStorageService{
+		DownloadFileFunc: func(key string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(nil)), nil
+		},
+	}, &MockParser{
+		ParseFileFunc: func(file io.Reader, filename string) ([]models.ParsedTransaction, error) {
+			day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+			if strings.HasSuffix(filename, "jan.csv") {
+				return []models.ParsedTransaction{
+					{TxnDate: day(1), Description: "SALARY CREDIT", Amount: 50000.00, TxnType: "credit"},
+					{TxnDate: day(10), Description: "RENT PAYMENT", Amount: -20000.00},
+				}, nil
+			}
+			// The second statement overlaps the first by the rent payment
+			return []models.ParsedTransaction{
+				{TxnDate: day(10), Description: "RENT PAYMENT", Amount: -20000.00},
+				{TxnDate: day(20), Description: "CAFE COFFEE DAY", Amount: -250.50},
+			}, nil
+		},
+	}, &MockCategorizer{}, db.New(fake))
+	handler.SetDuplicateDetector(services.NewDuplicateDetector(0))
+	handler.SetReconciler(services.NewBalanceReconciler(services.DefaultBalanceTolerance))
+
+	app := fiber.New()
+	app.Post("/process", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.ProcessUpload(c)
+	})
+	app.Post("/uploads/:id/reconcile", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.ReconcileUpload(c)
+	})
+
+	for _, name := range []string{"jan.csv", "feb.csv"} {
+		body := `{"file_key": "uploads/user_test123/1699564800-uuid-` + name + `"}`
+		req := httptest.NewRequest("POST", "/process", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+	require.Len(t, uploads, 2)
+	require.Len(t, stored, 3, "the overlapping rent payment should be refreshed, not inserted")
+
+	id := uuid.UUID(uploads[1].ID.Bytes).String()
+	req := httptest.NewRequest("POST", "/uploads/"+id+"/reconcile", bytes.NewBufferString(`{"total_debits": 20250.50}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, float64(2), result["transaction_count"])
+	assert.Equal(t, true, result["reconciled"])
+}
+
+// TestProcessUpload_StoresCategoryScore tests that the winning rule's score is saved with each transaction
+func TestProcessUpload_StoresCategoryScore(t *testing.T) {
+	userID := uuid.New()
+
+	var scores []pgtype.Float8
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"CreateUploadHistory": createdUploadHistory,
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"CreateTransaction": func(args []interface{}) [][]interface{} {
+			scores = append(scores, args[11].(pgtype.Float8))
+			txn := db.Transaction{
+				ID:            pgtype.UUID{Bytes: uuid.New(), Valid: true},
+				UserID:        args[0].(pgtype.UUID),
+				Description:   args[2].(string),
+				Category:      args[5].(pgtype.Text),
+				Flags:         args[9].([]string),
+				CategoryScore: args[11].(pgtype.Float8),
+			}
+			return [][]interface{}{transactionRow(txn)}
+		},
+	}}
+
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	mockStorage := &MockStorageService{
+		DownloadFileFunc: func(key string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(nil)), nil
+		},
+	}
+	mockParser := &MockParser{
+		ParseFileFunc: func(file io.Reader, filename string) ([]models.ParsedTransaction, error) {
+			return []models.ParsedTransaction{
+				{TxnDate: day, Description: "AWS SERVICES", Amount: -3500.00, TxnType: "debit"},
+				{TxnDate: day, Description: "UPI/123456/ZOMATO", Amount: -450.00, TxnType: "debit"},
+				{TxnDate: day, Description: "UNKNOWN MERCHANT", Amount: -100.00, TxnType: "debit"},
+			}, nil
+		},
+	}
+	categorizer := &MockMatchCategorizer{Matches: map[string]models.CategoryMatch{
+		"AWS SERVICES":      {Category: "Cloud & Hosting", RuleType: "global", MatchType: "substring", Score: 0.25},
+		"UPI/123456/ZOMATO": {Category: "Food & Dining", RuleType: "global", MatchType: "regex", Score: 0.8},
+	}}
+
+	handler := NewUploadHandlerFull(mockStorage, mockParser, categorizer, db.New(fake))
+	app := fiber.New()
+	app.Post("/process", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.ProcessUpload(c)
+	})
+
+	body := `{"file_key": "uploads/user_test123/1699564800-uuid-statement.csv"}`
+	req := httptest.NewRequest("POST", "/process", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, []pgtype.Float8{
+		{Float64: 0.25, Valid: true},
+		{Float64: 0.8, Valid: true},
+		{}, // uncategorized rows store NULL
+	}, scores)
+}
+
+// TestProcessUpload_AppliesRuleTags tests that user rules with tags label matching transactions on import
+func TestProcessUpload_AppliesRuleTags(t *testing.T) {
+	userID := uuid.New()
+	userRule := func(keyword, category string, tags ...string) []interface{} {
+		return []interface{}{
+			pgtype.UUID{Bytes: uuid.New(), Valid: true}, pgtype.UUID{Bytes: userID, Valid: true}, keyword, category,
+			pgtype.Int4{Int32: 100, Valid: true}, pgtype.Text{String: "substring", Valid: true}, pgtype.Numeric{},
+			pgtype.Bool{Bool: true, Valid: true}, pgtype.Timestamptz{}, pgtype.Timestamptz{Valid: true},
+			pgtype.Numeric{}, pgtype.Numeric{}, tags, pgtype.Text{},
+		}
+	}
+
+	saved := map[string][]string{}
+	categories := map[string]string{}
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{userRow(userID)}
+		},
+		"GetUserRulesChangedSince": func(args []interface{}) [][]interface{} {
+			return [][]interface{}{
+				userRule("uber", "Travel", "reimbursable"),
+				userRule("client", "", "billable"), // Tag-only
+			}
+		},
+		"CreateUploadHistory": createdUploadHistory,
+		"CreateTransaction": func(args []interface{}) [][]interface{} {
+			description := args[2].(string)
+			saved[description] = args[12].([]string)
+			categories[description] = args[5].(pgtype.Text).String
+			txn := db.Transaction{
+				ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+				Description: description,
+				Flags:       args[9].([]string),
+				Tags:        args[12].([]string),
+			}
+			return [][]interface{}{transactionRow(txn)}
+		},
+	}}
+	queries := db.New(fake)
+
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	mockStorage := &MockStorageService{
+		DownloadFileFunc: func(key string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(nil)), nil
+		},
+	}
+	mockParser := &MockParser{
+		ParseFileFunc: func(file io.Reader, filename string) ([]models.ParsedTransaction, error) {
+			return []models.ParsedTransaction{
+				{TxnDate: day, Description: "UBER TRIP CLIENT VISIT", Amount: -350.00, TxnType: "debit"},
+				{TxnDate: day, Description: "UBER TRIP HOME", Amount: -250.00, TxnType: "debit"},
+				{TxnDate: day, Description: "CLIENT LUNCH", Amount: -1200.00, TxnType: "debit"},
+				{TxnDate: day, Description: "AMAZON PAY", Amount: -500.00, TxnType: "debit"},
+			}, nil
+		},
+	}
+
+	handler := NewUploadHandlerFull(mockStorage, mockParser, services.NewCategorizer(queries), queries)
+	app := fiber.New()
+	app.Post("/process", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", "user_test123")
+		return handler.ProcessUpload(c)
+	})
+
+	body := `{"file_key": "uploads/user_test123/1699564800-uuid-statement.csv"}`
+	req := httptest.NewRequest("POST", "/process", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, map[string][]string{
+		"UBER TRIP CLIENT VISIT": {"billable", "reimbursable"},
+		"UBER TRIP HOME":         {"reimbursable"},
+		"CLIENT LUNCH":           {"billable"},
+		"AMAZON PAY":             {},
+	}, saved)
+	// Tags do not change categorization
+	assert.Equal(t, "Travel", categories["UBER TRIP CLIENT VISIT"])
+	assert.Equal(t, "", categories["CLIENT LUNCH"])
+}
+
+// countingCategorizer counts rule matching calls; amount rules categorize large
+// debits of the same description differently
+type countingCategorizer struct {
+	MockCategorizer
+	amountRules bool
+	calls       int
+}
+
+func (m *countingCategorizer) CategorizeMatchWithAmount(ctx context.Context, description string, amount float64, userID uuid.UUID) (models.CategoryMatch, error) {
+	m.calls++
+	if m.amountRules && amount <= -10000 {
+		return models.CategoryMatch{Category: "Rent"}, nil
+	}
+	return models.CategoryMatch{Category: m.Categories[strings.ToUpper(strings.TrimSpace(description))]}, nil
+}
+
+func (m *countingCategorizer) HasAmountRules(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return m.amountRules, nil
+}
+
+// repeatedMerchantStatement returns n transactions cycling through a few merchants,
+// with varying amounts
+func repeatedMerchantStatement(n int) []models.ParsedTransaction {
+	merchants := []string{"SWIGGY BANGALORE", "Uber Trip", "AWS SERVICES", "ZOMATO LTD", "HOUSING SOCIETY"}
+	transactions := make([]models.ParsedTransaction, n)
+	for i := range transactions {
+		transactions[i] = models.ParsedTransaction{
+			Description: merchants[i%len(merchants)],
+			Amount:      -float64(100 + i%7*5000),
+		}
+	}
+	return transactions
+}
+
+func TestCategoryMemo(t *testing.T) {
+	categories := map[string]string{"SWIGGY BANGALORE": "Food", "UBER TRIP": "Travel", "AWS SERVICES": "Cloud & Hosting"}
+	userID := uuid.New()
+
+	categorizeAll := func(h *UploadHandler, transactions []models.ParsedTransaction) []string {
+		memo := h.newCategoryMemo(context.Background(), userID)
+		var got []string
+		for _, txn := range transactions {
+			match, err := memo.categorize(context.Background(), txn.Description, txn.Amount, userID)
+			require.NoError(t, err)
+			got = append(got, match.Category)
+		}
+		return got
+	}
+
+	transactions := []models.ParsedTransaction{
+		{Description: "SWIGGY BANGALORE", Amount: -450},
+		{Description: "swiggy bangalore ", Amount: -230},
+		{Description: "UBER TRIP", Amount: -300},
+		{Description: "SWIGGY BANGALORE", Amount: -15000},
+		{Description: "UBER TRIP", Amount: -300},
+	}
+
+	t.Run("Repeated descriptions categorized once", func(t *testing.T) {
+		categorizer := &countingCategorizer{MockCategorizer: MockCategorizer{Categories: categories}}
+		h := NewUploadHandlerFull(&MockStorageService{}, &MockParser{}, categorizer, nil)
+
+		got := categorizeAll(h, transactions)
+		assert.Equal(t, []string{"Food", "Food", "Travel", "Food", "Travel"}, got)
+		assert.Equal(t, 2, categorizer.calls)
+	})
+
+	t.Run("Amount rules keep the amount in the key", func(t *testing.T) {
+		categorizer := &countingCategorizer{MockCategorizer: MockCategorizer{Categories: categories}, amountRules: true}
+		h := NewUploadHandlerFull(&MockStorageService{}, &MockParser{}, categorizer, nil)
+
+		got := categorizeAll(h, transactions)
+		assert.Equal(t, []string{"Food", "Food", "Travel", "Rent", "Travel"}, got)
+		assert.Equal(t, 4, categorizer.calls, "only the identical UBER TRIP row is reused")
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		categorizer := &countingCategorizer{MockCategorizer: MockCategorizer{Categories: categories}}
+		h := NewUploadHandlerFull(&MockStorageService{}, &MockParser{}, categorizer, nil)
+		h.SetCategoryMemo(false)
+
+		categorizeAll(h, transactions)
+		assert.Equal(t, len(transactions), categorizer.calls)
+	})
+}
+
+// BenchmarkCategoryMemo categorizes a statement of 1000 rows over 5 merchants with
+// and without the per-upload memo, reporting rule matching calls per upload
+func BenchmarkCategoryMemo(b *testing.B) {
+	transactions := repeatedMerchantStatement(1000)
+	userID := uuid.New()
+
+	for _, tc := range []struct {
+		name    string
+		enabled bool
+	}{{"Unmemoized", false}, {"Memoized", true}} {
+		b.Run(tc.name, func(b *testing.B) {
+			categorizer := &countingCategorizer{MockCategorizer: MockCategorizer{Categories: map[string]string{"SWIGGY BANGALORE": "Food"}}}
+			h := NewUploadHandlerFull(&MockStorageService{}, &MockParser{}, categorizer, nil)
+			h.SetCategoryMemo(tc.enabled)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				memo := h.newCategoryMemo(context.Background(), userID)
+				for _, txn := range transactions {
+					_, _ = memo.categorize(context.Background(), txn.Description, txn.Amount, userID)
+				}
+			}
+			b.ReportMetric(float64(categorizer.calls)/float64(b.N), "matches/op")
+		})
+	}
+}
+
+// TestProcessUpload_UploadRecord tests that each processing attempt is recorded, and that
+// a file that already completed or is still processing is not imported again
+func TestProcessUpload_UploadRecord(t *testing.T) {
+	userID := uuid.New()
+	uploadID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	fileKey := "uploads/user_test123/1699564800-uuid-statement.csv"
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	newFake := func(completed map[string]func(args []interface{}) [][]interface{}) *fakeDBTX {
+		results := map[string]func(args []interface{}) [][]interface{}{
+			"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+				return [][]interface{}{userRow(userID)}
+			},
+			"CreateUploadHistory": func(args []interface{}) [][]interface{} {
+				return [][]interface{}{uploadHistoryRow(db.UploadHistory{
+					ID:      uploadID,
+					UserID:  args[0].(pgtype.UUID),
+					FileKey: args[2].(string),
+					Status:  args[6].(db.UploadStatus),
+				})}
+			},
+			"CreateTransaction": func(args []interface{}) [][]interface{} {
+				return [][]interface{}{transactionRow(newTestTransaction(t, args[2].(string), -3500))}
+			},
+		}
+		for name, result := range completed {
+			results[name] = result
+		}
+		return &fakeDBTX{results: results}
+	}
+	categorizer := &MockCategorizer{}
+	process := func(fake *fakeDBTX, parser *MockParser, downloads *int) (int, map[string]interface{}) {
+		mockStorage := &MockStorageService{
+			DownloadFileFunc: func(key string) (io.ReadCloser, error) {
+				*downloads++
+				return io.NopCloser(bytes.NewReader(nil)), nil
+			},
+		}
+		handler := NewUploadHandlerFull(mockStorage, parser, categorizer, db.New(fake))
+		app := fiber.New()
+		app.Post("/process", func(c fiber.Ctx) error {
+			c.Locals("clerk_user_id", "user_test123")
+			return handler.ProcessUpload(c)
+		})
+
+		body := fmt.Sprintf(`{"file_key": %q}`, fileKey)
+		req := httptest.NewRequest("POST", "/process", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var result map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return resp.StatusCode, result
+	}
+	parser := &MockParser{
+		ParseFileFunc: func(file io.Reader, filename string) ([]models.ParsedTransaction, error) {
+			return []models.ParsedTransaction{
+				{TxnDate: day, Description: "AWS SERVICES", Amount: -3500.00, TxnType: "debit"},
+			}, nil
+		},
+	}
+
+	t.Run("First process records and completes the upload", func(t *testing.T) {
+		var status db.UploadStatus
+		var saved []byte
+		fake := newFake(map[string]func(args []interface{}) [][]interface{}{
+			"CompleteUploadProcessing": func(args []interface{}) [][]interface{} {
+				status = args[1].(db.UploadStatus)
+				return nil
+			},
+			"SaveUploadResult": func(args []interface{}) [][]interface{} {
+				saved = args[1].([]byte)
+				return nil
+			},
+		})
+		downloads := 0
+
+		code, result := process(fake, parser, &downloads)
+		require.Equal(t, fiber.StatusOK, code)
+		assert.Equal(t, 1, downloads)
+		assert.NotContains(t, result, "already_processed")
+		assert.Equal(t, db.UploadStatusCompleted, status)
+		assert.NotEmpty(t, saved)
+
+		// The record exists before the file is parsed and saved
+		assert.Less(t, slices.Index(fake.calls, "CreateUploadHistory"), slices.Index(fake.calls, "CreateTransaction"))
+	})
+
+	t.Run("Completed file returns the stored summary", func(t *testing.T) {
+		fake := newFake(map[string]func(args []interface{}) [][]interface{}{
+			"GetCompletedUploadByFileKey": func(args []interface{}) [][]interface{} {
+				assert.Equal(t, fileKey, args[1])
+				summary := []byte(`{"status": "success", "total_transactions": 1}`)
+				return [][]interface{}{{uploadID, summary}}
+			},
+		})
+		downloads := 0
+
+		code, result := process(fake, parser, &downloads)
+		require.Equal(t, fiber.StatusOK, code)
+		assert.Equal(t, true, result["already_processed"])
+		assert.Equal(t, float64(1), result["total_transactions"])
+		assert.Equal(t, 0, downloads)
+		assert.NotContains(t, fake.calls, "CreateUploadHistory")
+		assert.NotContains(t, fake.calls, "CreateTransaction")
+	})
+
+	t.Run("Parse failure marks the upload failed", func(t *testing.T) {
+		var status db.UploadStatus
+		var reason pgtype.Text
+		fake := newFake(map[string]func(args []interface{}) [][]interface{}{
+			"CompleteUploadProcessing": func(args []interface{}) [][]interface{} {
+				status = args[1].(db.UploadStatus)
+				reason = args[2].(pgtype.Text)
+				return nil
+			},
+		})
+		failing := &MockParser{
+			ParseFileFunc: func(file io.Reader, filename string) ([]models.ParsedTransaction, error) {
+				return nil, fmt.Errorf("unknown bank format")
+			},
+		}
+		downloads := 0
+
+		code, _ := process(fake, failing, &downloads)
+		require.Equal(t, fiber.StatusBadRequest, code)
+		assert.Equal(t, db.UploadStatusFailed, status)
+		assert.Equal(t, pgtype.Text{String: "unknown bank format", Valid: true}, reason)
+		assert.NotContains(t, fake.calls, "SaveUploadResult")
+	})
+
+	t.Run("Rule loading failure marks the upload failed", func(t *testing.T) {
+		var status db.UploadStatus
+		fake := newFake(map[string]func(args []interface{}) [][]interface{}{
+			"CompleteUploadProcessing": func(args []interface{}) [][]interface{} {
+				status = args[1].(db.UploadStatus)
+				return nil
+			},
+		})
+		categorizer.LoadErr = errors.New("connection refused")
+		defer func() { categorizer.LoadErr = nil }()
+		downloads := 0
+
+		code, _ := process(fake, parser, &downloads)
+		require.Equal(t, fiber.StatusInternalServerError, code)
+		assert.Equal(t, db.UploadStatusFailed, status)
+	})
+
+	t.Run("Upload in progress is not imported again", func(t *testing.T) {
+		// The partial unique index on active uploads rejects the second record
+		fake := newFake(nil)
+		fake.errs = map[string]error{
+			"CreateUploadHistory": &pgconn.PgError{Code: "23505", ConstraintName: "idx_upload_history_user_file_key_active"},
+		}
+		downloads := 0
+
+		code, result := process(fake, parser, &downloads)
+		require.Equal(t, fiber.StatusConflict, code)
+		assert.Equal(t, "file is already being processed", result["error"])
+		assert.Equal(t, 0, downloads)
+		assert.NotContains(t, fake.calls, "CreateTransaction")
+	})
+
+	t.Run("Stale processing record is reclaimed", func(t *testing.T) {
+		staleID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+		var failed []pgtype.UUID
+		var fake *fakeDBTX
+		fake = newFake(map[string]func(args []interface{}) [][]interface{}{
+			"GetStuckUploads": func(args []interface{}) [][]interface{} {
+				// The stale record no longer blocks the file once it is failed
+				delete(fake.errs, "CreateUploadHistory")
+				return [][]interface{}{
+					uploadHistoryRow(db.UploadHistory{ID: staleID, UserID: pgtype.UUID{Bytes: userID, Valid: true}, FileKey: fileKey}),
+					uploadHistoryRow(db.UploadHistory{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, UserID: pgtype.UUID{Bytes: userID, Valid: true}, FileKey: "uploads/user_test123/other.csv"}),
+				}
+			},
+			"CompleteUploadProcessing": func(args []interface{}) [][]interface{} {
+				if args[1].(db.UploadStatus) == db.UploadStatusFailed {
+					failed = append(failed, args[0].(pgtype.UUID))
+				}
+				return nil
+			},
+		})
+		fake.errs = map[string]error{
+			"CreateUploadHistory": &pgconn.PgError{Code: "23505", ConstraintName: "idx_upload_history_user_file_key_active"},
+		}
+		downloads := 0
+
+		code, _ := process(fake, parser, &downloads)
+		require.Equal(t, fiber.StatusOK, code)
+		assert.Equal(t, []pgtype.UUID{staleID}, failed)
+		assert.Equal(t, 1, downloads)
+		assert.Contains(t, fake.calls, "CreateTransaction")
+	})
+
+	t.Run("Record failure is not imported", func(t *testing.T) {
+		fake := newFake(nil)
+		fake.errs = map[string]error{"CreateUploadHistory": errors.New("connection refused")}
+		downloads := 0
+
+		code, result := process(fake, parser, &downloads)
+		require.Equal(t, fiber.StatusInternalServerError, code)
+		assert.Equal(t, "failed to record upload", result["error"])
+		assert.Equal(t, 0, downloads)
+		assert.NotContains(t, fake.calls, "CreateTransaction")
+	})
+}
+
+// cancelCheckingDBTX records whether queries ran on a cancelled context
+type cancelCheckingDBTX struct {
+	*fakeDBTX
+	cancelled []string
+}
+
+func (f *cancelCheckingDBTX) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	if ctx.Err() != nil {
+		f.cancelled = append(f.cancelled, queryName(sql))
+	}
+	return f.fakeDBTX.QueryRow(ctx, sql, args...)
+}
+
+// TestFailUpload_CancelledRequest tests that a cancelled request still marks its upload failed
+func TestFailUpload_CancelledRequest(t *testing.T) {
+	fake := &cancelCheckingDBTX{fakeDBTX: &fakeDBTX{}}
+	handler := NewUploadHandlerFull(&MockStorageService{}, &MockParser{}, nil, db.New(fake))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	handler.failUpload(ctx, pgtype.UUID{Bytes: uuid.New(), Valid: true}, "request cancelled")
+
+	assert.Equal(t, []string{"CompleteUploadProcessing"}, fake.calls)
+	assert.Empty(t, fake.cancelled)
+}
@@ -2,19 +2,55 @@ package handlers
 
 import (
 	"context"
-	"time"
+	"errors"
+	"fmt"
 
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// TxBeginner starts database transactions (satisfied by *pgxpool.Pool)
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// UserFileDeleter removes all of a user's stored statement files
+type UserFileDeleter interface {
+	DeleteUserFiles(userID string) (int, error)
+}
+
+// UserStatsInvalidator drops a user's cached statistics
+type UserStatsInvalidator interface {
+	InvalidateUserStats(userID uuid.UUID)
+}
+
 type UsersHandler struct {
-	db *pgxpool.Pool
+	db      *db.Queries
+	txs     TxBeginner
+	storage UserFileDeleter
+	stats   UserStatsInvalidator
+}
+
+func NewUsersHandler(database *db.Queries) *UsersHandler {
+	return &UsersHandler{db: database}
+}
+
+// SetTxBeginner sets the database used to delete a user's data in one transaction
+func (h *UsersHandler) SetTxBeginner(txs TxBeginner) {
+	h.txs = txs
+}
+
+// SetStorageService sets the storage whose files are removed when a user is deleted
+func (h *UsersHandler) SetStorageService(storage UserFileDeleter) {
+	h.storage = storage
 }
 
-func NewUsersHandler(db *pgxpool.Pool) *UsersHandler {
-	return &UsersHandler{db: db}
+// SetStatsService sets the stats cache cleared when a user is deleted
+func (h *UsersHandler) SetStatsService(stats UserStatsInvalidator) {
+	h.stats = stats
 }
 
 type CreateUserRequest struct {
@@ -28,7 +64,13 @@ type UpdateUserRequest struct {
 	FullName string `json:"full_name"`
 }
 
-// CreateUser creates a new user in the database (called by Clerk webhook)
+// fullNameText stores an empty name as NULL
+func fullNameText(fullName string) pgtype.Text {
+	return pgtype.Text{String: fullName, Valid: fullName != ""}
+}
+
+// CreateUser creates a new user in the database (called by Clerk webhook).
+// An existing Clerk user gets its email and name refreshed instead.
 func (h *UsersHandler) CreateUser(c fiber.Ctx) error {
 	var req CreateUserRequest
 	if err := c.Bind().JSON(&req); err != nil {
@@ -44,48 +86,13 @@ func (h *UsersHandler) CreateUser(c fiber.Ctx) error {
 		})
 	}
 
-	// Generate UUID for user
-	userID := uuid.New()
-
-	// Insert user into database
-	query := `
-		INSERT INTO users (id, clerk_user_id, email, full_name, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		ON CONFLICT (clerk_user_id) DO UPDATE
-		SET email = EXCLUDED.email,
-		    full_name = EXCLUDED.full_name,
-		    updated_at = EXCLUDED.updated_at
-		RETURNING id, clerk_user_id, email, full_name, created_at, updated_at
-	`
-
-	now := time.Now()
-	var user struct {
-		ID          uuid.UUID `json:"id"`
-		ClerkUserID string    `json:"clerk_user_id"`
-		Email       string    `json:"email"`
-		FullName    *string   `json:"full_name"`
-		CreatedAt   time.Time `json:"created_at"`
-		UpdatedAt   time.Time `json:"updated_at"`
-	}
-
-	err := h.db.QueryRow(
-		context.Background(),
-		query,
-		userID,
-		req.ClerkUserID,
-		req.Email,
-		req.FullName,
-		now,
-		now,
-	).Scan(
-		&user.ID,
-		&user.ClerkUserID,
-		&user.Email,
-		&user.FullName,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
+	// Insert user into database, generating its UUID
+	user, err := h.db.CreateUser(c.Context(), db.CreateUserParams{
+		ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		ClerkUserID: req.ClerkUserID,
+		Email:       req.Email,
+		FullName:    fullNameText(req.FullName),
+	})
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   "Failed to create user",
@@ -112,40 +119,16 @@ func (h *UsersHandler) UpdateUser(c fiber.Ctx) error {
 		})
 	}
 
-	query := `
-		UPDATE users
-		SET email = $1,
-		    full_name = $2,
-		    updated_at = $3
-		WHERE clerk_user_id = $4
-		RETURNING id, clerk_user_id, email, full_name, created_at, updated_at
-	`
-
-	var user struct {
-		ID          uuid.UUID `json:"id"`
-		ClerkUserID string    `json:"clerk_user_id"`
-		Email       string    `json:"email"`
-		FullName    *string   `json:"full_name"`
-		CreatedAt   time.Time `json:"created_at"`
-		UpdatedAt   time.Time `json:"updated_at"`
-	}
-
-	err := h.db.QueryRow(
-		context.Background(),
-		query,
-		req.Email,
-		req.FullName,
-		time.Now(),
-		clerkUserID,
-	).Scan(
-		&user.ID,
-		&user.ClerkUserID,
-		&user.Email,
-		&user.FullName,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
-
+	user, err := h.db.UpdateUserByClerkID(c.Context(), db.UpdateUserByClerkIDParams{
+		ClerkUserID: clerkUserID,
+		Email:       req.Email,
+		FullName:    fullNameText(req.FullName),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   "Failed to update user",
@@ -158,32 +141,14 @@ func (h *UsersHandler) UpdateUser(c fiber.Ctx) error {
 
 // GetUser retrieves a user by Clerk user ID
 func (h *UsersHandler) GetUser(c fiber.Ctx) error {
-	clerkUserID := c.Locals("clerk_user_id").(string)
-
-	query := `
-		SELECT id, clerk_user_id, email, full_name, created_at, updated_at
-		FROM users
-		WHERE clerk_user_id = $1
-	`
-
-	var user struct {
-		ID          uuid.UUID `json:"id"`
-		ClerkUserID string    `json:"clerk_user_id"`
-		Email       string    `json:"email"`
-		FullName    *string   `json:"full_name"`
-		CreatedAt   time.Time `json:"created_at"`
-		UpdatedAt   time.Time `json:"updated_at"`
-	}
-
-	err := h.db.QueryRow(context.Background(), query, clerkUserID).Scan(
-		&user.ID,
-		&user.ClerkUserID,
-		&user.Email,
-		&user.FullName,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	clerkUserID, ok := c.Locals("clerk_user_id").(string)
+	if !ok || clerkUserID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized - user not authenticated",
+		})
+	}
 
+	user, err := h.db.GetUserByClerkID(c.Context(), clerkUserID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "User not found",
@@ -192,3 +157,109 @@ func (h *UsersHandler) GetUser(c fiber.Ctx) error {
 
 	return c.JSON(user)
 }
+
+// DeleteUserResponse reports what was removed with a user
+type DeleteUserResponse struct {
+	ClerkUserID         string `json:"clerk_user_id"`
+	DeletedTransactions int64  `json:"deleted_transactions"`
+	DeletedRules        int64  `json:"deleted_rules"`
+	DeletedUploads      int64  `json:"deleted_uploads"`
+	DeletedFiles        int    `json:"deleted_files"`
+}
+
+// DeleteUser permanently deletes a user and all of their data (called by Clerk webhook
+// on account closure). Transactions, rules, upload records and the user row are deleted
+// in one transaction; stored files are removed before it commits, so a failure leaves
+// the user in place for the webhook to retry.
+// DELETE /v1/internal/users/:clerk_id
+func (h *UsersHandler) DeleteUser(c fiber.Ctx) error {
+	clerkUserID := c.Params("clerk_id")
+	if clerkUserID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "user id is required",
+		})
+	}
+	if h.txs == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "User deletion is not configured",
+		})
+	}
+
+	// 1. Resolve the Clerk ID to the user's UUID
+	user, err := h.db.GetUserByClerkID(c.Context(), clerkUserID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to get user",
+			"details": err.Error(),
+		})
+	}
+
+	// 2. Delete the user's rows in one transaction
+	tx, err := h.txs.Begin(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to delete user",
+			"details": err.Error(),
+		})
+	}
+	defer tx.Rollback(context.Background())
+
+	resp, err := deleteUserData(c.Context(), h.db.WithTx(tx), user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to delete user",
+			"details": err.Error(),
+		})
+	}
+	resp.ClerkUserID = clerkUserID
+
+	// 3. Delete stored files; upload keys are prefixed with the Clerk ID (uploads/{clerk_id}/)
+	if h.storage != nil {
+		resp.DeletedFiles, err = h.storage.DeleteUserFiles(clerkUserID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Failed to delete user files",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	// 4. Commit
+	if err := tx.Commit(c.Context()); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to delete user",
+			"details": err.Error(),
+		})
+	}
+	if h.stats != nil {
+		h.stats.InvalidateUserStats(uuid.UUID(user.ID.Bytes))
+	}
+
+	return c.JSON(resp)
+}
+
+// deleteUserData deletes a user's transactions, rules, upload records and finally the
+// user row, all scoped by the user's UUID
+func deleteUserData(ctx context.Context, queries *db.Queries, userID pgtype.UUID) (DeleteUserResponse, error) {
+	var resp DeleteUserResponse
+	var err error
+
+	if resp.DeletedTransactions, err = queries.DeleteUserTransactions(ctx, userID); err != nil {
+		return resp, fmt.Errorf("failed to delete transactions: %w", err)
+	}
+	if resp.DeletedRules, err = queries.DeleteAllUserRules(ctx, userID); err != nil {
+		return resp, fmt.Errorf("failed to delete rules: %w", err)
+	}
+	if resp.DeletedUploads, err = queries.DeleteUserUploadHistory(ctx, userID); err != nil {
+		return resp, fmt.Errorf("failed to delete upload history: %w", err)
+	}
+	if _, err = queries.DeleteUser(ctx, userID); err != nil {
+		return resp, fmt.Errorf("failed to delete user: %w", err)
+	}
+	return resp, nil
+}
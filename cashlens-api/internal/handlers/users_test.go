@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUsersTestApp serves the users routes over an in-memory users table keyed by Clerk ID
+func newUsersTestApp(users map[string]db.User) (*fiber.App, *fakeDBTX) {
+	now := pgtype.Timestamptz{Time: time.Date(2024, 11, 4, 10, 0, 0, 0, time.UTC), Valid: true}
+	row := func(user db.User) []interface{} {
+		return []interface{}{user.ID, user.ClerkUserID, user.Email, user.FullName, user.CreatedAt, user.UpdatedAt}
+	}
+
+	fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+		// Mirror the upsert on clerk_user_id
+		"CreateUser": func(args []interface{}) [][]interface{} {
+			clerkUserID := args[1].(string)
+			user, exists := users[clerkUserID]
+			if !exists {
+				user = db.User{ID: args[0].(pgtype.UUID), ClerkUserID: clerkUserID, CreatedAt: now}
+			}
+			user.Email = args[2].(string)
+			user.FullName = args[3].(pgtype.Text)
+			user.UpdatedAt = now
+			users[clerkUserID] = user
+			return [][]interface{}{row(user)}
+		},
+		"UpdateUserByClerkID": func(args []interface{}) [][]interface{} {
+			user, exists := users[args[0].(string)]
+			if !exists {
+				return nil
+			}
+			user.Email = args[1].(string)
+			user.FullName = args[2].(pgtype.Text)
+			users[user.ClerkUserID] = user
+			return [][]interface{}{row(user)}
+		},
+		"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+			user, exists := users[args[0].(string)]
+			if !exists {
+				return nil
+			}
+			return [][]interface{}{row(user)}
+		},
+	}}
+	handler := NewUsersHandler(db.New(fake))
+
+	app := fiber.New()
+	app.Post("/internal/users", handler.CreateUser)
+	app.Put("/internal/users/:id", handler.UpdateUser)
+	app.Get("/user", func(c fiber.Ctx) error {
+		c.Locals("clerk_user_id", c.Get("X-Test-Clerk-ID"))
+		return handler.GetUser(c)
+	})
+	return app, fake
+}
+
+func usersRequest(t *testing.T, app *fiber.App, method, path, body string) (int, map[string]interface{}) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Clerk-ID", "user_clerk_1")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	return resp.StatusCode, result
+}
+
+// TestUsersHandler_CreateUser tests that the webhook creates a user and upserts on a redelivery
+func TestUsersHandler_CreateUser(t *testing.T) {
+	users := map[string]db.User{}
+	app, fake := newUsersTestApp(users)
+
+	status, created := usersRequest(t, app, "POST", "/internal/users",
+		`{"clerk_user_id":"user_clerk_1","email":"asha@example.com","full_name":"Asha Rao"}`)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, "user_clerk_1", created["clerk_user_id"])
+	assert.Equal(t, "asha@example.com", created["email"])
+	assert.Equal(t, "Asha Rao", created["full_name"])
+	assert.NotEmpty(t, created["id"])
+
+	// The same Clerk user again keeps its ID and takes the new email
+	status, upserted := usersRequest(t, app, "POST", "/internal/users",
+		`{"clerk_user_id":"user_clerk_1","email":"asha.rao@example.com"}`)
+	assert.Equal(t, fiber.StatusCreated, status)
+	assert.Equal(t, created["id"], upserted["id"])
+	assert.Equal(t, "asha.rao@example.com", upserted["email"])
+	assert.Nil(t, upserted["full_name"])
+	assert.Len(t, users, 1)
+	assert.Equal(t, []string{"CreateUser", "CreateUser"}, fake.calls)
+
+	status, _ = usersRequest(t, app, "POST", "/internal/users", `{"clerk_user_id":"user_clerk_2"}`)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
+
+// TestUsersHandler_UpdateAndGetUser tests updating a user by Clerk ID and reading it back
+func TestUsersHandler_UpdateAndGetUser(t *testing.T) {
+	users := map[string]db.User{
+		"user_clerk_1": {ClerkUserID: "user_clerk_1", Email: "asha@example.com"},
+	}
+	app, _ := newUsersTestApp(users)
+
+	status, updated := usersRequest(t, app, "PUT", "/internal/users/user_clerk_1",
+		`{"email":"asha@example.org","full_name":"Asha Rao"}`)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "asha@example.org", updated["email"])
+	assert.Equal(t, "Asha Rao", updated["full_name"])
+
+	status, user := usersRequest(t, app, "GET", "/user", "")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "asha@example.org", user["email"])
+
+	status, _ = usersRequest(t, app, "PUT", "/internal/users/user_missing", `{"email":"x@example.com"}`)
+	assert.Equal(t, fiber.StatusNotFound, status)
+}
+
+// fakeTx runs queries against a fakeDBTX and records how the transaction ended
+type fakeTx struct {
+	pgx.Tx
+	db         *fakeDBTX
+	committed  bool
+	rolledBack bool
+}
+
+func (t *fakeTx) Begin(ctx context.Context) (pgx.Tx, error) { return t, nil }
+
+func (t *fakeTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return t.db.Exec(ctx, sql, args...)
+}
+
+func (t *fakeTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return t.db.Query(ctx, sql, args...)
+}
+
+func (t *fakeTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return t.db.QueryRow(ctx, sql, args...)
+}
+
+func (t *fakeTx) Commit(ctx context.Context) error {
+	t.committed = true
+	return nil
+}
+
+func (t *fakeTx) Rollback(ctx context.Context) error {
+	if !t.committed {
+		t.rolledBack = true
+	}
+	return nil
+}
+
+// fakeUserFiles stores object keys per user ID
+type fakeUserFiles struct {
+	files map[string][]string
+	err   error
+}
+
+func (f *fakeUserFiles) DeleteUserFiles(userID string) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	deleted := len(f.files[userID])
+	delete(f.files, userID)
+	return deleted, nil
+}
+
+// TestUsersHandler_DeleteUser tests that deleting a user removes only that user's data
+func TestUsersHandler_DeleteUser(t *testing.T) {
+	asha, ravi := uuid.New(), uuid.New()
+	clerkIDs := map[string]uuid.UUID{"user_asha": asha, "user_ravi": ravi}
+
+	// Rows per table, keyed by owner
+	tables := map[string]map[uuid.UUID]int64{
+		"DeleteUserTransactions":  {asha: 42, ravi: 7},
+		"DeleteAllUserRules":      {asha: 3, ravi: 1},
+		"DeleteUserUploadHistory": {asha: 2, ravi: 1},
+		"DeleteUser":              {asha: 1, ravi: 1},
+	}
+
+	newApp := func(files *fakeUserFiles) (*fiber.App, *fakeTx) {
+		fake := &fakeDBTX{results: map[string]func(args []interface{}) [][]interface{}{
+			"GetUserByClerkID": func(args []interface{}) [][]interface{} {
+				id, ok := clerkIDs[args[0].(string)]
+				if !ok || tables["DeleteUser"][id] == 0 {
+					return nil
+				}
+				return [][]interface{}{userRow(id)}
+			},
+		}}
+		for name, rows := range tables {
+			fake.results[name] = func(args []interface{}) [][]interface{} {
+				owner := uuid.UUID(args[0].(pgtype.UUID).Bytes)
+				deleted := rows[owner]
+				delete(rows, owner)
+				return [][]interface{}{{deleted}}
+			}
+		}
+		tx := &fakeTx{db: fake}
+
+		handler := NewUsersHandler(db.New(fake))
+		handler.SetTxBeginner(tx)
+		handler.SetStorageService(files)
+
+		app := fiber.New()
+		app.Delete("/internal/users/:clerk_id", handler.DeleteUser)
+		return app, tx
+	}
+
+	t.Run("Storage failure rolls back", func(t *testing.T) {
+		files := &fakeUserFiles{err: errors.New("s3 unavailable")}
+		app, tx := newApp(files)
+
+		status, _ := usersRequest(t, app, "DELETE", "/internal/users/user_asha", "")
+		assert.Equal(t, fiber.StatusInternalServerError, status)
+		assert.False(t, tx.committed)
+		assert.True(t, tx.rolledBack)
+
+		// Undo the fake's deletes, as the rollback would
+		tables["DeleteUserTransactions"][asha] = 42
+		tables["DeleteAllUserRules"][asha] = 3
+		tables["DeleteUserUploadHistory"][asha] = 2
+		tables["DeleteUser"][asha] = 1
+	})
+
+	t.Run("Deletes the user's data and files", func(t *testing.T) {
+		files := &fakeUserFiles{files: map[string][]string{
+			"user_asha": {"uploads/user_asha/1-a.csv", "uploads/user_asha/2-b.pdf"},
+			"user_ravi": {"uploads/user_ravi/1-c.csv"},
+		}}
+		app, tx := newApp(files)
+
+		status, result := usersRequest(t, app, "DELETE", "/internal/users/user_asha", "")
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "user_asha", result["clerk_user_id"])
+		assert.Equal(t, 42.0, result["deleted_transactions"])
+		assert.Equal(t, 3.0, result["deleted_rules"])
+		assert.Equal(t, 2.0, result["deleted_uploads"])
+		assert.Equal(t, 2.0, result["deleted_files"])
+		assert.True(t, tx.committed)
+		assert.False(t, tx.rolledBack)
+		assert.Equal(t, []string{
+			"GetUserByClerkID", "DeleteUserTransactions", "DeleteAllUserRules", "DeleteUserUploadHistory", "DeleteUser",
+		}, tx.db.calls)
+
+		// The other user's rows and files are untouched
+		for name, rows := range tables {
+			assert.NotContains(t, rows, asha, name)
+			assert.Contains(t, rows, ravi, name)
+		}
+		assert.NotContains(t, files.files, "user_asha")
+		assert.Len(t, files.files["user_ravi"], 1)
+
+		// A redelivered webhook finds nothing to delete
+		status, _ = usersRequest(t, app, "DELETE", "/internal/users/user_asha", "")
+		assert.Equal(t, fiber.StatusNotFound, status)
+	})
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"os"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// AdminAuth middleware restricts maintenance routes to callers presenting
+// the shared ADMIN_API_TOKEN in the X-Admin-Token header
+func AdminAuth() fiber.Handler {
+	adminToken := os.Getenv("ADMIN_API_TOKEN")
+
+	return func(c fiber.Ctx) error {
+		// Refuse everything when no token is configured
+		if adminToken == "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Admin access is not configured",
+			})
+		}
+
+		token := c.Get("X-Admin-Token")
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid admin token",
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -25,6 +25,9 @@ func CORS() fiber.Handler {
 			"DELETE",
 			"OPTIONS",
 		},
+		ExposeHeaders: []string{
+			RequestIDHeader,
+		},
 		AllowCredentials: true,
 	})
 }
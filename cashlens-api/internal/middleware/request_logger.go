@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is the response header carrying the request ID
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDLocal is the c.Locals key holding the request ID
+const requestIDLocal = "request_id"
+
+// RequestLogger middleware assigns each request a UUID request ID, stored in
+// c.Locals and echoed in the X-Request-ID header, and logs one structured JSON
+// line per request with method, path, status, latency and user_id when present.
+// JSON error bodies ({"error": ...}) written by handlers get a request_id field.
+func RequestLogger() fiber.Handler {
+	return newRequestLogger(os.Stdout)
+}
+
+// newRequestLogger builds RequestLogger writing its log lines to w
+func newRequestLogger(w io.Writer) fiber.Handler {
+	logger := slog.New(slog.NewJSONHandler(w, nil))
+
+	return func(c fiber.Ctx) error {
+		start := time.Now()
+		requestID := uuid.NewString()
+		c.Locals(requestIDLocal, requestID)
+		c.Set(RequestIDHeader, requestID)
+
+		err := c.Next()
+		if err == nil && c.Response().StatusCode() >= fiber.StatusBadRequest {
+			addRequestID(c, requestID)
+		}
+
+		// Errors returned by handlers get their status from the error handler after
+		// this middleware returns, so derive it the same way here
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		attrs := []slog.Attr{
+			slog.String("request_id", requestID),
+			slog.String("method", c.Method()),
+			slog.String("path", c.Path()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+		}
+		// Set by ClerkAuth on protected routes
+		if userID, ok := c.Locals("user_id").(string); ok && userID != "" {
+			attrs = append(attrs, slog.String("user_id", userID))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.LogAttrs(c.Context(), level, "request", attrs...)
+
+		return err
+	}
+}
+
+// RequestID returns the ID RequestLogger assigned to the request, or "" when the
+// middleware is not installed
+func RequestID(c fiber.Ctx) string {
+	requestID, _ := c.Locals(requestIDLocal).(string)
+	return requestID
+}
+
+// ErrorHandler is the app's fiber.Config ErrorHandler for errors returned by handlers.
+// It responds with the same {"error", "request_id"} JSON shape handlers use instead of
+// Fiber's plain-text default.
+func ErrorHandler(c fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		status = fiberErr.Code
+	}
+
+	body := fiber.Map{"error": err.Error()}
+	if requestID := RequestID(c); requestID != "" {
+		body["request_id"] = requestID
+	}
+	return c.Status(status).JSON(body)
+}
+
+// addRequestID adds request_id to a JSON error body that does not have one yet
+func addRequestID(c fiber.Ctx, requestID string) {
+	if !bytes.HasPrefix(c.Response().Header.ContentType(), []byte(fiber.MIMEApplicationJSON)) {
+		return
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(c.Response().Body(), &body); err != nil {
+		return
+	}
+	if _, ok := body["error"]; !ok {
+		return
+	}
+	if _, ok := body["request_id"]; ok {
+		return
+	}
+
+	body["request_id"], _ = json.Marshal(requestID)
+	if encoded, err := json.Marshal(body); err == nil {
+		c.Response().SetBodyRaw(encoded)
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequestLogger tests that the request ID reaches the header, the handler, the log
+// line and error bodies, and that returned errors are logged with their final status
+func TestRequestLogger(t *testing.T) {
+	testCases := []struct {
+		name           string
+		handler        fiber.Handler
+		expectedStatus int
+		expectedError  string // Error in the response body ("" = success)
+	}{
+		{"Success", func(c fiber.Ctx) error {
+			return c.JSON(fiber.Map{"request_id_seen": RequestID(c)})
+		}, fiber.StatusOK, ""},
+		{"Error body", func(c fiber.Ctx) error {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request"})
+		}, fiber.StatusBadRequest, "invalid request"},
+		{"Returned fiber error", func(c fiber.Ctx) error {
+			return fiber.NewError(fiber.StatusNotFound, "transaction not found")
+		}, fiber.StatusNotFound, "transaction not found"},
+		{"Returned error", func(c fiber.Ctx) error {
+			return io.ErrUnexpectedEOF
+		}, fiber.StatusInternalServerError, "unexpected EOF"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			var seen string
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			app.Use(newRequestLogger(&logs))
+			app.Get("/test", func(c fiber.Ctx) error {
+				seen = RequestID(c)
+				return tc.handler(c)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/test", nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+
+			requestID := resp.Header.Get(RequestIDHeader)
+			_, err = uuid.Parse(requestID)
+			require.NoError(t, err)
+			assert.Equal(t, requestID, seen)
+
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			if tc.expectedError != "" {
+				assert.Equal(t, tc.expectedError, body["error"])
+				assert.Equal(t, requestID, body["request_id"])
+			} else {
+				assert.Equal(t, requestID, body["request_id_seen"])
+				assert.NotContains(t, body, "request_id")
+			}
+
+			var line map[string]interface{}
+			require.NoError(t, json.Unmarshal(logs.Bytes(), &line))
+			assert.Equal(t, requestID, line["request_id"])
+			assert.Equal(t, float64(tc.expectedStatus), line["status"])
+			assert.Equal(t, "/test", line["path"])
+		})
+	}
+}
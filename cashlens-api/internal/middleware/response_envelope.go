@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/ashmitsharp/cashlens-api/internal/utils"
+	"github.com/gofiber/fiber/v3"
+)
+
+// ResponseEnvelope middleware wraps successful JSON responses as {"success": true, "data": ...}
+// using utils.SuccessResponse. Error responses and non-JSON bodies pass through unchanged.
+func ResponseEnvelope() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status < fiber.StatusOK || status >= fiber.StatusMultipleChoices {
+			return nil
+		}
+		if !bytes.HasPrefix(c.Response().Header.ContentType(), []byte(fiber.MIMEApplicationJSON)) {
+			return nil
+		}
+
+		body := append([]byte(nil), c.Response().Body()...)
+		if !json.Valid(body) || isEnveloped(body) {
+			return nil
+		}
+
+		return utils.SuccessResponse(c, json.RawMessage(body))
+	}
+}
+
+// isEnveloped reports whether a body already uses the {"success", "data"} shape
+func isEnveloped(body []byte) bool {
+	var envelope struct {
+		Success *bool           `json:"success"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return false
+	}
+	return envelope.Success != nil && envelope.Data != nil
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"os"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// WebhookAuth middleware restricts webhook callbacks to callers presenting
+// the shared WEBHOOK_SECRET in the X-Webhook-Secret header
+func WebhookAuth() fiber.Handler {
+	secret := os.Getenv("WEBHOOK_SECRET")
+
+	return func(c fiber.Ctx) error {
+		// Refuse everything when no secret is configured
+		if secret == "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Webhook access is not configured",
+			})
+		}
+
+		provided := c.Get("X-Webhook-Secret")
+		if provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(secret)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid webhook secret",
+			})
+		}
+
+		return c.Next()
+	}
+}
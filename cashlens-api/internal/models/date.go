@@ -0,0 +1,57 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// DateLayout is the wire format for date-only fields (transaction dates, date ranges)
+const DateLayout = "2006-01-02"
+
+// Date is a calendar date that serializes as YYYY-MM-DD instead of a full RFC3339
+// timestamp, so clients never shift it across time zones. The zero value serializes as null.
+type Date struct {
+	time.Time
+}
+
+// NewDate truncates t to its calendar date
+func NewDate(t time.Time) Date {
+	if t.IsZero() {
+		return Date{}
+	}
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// String formats the date as YYYY-MM-DD, or "" for the zero value
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(DateLayout)
+}
+
+// MarshalJSON implements json.Marshaler
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + d.Format(DateLayout) + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting YYYY-MM-DD or null
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*d = Date{}
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("invalid date %s, expected \"YYYY-MM-DD\"", data)
+	}
+	t, err := time.Parse(DateLayout, string(data[1:len(data)-1]))
+	if err != nil {
+		return fmt.Errorf("invalid date %s, expected \"YYYY-MM-DD\"", data)
+	}
+	*d = Date{t}
+	return nil
+}
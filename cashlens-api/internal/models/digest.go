@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Digest periods
+const (
+	DigestPeriodWeekly  = "weekly"  // The last complete Monday-Sunday week
+	DigestPeriodMonthly = "monthly" // The last complete calendar month
+)
+
+// ValidDigestPeriod reports whether period is a supported digest period
+func ValidDigestPeriod(period string) bool {
+	return period == DigestPeriodWeekly || period == DigestPeriodMonthly
+}
+
+// Digest is a user's financial summary for one period, structured for a mailer
+// to render and send. Amounts are magnitudes in rupees.
+type Digest struct {
+	UserID        uuid.UUID        `json:"user_id"`
+	Email         string           `json:"email"`
+	FullName      string           `json:"full_name,omitempty"`
+	Period        string           `json:"period"`
+	From          Date             `json:"from"`
+	To            Date             `json:"to"`
+	KPIs          DigestKPIs       `json:"kpis"`
+	TopCategories []DigestCategory `json:"top_categories"`
+	Anomalies     []DigestAnomaly  `json:"anomalies"`
+	GeneratedAt   time.Time        `json:"generated_at"`
+}
+
+// DigestKPIs are the headline figures of a digest period
+type DigestKPIs struct {
+	TotalInflow      float64 `json:"total_inflow"`
+	TotalOutflow     float64 `json:"total_outflow"`
+	NetCashFlow      float64 `json:"net_cash_flow"`
+	TransactionCount int64   `json:"transaction_count"`
+}
+
+// DigestCategory is one of the largest spending categories of a digest period
+type DigestCategory struct {
+	Category         string  `json:"category"`
+	Outflow          float64 `json:"outflow"`
+	TransactionCount int64   `json:"transaction_count"`
+	SharePercent     float64 `json:"share_percent"` // Of the period's total outflow
+}
+
+// DigestAnomaly is a category whose spending jumped compared to the previous period
+type DigestAnomaly struct {
+	Category        string  `json:"category"`
+	Outflow         float64 `json:"outflow"`
+	PreviousOutflow float64 `json:"previous_outflow"`
+	Message         string  `json:"message"`
+}
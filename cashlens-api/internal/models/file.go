@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// FileInfo describes a stored statement file
+type FileInfo struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"` // Bytes
+	LastModified time.Time `json:"last_modified"`
+}
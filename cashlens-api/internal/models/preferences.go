@@ -0,0 +1,24 @@
+package models
+
+// Preferences used for users who have not saved their own
+const (
+	DefaultCurrency  = "INR"
+	DefaultTimezone  = "Asia/Kolkata"
+	DefaultWeekStart = "monday"
+)
+
+// UserPreferences are a user's display settings for amounts, dates and weeks
+type UserPreferences struct {
+	Currency  string `json:"currency"`   // ISO 4217 code, e.g. INR
+	Timezone  string `json:"timezone"`   // IANA name, e.g. Asia/Kolkata
+	WeekStart string `json:"week_start"` // Lowercase weekday name, e.g. monday
+}
+
+// DefaultUserPreferences returns the preferences of a user with none saved
+func DefaultUserPreferences() UserPreferences {
+	return UserPreferences{
+		Currency:  DefaultCurrency,
+		Timezone:  DefaultTimezone,
+		WeekStart: DefaultWeekStart,
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+import "github.com/google/uuid"
+
+// Recurring cadences reported by /v1/transactions/recurring
+const (
+	CadenceMonthly   = "monthly"
+	CadenceQuarterly = "quarterly"
+)
+
+// RecurringGroup is a set of transactions with a similar description and amount that
+// repeat on a regular cadence, such as subscriptions and rent
+type RecurringGroup struct {
+	Description      string      `json:"description"`  // Description of the most recent occurrence
+	Counterparty     string      `json:"counterparty"` // Normalized description shared by the group
+	Direction        string      `json:"direction"`    // "debit" or "credit"
+	Cadence          string      `json:"cadence"`
+	Occurrences      int         `json:"occurrences"`
+	Months           int         `json:"months"` // Distinct calendar months with an occurrence
+	AverageAmount    float64     `json:"average_amount"`
+	LastDate         Date        `json:"last_date"`
+	NextExpectedDate Date        `json:"next_expected_date"`
+	TransactionIDs   []uuid.UUID `json:"transaction_ids"`
+}
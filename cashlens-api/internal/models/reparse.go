@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReparseOptions selects which stored transactions a reparse batch covers
+type ReparseOptions struct {
+	Bank      string     // Bank schema used to re-parse raw_data; only uploads detected as this bank are included (required)
+	Headers   []string   // Column layout the raw rows were captured with (required)
+	From      *time.Time // Optional inclusive lower bound on txn_date
+	To        *time.Time // Optional inclusive upper bound on txn_date
+	AfterID   uuid.UUID  // Resume cursor; zero value starts from the beginning
+	BatchSize int
+}
+
+// ReparseResult reports the progress of a single reparse batch
+type ReparseResult struct {
+	Scanned    int    `json:"scanned"`
+	Updated    int    `json:"updated"`
+	Failed     int    `json:"failed"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Done       bool   `json:"done"`
+}
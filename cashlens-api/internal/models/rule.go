@@ -0,0 +1,135 @@
+package models
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MatchTypeAny matches when any of a rule's keywords appears in the description
+	MatchTypeAny = "any"
+	// MatchTypeAll matches only when every one of a rule's keywords appears in the description
+	MatchTypeAll = "all"
+)
+
+// MatchTypes lists every supported rule match type
+var MatchTypes = []string{"exact", "substring", "regex", "fuzzy", MatchTypeAny, MatchTypeAll}
+
+const (
+	// DirectionCredit restricts a rule to credits (positive amounts)
+	DirectionCredit = "credit"
+	// DirectionDebit restricts a rule to debits (negative amounts)
+	DirectionDebit = "debit"
+)
+
+// ValidDirection reports whether direction is a rule direction; empty means either
+func ValidDirection(direction string) bool {
+	return direction == "" || direction == DirectionCredit || direction == DirectionDebit
+}
+
+// KeywordSeparator joins the keywords of a multi-keyword rule in its keyword column
+const KeywordSeparator = "|"
+
+// JoinKeywords stores several keywords in a single rule keyword, dropping blanks
+func JoinKeywords(keywords []string) string {
+	kept := make([]string, 0, len(keywords))
+	for _, k := range keywords {
+		if k = strings.TrimSpace(k); k != "" {
+			kept = append(kept, k)
+		}
+	}
+	return strings.Join(kept, KeywordSeparator)
+}
+
+// SplitKeywords returns the keywords of a multi-keyword rule.
+// Both pipes and commas separate keywords.
+func SplitKeywords(keyword string) []string {
+	fields := strings.FieldsFunc(keyword, func(r rune) bool {
+		return r == '|' || r == ','
+	})
+	keywords := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			keywords = append(keywords, f)
+		}
+	}
+	return keywords
+}
+
+// NormalizeTags trims and lowercases tags, dropping blanks and duplicates.
+// The result is sorted and never nil.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// ProposedRule is a categorization rule evaluated without being saved
+type ProposedRule struct {
+	Keyword             string   `json:"keyword"`
+	Category            string   `json:"category"`
+	Priority            int32    `json:"priority"`
+	MatchType           string   `json:"match_type"` // substring, regex, exact, fuzzy, any, all
+	SimilarityThreshold float64  `json:"similarity_threshold"`
+	MinAmount           *float64 `json:"min_amount,omitempty"` // Optional bounds on the absolute amount
+	MaxAmount           *float64 `json:"max_amount,omitempty"`
+	Direction           string   `json:"direction,omitempty"` // credit or debit; empty matches either
+}
+
+// CategoryChange describes how a transaction's category would change under proposed rules.
+// An empty category means uncategorized.
+type CategoryChange struct {
+	TransactionID  uuid.UUID `json:"transaction_id"`
+	Description    string    `json:"description"`
+	OldCategory    string    `json:"old_category"`
+	NewCategory    string    `json:"new_category"`
+	MatchedKeyword string    `json:"matched_keyword,omitempty"` // What matched for the new category
+}
+
+// RuleTestMatch is a transaction matched by a rule under test
+type RuleTestMatch struct {
+	TransactionID   uuid.UUID `json:"transaction_id"`
+	Description     string    `json:"description"`
+	Amount          float64   `json:"amount"`
+	CurrentCategory string    `json:"current_category"` // Empty when uncategorized
+	Score           float64   `json:"score"`
+	MatchedKeyword  string    `json:"matched_keyword,omitempty"`
+}
+
+// EffectiveRule is a cached rule as the categorizer evaluates it for a user.
+// Rules are listed in precedence order; see Categorizer.EffectiveRules.
+type EffectiveRule struct {
+	Position            int       `json:"position"` // 1-based precedence
+	ID                  uuid.UUID `json:"id"`
+	Source              string    `json:"source"` // user or global
+	Keyword             string    `json:"keyword"`
+	Category            string    `json:"category"`
+	Priority            int32     `json:"priority"`
+	MatchType           string    `json:"match_type"`   // As evaluated; unknown types run as substring
+	MatchWeight         float64   `json:"match_weight"` // Multiplies the match score when priorities tie
+	SimilarityThreshold float64   `json:"similarity_threshold,omitempty"`
+	MinAmount           *float64  `json:"min_amount,omitempty"`
+	MaxAmount           *float64  `json:"max_amount,omitempty"`
+	Direction           string    `json:"direction,omitempty"`
+}
+
+// CategoryMatch describes the rule that categorized a transaction
+type CategoryMatch struct {
+	Category  string    `json:"category"`
+	RuleID    uuid.UUID `json:"rule_id"`
+	RuleType  string    `json:"rule_type"`  // global, user or dictionary
+	MatchType string    `json:"match_type"` // substring, regex, exact, fuzzy, any, all
+	Score     float64   `json:"score"`
+	// Keyword or description text that matched, e.g. "zomato"
+	MatchedKeyword string `json:"matched_keyword,omitempty"`
+}
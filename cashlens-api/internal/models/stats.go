@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// UserStats holds cached transaction statistics for a user
+type UserStats struct {
+	TotalCount         int64     `json:"total_transactions"`
+	CategorizedCount   int64     `json:"categorized_count"`
+	UncategorizedCount int64     `json:"uncategorized_count"`
+	AccuracyPercent    float64   `json:"accuracy_percent"`
+	ComputedAt         time.Time `json:"computed_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "github.com/google/uuid"
+
+// RuleSuggestion proposes a categorization rule derived from the user's transactions.
+// Keyword, Category, MatchType and Direction can be posted to /v1/rules as-is to create the rule.
+type RuleSuggestion struct {
+	Category       string      `json:"category"`
+	Keyword        string      `json:"keyword"`
+	MatchType      string      `json:"match_type"`
+	Direction      string      `json:"direction,omitempty"` // Set when the rule should only match credits or debits
+	Counterparty   string      `json:"counterparty"`
+	Occurrences    int         `json:"occurrences"`
+	AverageAmount  float64     `json:"average_amount"`
+	TransactionIDs []uuid.UUID `json:"transaction_ids"`
+}
@@ -10,17 +10,67 @@ import (
 type Transaction struct {
 	ID          uuid.UUID  `json:"id"`
 	UserID      uuid.UUID  `json:"user_id"`
-	TxnDate     time.Time  `json:"txn_date"`
+	TxnDate     Date       `json:"txn_date"`
 	Description string     `json:"description"`
 	Amount      float64    `json:"amount"` // Negative for debit, positive for credit
 	TxnType     string     `json:"txn_type"` // "credit" or "debit"
 	Category    *string    `json:"category,omitempty"` // Nullable, set after categorization
 	IsReviewed  bool       `json:"is_reviewed"`
 	RawData     *string    `json:"raw_data,omitempty"` // Original CSV row for debugging
+	Source      *string    `json:"source,omitempty"` // How the transaction entered the system (csv, xlsx, pdf, ofx, qif, manual, api)
+	Flags       []string   `json:"flags,omitempty"` // Issues detected during import (bad_date, zero_amount, ...)
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
+// Transaction sources record how a transaction entered the system
+const (
+	SourceCSV    = "csv"
+	SourceXLSX   = "xlsx"
+	SourceXLS    = "xls"
+	SourcePDF    = "pdf"
+	SourceOFX    = "ofx"
+	SourceQIF    = "qif"
+	SourceManual = "manual"
+	SourceAPI    = "api"
+)
+
+// ValidSources lists every accepted transaction source
+var ValidSources = map[string]bool{
+	SourceCSV:    true,
+	SourceXLSX:   true,
+	SourceXLS:    true,
+	SourcePDF:    true,
+	SourceOFX:    true,
+	SourceQIF:    true,
+	SourceManual: true,
+	SourceAPI:    true,
+}
+
+// Import flags mark transactions that need review after import
+const (
+	FlagBadDate                = "bad_date"
+	FlagZeroAmount             = "zero_amount"
+	FlagReconciliationMismatch = "reconciliation_mismatch"
+)
+
+// ValidFlags lists every import flag
+var ValidFlags = map[string]bool{
+	FlagBadDate:                true,
+	FlagZeroAmount:             true,
+	FlagReconciliationMismatch: true,
+}
+
+// Categories assigned by built-in detection rather than user or global rules
+const (
+	CategoryReversal = "Reversal"        // Both sides of a debit reversed by a matching credit
+	CategorySalary   = "Salary"          // Suggested for recurring monthly credits from an employer
+	CategoryCash     = "Cash Withdrawal" // Suggested for ATM and branch cash withdrawals
+	CategoryInterest = "Interest Income" // Suggested for savings and deposit interest credits
+	CategoryDividend = "Dividend Income" // Suggested for dividend payouts
+	CategoryLoanEMI  = "Loan EMI"        // Suggested for fixed monthly loan repayments (configurable)
+)
+
 // ParsedTransaction represents a transaction after CSV parsing but before DB insertion
 type ParsedTransaction struct {
 	TxnDate     time.Time `json:"txn_date"`
@@ -28,16 +78,29 @@ type ParsedTransaction struct {
 	Amount      float64   `json:"amount"` // Negative for debit, positive for credit
 	TxnType     string    `json:"txn_type"` // "credit" or "debit"
 	RawData     string    `json:"raw_data"` // Original CSV row
+	Flags       []string  `json:"flags,omitempty"` // Issues detected during parsing
+	Account     string    `json:"account,omitempty"` // Account number in combined multi-account statements
+	ReferenceNo string    `json:"reference_no,omitempty"` // UPI/NEFT/IMPS/RTGS or cheque reference
+	Balance     float64   `json:"balance,omitempty"` // Running balance after this row, when the statement has one
 }
 
 // BankSchema defines the column structure for each bank's CSV format
 type BankSchema struct {
-	BankName          string
-	DateColumn        string
-	DescriptionColumn string
-	DebitColumn       string  // For banks with separate debit/credit columns
-	CreditColumn      string
-	AmountColumn      string  // For banks with single amount column
-	DrCrColumn        string  // For banks with Dr/Cr indicator
-	HasSeparateAmounts bool   // true if debit/credit are separate columns
+	BankName          string `json:"bank_name" yaml:"bank_name"`
+	DateColumn        string `json:"date_column" yaml:"date_column"`
+	DescriptionColumn string `json:"description_column" yaml:"description_column"`
+	DebitColumn       string `json:"debit_column,omitempty" yaml:"debit_column"`   // For banks with separate debit/credit columns
+	CreditColumn      string `json:"credit_column,omitempty" yaml:"credit_column"`
+	AmountColumn      string `json:"amount_column,omitempty" yaml:"amount_column"` // For banks with single amount column
+	DrCrColumn        string `json:"dr_cr_column,omitempty" yaml:"dr_cr_column"`   // For banks with Dr/Cr indicator
+	ReferenceColumn   string `json:"reference_column,omitempty" yaml:"reference_column"` // Optional cheque/reference number column
+	BalanceColumn     string `json:"balance_column,omitempty" yaml:"balance_column"` // Optional running balance column, used to validate amounts
+	HasSeparateAmounts bool  `json:"has_separate_amounts" yaml:"has_separate_amounts"` // true if debit/credit are separate columns
+	InvertAmountSign   bool  `json:"invert_amount_sign,omitempty" yaml:"invert_amount_sign"` // Single amount column where positive means a debit (credit card charges)
+	SignedAmount       bool  `json:"signed_amount,omitempty" yaml:"signed_amount"` // Single amount column where every amount carries a leading + (credit) or - (debit)
+
+	// Detection: a statement belongs to this bank when every DetectHeaders column is present.
+	// StrictDetectHeaders are also required unless strict detection is turned off.
+	DetectHeaders       []string `json:"detect_headers" yaml:"detect_headers"`
+	StrictDetectHeaders []string `json:"strict_detect_headers,omitempty" yaml:"strict_detect_headers"`
 }
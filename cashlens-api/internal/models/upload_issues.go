@@ -0,0 +1,30 @@
+package models
+
+// UploadIssues are the problems recorded while importing an upload, kept so the
+// import can be diagnosed after the fact
+type UploadIssues struct {
+	SkippedRows            []string     `json:"skipped_rows"`            // Rows the parser could not read
+	RowErrors              []string     `json:"row_errors"`              // Rows that failed to categorize, tag or save
+	ReconciliationWarnings []string     `json:"reconciliation_warnings"` // Running balance mismatches
+	PagesWarning           string       `json:"pages_warning,omitempty"` // PDF processed fewer pages than expected
+	FlaggedRows            []FlaggedRow `json:"flagged_rows"`            // Rows with date or amount flags
+}
+
+// FlaggedRow is a parsed row that carried flags such as bad_date or zero_amount
+type FlaggedRow struct {
+	Row         int      `json:"row"` // 1-based position among the parsed transactions
+	TxnDate     Date     `json:"txn_date"`
+	Description string   `json:"description"`
+	Amount      float64  `json:"amount"`
+	Flags       []string `json:"flags"`
+}
+
+// NewUploadIssues returns UploadIssues with empty lists, so they serialize as [] rather than null
+func NewUploadIssues() UploadIssues {
+	return UploadIssues{
+		SkippedRows:            []string{},
+		RowErrors:              []string{},
+		ReconciliationWarnings: []string{},
+		FlaggedRows:            []FlaggedRow{},
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// RuleCacheChannel is the Postgres NOTIFY channel that NotifyRuleCacheInvalidation publishes on
+const RuleCacheChannel = "cashlens_rule_cache"
+
+// invalidationPublishTimeout bounds the NOTIFY sent after a rule change
+const invalidationPublishTimeout = 2 * time.Second
+
+// NotificationListener is a dedicated connection that can LISTEN for notifications
+// (satisfied by *pgx.Conn)
+type NotificationListener interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+}
+
+// SetBroadcastInvalidations makes InvalidateUserCache also publish the invalidation
+// on RuleCacheChannel, for deployments running several API instances
+func (c *Categorizer) SetBroadcastInvalidations(enabled bool) {
+	c.broadcast = enabled
+}
+
+// publishInvalidation notifies the other instances that a user's rules changed.
+// A failed publish is logged; the other instances catch up when their cache expires.
+func (c *Categorizer) publishInvalidation(userID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), invalidationPublishTimeout)
+	defer cancel()
+	if err := c.db.NotifyRuleCacheInvalidation(ctx, userID.String()); err != nil {
+		log.Printf("Warning: failed to broadcast rule cache invalidation for user %s: %v", userID, err)
+	}
+}
+
+// ListenForInvalidations subscribes conn to RuleCacheChannel and drops the cached
+// rules of each user named in a notification, until ctx is cancelled or the
+// connection fails. Notifications published by this instance arrive too and only
+// cost one extra reload.
+func (c *Categorizer) ListenForInvalidations(ctx context.Context, conn NotificationListener) error {
+	if _, err := conn.Exec(ctx, "LISTEN "+RuleCacheChannel); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", RuleCacheChannel, err)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to receive rule cache notification: %w", err)
+		}
+		c.applyInvalidation(notification.Payload)
+	}
+}
+
+// applyInvalidation drops the cache for the user ID in a notification payload;
+// malformed payloads are ignored
+func (c *Categorizer) applyInvalidation(payload string) {
+	userID, err := uuid.Parse(payload)
+	if err != nil {
+		return
+	}
+	c.dropUserCache(userID)
+}
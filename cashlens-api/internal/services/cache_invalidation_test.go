@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeListener delivers queued notifications and records the statements it executes
+type fakeListener struct {
+	executed      []string
+	notifications chan *pgconn.Notification
+	handled       chan struct{} // Signalled each time the listener asks for the next notification
+}
+
+func (l *fakeListener) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	l.executed = append(l.executed, sql)
+	return pgconn.CommandTag{}, nil
+}
+
+func (l *fakeListener) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	l.handled <- struct{}{}
+	select {
+	case n := <-l.notifications:
+		return n, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fakeNotifyDB records NOTIFY statements sent through the sqlc DBTX interface
+type fakeNotifyDB struct {
+	fakeRulesDB
+	payloads []interface{}
+	err      error
+}
+
+func (f *fakeNotifyDB) Exec(_ context.Context, _ string, args ...interface{}) (pgconn.CommandTag, error) {
+	f.payloads = append(f.payloads, args...)
+	return pgconn.CommandTag{}, f.err
+}
+
+func TestListenForInvalidations_DropsNotifiedUserCache(t *testing.T) {
+	notified := uuid.New()
+	other := uuid.New()
+
+	c := NewCategorizer(nil)
+	c.userRules[notified] = []Rule{{Keyword: "SWIGGY", Category: "Food"}}
+	c.userMarks[notified] = userRuleState{loadedAt: time.Now()}
+	c.userRules[other] = []Rule{{Keyword: "AWS", Category: "Cloud & Hosting"}}
+
+	listener := &fakeListener{
+		notifications: make(chan *pgconn.Notification, 2),
+		handled:       make(chan struct{}),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- c.ListenForInvalidations(ctx, listener) }()
+
+	<-listener.handled
+	listener.notifications <- &pgconn.Notification{Channel: RuleCacheChannel, Payload: "not-a-uuid"}
+	<-listener.handled
+	listener.notifications <- &pgconn.Notification{Channel: RuleCacheChannel, Payload: notified.String()}
+	<-listener.handled // Both notifications have been applied
+
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Equal(t, []string{"LISTEN " + RuleCacheChannel}, listener.executed)
+	assert.NotContains(t, c.userRules, notified)
+	assert.NotContains(t, c.userMarks, notified)
+	assert.Contains(t, c.userRules, other)
+}
+
+func TestInvalidateUserCache_Broadcast(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		fake := &fakeNotifyDB{}
+		c := NewCategorizer(db.New(fake))
+		c.userRules[userID] = []Rule{}
+
+		c.InvalidateUserCache(userID)
+
+		assert.NotContains(t, c.userRules, userID)
+		assert.Empty(t, fake.payloads)
+	})
+
+	t.Run("Publishes the user ID", func(t *testing.T) {
+		fake := &fakeNotifyDB{}
+		c := NewCategorizer(db.New(fake))
+		c.SetBroadcastInvalidations(true)
+		c.userRules[userID] = []Rule{}
+
+		c.InvalidateUserCache(userID)
+
+		assert.NotContains(t, c.userRules, userID)
+		assert.Equal(t, []interface{}{userID.String()}, fake.payloads)
+	})
+
+	t.Run("Publish failure still drops the local cache", func(t *testing.T) {
+		fake := &fakeNotifyDB{err: errors.New("connection refused")}
+		c := NewCategorizer(db.New(fake))
+		c.SetBroadcastInvalidations(true)
+		c.userRules[userID] = []Rule{}
+
+		c.InvalidateUserCache(userID)
+
+		assert.NotContains(t, c.userRules, userID)
+	})
+}
@@ -0,0 +1,17 @@
+package services
+
+import "github.com/ashmitsharp/cashlens-api/internal/models"
+
+// CashWithdrawalPatterns are the built-in regex rules for cash withdrawals.
+// ATM fees ("ATM CHARGE") are deliberately not matched.
+var CashWithdrawalPatterns = []SuggestionPattern{
+	{"ATM withdrawal", `\bATM\b.*\b(WDL|WD|CASH|WITHDRAWAL)\b`, models.CategoryCash},
+	{"Cash withdrawal", `\bCASH (WDL|WITHDRAWAL)\b`, models.CategoryCash},
+	{"Other bank ATM (NWD/ATW)", `^(NWD|ATW)[- ]`, models.CategoryCash},
+}
+
+// NewCashWithdrawalSuggester suggests the built-in cash withdrawal rules that would
+// categorize the user's uncategorized ATM and branch withdrawals
+func NewCashWithdrawalSuggester() *RegexSuggester {
+	return NewRegexSuggester(models.DirectionDebit, CashWithdrawalPatterns)
+}
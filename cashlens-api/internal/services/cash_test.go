@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cashTxn(description string, amount float64, category *string) models.Transaction {
+	return models.Transaction{
+		ID:          uuid.New(),
+		Description: description,
+		Amount:      amount,
+		Category:    category,
+	}
+}
+
+func TestCashWithdrawalSuggester_Suggest(t *testing.T) {
+	cash := models.CategoryCash
+	txns := []models.Transaction{
+		cashTxn("ATM WDL ATM CASH 1234 MG ROAD", -5000, nil),
+		cashTxn("ATM/CASH WDL/17-03-2024/SBIN0001234", -2000, nil),
+		cashTxn("Cash Withdrawal By Self", -10000, nil),
+		cashTxn("NWD-416021XXXXXX1234-S1ANMU12-MUMBAI", -3000, nil),
+		// Already categorized, fees, credits and unrelated debits are skipped
+		cashTxn("ATM WDL ATM CASH 9999", -1000, &cash),
+		cashTxn("ATM CHARGE SERVICE TAX", -23.60, nil),
+		cashTxn("CASH DEPOSIT ATM CASH", 5000, nil),
+		cashTxn("UPI/ZOMATO/ORDER", -350, nil),
+	}
+
+	suggestions := NewCashWithdrawalSuggester().Suggest(txns)
+
+	require.Len(t, suggestions, 3)
+	for i, suggestion := range suggestions {
+		assert.Equal(t, models.CategoryCash, suggestion.Category)
+		assert.Equal(t, "regex", suggestion.MatchType)
+		assert.Equal(t, models.DirectionDebit, suggestion.Direction)
+		assert.Equal(t, CashWithdrawalPatterns[i].Pattern, suggestion.Keyword)
+	}
+	assert.Equal(t, []uuid.UUID{txns[0].ID, txns[1].ID}, suggestions[0].TransactionIDs)
+	assert.InDelta(t, -3500.0, suggestions[0].AverageAmount, 0.01)
+	assert.Equal(t, []uuid.UUID{txns[2].ID}, suggestions[1].TransactionIDs)
+	assert.Equal(t, []uuid.UUID{txns[3].ID}, suggestions[2].TransactionIDs)
+}
+
+func TestCashWithdrawalSuggester_NoWithdrawals(t *testing.T) {
+	txns := []models.Transaction{cashTxn("UPI/ZOMATO/ORDER", -350, nil)}
+
+	assert.Empty(t, NewCashWithdrawalSuggester().Suggest(txns))
+}
+
+// TestCategorizer_CashWithdrawalRules tests the built-in patterns through the categorizer's
+// uppercase regex matching, as user rules created from the suggestions
+func TestCategorizer_CashWithdrawalRules(t *testing.T) {
+	c := NewCategorizer(nil)
+	c.globalRules = []Rule{
+		{Keyword: ".*ATM.*CHARGE", Category: "Banking Fees", Priority: 9, MatchType: "regex", RuleType: "global"},
+	}
+	c.lastLoaded = time.Now()
+	userID := uuid.New()
+	for _, p := range CashWithdrawalPatterns {
+		c.userRules[userID] = append(c.userRules[userID], Rule{
+			Keyword: p.Pattern, Category: models.CategoryCash, Priority: 100, MatchType: "regex", RuleType: "user",
+		})
+	}
+
+	tests := []struct {
+		description string
+		expected    string
+	}{
+		{"ATM WDL ATM CASH 1234 MG ROAD", models.CategoryCash},
+		{"atm wdl atm cash 1234 mg road", models.CategoryCash},
+		{"ATM/CASH WDL/17-03-2024/SBIN0001234", models.CategoryCash},
+		{"  Cash Withdrawal By Self  ", models.CategoryCash},
+		{"CASH WDL CHQ 000123", models.CategoryCash},
+		{"NWD-416021XXXXXX1234-S1ANMU12-MUMBAI", models.CategoryCash},
+		{"ATW-416021XXXXXX1234-HDFC0001234-PUNE", models.CategoryCash},
+		{"ATM CHARGE SERVICE TAX", "Banking Fees"},
+		{"UPI/ZOMATO/ORDER", ""},
+		{"GATMAN TRADERS CASHBACK", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			category, err := c.Categorize(context.Background(), tt.description, userID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, category)
+		})
+	}
+}
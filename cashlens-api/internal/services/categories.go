@@ -0,0 +1,50 @@
+package services
+
+import (
+	"sort"
+	"strings"
+)
+
+// CategoryWhitelist restricts transaction and rule categories to a controlled taxonomy.
+// A nil or empty whitelist allows any category.
+type CategoryWhitelist struct {
+	allowed map[string]bool
+}
+
+// NewCategoryWhitelist builds a whitelist from category names; blank names are ignored
+func NewCategoryWhitelist(categories []string) *CategoryWhitelist {
+	allowed := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		if category = strings.TrimSpace(category); category != "" {
+			allowed[category] = true
+		}
+	}
+	return &CategoryWhitelist{allowed: allowed}
+}
+
+// Enabled reports whether categories are restricted at all
+func (w *CategoryWhitelist) Enabled() bool {
+	return w != nil && len(w.allowed) > 0
+}
+
+// Allows reports whether category belongs to the taxonomy. Matching is exact so
+// stored categories keep the configured spelling.
+func (w *CategoryWhitelist) Allows(category string) bool {
+	if !w.Enabled() {
+		return true
+	}
+	return w.allowed[category]
+}
+
+// Categories returns the allowed categories sorted by name
+func (w *CategoryWhitelist) Categories() []string {
+	if !w.Enabled() {
+		return nil
+	}
+	categories := make([]string, 0, len(w.allowed))
+	for category := range w.allowed {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryWhitelist(t *testing.T) {
+	whitelist := NewCategoryWhitelist([]string{"Travel", " Salary ", "", "Cloud & Hosting"})
+
+	assert.True(t, whitelist.Enabled())
+	assert.Equal(t, []string{"Cloud & Hosting", "Salary", "Travel"}, whitelist.Categories())
+	assert.True(t, whitelist.Allows("Travel"))
+	assert.True(t, whitelist.Allows("Cloud & Hosting"))
+	assert.False(t, whitelist.Allows("Crypto"))
+	assert.False(t, whitelist.Allows("travel"))
+}
+
+func TestCategoryWhitelist_DisabledAllowsAny(t *testing.T) {
+	var unset *CategoryWhitelist
+	empty := NewCategoryWhitelist([]string{" ", ""})
+
+	for _, whitelist := range []*CategoryWhitelist{unset, empty} {
+		assert.False(t, whitelist.Enabled())
+		assert.True(t, whitelist.Allows("Crypto"))
+		assert.Nil(t, whitelist.Categories())
+	}
+}
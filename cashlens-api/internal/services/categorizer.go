@@ -1,9 +1,11 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"regexp"
+	"math"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -11,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/ashmitsharp/cashlens-api/internal/models"
 )
 
 // Rule represents a categorization rule
@@ -19,9 +22,116 @@ type Rule struct {
 	Keyword             string
 	Category            string
 	Priority            int32
-	MatchType           string  // substring, regex, exact, fuzzy
-	SimilarityThreshold float64 // For fuzzy matching (0-1)
-	RuleType            string  // global or user
+	MatchType           string   // substring, regex, exact, fuzzy, any, all
+	SimilarityThreshold float64  // For fuzzy matching (0-1)
+	RuleType            string   // global, user or dictionary
+	MinAmount           *float64 // Optional bounds on the absolute transaction amount
+	MaxAmount           *float64
+	Direction           string   // credit or debit restricts the amount sign; empty matches either
+	Tags                []string // Added to matching transactions on import
+}
+
+// tagOnly reports whether the rule only tags and never categorizes
+func (r Rule) tagOnly() bool {
+	return r.Category == "" && len(r.Tags) > 0
+}
+
+// hasAmountRange reports whether the rule only applies to some amounts
+func (r Rule) hasAmountRange() bool {
+	return r.MinAmount != nil || r.MaxAmount != nil || r.Direction != ""
+}
+
+// matchesAmount reports whether the amount has the rule's direction and its absolute
+// value is within the rule's bounds. A rule with a direction or bounds never matches
+// when the amount is unknown (nil).
+func (r Rule) matchesAmount(amount *float64) bool {
+	if !r.hasAmountRange() {
+		return true
+	}
+	if amount == nil {
+		return false
+	}
+	switch r.Direction {
+	case models.DirectionCredit:
+		if *amount <= 0 {
+			return false
+		}
+	case models.DirectionDebit:
+		if *amount >= 0 {
+			return false
+		}
+	}
+	abs := math.Abs(*amount)
+	if r.MinAmount != nil && abs < *r.MinAmount {
+		return false
+	}
+	if r.MaxAmount != nil && abs > *r.MaxAmount {
+		return false
+	}
+	return true
+}
+
+// numericBound converts an optional NUMERIC column to an optional float
+func numericBound(n pgtype.Numeric) *float64 {
+	if !n.Valid {
+		return nil
+	}
+	f, err := n.Float64Value()
+	if err != nil || !f.Valid {
+		return nil
+	}
+	return &f.Float64
+}
+
+// MatchWeights scale each match type's score when rules tie on priority.
+// Raw scores are exact 1.0, regex 0.8, substring keyword/description length
+// and fuzzy similarity; any/all rules use the substring weight. The defaults
+// (all 1.0) keep the raw scores, so a regex beats a substring rule unless the
+// keyword covers more than 80% of the description. Weights such as
+// exact 4, regex 3, substring 2, fuzzy 1 make precedence strict by type.
+type MatchWeights struct {
+	Exact     float64
+	Regex     float64
+	Substring float64
+	Fuzzy     float64
+}
+
+// DefaultMatchWeights leaves raw match scores unchanged
+var DefaultMatchWeights = MatchWeights{Exact: 1, Regex: 1, Substring: 1, Fuzzy: 1}
+
+// weight returns the factor applied to a match type's score
+func (w MatchWeights) weight(matchType string) float64 {
+	switch matchType {
+	case "exact":
+		return w.Exact
+	case "regex":
+		return w.Regex
+	case "fuzzy":
+		return w.Fuzzy
+	default:
+		return w.Substring
+	}
+}
+
+// DefaultRuleBatchSize is how many rules each database round trip loads
+const DefaultRuleBatchSize = 500
+
+// DefaultRuleFullReloadInterval is how often rule caches are reloaded from scratch
+// instead of incrementally
+const DefaultRuleFullReloadInterval = time.Hour
+
+// ruleWatermark is the newest (updated_at, id) a rule load has seen. The next
+// load fetches only rules updated past it.
+type ruleWatermark struct {
+	updatedAt time.Time
+	id        uuid.UUID
+}
+
+// userRuleState tracks how far a user's cached rules have been loaded
+type userRuleState struct {
+	watermark    ruleWatermark
+	loadedAt     time.Time
+	fullLoadedAt time.Time
 }
 
 // Categorizer handles transaction categorization
@@ -32,6 +142,17 @@ type Categorizer struct {
 	cacheMutex  sync.RWMutex
 	cacheTTL    time.Duration
 	lastLoaded  time.Time
+	regexCache  *RegexCache   // Compiled regex rule patterns, capped with LRU eviction
+	weights     *MatchWeights // Tie-break weights by match type (nil = DefaultMatchWeights)
+	batchSize   int32         // Rules per load query (0 = DefaultRuleBatchSize)
+	globalMark  ruleWatermark
+	globalFull  time.Time     // Last full load of the global rules
+	fullReload  time.Duration // Interval between full loads (0 = DefaultRuleFullReloadInterval)
+	userMarks   map[uuid.UUID]userRuleState
+	broadcast   bool     // Publish user cache invalidations to other instances
+	prefixes    []string // Uppercased channel prefixes trimmed before non-regex matching
+	dictionary  []Rule   // Merchant dictionary fallback for unmatched descriptions (nil = off)
+	hideTokens  bool     // Leave MatchedKeyword out of match results
 }
 
 // NewCategorizer creates a new categorizer instance
@@ -41,10 +162,84 @@ func NewCategorizer(database *db.Queries) *Categorizer {
 		userRules:  make(map[uuid.UUID][]Rule),
 		cacheTTL:   5 * time.Minute, // Cache rules for 5 minutes
 		lastLoaded: time.Time{},
+		regexCache: NewRegexCache(DefaultRegexCacheSize),
+		batchSize:  DefaultRuleBatchSize,
+		userMarks:  make(map[uuid.UUID]userRuleState),
+		prefixes:   DefaultDescriptionPrefixes,
+	}
+}
+
+// SetRuleBatchSize sets how many rules each load query fetches; non-positive sizes use the default
+func (c *Categorizer) SetRuleBatchSize(size int) {
+	if size <= 0 {
+		size = DefaultRuleBatchSize
+	}
+	c.batchSize = int32(size)
+}
+
+// ruleBatchSize returns the configured rules per load query
+func (c *Categorizer) ruleBatchSize() int32 {
+	if c.batchSize <= 0 {
+		return DefaultRuleBatchSize
+	}
+	return c.batchSize
+}
+
+// SetFullReloadInterval sets how often rule caches are reloaded from scratch; non-positive
+// intervals use the default
+func (c *Categorizer) SetFullReloadInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultRuleFullReloadInterval
+	}
+	c.fullReload = interval
+}
+
+// fullReloadDue reports whether a cache last fully loaded at lastFull must be reloaded
+// from scratch
+func (c *Categorizer) fullReloadDue(lastFull time.Time) bool {
+	interval := c.fullReload
+	if interval <= 0 {
+		interval = DefaultRuleFullReloadInterval
 	}
+	return time.Since(lastFull) >= interval
+}
+
+// SetReportMatchedKeyword controls whether match results include the keyword or
+// text that matched (on by default)
+func (c *Categorizer) SetReportMatchedKeyword(enabled bool) {
+	c.hideTokens = !enabled
+}
+
+// SetRegexCacheSize replaces the compiled-regex cache with one holding at most size patterns
+func (c *Categorizer) SetRegexCacheSize(size int) {
+	c.regexCache = NewRegexCache(size)
 }
 
-// LoadGlobalRules loads all global rules from database into memory
+// SetMatchWeights sets the per-match-type weights used to break priority ties.
+// Non-positive weights fall back to the default of 1.
+func (c *Categorizer) SetMatchWeights(weights MatchWeights) {
+	for _, w := range []*float64{&weights.Exact, &weights.Regex, &weights.Substring, &weights.Fuzzy} {
+		if *w <= 0 {
+			*w = 1
+		}
+	}
+	c.weights = &weights
+}
+
+// matchWeights returns the configured tie-break weights
+func (c *Categorizer) matchWeights() MatchWeights {
+	if c.weights == nil {
+		return DefaultMatchWeights
+	}
+	return *c.weights
+}
+
+// LoadGlobalRules loads global rules from database into memory. After the first
+// load only rules updated since the previous load are fetched, in batches, and
+// merged into the cache; deactivated rules are dropped. updated_at is the writing
+// transaction's start time, so a rule committed late can fall behind the watermark,
+// and hard deletes are never seen incrementally. The cache is therefore reloaded
+// from scratch every full reload interval.
 func (c *Categorizer) LoadGlobalRules(ctx context.Context) error {
 	c.cacheMutex.Lock()
 	defer c.cacheMutex.Unlock()
@@ -54,36 +249,69 @@ func (c *Categorizer) LoadGlobalRules(ctx context.Context) error {
 		return nil
 	}
 
-	dbRules, err := c.db.GetAllGlobalRules(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to load global rules: %w", err)
-	}
-
-	c.globalRules = make([]Rule, 0, len(dbRules))
-	for _, r := range dbRules {
-		// Convert pgtype.UUID to uuid.UUID
-		var id uuid.UUID
-		copy(id[:], r.ID.Bytes[:])
-
-		// Convert pgtype.Numeric to float64
-		similarity, _ := r.SimilarityThreshold.Float64Value()
-
-		c.globalRules = append(c.globalRules, Rule{
-			ID:                  id,
-			Keyword:             r.Keyword,
-			Category:            r.Category,
-			Priority:            r.Priority.Int32,
-			MatchType:           r.MatchType.String,
-			SimilarityThreshold: similarity.Float64,
-			RuleType:            "global",
+	full := c.fullReloadDue(c.globalFull)
+	changed := make(map[uuid.UUID]*Rule)
+	mark := c.globalMark
+	if full {
+		mark = ruleWatermark{}
+	}
+	batchSize := c.ruleBatchSize()
+	for {
+		dbRules, err := c.db.GetGlobalRulesChangedSince(ctx, db.GetGlobalRulesChangedSinceParams{
+			UpdatedAt: pgtype.Timestamptz{Time: mark.updatedAt, Valid: true},
+			ID:        pgtype.UUID{Bytes: mark.id, Valid: true},
+			Limit:     batchSize,
 		})
+		if err != nil {
+			return fmt.Errorf("failed to load global rules: %w", err)
+		}
+
+		for _, r := range dbRules {
+			// Convert pgtype.UUID to uuid.UUID
+			var id uuid.UUID
+			copy(id[:], r.ID.Bytes[:])
+
+			// Convert pgtype.Numeric to float64
+			similarity, _ := r.SimilarityThreshold.Float64Value()
+
+			changed[id] = activeRule(r.IsActive, Rule{
+				ID:                  id,
+				Keyword:             r.Keyword,
+				Category:            r.Category,
+				Priority:            r.Priority.Int32,
+				MatchType:           r.MatchType.String,
+				SimilarityThreshold: similarity.Float64,
+				RuleType:            "global",
+			})
+			mark = ruleWatermark{updatedAt: r.UpdatedAt.Time, id: id}
+		}
+
+		if len(dbRules) < int(batchSize) {
+			break
+		}
 	}
 
+	if full {
+		c.globalRules = mergeRules(nil, changed)
+		c.globalFull = time.Now()
+	} else {
+		c.globalRules = mergeRules(c.globalRules, changed)
+	}
+	c.globalMark = mark
 	c.lastLoaded = time.Now()
 	return nil
 }
 
-// LoadUserRules loads user-specific rules and caches them
+// GlobalRuleCount returns the number of global rules currently cached
+func (c *Categorizer) GlobalRuleCount() int {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+	return len(c.globalRules)
+}
+
+// LoadUserRules loads user-specific rules and caches them. A cached user is
+// refreshed incrementally like LoadGlobalRules; after InvalidateUserCache (e.g.
+// a rule was deleted) the next load starts over.
 func (c *Categorizer) LoadUserRules(ctx context.Context, userID uuid.UUID) error {
 	c.cacheMutex.Lock()
 	defer c.cacheMutex.Unlock()
@@ -93,58 +321,268 @@ func (c *Categorizer) LoadUserRules(ctx context.Context, userID uuid.UUID) error
 	pgUserID.Bytes = userID
 	pgUserID.Valid = true
 
-	dbRules, err := c.db.GetUserRules(ctx, pgUserID)
-	if err != nil {
-		return fmt.Errorf("failed to load user rules: %w", err)
-	}
-
-	rules := make([]Rule, 0, len(dbRules))
-	for _, r := range dbRules {
-		// Convert pgtype.UUID to uuid.UUID
-		var id uuid.UUID
-		copy(id[:], r.ID.Bytes[:])
-
-		// Convert pgtype.Numeric to float64
-		similarity, _ := r.SimilarityThreshold.Float64Value()
-
-		rules = append(rules, Rule{
-			ID:                  id,
-			Keyword:             r.Keyword,
-			Category:            r.Category,
-			Priority:            r.Priority.Int32,
-			MatchType:           r.MatchType.String,
-			SimilarityThreshold: similarity.Float64,
-			RuleType:            "user",
+	// Untracked users (never loaded or invalidated) and users due a full reload load everything
+	state, tracked := c.userMarks[userID]
+	cached := c.userRules[userID]
+	full := !tracked || c.fullReloadDue(state.fullLoadedAt)
+	if full {
+		state = userRuleState{fullLoadedAt: time.Now()}
+		cached = nil
+	}
+
+	changed := make(map[uuid.UUID]*Rule)
+	mark := state.watermark
+	batchSize := c.ruleBatchSize()
+	for {
+		dbRules, err := c.db.GetUserRulesChangedSince(ctx, db.GetUserRulesChangedSinceParams{
+			UserID:    pgUserID,
+			UpdatedAt: pgtype.Timestamptz{Time: mark.updatedAt, Valid: true},
+			ID:        pgtype.UUID{Bytes: mark.id, Valid: true},
+			Limit:     batchSize,
 		})
+		if err != nil {
+			return fmt.Errorf("failed to load user rules: %w", err)
+		}
+
+		for _, r := range dbRules {
+			// Convert pgtype.UUID to uuid.UUID
+			var id uuid.UUID
+			copy(id[:], r.ID.Bytes[:])
+
+			// Convert pgtype.Numeric to float64
+			similarity, _ := r.SimilarityThreshold.Float64Value()
+
+			changed[id] = activeRule(r.IsActive, Rule{
+				ID:                  id,
+				Keyword:             r.Keyword,
+				Category:            r.Category,
+				Priority:            r.Priority.Int32,
+				MatchType:           r.MatchType.String,
+				SimilarityThreshold: similarity.Float64,
+				RuleType:            "user",
+				MinAmount:           numericBound(r.MinAmount),
+				MaxAmount:           numericBound(r.MaxAmount),
+				Tags:                r.Tags,
+				Direction:           r.Direction.String,
+			})
+			mark = ruleWatermark{updatedAt: r.UpdatedAt.Time, id: id}
+		}
+
+		if len(dbRules) < int(batchSize) {
+			break
+		}
 	}
 
-	c.userRules[userID] = rules
+	if c.userMarks == nil {
+		c.userMarks = make(map[uuid.UUID]userRuleState)
+	}
+	c.userRules[userID] = mergeRules(cached, changed)
+	c.userMarks[userID] = userRuleState{watermark: mark, loadedAt: time.Now(), fullLoadedAt: state.fullLoadedAt}
 	return nil
 }
 
-// InvalidateUserCache clears the cached rules for a specific user
+// activeRule returns the rule, or nil when it is inactive and should leave the cache
+func activeRule(isActive pgtype.Bool, rule Rule) *Rule {
+	if !isActive.Valid || !isActive.Bool {
+		return nil
+	}
+	return &rule
+}
+
+// mergeRules applies changed rules (keyed by ID, nil meaning removed) to a cached
+// rule set, keeping the priority DESC, keyword ASC order of a full load
+func mergeRules(cached []Rule, changed map[uuid.UUID]*Rule) []Rule {
+	merged := make([]Rule, 0, len(cached)+len(changed))
+	for _, rule := range cached {
+		if _, ok := changed[rule.ID]; !ok {
+			merged = append(merged, rule)
+		}
+	}
+	for _, rule := range changed {
+		if rule != nil {
+			merged = append(merged, *rule)
+		}
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].Priority != merged[j].Priority {
+			return merged[i].Priority > merged[j].Priority
+		}
+		if merged[i].Keyword != merged[j].Keyword {
+			return merged[i].Keyword < merged[j].Keyword
+		}
+		return bytes.Compare(merged[i].ID[:], merged[j].ID[:]) < 0
+	})
+	return merged
+}
+
+// InvalidateUserCache clears the cached rules for a specific user and, with
+// SetBroadcastInvalidations, tells the other API instances to do the same
 func (c *Categorizer) InvalidateUserCache(userID uuid.UUID) {
+	c.dropUserCache(userID)
+	if c.broadcast {
+		c.publishInvalidation(userID)
+	}
+}
+
+// dropUserCache clears this instance's cached rules for a user
+func (c *Categorizer) dropUserCache(userID uuid.UUID) {
 	c.cacheMutex.Lock()
 	defer c.cacheMutex.Unlock()
 	delete(c.userRules, userID)
+	delete(c.userMarks, userID)
 }
 
 // Categorize attempts to categorize a transaction description
-// Returns category string or empty string if no match
+// Returns category string or empty string if no match.
+// Rules with an amount range are skipped; use CategorizeWithAmount for those.
 func (c *Categorizer) Categorize(ctx context.Context, description string, userID uuid.UUID) (string, error) {
+	allRules, err := c.rulesForUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	// Match description against rules
+	return c.matchDescription(description, nil, allRules), nil
+}
+
+// CategorizeWithAmount categorizes like Categorize, also applying rules whose
+// amount range contains the transaction amount (compared by absolute value)
+func (c *Categorizer) CategorizeWithAmount(ctx context.Context, description string, amount float64, userID uuid.UUID) (string, error) {
+	allRules, err := c.rulesForUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return c.matchDescription(description, &amount, allRules), nil
+}
+
+// CategorizeWithScore categorizes like Categorize and also returns the winning
+// rule's match score, normalized 0-1 across match types: exact 1.0, regex 0.8,
+// substring by keyword/description length, fuzzy by similarity. No match scores 0.
+func (c *Categorizer) CategorizeWithScore(ctx context.Context, description string, userID uuid.UUID) (string, float64, error) {
+	match, err := c.CategorizeMatch(ctx, description, userID)
+	if err != nil {
+		return "", 0, err
+	}
+	return match.Category, match.Score, nil
+}
+
+// CategorizeMatch categorizes a description like Categorize and also reports
+// which rule matched. The zero CategoryMatch means no rule matched.
+func (c *Categorizer) CategorizeMatch(ctx context.Context, description string, userID uuid.UUID) (models.CategoryMatch, error) {
+	return c.categorizeMatch(ctx, description, nil, userID)
+}
+
+// CategorizeMatchWithAmount is CategorizeMatch with amount-range rules applied
+func (c *Categorizer) CategorizeMatchWithAmount(ctx context.Context, description string, amount float64, userID uuid.UUID) (models.CategoryMatch, error) {
+	return c.categorizeMatch(ctx, description, &amount, userID)
+}
+
+// categorizeMatch reports the winning rule for a description and optional amount
+func (c *Categorizer) categorizeMatch(ctx context.Context, description string, amount *float64, userID uuid.UUID) (models.CategoryMatch, error) {
+	allRules, err := c.rulesForUser(ctx, userID)
+	if err != nil {
+		return models.CategoryMatch{}, err
+	}
+
+	rule, score, ok := c.bestMatchWithFallback(description, amount, allRules)
+	if !ok {
+		return models.CategoryMatch{}, nil
+	}
+	return models.CategoryMatch{
+		Category:       rule.Category,
+		RuleID:         rule.ID,
+		RuleType:       rule.RuleType,
+		MatchType:      effectiveMatchType(rule.MatchType),
+		Score:          normalizeScore(score),
+		MatchedKeyword: c.matchedKeyword(description, rule),
+	}, nil
+}
+
+// HasAmountRules reports whether any of the user's or global rules only applies to
+// some amounts or to one direction, so that categorizing the same description can
+// give different results for different transactions
+func (c *Categorizer) HasAmountRules(ctx context.Context, userID uuid.UUID) (bool, error) {
+	allRules, err := c.rulesForUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, rule := range allRules {
+		if rule.hasAmountRange() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// normalizeScore bounds a match score to 0-1 (an empty description can yield NaN)
+func normalizeScore(score float64) float64 {
+	if math.IsNaN(score) || score < 0 {
+		return 0
+	}
+	if score > 1 {
+		return 1
+	}
+	return score
+}
+
+// MatchTags returns the tags of every rule matching a transaction, sorted and
+// without duplicates. Unlike categorization, all matching rules contribute, not
+// just the winner; rules with an amount range compare the absolute amount.
+func (c *Categorizer) MatchTags(ctx context.Context, description string, amount float64, userID uuid.UUID) ([]string, error) {
+	allRules, err := c.rulesForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	text := c.prepareText(description)
+
+	seen := make(map[string]bool)
+	tags := []string{}
+	for _, rule := range allRules {
+		if len(rule.Tags) == 0 || !rule.matchesAmount(&amount) {
+			continue
+		}
+		if matched, _, _ := c.matchCased(text, rule); !matched {
+			continue
+		}
+		for _, tag := range rule.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// effectiveMatchType returns the match type a rule is evaluated with; unknown types fall back to substring
+func effectiveMatchType(matchType string) string {
+	switch matchType {
+	case "exact", "substring", "regex", "fuzzy", models.MatchTypeAny, models.MatchTypeAll:
+		return matchType
+	default:
+		return "substring"
+	}
+}
+
+// rulesForUser returns the user's rules followed by global rules, loading either into the cache if needed
+func (c *Categorizer) rulesForUser(ctx context.Context, userID uuid.UUID) ([]Rule, error) {
 	// Ensure global rules are loaded
 	if err := c.LoadGlobalRules(ctx); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// Load user rules if not cached
+	// Load user rules if not cached, refreshing changed rules once the cache TTL passes
 	c.cacheMutex.RLock()
 	_, userRulesExist := c.userRules[userID]
+	state, tracked := c.userMarks[userID]
 	c.cacheMutex.RUnlock()
 
-	if !userRulesExist {
+	if !userRulesExist || (tracked && time.Since(state.loadedAt) >= c.cacheTTL) {
 		if err := c.LoadUserRules(ctx, userID); err != nil {
-			return "", err
+			return nil, err
 		}
 	}
 
@@ -155,70 +593,316 @@ func (c *Categorizer) Categorize(ctx context.Context, description string, userID
 	c.cacheMutex.RUnlock()
 
 	// Combine rules: user rules first (higher priority)
-	allRules := append(userRules, globalRules...)
+	allRules := make([]Rule, 0, len(userRules)+len(globalRules))
+	allRules = append(allRules, userRules...)
+	return append(allRules, globalRules...), nil
+}
 
-	// Match description against rules
-	return c.matchDescription(description, allRules), nil
+// PreviewRuleImpact categorizes transactions with the proposed rules layered on top of
+// the user's current rules and returns those whose category would change. Nothing is
+// saved. Reviewed transactions are skipped since they hold the user's own choice.
+func (c *Categorizer) PreviewRuleImpact(ctx context.Context, userID uuid.UUID, transactions []models.Transaction, proposed []models.ProposedRule) ([]models.CategoryChange, error) {
+	currentRules, err := c.rulesForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, 0, len(proposed)+len(currentRules))
+	for _, p := range proposed {
+		rules = append(rules, proposedRule(p))
+	}
+	rules = append(rules, currentRules...)
+
+	changes := []models.CategoryChange{}
+	for _, txn := range transactions {
+		if txn.IsReviewed {
+			continue
+		}
+
+		amount := txn.Amount
+		rule, _, matched := c.bestMatchWithFallback(txn.Description, &amount, rules)
+		newCategory, matchedKeyword := "", ""
+		if matched {
+			newCategory = rule.Category
+			matchedKeyword = c.matchedKeyword(txn.Description, rule)
+		}
+		oldCategory := ""
+		if txn.Category != nil {
+			oldCategory = *txn.Category
+		}
+		if newCategory == oldCategory {
+			continue
+		}
+
+		changes = append(changes, models.CategoryChange{
+			TransactionID:  txn.ID,
+			Description:    txn.Description,
+			OldCategory:    oldCategory,
+			NewCategory:    newCategory,
+			MatchedKeyword: matchedKeyword,
+		})
+	}
+
+	return changes, nil
 }
 
-// matchDescription finds the best matching rule for a description
-func (c *Categorizer) matchDescription(description string, rules []Rule) string {
-	descUpper := strings.ToUpper(strings.TrimSpace(description))
-	descLower := strings.ToLower(descUpper)
+// PreviewRuleRemoval categorizes transactions with and without one of the user's rules
+// and returns those whose category would change if the rule were deleted. Nothing is
+// saved and reviewed transactions are skipped. found is false when ruleID is not one of
+// the user's active rules.
+func (c *Categorizer) PreviewRuleRemoval(ctx context.Context, userID, ruleID uuid.UUID, transactions []models.Transaction) (changes []models.CategoryChange, found bool, err error) {
+	currentRules, err := c.rulesForUser(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
 
-	var bestMatch string
+	remaining := make([]Rule, 0, len(currentRules))
+	for _, rule := range currentRules {
+		if rule.RuleType == "user" && rule.ID == ruleID {
+			found = true
+			continue
+		}
+		remaining = append(remaining, rule)
+	}
+	if !found {
+		return nil, false, nil
+	}
+
+	changes = []models.CategoryChange{}
+	for _, txn := range transactions {
+		if txn.IsReviewed {
+			continue
+		}
+
+		amount := txn.Amount
+		oldCategory := c.matchDescription(txn.Description, &amount, currentRules)
+		newCategory := c.matchDescription(txn.Description, &amount, remaining)
+		if newCategory == oldCategory {
+			continue
+		}
+
+		changes = append(changes, models.CategoryChange{
+			TransactionID: txn.ID,
+			Description:   txn.Description,
+			OldCategory:   oldCategory,
+			NewCategory:   newCategory,
+		})
+	}
+
+	return changes, true, nil
+}
+
+// EffectiveRules returns the rules Categorize evaluates for a user, loading them into
+// the cache if needed, ordered by precedence: higher priority first, then user rules
+// before global rules, then cache order. Within one priority a later rule still wins
+// if its weighted match score is strictly higher, so MatchWeight is reported too.
+// Tag-only rules are left out since they never set a category.
+func (c *Categorizer) EffectiveRules(ctx context.Context, userID uuid.UUID) ([]models.EffectiveRule, error) {
+	rules, err := c.rulesForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.tagOnly() {
+			ordered = append(ordered, rule)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Priority != ordered[j].Priority {
+			return ordered[i].Priority > ordered[j].Priority
+		}
+		return ordered[i].RuleType == "user" && ordered[j].RuleType != "user"
+	})
+
+	weights := c.matchWeights()
+	effective := make([]models.EffectiveRule, len(ordered))
+	for i, rule := range ordered {
+		matchType := effectiveMatchType(rule.MatchType)
+		effective[i] = models.EffectiveRule{
+			Position:    i + 1,
+			ID:          rule.ID,
+			Source:      rule.RuleType,
+			Keyword:     rule.Keyword,
+			Category:    rule.Category,
+			Priority:    rule.Priority,
+			MatchType:   matchType,
+			MatchWeight: weights.weight(matchType),
+			MinAmount:   rule.MinAmount,
+			MaxAmount:   rule.MaxAmount,
+			Direction:   rule.Direction,
+		}
+		if matchType == "fuzzy" {
+			effective[i].SimilarityThreshold = rule.SimilarityThreshold
+		}
+	}
+	return effective, nil
+}
+
+// TestRule runs a single proposed rule on its own against transactions and returns
+// those it matches, in the order given. Other rules are ignored and nothing is saved.
+func (c *Categorizer) TestRule(rule models.ProposedRule, transactions []models.Transaction) []models.RuleTestMatch {
+	rules := []Rule{proposedRule(rule)}
+
+	matches := []models.RuleTestMatch{}
+	for _, txn := range transactions {
+		amount := txn.Amount
+		rule, score, ok := c.bestMatch(txn.Description, &amount, rules)
+		if !ok {
+			continue
+		}
+
+		currentCategory := ""
+		if txn.Category != nil {
+			currentCategory = *txn.Category
+		}
+		matches = append(matches, models.RuleTestMatch{
+			TransactionID:   txn.ID,
+			Description:     txn.Description,
+			Amount:          txn.Amount,
+			CurrentCategory: currentCategory,
+			Score:           normalizeScore(score),
+			MatchedKeyword:  c.matchedKeyword(txn.Description, rule),
+		})
+	}
+	return matches
+}
+
+// proposedRule converts an unsaved rule to the form used for matching
+func proposedRule(p models.ProposedRule) Rule {
+	return Rule{
+		Keyword:             p.Keyword,
+		Category:            p.Category,
+		Priority:            p.Priority,
+		MatchType:           p.MatchType,
+		SimilarityThreshold: p.SimilarityThreshold,
+		RuleType:            "proposed",
+		MinAmount:           p.MinAmount,
+		MaxAmount:           p.MaxAmount,
+		Direction:           p.Direction,
+	}
+}
+
+// matchDescription finds the best matching rule for a description.
+// A nil amount skips rules with an amount range.
+func (c *Categorizer) matchDescription(description string, amount *float64, rules []Rule) string {
+	rule, _, ok := c.bestMatchWithFallback(description, amount, rules)
+	if !ok {
+		return ""
+	}
+	return rule.Category
+}
+
+// bestMatch returns the winning rule for a description and its match score
+func (c *Categorizer) bestMatch(description string, amount *float64, rules []Rule) (Rule, float64, bool) {
+	text := c.prepareText(description)
+
+	var bestMatch Rule
+	found := false
 	highestPriority := int32(-1)
 	highestScore := 0.0
+	highestWeighted := 0.0
+	weights := c.matchWeights()
 
 	for _, rule := range rules {
-		var matched bool
-		var score float64
-
-		// For regex, use uppercase (Indian bank CSVs are usually uppercase)
-		// For other match types, use lowercase
-		if rule.MatchType == "regex" {
-			matched, score = c.matchRule(descUpper, rule)
-		} else {
-			matched, score = c.matchRule(descLower, rule)
+		if rule.tagOnly() || !rule.matchesAmount(amount) {
+			continue
 		}
 
+		matched, score, _ := c.matchCased(text, rule)
 		if matched {
+			weighted := score * weights.weight(rule.MatchType)
 			// Higher priority wins
 			if rule.Priority > highestPriority {
-				bestMatch = rule.Category
+				bestMatch = rule
+				found = true
 				highestPriority = rule.Priority
 				highestScore = score
-			} else if rule.Priority == highestPriority && score > highestScore {
-				// If priority is equal, higher score wins
-				bestMatch = rule.Category
+				highestWeighted = weighted
+			} else if rule.Priority == highestPriority && weighted > highestWeighted {
+				// If priority is equal, higher weighted score wins
+				bestMatch = rule
 				highestScore = score
+				highestWeighted = weighted
 			}
 		}
 	}
 
-	return bestMatch
+	return bestMatch, highestScore, found
 }
 
-// matchRule checks if a description matches a rule based on match_type
-func (c *Categorizer) matchRule(description string, rule Rule) (bool, float64) {
+// matchedKeyword returns the keyword or description text through which a rule
+// matched, or "" when reporting is turned off. It is only computed for winning
+// rules so that bestMatch stays cheap.
+func (c *Categorizer) matchedKeyword(description string, rule Rule) string {
+	if c.hideTokens {
+		return ""
+	}
+	text := c.prepareText(description)
+	_, _, token := c.matchCased(text, rule)
+	return token
+}
+
+// matchCased matches a rule against the description in the case its match type expects.
+// Regex uses uppercase (Indian bank CSVs are usually uppercase); other types use lowercase,
+// with channel prefixes trimmed. Keywords can include a prefix themselves (the seeded
+// "neft salary" and "atm fee"), so those types also try the untrimmed description and
+// keep the better score.
+func (c *Categorizer) matchCased(text matchText, rule Rule) (bool, float64, string) {
+	if rule.MatchType == "regex" {
+		return c.matchRule(text.upper, rule)
+	}
+	matched, score, token := c.matchRule(text.lower, rule)
+	if text.lowerFull == text.lower {
+		return matched, score, token
+	}
+	if fullMatched, fullScore, fullToken := c.matchRule(text.lowerFull, rule); fullMatched && (!matched || fullScore > score) {
+		return fullMatched, fullScore, fullToken
+	}
+	return matched, score, token
+}
+
+// matchRule checks if a description matches a rule based on match_type. On a match
+// it also returns the matched token: the keyword for exact and substring rules, the
+// best keyword for any, all keywords for all, the matched text for regex, and the
+// description word (or whole description) for fuzzy.
+func (c *Categorizer) matchRule(description string, rule Rule) (bool, float64, string) {
 	switch rule.MatchType {
 	case "exact":
 		keywordLower := strings.ToLower(rule.Keyword)
-		return c.matchExact(description, keywordLower)
+		matched, score := c.matchExact(description, keywordLower)
+		return matched, score, matchedToken(matched, keywordLower)
 	case "substring":
 		keywordLower := strings.ToLower(rule.Keyword)
-		return c.matchSubstring(description, keywordLower)
+		matched, score := c.matchSubstring(description, keywordLower)
+		return matched, score, matchedToken(matched, keywordLower)
 	case "regex":
 		// Don't lowercase regex patterns
 		return c.matchRegex(description, rule.Keyword)
 	case "fuzzy":
 		keywordLower := strings.ToLower(rule.Keyword)
 		return c.matchFuzzy(description, keywordLower, rule.SimilarityThreshold)
+	case models.MatchTypeAny:
+		return c.matchAny(description, models.SplitKeywords(strings.ToLower(rule.Keyword)))
+	case models.MatchTypeAll:
+		keywords := models.SplitKeywords(strings.ToLower(rule.Keyword))
+		matched, score := c.matchAll(description, keywords)
+		return matched, score, matchedToken(matched, strings.Join(keywords, ", "))
 	default:
 		// Default to substring matching
 		keywordLower := strings.ToLower(rule.Keyword)
-		return c.matchSubstring(description, keywordLower)
+		matched, score := c.matchSubstring(description, keywordLower)
+		return matched, score, matchedToken(matched, keywordLower)
+	}
+}
+
+// matchedToken returns token for a match and "" otherwise
+func matchedToken(matched bool, token string) string {
+	if !matched {
+		return ""
 	}
+	return token
 }
 
 // matchExact performs exact string matching
@@ -239,54 +923,92 @@ func (c *Categorizer) matchSubstring(description, keyword string) (bool, float64
 	return false, 0.0
 }
 
-// matchRegex performs regular expression matching
-func (c *Categorizer) matchRegex(description, pattern string) (bool, float64) {
-	// Compile regex (in production, cache compiled regexes)
-	re, err := regexp.Compile(pattern)
+// matchAny matches when any keyword is a substring, scoring by (and returning) the
+// best-matching keyword
+func (c *Categorizer) matchAny(description string, keywords []string) (bool, float64, string) {
+	matched := false
+	bestScore := 0.0
+	bestKeyword := ""
+	for _, keyword := range keywords {
+		if ok, score := c.matchSubstring(description, keyword); ok {
+			matched = true
+			if score > bestScore {
+				bestScore = score
+				bestKeyword = keyword
+			}
+		}
+	}
+	return matched, bestScore, bestKeyword
+}
+
+// matchAll matches only when every keyword is a substring. The score is the share
+// of the description covered by the keywords, capped at 1.
+func (c *Categorizer) matchAll(description string, keywords []string) (bool, float64) {
+	if len(keywords) == 0 {
+		return false, 0.0
+	}
+	total := 0.0
+	for _, keyword := range keywords {
+		ok, score := c.matchSubstring(description, keyword)
+		if !ok {
+			return false, 0.0
+		}
+		total += score
+	}
+	if total > 1 {
+		total = 1
+	}
+	return true, total
+}
+
+// matchRegex performs regular expression matching, returning the matched text
+func (c *Categorizer) matchRegex(description, pattern string) (bool, float64, string) {
+	re, err := c.regexCache.Get(pattern)
 	if err != nil {
 		// Invalid regex, skip this rule
-		return false, 0.0
+		return false, 0.0, ""
 	}
 
-	if re.MatchString(description) {
+	if loc := re.FindStringIndex(description); loc != nil {
 		// For regex, score is 0.8 (slightly lower than exact match)
-		return true, 0.8
+		return true, 0.8, description[loc[0]:loc[1]]
 	}
-	return false, 0.0
+	return false, 0.0, ""
 }
 
-// matchFuzzy performs fuzzy string matching using Levenshtein distance
-func (c *Categorizer) matchFuzzy(description, keyword string, threshold float64) (bool, float64) {
+// matchFuzzy performs fuzzy string matching using Levenshtein distance, returning
+// the keyword, description or description word that matched
+func (c *Categorizer) matchFuzzy(description, keyword string, threshold float64) (bool, float64, string) {
 	// Check if keyword exists as substring first (fast path)
 	if strings.Contains(description, keyword) {
-		return true, 1.0
+		return true, 1.0, keyword
 	}
 
 	// Calculate similarity for the entire description
-	similarity := c.calculateSimilarity(description, keyword)
+	similarity := calculateSimilarity(description, keyword)
 	if similarity >= threshold {
-		return true, similarity
+		return true, similarity, description
 	}
 
 	// Also check against individual words in description
 	words := strings.Fields(description)
 	maxSimilarity := 0.0
 	for _, word := range words {
-		wordSimilarity := c.calculateSimilarity(word, keyword)
+		wordSimilarity := calculateSimilarity(word, keyword)
 		if wordSimilarity > maxSimilarity {
 			maxSimilarity = wordSimilarity
 		}
 		if wordSimilarity >= threshold {
-			return true, wordSimilarity
+			return true, wordSimilarity, word
 		}
 	}
 
-	return false, maxSimilarity
+	return false, maxSimilarity, ""
 }
 
 // calculateSimilarity computes similarity score using Levenshtein distance
 // Returns a value between 0 and 1, where 1 is identical
-func (c *Categorizer) calculateSimilarity(s1, s2 string) float64 {
+func calculateSimilarity(s1, s2 string) float64 {
 	// Handle empty strings
 	if len(s1) == 0 && len(s2) == 0 {
 		return 1.0
@@ -296,7 +1018,7 @@ func (c *Categorizer) calculateSimilarity(s1, s2 string) float64 {
 	}
 
 	// Calculate Levenshtein distance
-	distance := c.levenshteinDistance(s1, s2)
+	distance := levenshteinDistance(s1, s2)
 
 	// Convert distance to similarity (0-1 scale)
 	maxLen := len(s1)
@@ -309,7 +1031,7 @@ func (c *Categorizer) calculateSimilarity(s1, s2 string) float64 {
 }
 
 // levenshteinDistance calculates the Levenshtein distance between two strings
-func (c *Categorizer) levenshteinDistance(s1, s2 string) int {
+func levenshteinDistance(s1, s2 string) int {
 	// Create matrix
 	matrix := make([][]int, len(s1)+1)
 	for i := range matrix {
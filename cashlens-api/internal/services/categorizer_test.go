@@ -1,10 +1,22 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/ashmitsharp/cashlens-api/internal/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Test exact matching
@@ -100,6 +112,113 @@ func TestCategorizer_MatchSubstring(t *testing.T) {
 	}
 }
 
+// Test "any of" multi-keyword matching
+func TestCategorizer_MatchAny(t *testing.T) {
+	c := &Categorizer{}
+
+	tests := []struct {
+		name        string
+		description string
+		keyword     string
+		wantMatch   bool
+	}{
+		{
+			name:        "First keyword",
+			description: "swiggy order 1234",
+			keyword:     "swiggy|zomato|dunzo",
+			wantMatch:   true,
+		},
+		{
+			name:        "Later keyword",
+			description: "upi-zomato-payment",
+			keyword:     "swiggy|zomato|dunzo",
+			wantMatch:   true,
+		},
+		{
+			name:        "Comma separated",
+			description: "dunzo daily",
+			keyword:     "swiggy, zomato, dunzo",
+			wantMatch:   true,
+		},
+		{
+			name:        "None present",
+			description: "uber trip",
+			keyword:     "swiggy|zomato|dunzo",
+			wantMatch:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMatch, _, _ := c.matchRule(tt.description, Rule{Keyword: tt.keyword, MatchType: "any"})
+			assert.Equal(t, tt.wantMatch, gotMatch)
+		})
+	}
+}
+
+func TestCategorizer_MatchAny_ScoresBestKeyword(t *testing.T) {
+	c := &Categorizer{}
+
+	_, shortScore := c.matchSubstring("swiggy instamart", "swiggy")
+	_, longScore := c.matchSubstring("swiggy instamart", "swiggy instamart")
+
+	matched, score, _ := c.matchAny("swiggy instamart", []string{"swiggy", "swiggy instamart"})
+	assert.True(t, matched)
+	assert.Greater(t, longScore, shortScore)
+	assert.Equal(t, longScore, score)
+}
+
+// Test "all of" multi-keyword matching
+func TestCategorizer_MatchAll(t *testing.T) {
+	c := &Categorizer{}
+
+	tests := []struct {
+		name        string
+		description string
+		keyword     string
+		wantMatch   bool
+	}{
+		{
+			name:        "All present",
+			description: "aws invoice 2024-01",
+			keyword:     "aws|invoice",
+			wantMatch:   true,
+		},
+		{
+			name:        "All present in any order",
+			description: "invoice for aws",
+			keyword:     "aws, invoice",
+			wantMatch:   true,
+		},
+		{
+			name:        "Only one present",
+			description: "aws marketplace",
+			keyword:     "aws|invoice",
+			wantMatch:   false,
+		},
+		{
+			name:        "None present",
+			description: "gcp invoice",
+			keyword:     "aws|refund",
+			wantMatch:   false,
+		},
+		{
+			name:        "No keywords",
+			description: "aws invoice",
+			keyword:     "|",
+			wantMatch:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMatch, score, _ := c.matchRule(tt.description, Rule{Keyword: tt.keyword, MatchType: "all"})
+			assert.Equal(t, tt.wantMatch, gotMatch)
+			assert.LessOrEqual(t, score, 1.0)
+		})
+	}
+}
+
 // Test regex matching
 func TestCategorizer_MatchRegex(t *testing.T) {
 	c := &Categorizer{}
@@ -162,7 +281,7 @@ func TestCategorizer_MatchRegex(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotMatch, _ := c.matchRegex(tt.description, tt.pattern)
+			gotMatch, _, _ := c.matchRegex(tt.description, tt.pattern)
 			assert.Equal(t, tt.wantMatch, gotMatch)
 		})
 	}
@@ -232,7 +351,7 @@ func TestCategorizer_MatchFuzzy(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotMatch, _ := c.matchFuzzy(tt.description, tt.keyword, tt.threshold)
+			gotMatch, _, _ := c.matchFuzzy(tt.description, tt.keyword, tt.threshold)
 			assert.Equal(t, tt.wantMatch, gotMatch)
 		})
 	}
@@ -240,8 +359,6 @@ func TestCategorizer_MatchFuzzy(t *testing.T) {
 
 // Test Levenshtein distance calculation
 func TestCategorizer_LevenshteinDistance(t *testing.T) {
-	c := &Categorizer{}
-
 	tests := []struct {
 		name     string
 		s1       string
@@ -288,7 +405,7 @@ func TestCategorizer_LevenshteinDistance(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotDist := c.levenshteinDistance(tt.s1, tt.s2)
+			gotDist := levenshteinDistance(tt.s1, tt.s2)
 			assert.Equal(t, tt.wantDist, gotDist)
 		})
 	}
@@ -296,8 +413,6 @@ func TestCategorizer_LevenshteinDistance(t *testing.T) {
 
 // Test similarity calculation
 func TestCategorizer_CalculateSimilarity(t *testing.T) {
-	c := &Categorizer{}
-
 	tests := []struct {
 		name           string
 		s1             string
@@ -332,7 +447,7 @@ func TestCategorizer_CalculateSimilarity(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotSimilarity := c.calculateSimilarity(tt.s1, tt.s2)
+			gotSimilarity := calculateSimilarity(tt.s1, tt.s2)
 			assert.InDelta(t, tt.wantSimilarity, gotSimilarity, 0.01)
 		})
 	}
@@ -411,7 +526,7 @@ func TestCategorizer_MatchDescription(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotCategory := c.matchDescription(tt.description, tt.rules)
+			gotCategory := c.matchDescription(tt.description, nil, tt.rules)
 			assert.Equal(t, tt.wantCategory, gotCategory)
 		})
 	}
@@ -509,7 +624,7 @@ func TestCategorizer_IndianTransactions(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			descLower := strings.ToLower(tt.description)
-			gotCategory := c.matchDescription(descLower, rules)
+			gotCategory := c.matchDescription(descLower, nil, rules)
 			assert.Equal(t, tt.wantCategory, gotCategory, "Failed to categorize: %s", tt.description)
 		})
 	}
@@ -534,6 +649,497 @@ func BenchmarkCategorizer_MatchDescription(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = c.matchDescription(description, rules)
+		_ = c.matchDescription(description, nil, rules)
+	}
+}
+
+// BenchmarkCategorizer_MatchDescription_Regex compares a rule set with regex rules
+// (including an invalid pattern) with and without the compiled-regex cache
+func BenchmarkCategorizer_MatchDescription_Regex(b *testing.B) {
+	rules := make([]Rule, 0, 101)
+	for i := 0; i < 50; i++ {
+		rules = append(rules, Rule{
+			Keyword:   fmt.Sprintf("keyword%d", i),
+			Category:  fmt.Sprintf("Category%d", i),
+			Priority:  int32(i),
+			MatchType: "substring",
+		})
+		rules = append(rules, Rule{
+			Keyword:   fmt.Sprintf(`^UPI/\d+/MERCHANT%d\b`, i),
+			Category:  fmt.Sprintf("Category%d", i),
+			Priority:  int32(i),
+			MatchType: "regex",
+		})
 	}
+	rules = append(rules, Rule{Keyword: "[unclosed", Category: "Broken", MatchType: "regex"})
+
+	description := "UPI/123456789/MERCHANT42/PAYMENT"
+
+	b.Run("Uncached", func(b *testing.B) {
+		c := &Categorizer{} // nil cache compiles on every match
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = c.matchDescription(description, nil, rules)
+		}
+	})
+
+	b.Run("Cached", func(b *testing.B) {
+		c := &Categorizer{regexCache: NewRegexCache(DefaultRegexCacheSize)}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = c.matchDescription(description, nil, rules)
+		}
+	})
+}
+
+// fakeRulesDB serves a fixed set of global rules through the sqlc DBTX interface,
+// applying GetGlobalRulesChangedSince's watermark and limit
+type fakeRulesDB struct {
+	rules   []db.GlobalCategorizationRule
+	fetched []string // Keywords of every rule returned, in order
+	queries int
+}
+
+func (f *fakeRulesDB) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, nil
+}
+
+func (f *fakeRulesDB) Query(_ context.Context, _ string, args ...interface{}) (pgx.Rows, error) {
+	f.queries++
+	updatedAt := args[0].(pgtype.Timestamptz).Time
+	id := args[1].(pgtype.UUID).Bytes
+	limit := int(args[2].(int32))
+
+	sorted := append([]db.GlobalCategorizationRule{}, f.rules...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].UpdatedAt.Time.Equal(sorted[j].UpdatedAt.Time) {
+			return sorted[i].UpdatedAt.Time.Before(sorted[j].UpdatedAt.Time)
+		}
+		return bytes.Compare(sorted[i].ID.Bytes[:], sorted[j].ID.Bytes[:]) < 0
+	})
+
+	rules := []db.GlobalCategorizationRule{}
+	for _, rule := range sorted {
+		after := rule.UpdatedAt.Time.After(updatedAt) ||
+			(rule.UpdatedAt.Time.Equal(updatedAt) && bytes.Compare(rule.ID.Bytes[:], id[:]) > 0)
+		if after && len(rules) < limit {
+			rules = append(rules, rule)
+			f.fetched = append(f.fetched, rule.Keyword)
+		}
+	}
+	return &fakeRuleRows{rules: rules, pos: -1}, nil
+}
+
+func (f *fakeRulesDB) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return nil
+}
+
+func (f *fakeRulesDB) CopyFrom(context.Context, pgx.Identifier, []string, pgx.CopyFromSource) (int64, error) {
+	return 0, nil
+}
+
+// fakeRuleRows iterates global rules in global_categorization_rules column order
+type fakeRuleRows struct {
+	pgx.Rows
+	rules []db.GlobalCategorizationRule
+	pos   int
+}
+
+func (r *fakeRuleRows) Next() bool {
+	r.pos++
+	return r.pos < len(r.rules)
+}
+
+func (r *fakeRuleRows) Scan(dest ...any) error {
+	rule := r.rules[r.pos]
+	*dest[0].(*pgtype.UUID) = rule.ID
+	*dest[1].(*string) = rule.Keyword
+	*dest[2].(*string) = rule.Category
+	*dest[3].(*pgtype.Int4) = rule.Priority
+	*dest[4].(*pgtype.Text) = rule.MatchType
+	*dest[5].(*pgtype.Numeric) = rule.SimilarityThreshold
+	*dest[6].(*pgtype.Bool) = rule.IsActive
+	*dest[7].(*pgtype.Timestamptz) = rule.CreatedAt
+	*dest[8].(*pgtype.Timestamptz) = rule.UpdatedAt
+	return nil
+}
+
+func (r *fakeRuleRows) Close() {}
+
+func (r *fakeRuleRows) Err() error { return nil }
+
+// Test that warming up the cache populates global rules
+func TestCategorizer_LoadGlobalRules(t *testing.T) {
+	fake := &fakeRulesDB{
+		rules: []db.GlobalCategorizationRule{
+			{
+				ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+				Keyword:   "aws",
+				Category:  "Cloud & Hosting",
+				Priority:  pgtype.Int4{Int32: 10, Valid: true},
+				MatchType: pgtype.Text{String: "substring", Valid: true},
+				IsActive:  pgtype.Bool{Bool: true, Valid: true},
+			},
+			{
+				ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+				Keyword:   "swiggy",
+				Category:  "Team Meals",
+				Priority:  pgtype.Int4{Int32: 5, Valid: true},
+				MatchType: pgtype.Text{String: "substring", Valid: true},
+				IsActive:  pgtype.Bool{Bool: true, Valid: true},
+			},
+		},
+	}
+	c := NewCategorizer(db.New(fake))
+
+	require.NoError(t, c.LoadGlobalRules(context.Background()))
+
+	assert.Equal(t, 2, c.GlobalRuleCount())
+	require.Len(t, c.globalRules, 2)
+	assert.Equal(t, "aws", c.globalRules[0].Keyword)
+	assert.Equal(t, "Cloud & Hosting", c.globalRules[0].Category)
+	assert.Equal(t, int32(10), c.globalRules[0].Priority)
+	assert.Equal(t, "global", c.globalRules[0].RuleType)
+}
+
+// Test that CategorizeMatch reports the winning rule
+func TestCategorizer_CategorizeMatch(t *testing.T) {
+	ruleID := uuid.New()
+	fake := &fakeRulesDB{
+		rules: []db.GlobalCategorizationRule{
+			{
+				ID:        pgtype.UUID{Bytes: ruleID, Valid: true},
+				Keyword:   "^UPI.*SWIGGY",
+				Category:  "Team Meals",
+				Priority:  pgtype.Int4{Int32: 10, Valid: true},
+				MatchType: pgtype.Text{String: "regex", Valid: true},
+				IsActive:  pgtype.Bool{Bool: true, Valid: true},
+			},
+		},
+	}
+	c := NewCategorizer(db.New(fake))
+	userID := uuid.New()
+	c.userRules[userID] = []Rule{} // No user rules
+
+	match, err := c.CategorizeMatch(context.Background(), "UPI/SWIGGY/1234", userID)
+	require.NoError(t, err)
+	assert.Equal(t, "Team Meals", match.Category)
+	assert.Equal(t, ruleID, match.RuleID)
+	assert.Equal(t, "global", match.RuleType)
+	assert.Equal(t, "regex", match.MatchType)
+	assert.Equal(t, 0.8, match.Score)
+
+	match, err = c.CategorizeMatch(context.Background(), "UNKNOWN PAYEE", userID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CategoryMatch{}, match)
+}
+
+// Test that CategorizeMatch reports the keyword or description text that matched
+func TestCategorizer_CategorizeMatch_MatchedKeyword(t *testing.T) {
+	c := NewCategorizer(db.New(&fakeRulesDB{}))
+	userID := uuid.New()
+	c.userRules[userID] = []Rule{
+		{Keyword: "Zomato", Category: "Food & Dining", Priority: 100, MatchType: "substring", RuleType: "user"},
+		{Keyword: "swiggy", Category: "Food & Dining", Priority: 100, MatchType: "fuzzy", SimilarityThreshold: 0.8, RuleType: "user"},
+		{Keyword: "uber, ola", Category: "Travel", Priority: 100, MatchType: "any", RuleType: "user"},
+		{Keyword: "^NEFT.*SALARY", Category: "Salary", Priority: 100, MatchType: "regex", RuleType: "user"},
+	}
+
+	tests := []struct {
+		description string
+		category    string
+		keyword     string
+	}{
+		{"UPI/ZOMATO ORDER/1234", "Food & Dining", "zomato"},
+		{"SWIGY INSTAMART", "Food & Dining", "swigy"},
+		{"SWIGGY ORDER", "Food & Dining", "swiggy"},
+		{"OLA CABS BLR", "Travel", "ola"},
+		{"NEFT ACME SALARY MAR", "Salary", "NEFT ACME SALARY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			match, err := c.CategorizeMatch(context.Background(), tt.description, userID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.category, match.Category)
+			assert.Equal(t, tt.keyword, match.MatchedKeyword)
+		})
+	}
+
+	c.SetReportMatchedKeyword(false)
+	match, err := c.CategorizeMatch(context.Background(), "UPI/ZOMATO ORDER/1234", userID)
+	require.NoError(t, err)
+	assert.Equal(t, "Food & Dining", match.Category)
+	assert.Empty(t, match.MatchedKeyword)
+}
+
+func TestCategorizer_MatchWeights_ResolveTies(t *testing.T) {
+	rules := []Rule{
+		{Keyword: "^NEFT.*", Category: "Transfers", Priority: 10, MatchType: "regex"},
+		{Keyword: "neft acme payroll", Category: "Salary", Priority: 10, MatchType: "substring"},
+		{Keyword: "acme payrol", Category: "Payroll", Priority: 10, MatchType: "fuzzy", SimilarityThreshold: 0.8},
+	}
+	description := "NEFT ACME PAYROLL" // substring covers the whole description (score 1.0)
+
+	tests := []struct {
+		name     string
+		weights  *MatchWeights
+		expected string
+	}{
+		{"defaults keep raw scores", nil, "Salary"},
+		{"regex boosted above substring", &MatchWeights{Exact: 4, Regex: 3, Substring: 2, Fuzzy: 1}, "Transfers"},
+		{"fuzzy boosted above all", &MatchWeights{Exact: 1, Regex: 1, Substring: 1, Fuzzy: 5}, "Payroll"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Categorizer{}
+			if tt.weights != nil {
+				c.SetMatchWeights(*tt.weights)
+			}
+			rule, _, ok := c.bestMatch(description, nil, rules)
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, rule.Category)
+		})
+	}
+}
+
+func TestCategorizer_MatchWeights_PriorityStillWins(t *testing.T) {
+	c := &Categorizer{}
+	c.SetMatchWeights(MatchWeights{Exact: 100, Regex: 1, Substring: 1, Fuzzy: 1})
+	rules := []Rule{
+		{Keyword: "swiggy", Category: "Food", Priority: 5, MatchType: "exact"},
+		{Keyword: "SWIG", Category: "Team Meals", Priority: 20, MatchType: "regex"},
+	}
+
+	rule, score, ok := c.bestMatch("SWIGGY", nil, rules)
+	require.True(t, ok)
+	assert.Equal(t, "Team Meals", rule.Category)
+	assert.Equal(t, 0.8, score) // Reported score stays unweighted
+}
+
+func TestCategorizer_SetMatchWeights_NonPositiveDefaults(t *testing.T) {
+	c := &Categorizer{}
+	c.SetMatchWeights(MatchWeights{Exact: 0, Regex: -2, Substring: 3, Fuzzy: 1})
+	assert.Equal(t, MatchWeights{Exact: 1, Regex: 1, Substring: 3, Fuzzy: 1}, c.matchWeights())
+	assert.Equal(t, DefaultMatchWeights, (&Categorizer{}).matchWeights())
+}
+
+func TestCategorizer_AmountRange(t *testing.T) {
+	c := &Categorizer{userRules: make(map[uuid.UUID][]Rule)}
+	userID := uuid.New()
+	floatPtr := func(f float64) *float64 { return &f }
+	c.userRules[userID] = []Rule{
+		{Keyword: "swiggy", Category: "Office Catering", Priority: 100, MatchType: "substring", MinAmount: floatPtr(2000)},
+		{Keyword: "swiggy", Category: "Food & Dining", Priority: 50, MatchType: "substring"},
+		{Keyword: "rent", Category: "Rent", Priority: 100, MatchType: "substring", MinAmount: floatPtr(10000), MaxAmount: floatPtr(100000)},
+	}
+	c.globalRules = []Rule{{Keyword: "aws", Category: "Cloud", Priority: 10, MatchType: "substring", RuleType: "global"}}
+	c.lastLoaded = time.Now()
+	c.cacheTTL = time.Hour
+
+	tests := []struct {
+		name        string
+		description string
+		amount      float64
+		expected    string
+	}{
+		{"Large swiggy order is catering", "SWIGGY ORDER 8812", -4500, "Office Catering"},
+		{"Small swiggy order falls through to dining", "SWIGGY ORDER 8812", -450, "Food & Dining"},
+		{"Bound is inclusive", "SWIGGY ORDER 8812", -2000, "Office Catering"},
+		{"Rent within range", "RENT JAN", -25000, "Rent"},
+		{"Rent below range", "RENT JAN", -500, ""},
+		{"Rent above range", "RENT JAN", -250000, ""},
+		{"Credits compare by absolute amount", "RENT REFUND", 25000, "Rent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category, err := c.CategorizeWithAmount(context.Background(), tt.description, tt.amount, userID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, category)
+
+			match, err := c.CategorizeMatchWithAmount(context.Background(), tt.description, tt.amount, userID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, match.Category)
+		})
+	}
+
+	// Without an amount, ranged rules are skipped
+	category, err := c.Categorize(context.Background(), "SWIGGY ORDER 8812", userID)
+	require.NoError(t, err)
+	assert.Equal(t, "Food & Dining", category)
+}
+
+// TestCategorizer_CategorizeWithScore tests that scores are normalized 0-1 per match type
+func TestCategorizer_CategorizeWithScore(t *testing.T) {
+	c := NewCategorizer(nil)
+	c.globalRules = []Rule{
+		{Keyword: "netflix subscription", Category: "Entertainment", Priority: 10, MatchType: "exact", RuleType: "global"},
+		{Keyword: `^UPI/\d+/ZOMATO`, Category: "Food & Dining", Priority: 10, MatchType: "regex", RuleType: "global"},
+		{Keyword: "aws", Category: "Cloud & Hosting", Priority: 10, MatchType: "substring", RuleType: "global"},
+		{Keyword: "swiggy", Category: "Food & Dining", Priority: 10, MatchType: "fuzzy", SimilarityThreshold: 0.7, RuleType: "global"},
+	}
+	c.lastLoaded = time.Now()
+	userID := uuid.New()
+	c.userRules[userID] = []Rule{}
+
+	tests := []struct {
+		name        string
+		description string
+		category    string
+		score       float64
+	}{
+		{"Exact", "NETFLIX SUBSCRIPTION", "Entertainment", 1.0},
+		{"Regex", "UPI/123456/ZOMATO", "Food & Dining", 0.8},
+		{"Substring by length ratio", "AWS SERVICES", "Cloud & Hosting", 3.0 / 12.0},
+		{"Fuzzy by similarity", "SWIGY", "Food & Dining", 5.0 / 6.0},
+		{"No match", "UNKNOWN MERCHANT", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category, score, err := c.CategorizeWithScore(context.Background(), tt.description, userID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.category, category)
+			assert.InDelta(t, tt.score, score, 0.001)
+			assert.GreaterOrEqual(t, score, 0.0)
+			assert.LessOrEqual(t, score, 1.0)
+		})
+	}
+}
+
+// Test that refreshing the global rule cache re-fetches only rules changed since the last load
+func TestCategorizer_LoadGlobalRules_Incremental(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rule := func(keyword, category string, priority int32, minutes int) db.GlobalCategorizationRule {
+		return db.GlobalCategorizationRule{
+			ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Keyword:   keyword,
+			Category:  category,
+			Priority:  pgtype.Int4{Int32: priority, Valid: true},
+			MatchType: pgtype.Text{String: "substring", Valid: true},
+			IsActive:  pgtype.Bool{Bool: true, Valid: true},
+			UpdatedAt: pgtype.Timestamptz{Time: base.Add(time.Duration(minutes) * time.Minute), Valid: true},
+		}
+	}
+	fake := &fakeRulesDB{rules: []db.GlobalCategorizationRule{
+		rule("aws", "Cloud & Hosting", 10, 1),
+		rule("swiggy", "Food & Dining", 5, 2),
+		rule("zomato", "Food & Dining", 5, 3),
+		rule("uber", "Travel", 5, 4),
+		rule("netflix", "Entertainment", 3, 4), // Same updated_at as uber; the id breaks the tie
+	}}
+	c := NewCategorizer(db.New(fake))
+	c.SetRuleBatchSize(2)
+
+	// First load fetches everything in batches of 2
+	require.NoError(t, c.LoadGlobalRules(context.Background()))
+	assert.Equal(t, 5, c.GlobalRuleCount())
+	assert.Len(t, fake.fetched, 5)
+	assert.Equal(t, 3, fake.queries)
+
+	// Recategorize swiggy, deactivate uber and add a new rule
+	fake.rules[1].Category = "Team Meals"
+	fake.rules[1].UpdatedAt.Time = base.Add(time.Hour)
+	fake.rules[3].IsActive = pgtype.Bool{Bool: false, Valid: true}
+	fake.rules[3].UpdatedAt.Time = base.Add(time.Hour + time.Minute)
+	fake.rules = append(fake.rules, rule("airtel", "Telecom", 7, 62))
+	fake.fetched = nil
+	fake.queries = 0
+
+	// Within the TTL nothing is fetched
+	require.NoError(t, c.LoadGlobalRules(context.Background()))
+	assert.Empty(t, fake.fetched)
+	assert.Equal(t, 0, fake.queries)
+
+	// Once the TTL passes only the changed rules are fetched
+	c.lastLoaded = time.Time{}
+	require.NoError(t, c.LoadGlobalRules(context.Background()))
+	assert.ElementsMatch(t, []string{"swiggy", "uber", "airtel"}, fake.fetched)
+	assert.Equal(t, 2, fake.queries)
+
+	keywords := []string{}
+	for _, r := range c.globalRules {
+		keywords = append(keywords, r.Keyword)
+	}
+	assert.Equal(t, []string{"aws", "airtel", "swiggy", "zomato", "netflix"}, keywords)
+	assert.Equal(t, "Team Meals", c.matchDescription("SWIGGY ORDER", nil, c.globalRules))
+
+	// A rule whose transaction committed late sits behind the watermark, and a hard
+	// delete leaves no row; incremental loads miss both
+	fake.rules = append(fake.rules[1:], rule("jio", "Telecom", 7, 30))
+	fake.fetched = nil
+	c.lastLoaded = time.Time{}
+	require.NoError(t, c.LoadGlobalRules(context.Background()))
+	assert.Empty(t, fake.fetched)
+	assert.Equal(t, 5, c.GlobalRuleCount())
+
+	// The periodic full reload picks them up
+	c.globalFull = time.Time{}
+	c.lastLoaded = time.Time{}
+	require.NoError(t, c.LoadGlobalRules(context.Background()))
+	keywords = []string{}
+	for _, r := range c.globalRules {
+		keywords = append(keywords, r.Keyword)
+	}
+	assert.Equal(t, []string{"airtel", "jio", "swiggy", "zomato", "netflix"}, keywords)
+}
+
+// Test that every matching rule contributes its tags and tag-only rules never categorize
+func TestCategorizer_MatchTags(t *testing.T) {
+	c := &Categorizer{userRules: make(map[uuid.UUID][]Rule)}
+	userID := uuid.New()
+	floatPtr := func(f float64) *float64 { return &f }
+	c.userRules[userID] = []Rule{
+		{Keyword: "uber", Category: "Travel", Priority: 100, MatchType: "substring", Tags: []string{"reimbursable"}},
+		{Keyword: "uber|ola", Priority: 200, MatchType: "any", Tags: []string{"commute", "reimbursable"}},
+		{Keyword: "hotel", Priority: 100, MatchType: "substring", MinAmount: floatPtr(5000), Tags: []string{"needs-receipt"}},
+	}
+	c.globalRules = []Rule{{Keyword: "hotel", Category: "Travel", Priority: 10, MatchType: "substring", RuleType: "global"}}
+	c.lastLoaded = time.Now()
+	c.cacheTTL = time.Hour
+
+	tests := []struct {
+		name        string
+		description string
+		amount      float64
+		tags        []string
+		category    string
+	}{
+		{"Tags from all matching rules", "UBER TRIP 1234", -350, []string{"commute", "reimbursable"}, "Travel"},
+		{"Tag-only rule does not categorize", "OLA CABS", -200, []string{"commute", "reimbursable"}, ""},
+		{"Amount range applies to tags", "HOTEL TAJ", -12000, []string{"needs-receipt"}, "Travel"},
+		{"Below range gets no tag", "HOTEL TAJ", -1200, []string{}, "Travel"},
+		{"No match", "AMAZON PAY", -500, []string{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := c.MatchTags(context.Background(), tt.description, tt.amount, userID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.tags, tags)
+
+			category, err := c.CategorizeWithAmount(context.Background(), tt.description, tt.amount, userID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.category, category)
+		})
+	}
+}
+
+func TestCategorizer_HasAmountRules(t *testing.T) {
+	c := &Categorizer{userRules: make(map[uuid.UUID][]Rule)}
+	plainUser, directionUser := uuid.New(), uuid.New()
+	c.userRules[plainUser] = []Rule{{Keyword: "swiggy", Category: "Food", MatchType: "substring"}}
+	c.userRules[directionUser] = []Rule{{Keyword: "int pd", Category: "Interest Income", MatchType: "substring", Direction: "credit"}}
+	c.globalRules = []Rule{{Keyword: "aws", Category: "Cloud & Hosting", MatchType: "substring", RuleType: "global"}}
+	c.lastLoaded = time.Now()
+	c.cacheTTL = time.Hour
+
+	hasAmountRules, err := c.HasAmountRules(context.Background(), plainUser)
+	require.NoError(t, err)
+	assert.False(t, hasAmountRules)
+
+	hasAmountRules, err = c.HasAmountRules(context.Background(), directionUser)
+	require.NoError(t, err)
+	assert.True(t, hasAmountRules)
 }
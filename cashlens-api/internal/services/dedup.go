@@ -0,0 +1,84 @@
+package services
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+)
+
+// DuplicateDetector flags incoming transactions that match already-imported ones.
+// When both carry a reference number, they match when reference number and amount
+// are equal. Otherwise they match when the normalized description and amount are
+// equal and their dates are at most ToleranceDays apart. The default tolerance of 0
+// requires an exact date match; a wider window is opt-in because it can produce
+// false positives.
+type DuplicateDetector struct {
+	toleranceDays int
+}
+
+// NewDuplicateDetector creates a detector with the given date tolerance in days
+func NewDuplicateDetector(toleranceDays int) *DuplicateDetector {
+	if toleranceDays < 0 {
+		toleranceDays = 0
+	}
+	return &DuplicateDetector{toleranceDays: toleranceDays}
+}
+
+// ToleranceDays returns the configured date tolerance in days
+func (d *DuplicateDetector) ToleranceDays() int {
+	return d.toleranceDays
+}
+
+// IsDuplicate reports whether two transactions should be treated as duplicates
+func (d *DuplicateDetector) IsDuplicate(a, b models.ParsedTransaction) bool {
+	if math.Abs(a.Amount-b.Amount) >= 0.005 {
+		return false
+	}
+	// Reference numbers identify a transaction even if the description or posting date changed
+	refA, refB := strings.TrimSpace(a.ReferenceNo), strings.TrimSpace(b.ReferenceNo)
+	if refA != "" && refB != "" {
+		return strings.EqualFold(refA, refB)
+	}
+	if normalizeDescription(a.Description) != normalizeDescription(b.Description) {
+		return false
+	}
+	return daysBetween(a.TxnDate, b.TxnDate) <= d.toleranceDays
+}
+
+// FindMatches returns, for each incoming transaction, the index of the first
+// existing transaction it duplicates, or -1 when it is new. Each existing
+// transaction is matched at most once, so identical rows within one statement
+// (two equal coffees on the same day) stay separate transactions.
+func (d *DuplicateDetector) FindMatches(incoming, existing []models.ParsedTransaction) []int {
+	matches := make([]int, len(incoming))
+	used := make([]bool, len(existing))
+	for i, txn := range incoming {
+		matches[i] = -1
+		for j, prev := range existing {
+			if !used[j] && d.IsDuplicate(txn, prev) {
+				matches[i] = j
+				used[j] = true
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// normalizeDescription uppercases a description and collapses its whitespace
+func normalizeDescription(description string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(description), " "))
+}
+
+// daysBetween returns the absolute number of calendar days between two dates
+func daysBetween(a, b time.Time) int {
+	a = time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	b = time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(a.Sub(b).Hours() / 24)
+	if days < 0 {
+		return -days
+	}
+	return days
+}
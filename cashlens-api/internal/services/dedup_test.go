@@ -0,0 +1,122 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplicateDetector_ExactMatch(t *testing.T) {
+	d := NewDuplicateDetector(0)
+
+	existing := models.ParsedTransaction{
+		TxnDate:     time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		Description: "AWS SERVICES",
+		Amount:      -3500.00,
+	}
+
+	tests := []struct {
+		name     string
+		txn      models.ParsedTransaction
+		expected bool
+	}{
+		{"Same date, description and amount", existing, true},
+		{"Description differs only in case", models.ParsedTransaction{TxnDate: existing.TxnDate, Description: "aws services", Amount: -3500.00}, true},
+		{"Next day is not a duplicate without tolerance", models.ParsedTransaction{TxnDate: existing.TxnDate.AddDate(0, 0, 1), Description: "AWS SERVICES", Amount: -3500.00}, false},
+		{"Different amount", models.ParsedTransaction{TxnDate: existing.TxnDate, Description: "AWS SERVICES", Amount: -3600.00}, false},
+		{"Different description", models.ParsedTransaction{TxnDate: existing.TxnDate, Description: "GCP SERVICES", Amount: -3500.00}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, d.IsDuplicate(tt.txn, existing))
+		})
+	}
+}
+
+func TestDuplicateDetector_WindowedMatch(t *testing.T) {
+	d := NewDuplicateDetector(1)
+
+	// Two rent payments on the same day, so each incoming row can claim one
+	existing := []models.ParsedTransaction{
+		{TxnDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), Description: "RENT PAYMENT", Amount: -25000.00},
+		{TxnDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), Description: "RENT PAYMENT", Amount: -25000.00},
+	}
+	incoming := []models.ParsedTransaction{
+		{TxnDate: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), Description: "RENT PAYMENT", Amount: -25000.00},
+		{TxnDate: time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC), Description: "RENT PAYMENT", Amount: -25000.00},
+		{TxnDate: time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC), Description: "RENT PAYMENT", Amount: -25000.00},
+		{TxnDate: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), Description: "RENT PAYMENT", Amount: -24000.00},
+	}
+
+	assert.Equal(t, []int{0, 1, -1, -1}, d.FindMatches(incoming, existing))
+}
+
+func TestDuplicateDetector_FindMatches(t *testing.T) {
+	d := NewDuplicateDetector(0)
+
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	existing := []models.ParsedTransaction{
+		{TxnDate: day, Description: "RENT PAYMENT", Amount: -25000.00},
+		{TxnDate: day, Description: "SWIGGY ORDER", Amount: -450.00},
+	}
+	incoming := []models.ParsedTransaction{
+		{TxnDate: day, Description: "SWIGGY ORDER", Amount: -450.00},
+		{TxnDate: day, Description: "ZOMATO ORDER", Amount: -300.00},
+		{TxnDate: day, Description: "rent payment", Amount: -25000.00},
+	}
+
+	assert.Equal(t, []int{1, -1, 0}, d.FindMatches(incoming, existing))
+}
+
+func TestDuplicateDetector_ReferenceNumber(t *testing.T) {
+	d := NewDuplicateDetector(0)
+
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	existing := models.ParsedTransaction{TxnDate: day, Description: "UPI/ZOMATO/ORDER", Amount: -300.00, ReferenceNo: "401100000002"}
+
+	tests := []struct {
+		name     string
+		txn      models.ParsedTransaction
+		expected bool
+	}{
+		{"Same reference despite a different date and description", models.ParsedTransaction{TxnDate: day.AddDate(0, 0, 1), Description: "UPI ZOMATO", Amount: -300.00, ReferenceNo: " 401100000002 "}, true},
+		{"Different reference with otherwise identical fields", models.ParsedTransaction{TxnDate: day, Description: "UPI/ZOMATO/ORDER", Amount: -300.00, ReferenceNo: "401100000003"}, false},
+		{"Same reference, different amount", models.ParsedTransaction{TxnDate: day, Description: "UPI/ZOMATO/ORDER", Amount: -350.00, ReferenceNo: "401100000002"}, false},
+		{"No reference falls back to the description", models.ParsedTransaction{TxnDate: day, Description: "upi/zomato/order", Amount: -300.00}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, d.IsDuplicate(tt.txn, existing))
+		})
+	}
+}
+
+func TestDuplicateDetector_NormalizesWhitespace(t *testing.T) {
+	d := NewDuplicateDetector(0)
+
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	a := models.ParsedTransaction{TxnDate: day, Description: "RENT  PAYMENT\tJAN", Amount: -25000.00}
+	b := models.ParsedTransaction{TxnDate: day, Description: " rent payment jan ", Amount: -25000.00}
+
+	assert.True(t, d.IsDuplicate(a, b))
+}
+
+func TestDuplicateDetector_FindMatchesUsesEachExistingOnce(t *testing.T) {
+	d := NewDuplicateDetector(0)
+
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	coffee := models.ParsedTransaction{TxnDate: day, Description: "CAFE COFFEE DAY", Amount: -150.00}
+	existing := []models.ParsedTransaction{coffee}
+	incoming := []models.ParsedTransaction{coffee, coffee}
+
+	assert.Equal(t, []int{0, -1}, d.FindMatches(incoming, existing))
+}
+
+func TestNewDuplicateDetector_NegativeToleranceDefaultsToZero(t *testing.T) {
+	d := NewDuplicateDetector(-3)
+	assert.Equal(t, 0, d.ToleranceDays())
+}
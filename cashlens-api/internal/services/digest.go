@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	digestTopCategories = 5    // Categories listed in a digest, largest spend first
+	digestSpikeRatio    = 1.5  // Outflow at least this multiple of the previous period's is an anomaly...
+	digestMinSpike      = 1000 // ...when it also grew by at least this many rupees
+)
+
+// DigestStore is the subset of db.Queries needed to generate digests
+type DigestStore interface {
+	GetUserByID(ctx context.Context, id pgtype.UUID) (db.User, error)
+	GetKPIs(ctx context.Context, arg db.GetKPIsParams) (db.GetKPIsRow, error)
+	GetCategoryBreakdown(ctx context.Context, arg db.GetCategoryBreakdownParams) ([]db.GetCategoryBreakdownRow, error)
+	ListUserIDs(ctx context.Context) ([]pgtype.UUID, error)
+}
+
+// DigestService composes periodic financial digests. It does not send email;
+// the payloads are returned for a mailer to pick up.
+type DigestService struct {
+	store DigestStore
+	now   func() time.Time
+}
+
+// NewDigestService creates a new digest service instance
+func NewDigestService(store DigestStore) *DigestService {
+	return &DigestService{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// DigestWindow returns the inclusive date range a digest generated at now covers:
+// the last complete Monday-Sunday week, or the last complete calendar month
+func DigestWindow(period string, now time.Time) (time.Time, time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case models.DigestPeriodWeekly:
+		daysSinceMonday := (int(today.Weekday()) + 6) % 7
+		from := today.AddDate(0, 0, -daysSinceMonday-7)
+		return from, from.AddDate(0, 0, 6), nil
+	case models.DigestPeriodMonthly:
+		thisMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return thisMonth.AddDate(0, -1, 0), thisMonth.AddDate(0, 0, -1), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid digest period %q - must be %s or %s",
+			period, models.DigestPeriodWeekly, models.DigestPeriodMonthly)
+	}
+}
+
+// GenerateDigest composes a user's digest for the last complete period: KPIs,
+// the top spending categories, and categories whose spending jumped compared
+// to the period before
+func (s *DigestService) GenerateDigest(ctx context.Context, userID uuid.UUID, period string) (models.Digest, error) {
+	now := s.now()
+	from, to, err := DigestWindow(period, now)
+	if err != nil {
+		return models.Digest{}, err
+	}
+	// The previous period is the one completed as this one started
+	previousFrom, previousTo, _ := DigestWindow(period, from)
+
+	// Convert uuid.UUID to pgtype.UUID
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userID
+	pgUserID.Valid = true
+
+	user, err := s.store.GetUserByID(ctx, pgUserID)
+	if err != nil {
+		return models.Digest{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	kpis, err := s.store.GetKPIs(ctx, db.GetKPIsParams{
+		UserID:    pgUserID,
+		TxnDate:   pgtype.Date{Time: from, Valid: true},
+		TxnDate_2: pgtype.Date{Time: to, Valid: true},
+	})
+	if err != nil {
+		return models.Digest{}, fmt.Errorf("failed to get KPIs: %w", err)
+	}
+
+	current, err := s.store.GetCategoryBreakdown(ctx, db.GetCategoryBreakdownParams{
+		UserID:    pgUserID,
+		TxnDate:   pgtype.Date{Time: from, Valid: true},
+		TxnDate_2: pgtype.Date{Time: to, Valid: true},
+	})
+	if err != nil {
+		return models.Digest{}, fmt.Errorf("failed to get category breakdown: %w", err)
+	}
+	previous, err := s.store.GetCategoryBreakdown(ctx, db.GetCategoryBreakdownParams{
+		UserID:    pgUserID,
+		TxnDate:   pgtype.Date{Time: previousFrom, Valid: true},
+		TxnDate_2: pgtype.Date{Time: previousTo, Valid: true},
+	})
+	if err != nil {
+		return models.Digest{}, fmt.Errorf("failed to get previous category breakdown: %w", err)
+	}
+
+	// Debits may be stored negative, so report magnitudes and derive the net
+	inflow := math.Abs(numericFloat(kpis.TotalInflow))
+	outflow := math.Abs(numericFloat(kpis.TotalOutflow))
+
+	return models.Digest{
+		UserID:   userID,
+		Email:    user.Email,
+		FullName: user.FullName.String,
+		Period:   period,
+		From:     models.NewDate(from),
+		To:       models.NewDate(to),
+		KPIs: models.DigestKPIs{
+			TotalInflow:      roundRupees(inflow),
+			TotalOutflow:     roundRupees(outflow),
+			NetCashFlow:      roundRupees(inflow - outflow),
+			TransactionCount: kpis.TransactionCount,
+		},
+		TopCategories: topCategories(current),
+		Anomalies:     spendingAnomalies(current, previous, period),
+		GeneratedAt:   now,
+	}, nil
+}
+
+// GenerateAllDigests generates the digest for every user
+func (s *DigestService) GenerateAllDigests(ctx context.Context, period string) ([]models.Digest, error) {
+	if _, _, err := DigestWindow(period, s.now()); err != nil {
+		return nil, err
+	}
+
+	userIDs, err := s.store.ListUserIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	digests := make([]models.Digest, 0, len(userIDs))
+	for _, pgUserID := range userIDs {
+		// Convert pgtype.UUID to uuid.UUID
+		var userID uuid.UUID
+		copy(userID[:], pgUserID.Bytes[:])
+
+		digest, err := s.GenerateDigest(ctx, userID, period)
+		if err != nil {
+			return digests, fmt.Errorf("failed to generate digest for user %s: %w", userID, err)
+		}
+		digests = append(digests, digest)
+	}
+	return digests, nil
+}
+
+// topCategories returns the largest spending categories with their share of total outflow.
+// Rows arrive largest outflow first; categories with no outflow are left out.
+func topCategories(rows []db.GetCategoryBreakdownRow) []models.DigestCategory {
+	total := 0.0
+	for _, row := range rows {
+		total += numericFloat(row.TotalOutflow)
+	}
+
+	categories := []models.DigestCategory{}
+	for _, row := range rows {
+		outflow := numericFloat(row.TotalOutflow)
+		if outflow <= 0 || len(categories) == digestTopCategories {
+			break
+		}
+		categories = append(categories, models.DigestCategory{
+			Category:         row.Category,
+			Outflow:          roundRupees(outflow),
+			TransactionCount: row.TransactionCount,
+			SharePercent:     math.Round(outflow/total*1000) / 10,
+		})
+	}
+	return categories
+}
+
+// spendingAnomalies returns categories whose outflow grew by digestSpikeRatio and at
+// least digestMinSpike rupees over the previous period, largest increase first
+func spendingAnomalies(current, previous []db.GetCategoryBreakdownRow, period string) []models.DigestAnomaly {
+	previousOutflow := make(map[string]float64, len(previous))
+	for _, row := range previous {
+		previousOutflow[row.Category] = numericFloat(row.TotalOutflow)
+	}
+
+	unit := "week"
+	if period == models.DigestPeriodMonthly {
+		unit = "month"
+	}
+
+	anomalies := []models.DigestAnomaly{}
+	for _, row := range current {
+		outflow := numericFloat(row.TotalOutflow)
+		before := previousOutflow[row.Category]
+		if outflow < before*digestSpikeRatio || outflow-before < digestMinSpike {
+			continue
+		}
+
+		message := fmt.Sprintf("%s spending was %.2f with none the previous %s", row.Category, outflow, unit)
+		if before > 0 {
+			message = fmt.Sprintf("%s spending rose %.0f%% to %.2f from %.2f the previous %s",
+				row.Category, (outflow/before-1)*100, outflow, before, unit)
+		}
+		anomalies = append(anomalies, models.DigestAnomaly{
+			Category:        row.Category,
+			Outflow:         roundRupees(outflow),
+			PreviousOutflow: roundRupees(before),
+			Message:         message,
+		})
+	}
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		return anomalies[i].Outflow-anomalies[i].PreviousOutflow > anomalies[j].Outflow-anomalies[j].PreviousOutflow
+	})
+	return anomalies
+}
+
+// numericFloat converts an aggregate column scanned into interface{} to float64
+func numericFloat(val interface{}) float64 {
+	switch v := val.(type) {
+	case pgtype.Numeric:
+		f, _ := v.Float64Value()
+		return f.Float64
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	default:
+		return 0
+	}
+}
+
+// roundRupees rounds an amount to paise
+func roundRupees(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type digestTxn struct {
+	date     time.Time
+	category string
+	amount   float64 // Debits negative
+}
+
+// fakeDigestStore aggregates an in-memory list of transactions per user like the summary queries
+type fakeDigestStore struct {
+	users map[uuid.UUID]db.User
+	txns  map[uuid.UUID][]digestTxn
+}
+
+func (f *fakeDigestStore) inRange(userID pgtype.UUID, from, to pgtype.Date) []digestTxn {
+	var txns []digestTxn
+	for _, txn := range f.txns[uuid.UUID(userID.Bytes)] {
+		if !txn.date.Before(from.Time) && !txn.date.After(to.Time) {
+			txns = append(txns, txn)
+		}
+	}
+	return txns
+}
+
+func (f *fakeDigestStore) GetUserByID(ctx context.Context, id pgtype.UUID) (db.User, error) {
+	return f.users[uuid.UUID(id.Bytes)], nil
+}
+
+func (f *fakeDigestStore) GetKPIs(ctx context.Context, arg db.GetKPIsParams) (db.GetKPIsRow, error) {
+	var inflow, outflow float64
+	txns := f.inRange(arg.UserID, arg.TxnDate, arg.TxnDate_2)
+	for _, txn := range txns {
+		if txn.amount < 0 {
+			outflow += txn.amount
+		} else {
+			inflow += txn.amount
+		}
+	}
+	return db.GetKPIsRow{TotalInflow: inflow, TotalOutflow: outflow, TransactionCount: int64(len(txns))}, nil
+}
+
+func (f *fakeDigestStore) GetCategoryBreakdown(ctx context.Context, arg db.GetCategoryBreakdownParams) ([]db.GetCategoryBreakdownRow, error) {
+	outflows := map[string]float64{}
+	counts := map[string]int64{}
+	for _, txn := range f.inRange(arg.UserID, arg.TxnDate, arg.TxnDate_2) {
+		if txn.amount < 0 {
+			outflows[txn.category] -= txn.amount
+		}
+		counts[txn.category]++
+	}
+	rows := []db.GetCategoryBreakdownRow{}
+	for category, count := range counts {
+		rows = append(rows, db.GetCategoryBreakdownRow{Category: category, TotalOutflow: outflows[category], TransactionCount: count})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].TotalOutflow.(float64) > rows[j].TotalOutflow.(float64)
+	})
+	return rows, nil
+}
+
+func (f *fakeDigestStore) ListUserIDs(ctx context.Context) ([]pgtype.UUID, error) {
+	ids := []pgtype.UUID{}
+	for id := range f.users {
+		ids = append(ids, pgtype.UUID{Bytes: id, Valid: true})
+	}
+	return ids, nil
+}
+
+func TestDigestWindow(t *testing.T) {
+	// Wednesday 2024-03-13
+	now := time.Date(2024, 3, 13, 9, 30, 0, 0, time.UTC)
+
+	from, to, err := DigestWindow(models.DigestPeriodWeekly, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), to)
+
+	from, to, err = DigestWindow(models.DigestPeriodMonthly, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), to)
+
+	_, _, err = DigestWindow("daily", now)
+	assert.Error(t, err)
+}
+
+func TestDigestService_GenerateDigest(t *testing.T) {
+	userID := uuid.New()
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	store := &fakeDigestStore{
+		users: map[uuid.UUID]db.User{
+			userID: {Email: "priya@example.com", FullName: pgtype.Text{String: "Priya Shah", Valid: true}},
+		},
+		txns: map[uuid.UUID][]digestTxn{
+			userID: {
+				// Previous week (Feb 26 - Mar 3)
+				{day(1), "Food & Dining", -1200},
+				{day(2), "Travel", -800},
+				// Digest week (Mar 4 - Mar 10)
+				{day(4), "Salary", 50000},
+				{day(5), "Food & Dining", -1500},
+				{day(6), "Travel", -2000},
+				{day(7), "Travel", -1500},
+				{day(8), "Shopping", -4000},
+				{day(9), "Food & Dining", -500},
+				// After the digest week
+				{day(11), "Shopping", -9999},
+			},
+		},
+	}
+	svc := NewDigestService(store)
+	svc.now = func() time.Time { return time.Date(2024, 3, 13, 9, 30, 0, 0, time.UTC) }
+
+	digest, err := svc.GenerateDigest(context.Background(), userID, models.DigestPeriodWeekly)
+	require.NoError(t, err)
+
+	assert.Equal(t, userID, digest.UserID)
+	assert.Equal(t, "priya@example.com", digest.Email)
+	assert.Equal(t, "Priya Shah", digest.FullName)
+	assert.Equal(t, "2024-03-04", digest.From.String())
+	assert.Equal(t, "2024-03-10", digest.To.String())
+	assert.Equal(t, models.DigestKPIs{
+		TotalInflow:      50000,
+		TotalOutflow:     9500,
+		NetCashFlow:      40500,
+		TransactionCount: 6,
+	}, digest.KPIs)
+
+	assert.Equal(t, []models.DigestCategory{
+		{Category: "Shopping", Outflow: 4000, TransactionCount: 1, SharePercent: 42.1},
+		{Category: "Travel", Outflow: 3500, TransactionCount: 2, SharePercent: 36.8},
+		{Category: "Food & Dining", Outflow: 2000, TransactionCount: 2, SharePercent: 21.1},
+	}, digest.TopCategories)
+
+	// Food & Dining grew by less than 1000, so only Shopping (new) and Travel are anomalies
+	require.Len(t, digest.Anomalies, 2)
+	assert.Equal(t, "Shopping", digest.Anomalies[0].Category)
+	assert.Equal(t, 0.0, digest.Anomalies[0].PreviousOutflow)
+	assert.Equal(t, "Shopping spending was 4000.00 with none the previous week", digest.Anomalies[0].Message)
+	assert.Equal(t, "Travel", digest.Anomalies[1].Category)
+	assert.Equal(t, 800.0, digest.Anomalies[1].PreviousOutflow)
+	assert.Equal(t, "Travel spending rose 338% to 3500.00 from 800.00 the previous week", digest.Anomalies[1].Message)
+}
+
+func TestDigestService_GenerateAllDigests(t *testing.T) {
+	store := &fakeDigestStore{
+		users: map[uuid.UUID]db.User{
+			uuid.New(): {Email: "a@example.com"},
+			uuid.New(): {Email: "b@example.com"},
+		},
+	}
+	svc := NewDigestService(store)
+
+	digests, err := svc.GenerateAllDigests(context.Background(), models.DigestPeriodMonthly)
+	require.NoError(t, err)
+	require.Len(t, digests, 2)
+	for _, digest := range digests {
+		assert.Equal(t, models.DigestPeriodMonthly, digest.Period)
+		assert.Empty(t, digest.TopCategories)
+		assert.Empty(t, digest.Anomalies)
+	}
+
+	_, err = svc.GenerateAllDigests(context.Background(), "daily")
+	assert.Error(t, err)
+}
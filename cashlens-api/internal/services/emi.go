@@ -0,0 +1,60 @@
+package services
+
+import (
+	"math"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+)
+
+const (
+	emiMinOccurrences = 3 // Monthly debits needed before a loan series is suggested as EMIs
+	emiAmountSpread   = 1 // Rupees the installments of one loan may differ by (rounding)
+)
+
+// EMIPatterns are the built-in regex rules for loan EMI debits. They are suggested
+// debit-only, so loan disbursals and refunds credited to the account never match.
+var EMIPatterns = []SuggestionPattern{
+	{"EMI", `\bEMI\b`, models.CategoryLoanEMI},
+	{"ACH/NACH loan debit", `\bN?ACH\b.*LOAN`, models.CategoryLoanEMI},
+	{"Loan installment", `\bLOAN\b.*\b(REPAY(MENT)?|INST(AL+MENT)?)\b`, models.CategoryLoanEMI},
+}
+
+// NewEMISuggester suggests the built-in EMI rules for loan repayments: debits matching
+// an EMI pattern that recur monthly with a fixed amount. One-off matches such as a
+// foreclosure payment are left out. Suggestions use category, or
+// models.CategoryLoanEMI when it is empty.
+func NewEMISuggester(category string) *RegexSuggester {
+	patterns := make([]SuggestionPattern, len(EMIPatterns))
+	copy(patterns, EMIPatterns)
+	if category != "" {
+		for i := range patterns {
+			patterns[i].Category = category
+		}
+	}
+
+	s := NewRegexSuggester(models.DirectionDebit, patterns)
+	s.keep = fixedMonthlyInstallments
+	return s
+}
+
+// fixedMonthlyInstallments keeps the transactions that form fixed-amount monthly series
+func fixedMonthlyInstallments(txns []models.Transaction) []models.Transaction {
+	var kept []models.Transaction
+	for _, series := range FindMonthlySeries(txns, emiMinOccurrences) {
+		if isFixedAmount(series.Transactions) {
+			kept = append(kept, series.Transactions...)
+		}
+	}
+	return kept
+}
+
+// isFixedAmount reports whether every installment is within emiAmountSpread of the others
+func isFixedAmount(txns []models.Transaction) bool {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, txn := range txns {
+		amount := math.Abs(txn.Amount)
+		low = math.Min(low, amount)
+		high = math.Max(high, amount)
+	}
+	return high-low <= emiAmountSpread
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emiSeries returns count monthly debits of amount starting on start
+func emiSeries(description string, amount float64, start time.Time, count int) []models.Transaction {
+	txns := make([]models.Transaction, count)
+	for i := range txns {
+		txns[i] = recurringTxn(description, amount, start.AddDate(0, i, 0))
+	}
+	return txns
+}
+
+func TestEMISuggester_Suggest(t *testing.T) {
+	start := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	homeLoan := emiSeries("ACH D- HDFCLOAN-12345678", -24350, start, 6)
+	carLoan := emiSeries("EMI 0004 BAJAJ FINSERV LTD", -8120.50, start.AddDate(0, 0, 5), 4)
+
+	txns := append([]models.Transaction{}, homeLoan...)
+	txns = append(txns, carLoan...)
+	txns = append(txns,
+		// Fewer than three installments, varying amounts, credits and unrelated debits are skipped
+		recurringTxn("ACH D- ICICILOAN-555", -5000, start),
+		recurringTxn("ACH D- ICICILOAN-555", -5000, start.AddDate(0, 1, 0)),
+		recurringTxn("LOAN REPAYMENT TATA CAPITAL", -3000, start),
+		recurringTxn("LOAN REPAYMENT TATA CAPITAL", -3400, start.AddDate(0, 1, 0)),
+		recurringTxn("LOAN REPAYMENT TATA CAPITAL", -3200, start.AddDate(0, 2, 0)),
+		recurringTxn("ACH C- HDFCLOAN DISBURSAL", 500000, start),
+		recurringTxn("UPI/ZOMATO/ORDER", -350, start),
+	)
+
+	suggestions := NewEMISuggester("").Suggest(txns)
+
+	require.Len(t, suggestions, 2)
+	for _, suggestion := range suggestions {
+		assert.Equal(t, models.CategoryLoanEMI, suggestion.Category)
+		assert.Equal(t, "regex", suggestion.MatchType)
+		assert.Equal(t, models.DirectionDebit, suggestion.Direction)
+	}
+
+	assert.Equal(t, EMIPatterns[0].Pattern, suggestions[0].Keyword)
+	assert.Equal(t, 4, suggestions[0].Occurrences)
+	assert.InDelta(t, -8120.50, suggestions[0].AverageAmount, 0.01)
+
+	assert.Equal(t, EMIPatterns[1].Pattern, suggestions[1].Keyword)
+	require.Len(t, suggestions[1].TransactionIDs, 6)
+	for i, txn := range homeLoan {
+		assert.Equal(t, txn.ID, suggestions[1].TransactionIDs[i])
+	}
+	assert.InDelta(t, -24350.0, suggestions[1].AverageAmount, 0.01)
+}
+
+func TestEMISuggester_ConfiguredCategory(t *testing.T) {
+	start := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	txns := emiSeries("ACH D- HDFCLOAN-12345678", -24350, start, 4)
+
+	// Installments already in the category are not suggested again
+	category := "Home Loan"
+	txns[0].Category = &category
+
+	suggestions := NewEMISuggester(category).Suggest(txns)
+
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, "Home Loan", suggestions[0].Category)
+	assert.Equal(t, 3, suggestions[0].Occurrences)
+
+	for i := range txns {
+		txns[i].Category = &category
+	}
+	assert.Empty(t, NewEMISuggester(category).Suggest(txns))
+}
+
+// TestCategorizer_EMIRules tests the built-in patterns as debit-only user rules created
+// from the suggestions
+func TestCategorizer_EMIRules(t *testing.T) {
+	c := NewCategorizer(nil)
+	c.globalRules = []Rule{
+		{Keyword: "premium", Category: "Insurance", Priority: 9, MatchType: "substring", RuleType: "global"},
+	}
+	c.lastLoaded = time.Now()
+	userID := uuid.New()
+	for _, p := range EMIPatterns {
+		c.userRules[userID] = append(c.userRules[userID], Rule{
+			Keyword: p.Pattern, Category: models.CategoryLoanEMI, Priority: 100, MatchType: "regex", RuleType: "user",
+			Direction: models.DirectionDebit,
+		})
+	}
+
+	tests := []struct {
+		description string
+		amount      float64
+		expected    string
+	}{
+		{"ACH D- HDFCLOAN-12345678", -24350, models.CategoryLoanEMI},
+		{"NACH DR BAJAJ FINANCE LOAN 4XX12", -8120, models.CategoryLoanEMI},
+		{"emi 0004 bajaj finserv ltd", -8120.50, models.CategoryLoanEMI},
+		{"LOAN INSTALLMENT A/C 998877", -3000, models.CategoryLoanEMI},
+		{"ACH C- HDFCLOAN DISBURSAL", 500000, ""},
+		{"PREMIUM PAID LIC", -2500, "Insurance"},
+		{"UPI/ZOMATO/ORDER", -350, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %.2f", tt.description, tt.amount), func(t *testing.T) {
+			category, err := c.CategorizeWithAmount(context.Background(), tt.description, tt.amount, userID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, category)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// encodingSniffBytes is how much of a file is inspected to guess its encoding
+const encodingSniffBytes = 4 * 1024
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// utf8Reader transcodes a delimited text export to UTF-8. UTF-16 files are recognized
+// by their byte order mark (Excel's "Unicode text" export); a UTF-8 BOM is dropped.
+// Without a BOM, text that isn't valid UTF-8 is read as Windows-1252, the usual
+// encoding of Latin-1 exports from older core-banking systems.
+func utf8Reader(file io.Reader) io.Reader {
+	buffered := bufio.NewReaderSize(file, encodingSniffBytes)
+	sample, _ := buffered.Peek(encodingSniffBytes) // A short file returns what's there
+
+	switch {
+	case bytes.HasPrefix(sample, utf8BOM):
+		buffered.Discard(len(utf8BOM))
+		return buffered
+	case bytes.HasPrefix(sample, utf16LEBOM), bytes.HasPrefix(sample, utf16BEBOM):
+		decoder := unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder()
+		return transform.NewReader(buffered, decoder)
+	case !validUTF8Prefix(sample, len(sample) == encodingSniffBytes):
+		return transform.NewReader(buffered, charmap.Windows1252.NewDecoder())
+	}
+	return buffered
+}
+
+// validUTF8Prefix reports whether sample is valid UTF-8. A truncated sample may end
+// partway through a multi-byte character, so an incomplete final rune is ignored.
+func validUTF8Prefix(sample []byte, truncated bool) bool {
+	if truncated {
+		for i := 0; i < utf8.UTFMax-1 && len(sample) > 0; i++ {
+			if r, size := utf8.DecodeLastRune(sample); r != utf8.RuneError || size != 1 {
+				break
+			}
+			sample = sample[:len(sample)-1]
+		}
+	}
+	return utf8.Valid(sample)
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestParseCSV_UTF16(t *testing.T) {
+	file, err := os.Open("../../testdata/hdfc_utf16_sample.csv")
+	require.NoError(t, err)
+	defer file.Close()
+
+	transactions, err := NewParser().ParseCSV(file)
+
+	require.NoError(t, err)
+	require.Len(t, transactions, 10)
+	assert.Equal(t, "AWS SERVICES", transactions[0].Description)
+	assert.Equal(t, -3500.0, transactions[0].Amount)
+	assert.Equal(t, "SALARY CREDIT - ACME CORP", transactions[1].Description)
+	assert.Equal(t, 50000.0, transactions[1].Amount)
+	assert.Equal(t, "UBER FOR BUSINESS", transactions[9].Description)
+}
+
+func TestUTF8Reader(t *testing.T) {
+	const text = "Date,Narration\n15/01/2024,CAFÉ COFFEE DAY – MG ROAD\n"
+
+	utf16BE, err := unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewEncoder().String(text)
+	require.NoError(t, err)
+	windows1252, err := charmap.Windows1252.NewEncoder().String(text)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name  string
+		input []byte
+	}{
+		{"UTF-8", []byte(text)},
+		{"UTF-8 with BOM", append([]byte{0xEF, 0xBB, 0xBF}, text...)},
+		{"UTF-16 BE with BOM", []byte(utf16BE)},
+		{"Windows-1252", []byte(windows1252)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			decoded, err := io.ReadAll(utf8Reader(bytes.NewReader(tc.input)))
+			require.NoError(t, err)
+			assert.Equal(t, text, string(decoded))
+		})
+	}
+}
+
+func TestValidUTF8Prefix(t *testing.T) {
+	rupee := []byte("₹") // 3 bytes
+
+	assert.True(t, validUTF8Prefix([]byte("AMOUNT "+strings.Repeat("₹", 2)), false))
+	assert.True(t, validUTF8Prefix(append([]byte("AMOUNT "), rupee[:2]...), true), "rune cut by the sample size")
+	assert.False(t, validUTF8Prefix(append([]byte("AMOUNT "), rupee[:2]...), false))
+	assert.False(t, validUTF8Prefix([]byte("CAF\xC9 COFFEE"), true))
+}
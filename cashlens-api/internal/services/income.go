@@ -0,0 +1,18 @@
+package services
+
+import "github.com/ashmitsharp/cashlens-api/internal/models"
+
+// IncomePatterns are the built-in regex rules for interest and dividend credits. They
+// are suggested credit-only, so debits that mention interest (loan interest, "INT CHGS")
+// never match.
+var IncomePatterns = []SuggestionPattern{
+	{"Interest paid", `\bINT(EREST)?\.? ?(PD|PAID)\b`, models.CategoryInterest},
+	{"Interest credited", `\bINT(EREST)?\.? ?CREDITED\b`, models.CategoryInterest},
+	{"Dividend", `\bDIVIDEND\b`, models.CategoryDividend},
+}
+
+// NewIncomeSuggester suggests the built-in interest and dividend rules that would
+// categorize the user's uncategorized bank interest and investment payouts
+func NewIncomeSuggester() *RegexSuggester {
+	return NewRegexSuggester(models.DirectionCredit, IncomePatterns)
+}
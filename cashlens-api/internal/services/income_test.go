@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncomeSuggester_Suggest(t *testing.T) {
+	interest := models.CategoryInterest
+	txns := []models.Transaction{
+		cashTxn("INT PD 01-01-2024 TO 31-03-2024", 1520.50, nil),
+		cashTxn("Interest Paid Till 30-Jun-2024", 1479.50, nil),
+		cashTxn("INT.CREDITED FD 123456789", 8200, nil),
+		cashTxn("ACH C- INFOSYS LTD DIVIDEND 2024", 450, nil),
+		// Already categorized, debits and unrelated credits are skipped
+		cashTxn("INT PD 01-04-2024 TO 30-06-2024", 1400, &interest),
+		cashTxn("INTEREST PAID ON LOAN A/C 998877", -2300, nil),
+		cashTxn("DIVIDEND REINVESTMENT PURCHASE", -450, nil),
+		cashTxn("NEFT CR ACME PAYROLL", 85000, nil),
+	}
+
+	suggestions := NewIncomeSuggester().Suggest(txns)
+
+	require.Len(t, suggestions, 3)
+	for i, suggestion := range suggestions {
+		assert.Equal(t, IncomePatterns[i].Category, suggestion.Category)
+		assert.Equal(t, IncomePatterns[i].Pattern, suggestion.Keyword)
+		assert.Equal(t, "regex", suggestion.MatchType)
+		assert.Equal(t, models.DirectionCredit, suggestion.Direction)
+	}
+	assert.Equal(t, []uuid.UUID{txns[0].ID, txns[1].ID}, suggestions[0].TransactionIDs)
+	assert.InDelta(t, 1500.0, suggestions[0].AverageAmount, 0.01)
+	assert.Equal(t, []uuid.UUID{txns[2].ID}, suggestions[1].TransactionIDs)
+	assert.Equal(t, models.CategoryDividend, suggestions[2].Category)
+	assert.Equal(t, []uuid.UUID{txns[3].ID}, suggestions[2].TransactionIDs)
+}
+
+func TestIncomeSuggester_NoIncome(t *testing.T) {
+	txns := []models.Transaction{
+		cashTxn("UPI/ZOMATO/ORDER", -350, nil),
+		cashTxn("INT CHGS FOR OD LIMIT", -120, nil),
+	}
+
+	assert.Empty(t, NewIncomeSuggester().Suggest(txns))
+}
+
+// TestCategorizer_IncomeRules tests the built-in patterns as credit-only user rules created
+// from the suggestions: credits are categorized, similarly worded debits are not
+func TestCategorizer_IncomeRules(t *testing.T) {
+	c := NewCategorizer(nil)
+	c.globalRules = []Rule{
+		{Keyword: "loan", Category: "Loan Repayment", Priority: 9, MatchType: "substring", RuleType: "global"},
+	}
+	c.lastLoaded = time.Now()
+	userID := uuid.New()
+	for _, p := range IncomePatterns {
+		c.userRules[userID] = append(c.userRules[userID], Rule{
+			Keyword: p.Pattern, Category: p.Category, Priority: 100, MatchType: "regex", RuleType: "user",
+			Direction: models.DirectionCredit,
+		})
+	}
+
+	tests := []struct {
+		description string
+		amount      float64
+		expected    string
+	}{
+		{"INT PD 01-01-2024 TO 31-03-2024", 1520.50, models.CategoryInterest},
+		{"int.pd:1234567890:01-01-2024", 95, models.CategoryInterest},
+		{"INTEREST CREDITED", 310, models.CategoryInterest},
+		{"ACH C- INFOSYS LTD DIVIDEND 2024", 450, models.CategoryDividend},
+		{"INTEREST PAID ON LOAN A/C 998877", -2300, "Loan Repayment"},
+		{"INT PD REVERSAL", -95, ""},
+		{"DIVIDEND REINVESTMENT PURCHASE", -450, ""},
+		{"UPI/ZOMATO/ORDER", 350, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			category, err := c.CategorizeWithAmount(context.Background(), tt.description, tt.amount, userID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, category)
+		})
+	}
+
+	t.Run("Unknown amount skips credit-only rules", func(t *testing.T) {
+		category, err := c.Categorize(context.Background(), "INT PD 01-01-2024 TO 31-03-2024", userID)
+		require.NoError(t, err)
+		assert.Empty(t, category)
+	})
+}
+
+func TestRule_MatchesAmountDirection(t *testing.T) {
+	credit, debit, zero := 100.0, -100.0, 0.0
+	minAmount := 50.0
+
+	tests := []struct {
+		name     string
+		rule     Rule
+		amount   *float64
+		expected bool
+	}{
+		{"No direction matches credit", Rule{}, &credit, true},
+		{"No direction matches debit", Rule{}, &debit, true},
+		{"Credit rule matches credit", Rule{Direction: models.DirectionCredit}, &credit, true},
+		{"Credit rule skips debit", Rule{Direction: models.DirectionCredit}, &debit, false},
+		{"Credit rule skips zero", Rule{Direction: models.DirectionCredit}, &zero, false},
+		{"Debit rule matches debit", Rule{Direction: models.DirectionDebit}, &debit, true},
+		{"Debit rule skips credit", Rule{Direction: models.DirectionDebit}, &credit, false},
+		{"Direction rule skips unknown amount", Rule{Direction: models.DirectionDebit}, nil, false},
+		{"Direction combines with bounds", Rule{Direction: models.DirectionDebit, MinAmount: &minAmount}, &debit, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.rule.matchesAmount(tt.amount))
+		})
+	}
+}
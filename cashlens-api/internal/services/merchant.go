@@ -0,0 +1,42 @@
+package services
+
+import "strings"
+
+// paymentChannelWords appear in descriptions across many merchants (payment rails,
+// directions, legal suffixes), so they never identify one
+var paymentChannelWords = map[string]bool{
+	"UPI": true, "NEFT": true, "IMPS": true, "RTGS": true, "ACH": true, "ECS": true, "NACH": true,
+	"POS": true, "ATM": true, "CARD": true, "DEBIT": true, "CREDIT": true, "PURCHASE": true,
+	"PAYMENT": true, "PAY": true, "TRANSFER": true, "TRF": true, "TXN": true, "REF": true,
+	"BIL": true, "ONL": true, "INB": true, "MOB": true, "P2M": true, "P2A": true,
+	"TO": true, "FROM": true, "BY": true, "FOR": true, "THE": true, "AND": true,
+	"DR": true, "CR": true, "PVT": true, "LTD": true, "LIMITED": true, "INDIA": true,
+	"SERVICES": true, "PRIVATE": true, "BANK": true, "COM": true, "WWW": true,
+}
+
+// MerchantKeyword picks the word most likely to name the merchant in a transaction
+// description: the first word of at least three letters that carries no digits and
+// isn't a payment-rail or filler token (merchant names lead after the rail prefix).
+// Returns "" when nothing qualifies. The keyword is lowercased for a substring rule.
+func MerchantKeyword(description string) string {
+	for _, word := range strings.Fields(counterpartyKey(description)) {
+		if len(word) >= 3 && !paymentChannelWords[word] {
+			return strings.ToLower(word)
+		}
+	}
+	return ""
+}
+
+// RuleLearner derives the keyword of a rule learned from a category correction
+type RuleLearner struct{}
+
+// NewRuleLearner creates a rule learner
+func NewRuleLearner() *RuleLearner {
+	return &RuleLearner{}
+}
+
+// Keyword returns the substring keyword for a corrected transaction, or "" when its
+// description has no usable merchant word
+func (l *RuleLearner) Keyword(description string) string {
+	return MerchantKeyword(description)
+}
@@ -0,0 +1,116 @@
+package services
+
+// RuleTypeDictionary marks matches made by the built-in merchant dictionary fallback
+const RuleTypeDictionary = "dictionary"
+
+// MerchantEntry maps a well-known merchant keyword to its usual category
+type MerchantEntry struct {
+	Keyword  string
+	Category string
+}
+
+// DefaultMerchantDictionary is the seed list of common Indian merchants used when
+// no global or user rule matches. Keywords are substrings of the narration and are
+// kept long enough not to hit unrelated words; categories follow the global rules.
+var DefaultMerchantDictionary = []MerchantEntry{
+	// Food delivery and restaurants
+	{"zomato", "Team Meals"},
+	{"swiggy", "Team Meals"},
+	{"eatsure", "Team Meals"},
+	{"dominos", "Team Meals"},
+	{"mcdonalds", "Team Meals"},
+	{"starbucks", "Team Meals"},
+	{"haldiram", "Team Meals"},
+
+	// Travel
+	{"uber", "Travel"},
+	{"olacabs", "Travel"},
+	{"rapido", "Travel"},
+	{"irctc", "Travel"},
+	{"makemytrip", "Travel"},
+	{"goibibo", "Travel"},
+	{"cleartrip", "Travel"},
+	{"yatra.com", "Travel"},
+	{"redbus", "Travel"},
+	{"indigo", "Travel"},
+	{"air india", "Travel"},
+	{"zoomcar", "Travel"},
+	{"fastag", "Travel"},
+
+	// Telecom and utilities
+	{"airtel", "Utilities"},
+	{"reliance jio", "Utilities"},
+	{"vodafone", "Utilities"},
+	{"bsnl", "Utilities"},
+	{"act fibernet", "Utilities"},
+	{"tata power", "Utilities"},
+	{"adani electricity", "Utilities"},
+	{"bescom", "Utilities"},
+	{"mahanagar gas", "Utilities"},
+
+	// Software and cloud
+	{"google workspace", "Software & SaaS"},
+	{"microsoft", "Software & SaaS"},
+	{"zoom.us", "Software & SaaS"},
+	{"slack", "Software & SaaS"},
+	{"atlassian", "Software & SaaS"},
+	{"notion", "Software & SaaS"},
+	{"adobe", "Software & SaaS"},
+	{"canva", "Software & SaaS"},
+	{"amazon web services", "Cloud & Hosting"},
+	{"digitalocean", "Cloud & Hosting"},
+	{"hostinger", "Cloud & Hosting"},
+
+	// Office purchases
+	{"flipkart", "Office Supplies"},
+	{"croma", "Office Supplies"},
+	{"reliance digital", "Office Supplies"},
+
+	// Marketing
+	{"facebook ads", "Marketing"},
+	{"linkedin", "Marketing"},
+	{"justdial", "Marketing"},
+
+	// Insurance
+	{"lic of india", "Insurance"},
+	{"policybazaar", "Insurance"},
+	{"icici lombard", "Insurance"},
+	{"hdfc ergo", "Insurance"},
+
+	// Payment gateways
+	{"razorpay", "Payment Processing"},
+	{"cashfree", "Payment Processing"},
+}
+
+// SetMerchantDictionaryFallback enables categorizing transactions that match no
+// global or user rule with the built-in merchant dictionary
+func (c *Categorizer) SetMerchantDictionaryFallback(enabled bool) {
+	if !enabled {
+		c.dictionary = nil
+		return
+	}
+	c.dictionary = merchantDictionaryRules(DefaultMerchantDictionary)
+}
+
+// merchantDictionaryRules converts dictionary entries into substring rules
+func merchantDictionaryRules(entries []MerchantEntry) []Rule {
+	rules := make([]Rule, 0, len(entries))
+	for _, entry := range entries {
+		rules = append(rules, Rule{
+			Keyword:   entry.Keyword,
+			Category:  entry.Category,
+			MatchType: "substring",
+			RuleType:  RuleTypeDictionary,
+		})
+	}
+	return rules
+}
+
+// bestMatchWithFallback is bestMatch over the rules, falling back to the merchant
+// dictionary (when enabled) if none of them match
+func (c *Categorizer) bestMatchWithFallback(description string, amount *float64, rules []Rule) (Rule, float64, bool) {
+	if rule, score, ok := c.bestMatch(description, amount, rules); ok || len(c.dictionary) == 0 {
+		return rule, score, ok
+	}
+	return c.bestMatch(description, amount, c.dictionary)
+}
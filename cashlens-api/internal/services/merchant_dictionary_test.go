@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCachedCategorizer returns a categorizer whose rule caches are already loaded
+func newCachedCategorizer(userID uuid.UUID, globalRules, userRules []Rule) *Categorizer {
+	c := NewCategorizer(nil)
+	c.globalRules = globalRules
+	c.lastLoaded = time.Now()
+	c.userRules[userID] = userRules
+	return c
+}
+
+func TestMerchantDictionaryFallback(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	globalRules := []Rule{{Keyword: "aws", Category: "Cloud & Hosting", Priority: 5, MatchType: "substring", RuleType: "global"}}
+	userRules := []Rule{{Keyword: "acme", Category: "Consulting", Priority: 100, MatchType: "substring", RuleType: "user"}}
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		c := newCachedCategorizer(userID, globalRules, userRules)
+
+		category, err := c.Categorize(ctx, "UPI/ZOMATO/401234567890", userID)
+		require.NoError(t, err)
+		assert.Empty(t, category)
+	})
+
+	t.Run("Unmatched merchant categorized by the dictionary", func(t *testing.T) {
+		c := newCachedCategorizer(userID, globalRules, userRules)
+		c.SetMerchantDictionaryFallback(true)
+
+		match, err := c.CategorizeMatch(ctx, "UPI/ZOMATO/401234567890", userID)
+		require.NoError(t, err)
+		assert.Equal(t, "Team Meals", match.Category)
+		assert.Equal(t, RuleTypeDictionary, match.RuleType)
+		assert.Equal(t, "substring", match.MatchType)
+		assert.Equal(t, uuid.Nil, match.RuleID)
+
+		category, err := c.CategorizeWithAmount(ctx, "POS IRCTC E-TICKET", -1450, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "Travel", category)
+	})
+
+	t.Run("Rules win over the dictionary", func(t *testing.T) {
+		c := newCachedCategorizer(userID, globalRules, userRules)
+		c.SetMerchantDictionaryFallback(true)
+
+		category, err := c.Categorize(ctx, "ACME SWIGGY CORPORATE ORDER", userID)
+		require.NoError(t, err)
+		assert.Equal(t, "Consulting", category)
+	})
+
+	t.Run("Unknown merchant stays uncategorized", func(t *testing.T) {
+		c := newCachedCategorizer(userID, globalRules, userRules)
+		c.SetMerchantDictionaryFallback(true)
+
+		category, err := c.Categorize(ctx, "NEFT SHARMA TRADERS", userID)
+		require.NoError(t, err)
+		assert.Empty(t, category)
+	})
+}
+
+func TestDefaultMerchantDictionary(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, entry := range DefaultMerchantDictionary {
+		assert.NotEmpty(t, entry.Category, entry.Keyword)
+		assert.GreaterOrEqual(t, len(entry.Keyword), 4, "short keywords match unrelated words: %q", entry.Keyword)
+		assert.False(t, seen[entry.Keyword], "duplicate keyword %q", entry.Keyword)
+		seen[entry.Keyword] = true
+	}
+}
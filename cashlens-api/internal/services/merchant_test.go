@@ -0,0 +1,29 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerchantKeyword(t *testing.T) {
+	tests := []struct {
+		description string
+		expected    string
+	}{
+		{"UPI/SWIGGY/401234567890/PAYMENT", "swiggy"},
+		{"POS 4512XXXX1234 AMAZON PAY INDIA", "amazon"},
+		{"NEFT-HDFC0001234-ACME TECHNOLOGIES PVT LTD", "acme"},
+		{"PAYMENT TO AWS SERVICES", "aws"},
+		{"ACH D- ZERODHA BROKING", "zerodha"},
+		{"SWIGGY INSTAMART ORDER", "swiggy"},
+		{"IMPS/P2A/401234567890", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MerchantKeyword(tt.description))
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+)
+
+// ErrNotOFX is returned when a file has no OFX root element
+var ErrNotOFX = errors.New("not an OFX file: missing <OFX> element")
+
+// ErrUnsupportedOFX is returned for OFX files without a bank or credit card statement,
+// such as investment statements
+var ErrUnsupportedOFX = errors.New("unsupported OFX statement: only bank and credit card statements can be imported")
+
+var (
+	// ofxTransaction matches a transaction aggregate; aggregates are closed in both
+	// SGML (OFX 1.x) and XML (OFX 2.x) files
+	ofxTransaction = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+	// ofxElement matches a leaf element and its value. SGML leaves usually have no
+	// closing tag, so the value runs to the next tag or line break.
+	ofxElement = regexp.MustCompile(`<([A-Za-z0-9.]+)>([^<\r\n]*)`)
+	// ofxWhitespace collapses the layout of a transaction aggregate for raw_data
+	ofxWhitespace = regexp.MustCompile(`\s+`)
+)
+
+// ParseOFX parses an OFX bank or credit card statement (OFX 1.x SGML or 2.x XML).
+// Amounts keep the TRNAMT sign: negative for debits, positive for credits.
+func (p *Parser) ParseOFX(file io.Reader) ([]models.ParsedTransaction, error) {
+	data, err := io.ReadAll(utf8Reader(file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OFX file: %w", err)
+	}
+	content := string(data)
+	upper := strings.ToUpper(content)
+
+	if !strings.Contains(upper, "<OFX>") {
+		return nil, ErrNotOFX
+	}
+	if !strings.Contains(upper, "<BANKMSGSRSV1>") && !strings.Contains(upper, "<CREDITCARDMSGSRSV1>") {
+		return nil, ErrUnsupportedOFX
+	}
+
+	var transactions []models.ParsedTransaction
+	for i, match := range ofxTransaction.FindAllStringSubmatch(content, -1) {
+		txn, err := parseOFXTransaction(match[1])
+		if err != nil {
+			// Log error but continue parsing, as for CSV rows
+			fmt.Printf("Warning: skipping OFX transaction %d: %v\n", i+1, err)
+			continue
+		}
+		txn.RawData = strings.TrimSpace(ofxWhitespace.ReplaceAllString(match[0], " "))
+		txn.Flags = importFlags(txn, time.Now())
+		transactions = append(transactions, txn)
+	}
+
+	return transactions, nil
+}
+
+// parseOFXTransaction maps the elements of one STMTTRN aggregate onto a transaction
+func parseOFXTransaction(body string) (models.ParsedTransaction, error) {
+	var txn models.ParsedTransaction
+
+	fields := make(map[string]string)
+	for _, m := range ofxElement.FindAllStringSubmatch(body, -1) {
+		fields[strings.ToUpper(m[1])] = html.UnescapeString(strings.TrimSpace(m[2]))
+	}
+
+	date, err := parseOFXDate(fields["DTPOSTED"])
+	if err != nil {
+		return txn, err
+	}
+	txn.TxnDate = date
+
+	if fields["TRNAMT"] == "" {
+		return txn, fmt.Errorf("missing TRNAMT")
+	}
+	amount, err := ParseAmount(fields["TRNAMT"])
+	if err != nil {
+		return txn, fmt.Errorf("failed to parse amount: %w", err)
+	}
+	txn.Amount = amount
+	if amount < 0 {
+		txn.TxnType = "debit"
+	} else {
+		txn.TxnType = "credit"
+	}
+
+	txn.Description = joinPayeeMemo(fields["NAME"], fields["MEMO"])
+	if txn.Description == "" {
+		txn.Description = fields["PAYEE"]
+	}
+
+	// Reference number: cheque or bank reference when given, else the narration
+	txn.ReferenceNo = fields["CHECKNUM"]
+	if txn.ReferenceNo == "" {
+		txn.ReferenceNo = fields["REFNUM"]
+	}
+	if txn.ReferenceNo == "" {
+		txn.ReferenceNo = ExtractReferenceNo(txn.Description)
+	}
+
+	return txn, nil
+}
+
+// parseOFXDate parses an OFX datetime such as "20240115", "20240115120000" or
+// "20240115120000.000[+5.30:IST]". Only the date part is kept.
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid DTPOSTED: %q", value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid DTPOSTED: %q", value)
+	}
+	return date, nil
+}
+
+// joinPayeeMemo builds a description from a payee and memo. Banks often put a
+// truncated narration in the payee and the full one in the memo, so a memo that
+// already contains the payee replaces it.
+func joinPayeeMemo(payee, memo string) string {
+	switch {
+	case memo == "" || strings.Contains(payee, memo):
+		return payee
+	case payee == "" || strings.Contains(memo, payee):
+		return memo
+	default:
+		return payee + " - " + memo
+	}
+}
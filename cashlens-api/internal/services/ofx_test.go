@@ -0,0 +1,85 @@
+package services
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOFX_SGML(t *testing.T) {
+	file, err := os.Open("../../testdata/sample.ofx")
+	require.NoError(t, err)
+	defer file.Close()
+
+	transactions, err := NewParser().ParseOFX(file)
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+
+	assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), transactions[0].TxnDate)
+	assert.Equal(t, "AWS SERVICES", transactions[0].Description)
+	assert.Equal(t, -3500.0, transactions[0].Amount)
+	assert.Equal(t, "debit", transactions[0].TxnType)
+	assert.Contains(t, transactions[0].RawData, "<FITID>2024011501")
+
+	assert.Equal(t, "ACME CORP - NEFT/789012/SALARY JAN", transactions[1].Description)
+	assert.Equal(t, 50000.0, transactions[1].Amount)
+	assert.Equal(t, "credit", transactions[1].TxnType)
+	assert.Equal(t, "789012", transactions[1].ReferenceNo)
+
+	assert.Equal(t, "OFFICE RENT & MAINTENANCE", transactions[2].Description)
+	assert.Equal(t, "000451", transactions[2].ReferenceNo)
+}
+
+func TestParseOFX_XML(t *testing.T) {
+	ofx := `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE"?>
+<OFX><CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS><BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20240120</DTPOSTED><TRNAMT>-612.50</TRNAMT><NAME>ZOMATO LTD</NAME></STMTTRN>
+<STMTTRN><TRNTYPE>CREDIT</TRNTYPE><DTPOSTED>20240125</DTPOSTED><TRNAMT>2000.00</TRNAMT><NAME>PAYMENT RECEIVED</NAME></STMTTRN>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>2024</DTPOSTED><TRNAMT>-1.00</TRNAMT><NAME>BAD DATE</NAME></STMTTRN>
+</BANKTRANLIST></CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1></OFX>`
+
+	transactions, err := NewParser().ParseOFX(strings.NewReader(ofx))
+	require.NoError(t, err)
+	require.Len(t, transactions, 2, "the record with an unreadable date is skipped")
+
+	assert.Equal(t, "ZOMATO LTD", transactions[0].Description)
+	assert.Equal(t, -612.50, transactions[0].Amount)
+	assert.Equal(t, "debit", transactions[0].TxnType)
+	assert.Equal(t, 2000.0, transactions[1].Amount)
+	assert.Equal(t, "credit", transactions[1].TxnType)
+}
+
+func TestParseOFX_Unsupported(t *testing.T) {
+	t.Run("Investment statement", func(t *testing.T) {
+		ofx := "OFXHEADER:100\n<OFX><INVSTMTMSGSRSV1><INVSTMTTRNRS></INVSTMTTRNRS></INVSTMTMSGSRSV1></OFX>"
+		_, err := NewParser().ParseOFX(strings.NewReader(ofx))
+		assert.ErrorIs(t, err, ErrUnsupportedOFX)
+	})
+
+	t.Run("Not OFX", func(t *testing.T) {
+		_, err := NewParser().ParseOFX(strings.NewReader("Date,Narration,Amount\n"))
+		assert.ErrorIs(t, err, ErrNotOFX)
+	})
+}
+
+func TestParseFile_OFXAndQIF(t *testing.T) {
+	for _, name := range []string{"sample.ofx", "sample.qif"} {
+		t.Run(name, func(t *testing.T) {
+			file, err := os.Open("../../testdata/" + name)
+			require.NoError(t, err)
+			defer file.Close()
+
+			transactions, err := NewParser().ParseFile(file, strings.ToUpper(name))
+			require.NoError(t, err)
+			require.Len(t, transactions, 3)
+			assert.Equal(t, -3500.0, transactions[0].Amount)
+			assert.NotContains(t, transactions[0].Flags, models.FlagBadDate)
+		})
+	}
+}
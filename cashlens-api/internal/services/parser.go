@@ -1,20 +1,29 @@
 package services
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"mime/multipart"
 	"net/http"
+	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/ashmitsharp/cashlens-api/internal/models"
 	"github.com/xuri/excelize/v2"
+	"gopkg.in/yaml.v3"
 )
 
 // PDFParserResponse represents the response from the Python PDF parser microservice
@@ -23,108 +32,547 @@ type PDFParserResponse struct {
 	PagesProcessed int        `json:"pages_processed"`
 }
 
+// ErrLegacyXLS is returned for binary Excel 97-2003 (BIFF) workbooks, which excelize cannot read
+var ErrLegacyXLS = errors.New("legacy Excel 97-2003 (.xls) files are not supported; open the file in Excel and save it as .xlsx, then upload again")
+
+// UnknownBankFormatError is returned when headers don't match any known bank schema.
+// It carries the headers that were seen so users can report them or map a custom schema.
+type UnknownBankFormatError struct {
+	Headers []string
+}
+
+func (e *UnknownBankFormatError) Error() string {
+	return fmt.Sprintf("unknown bank format (headers: %s)", strings.Join(e.Headers, ", "))
+}
+
+// UnmatchedHeaders returns the headers that failed detection
+func (e *UnknownBankFormatError) UnmatchedHeaders() []string {
+	return e.Headers
+}
+
+// oleSignature is the header of OLE2 compound files used by legacy .xls workbooks
+var oleSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
 // Parser handles CSV/XLSX/PDF parsing for multiple bank formats
 type Parser struct {
 	bankSchemas   map[string]models.BankSchema
+	detectOrder   []string // Bank names in the order detectBank tries them
 	pdfServiceURL string
 	httpClient    *http.Client
+	pdfAttempts   int                   // Calls made to the PDF service before giving up on transient failures
+	pdfRetryDelay time.Duration         // Backoff before the second call, doubled for each later one
+	monthNames    map[string]time.Month // Extra localized month names used by parseDate
+	detectOpts    detectOptions         // Header matching behavior used by detectBank
+	zeroAmounts   string                // ZeroAmountSkip, ZeroAmountWarn ("" = default) or ZeroAmountKeep
+	warnings      *[]string             // Collects skipped-row warnings during ParseFileReportingSkips
+	warnOffset    int                   // Preamble rows skipped before parsing, added to warned line numbers
+	grouping      string                // Expected digit grouping of amounts (AmountGrouping*); "" skips the check
+	dupHeaders    string                // DuplicateHeadersError ("" = default) or DuplicateHeadersFirst
+	reconciler    *BalanceReconciler    // Tolerance of the running-balance check
+}
+
+// How rows with zero in both the debit and credit columns (declined or
+// informational entries) are handled
+const (
+	ZeroAmountSkip = "skip" // Drop the row silently
+	ZeroAmountWarn = "warn" // Drop the row and report it as a warning (default)
+	ZeroAmountKeep = "keep" // Import it as a zero-amount transaction, flagged zero_amount
+)
+
+// How a column the bank schema reads is handled when its header appears more than once
+const (
+	DuplicateHeadersError = "error" // Refuse to parse the file (default)
+	DuplicateHeadersFirst = "first" // Read the first column with that header
+)
+
+// Digit groupings amounts can be checked against. ParseAmount drops commas either
+// way, so a malformed grouping such as "12,3456.00" usually means a corrupted cell.
+const (
+	AmountGroupingIndian  = "indian"  // Lakh/crore grouping: 1,23,456.78
+	AmountGroupingWestern = "western" // Thousands grouping: 123,456.78
+	AmountGroupingAny     = "any"     // Either of the above
+)
+
+// errZeroAmountRow is returned by parseRow for rows with zero debit and credit
+var errZeroAmountRow = errors.New("both debit and credit are zero")
+
+// detectOptions controls how strictly headers are matched during bank detection
+type detectOptions struct {
+	stripPeriods  bool // Strip trailing periods from headers before matching
+	strictHeaders bool // Also require each schema's StrictDetectHeaders (e.g. Kotak's "Ref No.")
+}
+
+// defaultDetectOptions is used by DetectBank and new parsers
+var defaultDetectOptions = detectOptions{
+	stripPeriods:  true,
+	strictHeaders: true,
+}
+
+// builtinBankSchemas are the supported banks in detection order. Banks with
+// generic column names come last so more specific signatures win.
+var builtinBankSchemas = []models.BankSchema{
+	{
+		BankName:           "HDFC",
+		DateColumn:         "Date",
+		DescriptionColumn:  "Narration",
+		DebitColumn:        "Withdrawal Amt.",
+		CreditColumn:       "Deposit Amt.",
+		HasSeparateAmounts: true,
+		ReferenceColumn:    "Chq./Ref.No.",
+		BalanceColumn:      "Closing Balance",
+		DetectHeaders:      []string{"Narration", "Withdrawal Amt."},
+	},
+	{
+		BankName:           "ICICI",
+		DateColumn:         "Transaction Date",
+		DescriptionColumn:  "Transaction Remarks",
+		DebitColumn:        "Withdrawal Amount (INR)",
+		CreditColumn:       "Deposit Amount (INR)",
+		HasSeparateAmounts: true,
+		ReferenceColumn:    "Cheque Number",
+		BalanceColumn:      "Balance (INR)",
+		DetectHeaders:      []string{"Transaction Remarks", "Withdrawal Amount (INR)"},
+	},
+	{
+		BankName:           "SBI",
+		DateColumn:         "Txn Date",
+		DescriptionColumn:  "Description",
+		DebitColumn:        "Debit",
+		CreditColumn:       "Credit",
+		HasSeparateAmounts: true,
+		ReferenceColumn:    "Ref No./Cheque No.",
+		BalanceColumn:      "Balance",
+		DetectHeaders:      []string{"Txn Date", "Description"},
+	},
+	{
+		BankName:           "Axis",
+		DateColumn:         "Transaction Date",
+		DescriptionColumn:  "Particulars",
+		AmountColumn:       "Amount",
+		DrCrColumn:         "Dr/Cr",
+		HasSeparateAmounts: false,
+		ReferenceColumn:    "Cheque No.",
+		BalanceColumn:      "Balance",
+		DetectHeaders:      []string{"Particulars", "Dr/Cr"},
+	},
+	{
+		// Particulars like Axis, but separate debit/credit amount columns
+		BankName:           "IDFC First",
+		DateColumn:         "Transaction Date",
+		DescriptionColumn:  "Particulars",
+		DebitColumn:        "Debit Amount",
+		CreditColumn:       "Credit Amount",
+		HasSeparateAmounts: true,
+		ReferenceColumn:    "Cheque No.",
+		BalanceColumn:      "Balance",
+		DetectHeaders:      []string{"Particulars", "Debit Amount", "Credit Amount"},
+	},
+	{
+		// Shares Description/Debit/Credit with SBI and Kotak; "Running Balance"
+		// and "Transaction Date" set it apart
+		BankName:           "Yes Bank",
+		DateColumn:         "Transaction Date",
+		DescriptionColumn:  "Description",
+		DebitColumn:        "Debit",
+		CreditColumn:       "Credit",
+		HasSeparateAmounts: true,
+		ReferenceColumn:    "Cheque No",
+		BalanceColumn:      "Running Balance",
+		DetectHeaders:      []string{"Transaction Date", "Description", "Debit", "Credit", "Running Balance"},
+	},
+	{
+		// Credit card statements have one amount column: charges are plain amounts
+		// and payments/refunds carry a trailing "Cr"
+		BankName:          "HDFC Credit Card",
+		DateColumn:        "Date",
+		DescriptionColumn: "Transaction Description",
+		AmountColumn:      "Amount (in Rs.)",
+		InvertAmountSign:  true,
+		DetectHeaders:     []string{"Transaction Description", "Amount (in Rs.)"},
+	},
+	{
+		BankName:          "ICICI Credit Card",
+		DateColumn:        "Transaction Date",
+		DescriptionColumn: "Details",
+		AmountColumn:      "Amount (INR)",
+		InvertAmountSign:  true,
+		ReferenceColumn:   "Reference Number",
+		DetectHeaders:     []string{"Details", "Amount (INR)"},
+	},
+	{
+		// Most generic, check last
+		BankName:            "Kotak",
+		DateColumn:          "Date",
+		DescriptionColumn:   "Description",
+		DebitColumn:         "Debit",
+		CreditColumn:        "Credit",
+		HasSeparateAmounts:  true,
+		ReferenceColumn:     "Ref No.",
+		BalanceColumn:       "Balance",
+		DetectHeaders:       []string{"Date", "Debit", "Credit", "Description"},
+		StrictDetectHeaders: []string{"Ref No."},
+	},
+}
+
+// Defaults for the Python PDF parser microservice, suitable for local development
+const (
+	DefaultPDFServiceURL        = "http://localhost:5000"
+	DefaultPDFServiceTimeout    = 30 * time.Second
+	DefaultPDFServiceAttempts   = 3
+	DefaultPDFServiceRetryDelay = 500 * time.Millisecond
+)
+
+// ErrPDFServiceNotConfigured is returned for PDF statements when no PDF service URL is set
+var ErrPDFServiceNotConfigured = errors.New("PDF statements are not supported on this server (PDF parser service not configured)")
+
+// ErrPDFServiceUnavailable is wrapped by errors from PDF statements when the PDF parser
+// service could not be reached, so callers can tell a backend outage from a bad file
+var ErrPDFServiceUnavailable = errors.New("PDF parser service unavailable")
+
+// PDFServiceUnavailableError reports a connectivity failure talking to the PDF parser
+// service. It matches ErrPDFServiceUnavailable with errors.Is.
+type PDFServiceUnavailableError struct {
+	Err error
+}
+
+func (e *PDFServiceUnavailableError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrPDFServiceUnavailable, e.Err)
+}
+
+func (e *PDFServiceUnavailableError) Unwrap() []error {
+	return []error{ErrPDFServiceUnavailable, e.Err}
+}
+
+// ServiceUnavailable marks the error as a dependency outage rather than a problem with the file
+func (e *PDFServiceUnavailableError) ServiceUnavailable() bool {
+	return true
 }
 
 // NewParser creates a new parser instance with predefined bank schemas
 func NewParser() *Parser {
-	return NewParserWithPDFClient("http://localhost:5000")
+	return NewParserWithPDFClient(DefaultPDFServiceURL)
 }
 
 // NewParserWithPDFClient creates a parser with a custom PDF service URL (useful for testing)
 func NewParserWithPDFClient(pdfServiceURL string) *Parser {
-	return &Parser{
-		bankSchemas: map[string]models.BankSchema{
-			"HDFC": {
-				BankName:           "HDFC",
-				DateColumn:         "Date",
-				DescriptionColumn:  "Narration",
-				DebitColumn:        "Withdrawal Amt.",
-				CreditColumn:       "Deposit Amt.",
-				HasSeparateAmounts: true,
-			},
-			"ICICI": {
-				BankName:           "ICICI",
-				DateColumn:         "Transaction Date",
-				DescriptionColumn:  "Transaction Remarks",
-				DebitColumn:        "Withdrawal Amount (INR)",
-				CreditColumn:       "Deposit Amount (INR)",
-				HasSeparateAmounts: true,
-			},
-			"SBI": {
-				BankName:           "SBI",
-				DateColumn:         "Txn Date",
-				DescriptionColumn:  "Description",
-				DebitColumn:        "Debit",
-				CreditColumn:       "Credit",
-				HasSeparateAmounts: true,
-			},
-			"Axis": {
-				BankName:           "Axis",
-				DateColumn:         "Transaction Date",
-				DescriptionColumn:  "Particulars",
-				AmountColumn:       "Amount",
-				DrCrColumn:         "Dr/Cr",
-				HasSeparateAmounts: false,
-			},
-			"Kotak": {
-				BankName:           "Kotak",
-				DateColumn:         "Date",
-				DescriptionColumn:  "Description",
-				DebitColumn:        "Debit",
-				CreditColumn:       "Credit",
-				HasSeparateAmounts: true,
-			},
-		},
+	p := &Parser{
+		bankSchemas:   make(map[string]models.BankSchema),
 		pdfServiceURL: pdfServiceURL,
+		detectOpts:    defaultDetectOptions,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: DefaultPDFServiceTimeout,
 		},
+		pdfAttempts:   DefaultPDFServiceAttempts,
+		pdfRetryDelay: DefaultPDFServiceRetryDelay,
+		monthNames:    make(map[string]time.Month),
+		reconciler:    NewBalanceReconciler(DefaultBalanceTolerance),
+	}
+	for _, schema := range builtinBankSchemas {
+		p.registerBankSchema(schema)
 	}
+	return p
 }
 
-// DetectBank detects the bank from CSV headers
-func DetectBank(headers []string) string {
-	headerSet := make(map[string]bool)
-	for _, h := range headers {
-		headerSet[strings.ToLower(strings.TrimSpace(h))] = true
+// SetPDFService points PDF parsing at the microservice at url, bounding each request
+// by timeout (non-positive uses DefaultPDFServiceTimeout). An empty URL disables PDF
+// parsing, so PDF statements fail with ErrPDFServiceNotConfigured.
+func (p *Parser) SetPDFService(url string, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultPDFServiceTimeout
+	}
+	p.pdfServiceURL = strings.TrimRight(url, "/")
+	p.httpClient = &http.Client{Timeout: timeout}
+}
+
+// SetPDFRetry bounds how often a PDF statement is sent to the microservice when it
+// fails with a connection error or 5xx, as happens during cold starts. The wait before
+// each retry starts at baseDelay and doubles. Non-positive values use the defaults;
+// attempts of 1 disables retries.
+func (p *Parser) SetPDFRetry(attempts int, baseDelay time.Duration) {
+	if attempts <= 0 {
+		attempts = DefaultPDFServiceAttempts
+	}
+	if baseDelay <= 0 {
+		baseDelay = DefaultPDFServiceRetryDelay
+	}
+	p.pdfAttempts = attempts
+	p.pdfRetryDelay = baseDelay
+}
+
+// bankSchemaConfig is the layout of a bank schema config file
+type bankSchemaConfig struct {
+	Banks []models.BankSchema `json:"banks" yaml:"banks"`
+}
+
+// NewParserFromConfig creates a parser with the built-in bank schemas plus those
+// in the JSON or YAML file at path (chosen by the .yaml/.yml extension). Config
+// banks are detected before the built-in ones, and an entry with a built-in
+// bank's name replaces it. An empty path uses the built-in schemas only.
+func NewParserFromConfig(path string) (*Parser, error) {
+	p := NewParser()
+	if path == "" {
+		return p, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bank schema config: %w", err)
 	}
 
-	// HDFC detection
-	if headerSet["narration"] && headerSet["withdrawal amt."] {
-		return "HDFC"
+	var cfg bankSchemaConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &cfg)
+	default:
+		err = json.Unmarshal(data, &cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode bank schema config: %w", err)
+	}
+
+	for i, schema := range cfg.Banks {
+		if err := validateBankSchema(schema); err != nil {
+			return nil, fmt.Errorf("bank schema %d: %w", i+1, err)
+		}
+	}
+
+	// Config banks go first so their signatures take precedence
+	builtin := p.detectOrder
+	p.detectOrder = nil
+	for _, schema := range cfg.Banks {
+		p.registerBankSchema(schema)
+	}
+	for _, name := range builtin {
+		if !containsString(p.detectOrder, name) {
+			p.detectOrder = append(p.detectOrder, name)
+		}
+	}
+	return p, nil
+}
+
+// validateBankSchema checks that a configured schema can be detected and parsed
+func validateBankSchema(schema models.BankSchema) error {
+	switch {
+	case schema.BankName == "":
+		return errors.New("bank_name is required")
+	case schema.DateColumn == "" || schema.DescriptionColumn == "":
+		return fmt.Errorf("%s: date_column and description_column are required", schema.BankName)
+	case len(schema.DetectHeaders) == 0:
+		return fmt.Errorf("%s: detect_headers must list at least one column", schema.BankName)
+	case schema.HasSeparateAmounts && (schema.DebitColumn == "" || schema.CreditColumn == ""):
+		return fmt.Errorf("%s: debit_column and credit_column are required with has_separate_amounts", schema.BankName)
+	case !schema.HasSeparateAmounts && schema.AmountColumn == "":
+		return fmt.Errorf("%s: amount_column is required without has_separate_amounts", schema.BankName)
+	case schema.SignedAmount && (schema.HasSeparateAmounts || schema.DrCrColumn != "" || schema.InvertAmountSign):
+		return fmt.Errorf("%s: signed_amount only applies to a single amount column without dr_cr_column or invert_amount_sign", schema.BankName)
 	}
+	return nil
+}
 
-	// ICICI detection
-	if headerSet["transaction remarks"] && headerSet["withdrawal amount (inr)"] {
-		return "ICICI"
+// registerBankSchema adds or replaces a schema, appending new banks to the detection order
+func (p *Parser) registerBankSchema(schema models.BankSchema) {
+	if !containsString(p.detectOrder, schema.BankName) {
+		p.detectOrder = append(p.detectOrder, schema.BankName)
 	}
+	p.bankSchemas[schema.BankName] = schema
+}
 
-	// SBI detection
-	if headerSet["txn date"] && headerSet["description"] {
-		return "SBI"
+// schemasInOrder returns the registered schemas in detection order
+func (p *Parser) schemasInOrder() []models.BankSchema {
+	schemas := make([]models.BankSchema, 0, len(p.detectOrder))
+	for _, name := range p.detectOrder {
+		schemas = append(schemas, p.bankSchemas[name])
 	}
+	return schemas
+}
 
-	// Axis detection
-	if headerSet["particulars"] && headerSet["dr/cr"] {
-		return "Axis"
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
 	}
+	return false
+}
 
-	// Kotak detection (most generic, check last)
-	if headerSet["date"] && headerSet["debit"] && headerSet["credit"] && headerSet["description"] {
-		return "Kotak"
+// SetMonthNames registers additional month-name mappings (e.g. HindiMonthNames)
+// that are recognized when parsing dates. Keys are matched case-insensitively.
+func (p *Parser) SetMonthNames(names map[string]time.Month) {
+	for name, month := range names {
+		p.monthNames[strings.ToLower(name)] = month
+	}
+}
+
+// SetMonthNameLocales registers the month names of each named locale in MonthNameLocales
+// (e.g. "hindi"), returning an error for an unknown locale
+func (p *Parser) SetMonthNameLocales(locales []string) error {
+	for _, locale := range locales {
+		names, ok := MonthNameLocales[strings.ToLower(locale)]
+		if !ok {
+			return fmt.Errorf("unknown month-name locale %q", locale)
+		}
+		p.SetMonthNames(names)
+	}
+	return nil
+}
+
+// SetStripHeaderPeriods controls whether trailing periods are ignored when matching
+// headers ("Withdrawal Amt" matches "Withdrawal Amt."). Enabled by default.
+func (p *Parser) SetStripHeaderPeriods(strip bool) {
+	p.detectOpts.stripPeriods = strip
+}
+
+// SetStrictKotakDetection controls whether Kotak detection requires the "Ref No."
+// column. Kotak's other columns (Date, Description, Debit, Credit) are generic
+// enough to match other banks' statements, so this is enabled by default.
+// It applies to every schema's StrictDetectHeaders, including configured ones.
+func (p *Parser) SetStrictKotakDetection(strict bool) {
+	p.detectOpts.strictHeaders = strict
+}
+
+// SetBalanceTolerance sets how many rupees a row's running balance may be off by
+// before it is flagged reconciliation_mismatch (DefaultBalanceTolerance by default)
+func (p *Parser) SetBalanceTolerance(tolerance float64) {
+	p.reconciler = NewBalanceReconciler(tolerance)
+}
+
+// SetZeroAmountRows sets how rows with zero debit and credit are handled: ZeroAmountSkip,
+// ZeroAmountWarn (the default) or ZeroAmountKeep. An empty mode restores the default.
+func (p *Parser) SetZeroAmountRows(mode string) error {
+	switch mode {
+	case "", ZeroAmountSkip, ZeroAmountWarn, ZeroAmountKeep:
+		p.zeroAmounts = mode
+		return nil
+	default:
+		return fmt.Errorf("invalid zero-amount row mode %q: must be skip, warn or keep", mode)
+	}
+}
+
+// SetDuplicateHeaders sets how a repeated header of a column the schema reads is handled:
+// DuplicateHeadersError (the default) or DuplicateHeadersFirst. An empty mode restores the default.
+func (p *Parser) SetDuplicateHeaders(mode string) error {
+	switch mode {
+	case "", DuplicateHeadersError, DuplicateHeadersFirst:
+		p.dupHeaders = mode
+		return nil
+	default:
+		return fmt.Errorf("invalid duplicate header mode %q: must be error or first", mode)
+	}
+}
+
+// SetAmountGrouping enables warnings for amounts whose comma grouping is malformed for
+// the given AmountGrouping* locale. An empty grouping or "off" turns the check off.
+func (p *Parser) SetAmountGrouping(grouping string) error {
+	switch grouping {
+	case "", "off":
+		p.grouping = ""
+		return nil
+	case AmountGroupingIndian, AmountGroupingWestern, AmountGroupingAny:
+		p.grouping = grouping
+		return nil
+	default:
+		return fmt.Errorf("invalid amount grouping %q: must be indian, western, any or off", grouping)
+	}
+}
+
+// NormalizeHeader lowercases a column header, trims it and collapses internal
+// whitespace. When stripPeriods is set, trailing periods are removed as well.
+func NormalizeHeader(header string, stripPeriods bool) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(header)), " ")
+	if stripPeriods {
+		normalized = strings.TrimRight(normalized, ".")
+	}
+	return normalized
+}
+
+// DetectBank detects the bank from CSV headers using the built-in schemas
+func DetectBank(headers []string) string {
+	return detectBank(headers, builtinBankSchemas, defaultDetectOptions)
+}
+
+// detectBank returns the first schema whose detection headers are all present
+func detectBank(headers []string, schemas []models.BankSchema, opts detectOptions) string {
+	headerSet := make(map[string]bool)
+	for _, h := range headers {
+		headerSet[NormalizeHeader(h, opts.stripPeriods)] = true
+	}
+	hasAll := func(required []string) bool {
+		for _, header := range required {
+			if !headerSet[NormalizeHeader(header, opts.stripPeriods)] {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, schema := range schemas {
+		if len(schema.DetectHeaders) == 0 || !hasAll(schema.DetectHeaders) {
+			continue
+		}
+		if opts.strictHeaders && !hasAll(schema.StrictDetectHeaders) {
+			continue
+		}
+		return schema.BankName
 	}
 
 	return "UNKNOWN"
 }
 
+// EnglishMonthNames maps full English month names to months.
+// Short names ("Jan") are handled natively by time.Parse.
+var EnglishMonthNames = map[string]time.Month{
+	"january":   time.January,
+	"february":  time.February,
+	"march":     time.March,
+	"april":     time.April,
+	"may":       time.May,
+	"june":      time.June,
+	"july":      time.July,
+	"august":    time.August,
+	"september": time.September,
+	"october":   time.October,
+	"november":  time.November,
+	"december":  time.December,
+	"sept":      time.September,
+}
+
+// HindiMonthNames maps common Hindi transliterations of month names to months
+var HindiMonthNames = map[string]time.Month{
+	"janvari":  time.January,
+	"farvari":  time.February,
+	"march":    time.March,
+	"aprail":   time.April,
+	"mai":      time.May,
+	"joon":     time.June,
+	"julai":    time.July,
+	"agast":    time.August,
+	"sitambar": time.September,
+	"aktoobar": time.October,
+	"navambar": time.November,
+	"disambar": time.December,
+}
+
+// MonthNameLocales are the month-name sets SetMonthNameLocales can enable by name
+var MonthNameLocales = map[string]map[string]time.Month{
+	"hindi": HindiMonthNames,
+}
+
 // ParseDate parses date strings in multiple formats
 func ParseDate(dateStr string) (time.Time, error) {
+	return parseDateWithMonthNames(dateStr, nil)
+}
+
+// parseDate parses a date using the parser's configured month names
+func (p *Parser) parseDate(dateStr string) (time.Time, error) {
+	return parseDateWithMonthNames(dateStr, p.monthNames)
+}
+
+// parseDateWithMonthNames parses a date after rewriting any recognized
+// month name (English full names plus extraMonths) to its short English form
+func parseDateWithMonthNames(dateStr string, extraMonths map[string]time.Month) (time.Time, error) {
 	dateStr = strings.TrimSpace(dateStr)
+	normalized := normalizeMonthNames(dateStr, extraMonths)
 
 	dateFormats := []string{
 		"02/01/2006",   // DD/MM/YYYY (HDFC, ICICI, Kotak)
@@ -133,10 +581,13 @@ func ParseDate(dateStr string) (time.Time, error) {
 		"02-01-2006",   // DD-MM-YYYY
 		"02/01/06",     // DD/MM/YY
 		"Jan 02, 2006", // MMM DD, YYYY
+		"2 Jan 2006",   // D MMM YYYY ("15 January 2024" after normalization)
+		"2-Jan-06",     // D-MMM-YY
+		"Jan 2 2006",   // MMM D YYYY
 	}
 
 	for _, format := range dateFormats {
-		t, err := time.Parse(format, dateStr)
+		t, err := time.Parse(format, normalized)
 		if err == nil {
 			return t, nil
 		}
@@ -145,17 +596,59 @@ func ParseDate(dateStr string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
 }
 
-// ParseAmount parses amount strings, handling currency symbols and commas
+// normalizeMonthNames replaces alphabetic tokens that name a month with the
+// three-letter English abbreviation understood by time.Parse
+func normalizeMonthNames(dateStr string, extraMonths map[string]time.Month) string {
+	var b strings.Builder
+	var token strings.Builder
+
+	flush := func() {
+		if token.Len() == 0 {
+			return
+		}
+		word := token.String()
+		lower := strings.ToLower(word)
+		if month, ok := extraMonths[lower]; ok {
+			word = month.String()[:3]
+		} else if month, ok := EnglishMonthNames[lower]; ok {
+			word = month.String()[:3]
+		}
+		b.WriteString(word)
+		token.Reset()
+	}
+
+	for _, r := range dateStr {
+		if unicode.IsLetter(r) {
+			token.WriteRune(r)
+			continue
+		}
+		flush()
+		b.WriteRune(r)
+	}
+	flush()
+
+	return b.String()
+}
+
+// ParseAmount parses amount strings, handling currency symbols and commas.
+// A leading "+" or "-" (before or after the currency symbol) sets the sign.
 func ParseAmount(amountStr string) (float64, error) {
 	// Remove currency symbols and commas
 	cleaned := strings.ReplaceAll(amountStr, "₹", "")
 	cleaned = strings.ReplaceAll(cleaned, "Rs.", "")
 	cleaned = strings.ReplaceAll(cleaned, "Rs", "")
 	cleaned = strings.ReplaceAll(cleaned, ",", "")
-	cleaned = strings.TrimSpace(cleaned)
+
+	// Drop all whitespace, including NBSP (U+00A0) and thin/narrow spaces some exports use as thousands separators
+	cleaned = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, cleaned)
 
 	// Handle empty amounts
-	if cleaned == "" || cleaned == "-" {
+	if cleaned == "" || cleaned == "-" || cleaned == "+" {
 		return 0, nil
 	}
 
@@ -168,9 +661,138 @@ func ParseAmount(amountStr string) (float64, error) {
 	return amount, nil
 }
 
-// ParseCSV parses a CSV file and returns a list of transactions
+// explicitSign returns 1 or -1 for an amount written with a leading "+" or "-",
+// before or after any currency symbol, and 0 when it has no sign
+func explicitSign(amountStr string) int {
+	cleaned := strings.NewReplacer("₹", "", "Rs.", "", "Rs", "").Replace(amountStr)
+	cleaned = strings.TrimLeftFunc(cleaned, unicode.IsSpace)
+	switch {
+	case strings.HasPrefix(cleaned, "+"):
+		return 1
+	case strings.HasPrefix(cleaned, "-"):
+		return -1
+	default:
+		return 0
+	}
+}
+
+// WellFormedGrouping reports whether the comma grouping of an amount string is valid
+// for an AmountGrouping* locale. Amounts without commas are always well-formed.
+func WellFormedGrouping(amountStr, grouping string) bool {
+	cleaned := amountMarkerPattern.ReplaceAllString(strings.TrimSpace(amountStr), "")
+	cleaned = strings.NewReplacer("₹", "", "Rs.", "", "Rs", "").Replace(cleaned)
+	cleaned = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, cleaned)
+	cleaned = strings.Trim(cleaned, "+-()")
+
+	whole, fraction, _ := strings.Cut(cleaned, ".")
+	if strings.Contains(fraction, ",") {
+		return false
+	}
+	if !strings.Contains(whole, ",") {
+		return true
+	}
+	groups := strings.Split(whole, ",")
+	switch grouping {
+	case AmountGroupingIndian:
+		return digitGroupsMatch(groups, 2)
+	case AmountGroupingWestern:
+		return digitGroupsMatch(groups, 3)
+	default:
+		return digitGroupsMatch(groups, 2) || digitGroupsMatch(groups, 3)
+	}
+}
+
+// digitGroupsMatch reports whether comma-separated digit groups end in a group of
+// three, preceded by groups of size digits, the leading one possibly shorter
+func digitGroupsMatch(groups []string, size int) bool {
+	last := len(groups) - 1
+	for i, group := range groups {
+		if group == "" || strings.Trim(group, "0123456789") != "" {
+			return false
+		}
+		switch {
+		case i == last && len(group) != 3:
+			return false
+		case i == 0 && i != last && len(group) > size:
+			return false
+		case i != 0 && i != last && len(group) != size:
+			return false
+		}
+	}
+	return true
+}
+
+// malformedAmounts returns the amount cells of a row whose grouping does not match the
+// configured locale
+func (p *Parser) malformedAmounts(row []string, headerIndex map[string]int, schema models.BankSchema) []string {
+	if p.grouping == "" {
+		return nil
+	}
+	columns := []string{schema.AmountColumn}
+	if schema.HasSeparateAmounts {
+		columns = []string{schema.DebitColumn, schema.CreditColumn}
+	}
+	var malformed []string
+	for _, column := range columns {
+		idx, ok := headerIndex[NormalizeHeader(column, p.detectOpts.stripPeriods)]
+		if ok && !WellFormedGrouping(row[idx], p.grouping) {
+			malformed = append(malformed, strings.TrimSpace(row[idx]))
+		}
+	}
+	return malformed
+}
+
+// amountMarkerPattern matches the Cr/Dr marker credit card statements append to amounts
+var amountMarkerPattern = regexp.MustCompile(`(?i)\s*(cr|dr)\.?$`)
+
+// parseMarkedAmount parses an amount such as "1,250.00 Cr" or "499.00", returning the
+// lowercased Cr/Dr marker ("" when absent) separately from the amount
+func parseMarkedAmount(amountStr string) (float64, string, error) {
+	trimmed := strings.TrimSpace(amountStr)
+	var marker string
+	if loc := amountMarkerPattern.FindStringSubmatchIndex(trimmed); loc != nil {
+		marker = strings.ToLower(trimmed[loc[2]:loc[3]])
+		trimmed = trimmed[:loc[0]]
+	}
+	amount, err := ParseAmount(trimmed)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid amount: %s", amountStr)
+	}
+	return amount, marker, nil
+}
+
+// ParseCSV parses a CSV file and returns a list of transactions.
+// Comma, pipe, semicolon and tab delimiters are detected automatically.
 func (p *Parser) ParseCSV(file io.Reader) ([]models.ParsedTransaction, error) {
-	reader := csv.NewReader(file)
+	return p.parseCSV(file, 0)
+}
+
+// parseCSV parses a CSV file whose header row follows skipRows preamble rows
+func (p *Parser) parseCSV(file io.Reader, skipRows int) ([]models.ParsedTransaction, error) {
+	// Transcode to UTF-8, then peek at the start of the file to pick the delimiter without consuming it
+	buffered := bufio.NewReaderSize(utf8Reader(file), delimiterSniffBytes)
+	sample, _ := buffered.Peek(delimiterSniffBytes) // A short file returns what's there
+
+	reader := csv.NewReader(buffered)
+	reader.Comma = SniffDelimiter(sample)
+	// Bank exports often have unescaped quotes in narrations and ragged rows
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	// Skip preamble rows
+	for i := 0; i < skipRows; i++ {
+		if _, err := reader.Read(); err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("empty file")
+			}
+			return nil, fmt.Errorf("error reading row %d: %w", i+1, err)
+		}
+	}
 
 	// Read header row
 	headers, err := reader.Read()
@@ -183,7 +805,7 @@ func (p *Parser) ParseCSV(file io.Reader) ([]models.ParsedTransaction, error) {
 
 	// Read all data rows
 	var dataRows [][]string
-	rowNum := 1 // Start from 1 since we already read headers
+	rowNum := skipRows + 1 // Start after the preamble and headers
 
 	for {
 		row, err := reader.Read()
@@ -201,23 +823,85 @@ func (p *Parser) ParseCSV(file io.Reader) ([]models.ParsedTransaction, error) {
 	return p.parseRows(headers, dataRows)
 }
 
+// delimiterSniffBytes is how much of a delimited file SniffDelimiter looks at
+const delimiterSniffBytes = 16 * 1024
+
+// sniffLines caps how many non-empty lines SniffDelimiter samples
+const sniffLines = 20
+
+// candidateDelimiters are the separators seen in bank exports, in tie-break order.
+// Core-banking exports often use pipes even with a .csv or .txt extension.
+var candidateDelimiters = []rune{',', '|', ';', '\t'}
+
+// SniffDelimiter picks the field delimiter of a delimited text sample. Each
+// candidate is scored by how consistently it splits the sampled lines into the
+// same number of fields: the most common per-line count (ignoring quoted text)
+// times the number of lines sharing it. Defaults to comma.
+func SniffDelimiter(sample []byte) rune {
+	lines := strings.Split(strings.ReplaceAll(string(sample), "\r\n", "\n"), "\n")
+	if len(sample) == delimiterSniffBytes && len(lines) > 1 {
+		lines = lines[:len(lines)-1] // Drop the partial last line
+	}
+
+	sampled := make([]string, 0, sniffLines)
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			sampled = append(sampled, line)
+		}
+		if len(sampled) == sniffLines {
+			break
+		}
+	}
+
+	best, bestScore := ',', 0
+	for _, delim := range candidateDelimiters {
+		frequency := make(map[int]int)
+		for _, line := range sampled {
+			if n := countUnquoted(line, delim); n > 0 {
+				frequency[n]++
+			}
+		}
+		for count, lines := range frequency {
+			if score := count * lines; score > bestScore {
+				best, bestScore = delim, score
+			}
+		}
+	}
+	return best
+}
+
+// countUnquoted counts occurrences of delim outside double-quoted sections
+func countUnquoted(line string, delim rune) int {
+	count := 0
+	inQuotes := false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == delim && !inQuotes:
+			count++
+		}
+	}
+	return count
+}
+
 // parseRow parses a single CSV row into a ParsedTransaction
 func (p *Parser) parseRow(row []string, headerIndex map[string]int, schema models.BankSchema) (models.ParsedTransaction, error) {
 	var txn models.ParsedTransaction
 
 	// Parse date
-	dateIdx, ok := headerIndex[schema.DateColumn]
+	dateIdx, ok := headerIndex[NormalizeHeader(schema.DateColumn, p.detectOpts.stripPeriods)]
 	if !ok {
 		return txn, fmt.Errorf("date column '%s' not found", schema.DateColumn)
 	}
-	date, err := ParseDate(row[dateIdx])
+	date, err := p.parseDate(row[dateIdx])
 	if err != nil {
 		return txn, fmt.Errorf("failed to parse date: %w", err)
 	}
 	txn.TxnDate = date
 
 	// Parse description
-	descIdx, ok := headerIndex[schema.DescriptionColumn]
+	descIdx, ok := headerIndex[NormalizeHeader(schema.DescriptionColumn, p.detectOpts.stripPeriods)]
 	if !ok {
 		return txn, fmt.Errorf("description column '%s' not found", schema.DescriptionColumn)
 	}
@@ -226,8 +910,8 @@ func (p *Parser) parseRow(row []string, headerIndex map[string]int, schema model
 	// Parse amount based on schema type
 	if schema.HasSeparateAmounts {
 		// Banks with separate debit/credit columns (HDFC, ICICI, SBI, Kotak)
-		debitIdx := headerIndex[schema.DebitColumn]
-		creditIdx := headerIndex[schema.CreditColumn]
+		debitIdx := headerIndex[NormalizeHeader(schema.DebitColumn, p.detectOpts.stripPeriods)]
+		creditIdx := headerIndex[NormalizeHeader(schema.CreditColumn, p.detectOpts.stripPeriods)]
 
 		debit, _ := ParseAmount(row[debitIdx])
 		credit, _ := ParseAmount(row[creditIdx])
@@ -238,13 +922,44 @@ func (p *Parser) parseRow(row []string, headerIndex map[string]int, schema model
 		} else if credit > 0 {
 			txn.Amount = credit // Positive for credit
 			txn.TxnType = "credit"
+		} else if p.zeroAmounts == ZeroAmountKeep {
+			txn.Amount = 0 // Informational row, flagged zero_amount on import
+			txn.TxnType = "credit"
 		} else {
-			return txn, fmt.Errorf("both debit and credit are zero")
+			return txn, errZeroAmountRow
+		}
+	} else if schema.DrCrColumn == "" {
+		// Single signed amount column, optionally marked Cr/Dr (credit cards)
+		amountIdx := headerIndex[NormalizeHeader(schema.AmountColumn, p.detectOpts.stripPeriods)]
+
+		amount, marker, err := parseMarkedAmount(row[amountIdx])
+		if err != nil {
+			return txn, fmt.Errorf("failed to parse amount: %w", err)
+		}
+
+		switch {
+		case marker == "cr":
+			amount = math.Abs(amount)
+		case marker == "dr":
+			amount = -math.Abs(amount)
+		case schema.SignedAmount:
+			// The sign prefix is required, so an unsigned amount means a misread column
+			if amount != 0 && explicitSign(row[amountIdx]) == 0 {
+				return txn, fmt.Errorf("amount %q has no +/- sign", row[amountIdx])
+			}
+		case schema.InvertAmountSign:
+			amount = -amount
+		}
+		txn.Amount = amount
+		if amount < 0 {
+			txn.TxnType = "debit"
+		} else {
+			txn.TxnType = "credit"
 		}
 	} else {
 		// Banks with single amount column and Dr/Cr indicator (Axis)
-		amountIdx := headerIndex[schema.AmountColumn]
-		drCrIdx := headerIndex[schema.DrCrColumn]
+		amountIdx := headerIndex[NormalizeHeader(schema.AmountColumn, p.detectOpts.stripPeriods)]
+		drCrIdx := headerIndex[NormalizeHeader(schema.DrCrColumn, p.detectOpts.stripPeriods)]
 
 		amount, err := ParseAmount(row[amountIdx])
 		if err != nil {
@@ -263,12 +978,153 @@ func (p *Parser) parseRow(row []string, headerIndex map[string]int, schema model
 		}
 	}
 
-	// Store raw data for debugging
-	txn.RawData = strings.Join(row, ",")
+	// Reference number: the bank's reference column when filled, else the narration
+	if schema.ReferenceColumn != "" {
+		if idx, ok := headerIndex[NormalizeHeader(schema.ReferenceColumn, p.detectOpts.stripPeriods)]; ok {
+			txn.ReferenceNo = referenceFromColumn(row[idx])
+		}
+	}
+	if txn.ReferenceNo == "" {
+		txn.ReferenceNo = ExtractReferenceNo(txn.Description)
+	}
+
+	// Store raw data for debugging and reparsing
+	txn.RawData = encodeRawRow(row)
 
 	return txn, nil
 }
 
+// ReparseRawData re-parses a transaction from its stored raw_data using the given
+// bank schema. headers is the column layout the row was originally captured with.
+func (p *Parser) ReparseRawData(bankName string, headers []string, rawData string) (models.ParsedTransaction, error) {
+	schema, ok := p.bankSchemas[bankName]
+	if !ok {
+		return models.ParsedTransaction{}, fmt.Errorf("unknown bank: %s", bankName)
+	}
+
+	row, err := decodeRawRow(rawData)
+	if err != nil {
+		return models.ParsedTransaction{}, fmt.Errorf("invalid raw data: %w", err)
+	}
+	if len(row) != len(headers) {
+		return models.ParsedTransaction{}, fmt.Errorf("raw data has %d fields, expected %d", len(row), len(headers))
+	}
+
+	headerIndex, err := p.buildHeaderIndex(headers, schema)
+	if err != nil {
+		return models.ParsedTransaction{}, err
+	}
+
+	return p.parseRow(row, headerIndex, schema)
+}
+
+// encodeRawRow stores a row as one CSV record, so fields containing commas such as
+// "4,50,000.00" or "ACME CORP, BANGALORE" keep their columns when reparsed
+func encodeRawRow(row []string) string {
+	var buf strings.Builder
+	w := csv.NewWriter(&buf)
+	w.Write(row)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// decodeRawRow reads a row written by encodeRawRow. Rows stored earlier as plain
+// comma-joined text decode the same way when none of their fields held a comma.
+func decodeRawRow(rawData string) ([]string, error) {
+	r := csv.NewReader(strings.NewReader(rawData))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	return r.Read()
+}
+
+// buildHeaderIndex maps each normalized header to its column, keeping the first of
+// repeated headers. A repeated header of a column the schema reads is an error unless
+// duplicate headers are set to DuplicateHeadersFirst, since either copy may hold the data.
+func (p *Parser) buildHeaderIndex(headers []string, schema models.BankSchema) (map[string]int, error) {
+	read := make(map[string]bool)
+	for _, column := range []string{
+		schema.DateColumn, schema.DescriptionColumn, schema.DebitColumn, schema.CreditColumn,
+		schema.AmountColumn, schema.DrCrColumn, schema.ReferenceColumn, schema.BalanceColumn,
+	} {
+		if column != "" {
+			read[NormalizeHeader(column, p.detectOpts.stripPeriods)] = true
+		}
+	}
+
+	headerIndex := make(map[string]int)
+	for i, h := range headers {
+		key := NormalizeHeader(h, p.detectOpts.stripPeriods)
+		if _, seen := headerIndex[key]; seen {
+			if read[key] && p.dupHeaders != DuplicateHeadersFirst {
+				return nil, fmt.Errorf("column %q appears more than once in the header row; rename or remove the extra column",
+					strings.TrimSpace(h))
+			}
+			continue
+		}
+		headerIndex[key] = i
+	}
+	return headerIndex, nil
+}
+
+// paymentRailPattern finds the payment rail a narration reference follows
+var paymentRailPattern = regexp.MustCompile(`(?i)\b(UPI|NEFT|IMPS|RTGS)\b`)
+
+// ifscPattern matches IFSC codes, which share narrations with references but aren't one
+var ifscPattern = regexp.MustCompile(`^[A-Z]{4}0[A-Z0-9]{6}$`)
+
+// ExtractReferenceNo returns the UPI/NEFT/IMPS/RTGS reference in a narration such as
+// "UPI/123456789/ZOMATO/PAYMENT" or "NEFT CR-HDFC0000001-ACME-N012345678", or ""
+// when there is none. The reference is the first alphanumeric token after the rail
+// with at least six digits that isn't an IFSC code.
+func ExtractReferenceNo(description string) string {
+	loc := paymentRailPattern.FindStringIndex(description)
+	if loc == nil {
+		return ""
+	}
+
+	tokens := strings.FieldsFunc(description[loc[1]:], func(r rune) bool {
+		return r == '/' || r == '-' || r == ':' || unicode.IsSpace(r)
+	})
+	for _, token := range tokens {
+		token = strings.ToUpper(token)
+		if isReferenceToken(token) && !ifscPattern.MatchString(token) {
+			return token
+		}
+	}
+	return ""
+}
+
+// isReferenceToken reports whether token is alphanumeric with at least six digits
+func isReferenceToken(token string) bool {
+	digits := 0
+	for _, r := range token {
+		switch {
+		case unicode.IsDigit(r):
+			digits++
+		case r < 'A' || r > 'Z':
+			return false
+		}
+	}
+	return digits >= 6
+}
+
+// referenceFromColumn cleans a reference column value. Banks fill the column with
+// zeros when there's no reference, and some prefix it with the rail ("UPI/123456").
+func referenceFromColumn(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.Trim(value, "0") == "" {
+		return ""
+	}
+	if ref := ExtractReferenceNo(value); ref != "" {
+		return ref
+	}
+	if paymentRailPattern.MatchString(value) {
+		// Rail without a recognizable number: keep what follows the prefix
+		return strings.Trim(paymentRailPattern.ReplaceAllString(value, ""), "/-: ")
+	}
+	return value
+}
+
 // isEmptyRow checks if all fields in a row are empty
 func isEmptyRow(row []string) bool {
 	for _, field := range row {
@@ -279,6 +1135,50 @@ func isEmptyRow(row []string) bool {
 	return true
 }
 
+// accountHeaderPattern matches account-header rows such as "Account Number: XXXX1234" or "A/C No. 1234"
+var accountHeaderPattern = regexp.MustCompile(`(?i)^(?:account\s*(?:no\.?|number|#)|a/c\s*(?:no\.?)?)\s*[:\-]?\s*([A-Z0-9*]+)$`)
+
+// accountHeaderNumber returns the account number from a row that delimits an account
+// section in a combined statement. Such rows have at most two non-empty cells.
+func accountHeaderNumber(row []string) (string, bool) {
+	var cells []string
+	for _, field := range row {
+		if trimmed := strings.TrimSpace(field); trimmed != "" {
+			cells = append(cells, trimmed)
+		}
+	}
+	if len(cells) == 0 || len(cells) > 2 {
+		return "", false
+	}
+
+	match := accountHeaderPattern.FindStringSubmatch(strings.Join(cells, " "))
+	if match == nil {
+		return "", false
+	}
+	return strings.ToUpper(match[1]), true
+}
+
+// isRepeatedHeaderRow checks if a data row repeats the column headers
+func isRepeatedHeaderRow(row []string, headerIndex map[string]int, stripPeriods bool) bool {
+	matched := 0
+	for i, field := range row {
+		if idx, ok := headerIndex[NormalizeHeader(field, stripPeriods)]; ok && idx == i {
+			matched++
+		}
+	}
+	return matched == len(headerIndex) && matched > 0
+}
+
+// padRow extends a short row with empty fields so every header column can be indexed
+func padRow(row []string, width int) []string {
+	if len(row) >= width {
+		return row
+	}
+	padded := make([]string, width)
+	copy(padded, row)
+	return padded
+}
+
 // isSummaryRow checks if a row is a summary row
 func isSummaryRow(row []string) bool {
 	if len(row) == 0 {
@@ -299,12 +1199,33 @@ func isSummaryRow(row []string) bool {
 
 // ParseXLSX parses an XLSX file and returns a list of transactions
 func (p *Parser) ParseXLSX(file io.Reader) ([]models.ParsedTransaction, error) {
+	return p.parseXLSX(file, 0, "")
+}
+
+// ParseXLSXSheet parses an XLSX file, trying the named sheet before the others.
+// It fails if the workbook has no sheet with that name (case-insensitive).
+func (p *Parser) ParseXLSXSheet(file io.Reader, sheetName string, skipRows int) ([]models.ParsedTransaction, error) {
+	if skipRows < 0 {
+		return nil, fmt.Errorf("skip rows must not be negative")
+	}
+	return p.parseXLSX(file, skipRows, sheetName)
+}
+
+// parseXLSX parses the first sheet whose header row, after skipRows preamble rows,
+// matches a known bank. Banks often put a cover page before the statement sheet.
+// sheetHint, when set, names the sheet to try first.
+func (p *Parser) parseXLSX(file io.Reader, skipRows int, sheetHint string) ([]models.ParsedTransaction, error) {
 	// Read the XLSX file into memory
 	data, err := io.ReadAll(file)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	// Legacy binary workbooks share the .xls extension but aren't OOXML
+	if bytes.HasPrefix(data, oleSignature) {
+		return nil, ErrLegacyXLS
+	}
+
 	// Open the XLSX file
 	f, err := excelize.OpenReader(bytes.NewReader(data))
 	if err != nil {
@@ -312,153 +1233,460 @@ func (p *Parser) ParseXLSX(file io.Reader) ([]models.ParsedTransaction, error) {
 	}
 	defer f.Close()
 
-	// Get the first sheet
 	sheets := f.GetSheetList()
 	if len(sheets) == 0 {
 		return nil, fmt.Errorf("no sheets found in XLSX file")
 	}
-	sheetName := sheets[0]
+	if sheetHint != "" {
+		if sheets, err = hintedSheetOrder(sheets, sheetHint); err != nil {
+			return nil, err
+		}
+	}
 
-	// Read all rows from the first sheet
-	rows, err := f.GetRows(sheetName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read rows: %w", err)
+	// Parse the first sheet with a recognized header row, remembering the
+	// first unknown format so it can be reported if no sheet matches
+	var formatErr error
+	for _, sheetName := range sheets {
+		rows, err := f.GetRows(sheetName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rows: %w", err)
+		}
+
+		// Skip preamble rows; sheets without a header row are skipped entirely
+		if skipRows >= len(rows) {
+			continue
+		}
+		rows = rows[skipRows:]
+
+		transactions, err := p.parseRows(rows[0], rows[1:])
+		var unknownErr *UnknownBankFormatError
+		if errors.As(err, &unknownErr) {
+			if formatErr == nil {
+				formatErr = err
+			}
+			continue
+		}
+		return transactions, err
 	}
 
-	if len(rows) == 0 {
-		return nil, fmt.Errorf("empty file")
+	if formatErr != nil {
+		return nil, formatErr
 	}
+	return nil, fmt.Errorf("empty file")
+}
 
-	// Extract headers and data rows
-	headers := rows[0]
-	dataRows := rows[1:]
+// hintedSheetOrder moves the sheet matching hint (case-insensitive) to the front
+func hintedSheetOrder(sheets []string, hint string) ([]string, error) {
+	for i, name := range sheets {
+		if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(hint)) {
+			ordered := append([]string{name}, sheets[:i]...)
+			return append(ordered, sheets[i+1:]...), nil
+		}
+	}
+	return nil, fmt.Errorf("sheet %q not found in XLSX file (sheets: %s)", hint, strings.Join(sheets, ", "))
+}
 
-	// Use common parsing logic
-	return p.parseRows(headers, dataRows)
+// maxHeaderScanRows caps how many leading rows are searched for the header row
+const maxHeaderScanRows = 20
+
+// locateHeaderRow finds the header row among the first maxHeaderScanRows rows, since
+// many exports start with account holder details, and returns it with the rows
+// after it and the detected bank. When no row matches a bank, the rows are returned
+// unchanged with "UNKNOWN" so the first row is reported as the unmatched header.
+func (p *Parser) locateHeaderRow(headers []string, dataRows [][]string) ([]string, [][]string, string) {
+	schemas := p.schemasInOrder()
+	if bankName := detectBank(headers, schemas, p.detectOpts); bankName != "UNKNOWN" {
+		return headers, dataRows, bankName
+	}
+	for i := 0; i < len(dataRows) && i < maxHeaderScanRows-1; i++ {
+		if isEmptyRow(dataRows[i]) {
+			continue
+		}
+		if bankName := detectBank(dataRows[i], schemas, p.detectOpts); bankName != "UNKNOWN" {
+			return dataRows[i], dataRows[i+1:], bankName
+		}
+	}
+	return headers, dataRows, "UNKNOWN"
 }
 
 // parseRows is a common function that processes headers and data rows
 func (p *Parser) parseRows(headers []string, dataRows [][]string) ([]models.ParsedTransaction, error) {
-	// Detect bank
-	bankName := DetectBank(headers)
+	// Detect bank, looking past any preamble rows for the header
+	rowCount := len(dataRows) + 1
+	headers, dataRows, bankName := p.locateHeaderRow(headers, dataRows)
+	headerLine := rowCount - len(dataRows) // 1-based line of the header within the rows given
 	if bankName == "UNKNOWN" {
-		return nil, fmt.Errorf("unknown bank format")
+		trimmed := make([]string, len(headers))
+		for i, h := range headers {
+			trimmed[i] = strings.TrimSpace(h)
+		}
+		return nil, &UnknownBankFormatError{Headers: trimmed}
 	}
 
 	schema := p.bankSchemas[bankName]
 
-	// Create header index map
-	headerIndex := make(map[string]int)
-	for i, h := range headers {
-		headerIndex[strings.TrimSpace(h)] = i
+	// Create header index map keyed by normalized header
+	headerIndex, err := p.buildHeaderIndex(headers, schema)
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse data rows
 	var transactions []models.ParsedTransaction
+	var account string    // Set by account-header rows in combined statements
+	var hasBalance []bool // Whether each transaction's running balance was read
 	for rowNum, row := range dataRows {
 		// Skip empty rows
 		if isEmptyRow(row) {
 			continue
 		}
 
+		// Account-header rows switch the account for the rows that follow
+		if number, ok := accountHeaderNumber(row); ok {
+			account = number
+			continue
+		}
+
+		// Skip header rows repeated at the start of each account section
+		if isRepeatedHeaderRow(row, headerIndex, p.detectOpts.stripPeriods) {
+			continue
+		}
+
 		// Skip summary rows
 		if isSummaryRow(row) {
 			continue
 		}
 
 		// Parse transaction
-		txn, err := p.parseRow(row, headerIndex, schema)
+		padded := padRow(row, len(headers))
+		txn, err := p.parseRow(padded, headerIndex, schema)
 		if err != nil {
+			if errors.Is(err, errZeroAmountRow) && p.zeroAmounts == ZeroAmountSkip {
+				continue
+			}
 			// Log error but continue parsing
 			fmt.Printf("Warning: skipping row %d: %v\n", rowNum+2, err)
+			p.warn("line %d: skipped row: %v", p.warnOffset+headerLine+rowNum+1, err)
 			continue
 		}
 
+		for _, amount := range p.malformedAmounts(padded, headerIndex, schema) {
+			p.warn("line %d: amount %q has malformed digit grouping; it may have been misparsed", p.warnOffset+headerLine+rowNum+1, amount)
+		}
+
+		txn.Flags = importFlags(txn, time.Now())
+		txn.Account = account
+		balance, ok := p.parseBalance(padded, headerIndex, schema)
+		txn.Balance = balance
 		transactions = append(transactions, txn)
+		hasBalance = append(hasBalance, ok)
 	}
 
+	flagBalanceMismatches(transactions, hasBalance, p.reconciler)
 	return transactions, nil
 }
 
-// ParseFile is the unified entry point for parsing CSV, XLSX, or PDF files
+// parseBalance reads the running balance of a row. It reports false when the schema
+// has no balance column or the cell is empty or unreadable. A trailing "Dr" marks an
+// overdrawn (negative) balance.
+func (p *Parser) parseBalance(row []string, headerIndex map[string]int, schema models.BankSchema) (float64, bool) {
+	if schema.BalanceColumn == "" {
+		return 0, false
+	}
+	idx, ok := headerIndex[NormalizeHeader(schema.BalanceColumn, p.detectOpts.stripPeriods)]
+	if !ok || strings.TrimSpace(row[idx]) == "" {
+		return 0, false
+	}
+	balance, marker, err := parseMarkedAmount(row[idx])
+	if err != nil {
+		return 0, false
+	}
+	if marker == "dr" {
+		balance = -math.Abs(balance)
+	}
+	return balance, true
+}
+
+// flagBalanceMismatches flags rows whose running balance is not the previous row's
+// balance plus the row's amount, which usually means a misparsed amount or a skipped
+// row. Rows are walked in date order, so statements listing the newest row first are
+// checked backwards, and the chain restarts at each account in combined statements.
+// After a mismatch the next row may follow from either the stated or the computed
+// balance, so a single corrupted balance only flags its own row.
+func flagBalanceMismatches(transactions []models.ParsedTransaction, hasBalance []bool, reconciler *BalanceReconciler) {
+	order := make([]int, 0, len(transactions))
+	for i := range transactions {
+		if hasBalance[i] {
+			order = append(order, i)
+		}
+	}
+	if len(order) < 2 {
+		return
+	}
+	if transactions[order[0]].TxnDate.After(transactions[order[len(order)-1]].TxnDate) {
+		slices.Reverse(order)
+	}
+
+	var prev, computed float64
+	var havePrev, afterMismatch bool
+	var account string
+	for _, i := range order {
+		txn := &transactions[i]
+		if !havePrev || txn.Account != account {
+			prev, havePrev, afterMismatch, account = txn.Balance, true, false, txn.Account
+			continue
+		}
+		matches := reconciler.Matches(prev+txn.Amount, txn.Balance)
+		if !matches && afterMismatch {
+			matches = reconciler.Matches(computed+txn.Amount, txn.Balance)
+		}
+		if !matches {
+			txn.Flags = append(txn.Flags, models.FlagReconciliationMismatch)
+			computed = prev + txn.Amount
+		}
+		prev, afterMismatch = txn.Balance, !matches
+	}
+}
+
+// earliestPlausibleDate is the oldest transaction date accepted without flagging
+var earliestPlausibleDate = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// importFlags returns the review flags for a parsed transaction:
+// dates in the future or implausibly far in the past, and zero amounts
+func importFlags(txn models.ParsedTransaction, now time.Time) []string {
+	var flags []string
+	if txn.TxnDate.Before(earliestPlausibleDate) || txn.TxnDate.After(now.AddDate(0, 0, 1)) {
+		flags = append(flags, models.FlagBadDate)
+	}
+	if txn.Amount == 0 {
+		flags = append(flags, models.FlagZeroAmount)
+	}
+	return flags
+}
+
+// ParseFile is the unified entry point for parsing CSV, XLSX, PDF, OFX or QIF files
 func (p *Parser) ParseFile(file io.Reader, filename string) ([]models.ParsedTransaction, error) {
 	ext := strings.ToLower(filepath.Ext(filename))
 
 	switch ext {
-	case ".csv":
+	case ".csv", ".txt":
 		return p.ParseCSV(file)
 	case ".xlsx", ".xls":
 		return p.ParseXLSX(file)
 	case ".pdf":
 		return p.ParsePDF(file)
+	case ".ofx":
+		return p.ParseOFX(file)
+	case ".qif":
+		return p.ParseQIF(file)
 	default:
 		return nil, fmt.Errorf("unsupported file type: %s", ext)
 	}
 }
 
+// ParseFileSkippingRows parses a CSV or XLSX file after skipping a fixed number of
+// leading rows. It is a manual override for banks whose preambles are unpredictable.
+// PDF statements are parsed by the microservice and, like OFX and QIF files, ignore skipRows.
+func (p *Parser) ParseFileSkippingRows(file io.Reader, filename string, skipRows int) ([]models.ParsedTransaction, error) {
+	if skipRows < 0 {
+		return nil, fmt.Errorf("skip rows must not be negative")
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+
+	switch ext {
+	case ".csv", ".txt":
+		return p.parseCSV(file, skipRows)
+	case ".xlsx", ".xls":
+		return p.parseXLSX(file, skipRows, "")
+	case ".pdf":
+		return p.ParsePDF(file)
+	case ".ofx":
+		return p.ParseOFX(file)
+	case ".qif":
+		return p.ParseQIF(file)
+	default:
+		return nil, fmt.Errorf("unsupported file type: %s", ext)
+	}
+}
+
+// ParseFileReportingSkips parses a file like ParseFileSkippingRows, reading the named
+// sheet of a workbook when sheet is set, and also returns a warning for each row that
+// was skipped because it could not be parsed
+func (p *Parser) ParseFileReportingSkips(file io.Reader, filename string, skipRows int, sheet string) ([]models.ParsedTransaction, []string, error) {
+	warnings := []string{}
+	collecting := *p
+	collecting.warnings = &warnings
+	collecting.warnOffset = skipRows
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	var transactions []models.ParsedTransaction
+	var err error
+	if sheet != "" && (ext == ".xlsx" || ext == ".xls") {
+		transactions, err = collecting.ParseXLSXSheet(file, sheet, skipRows)
+	} else {
+		transactions, err = collecting.ParseFileSkippingRows(file, filename, skipRows)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return transactions, warnings, nil
+}
+
+// warn records a skipped-row warning when the parser is collecting them
+func (p *Parser) warn(format string, args ...interface{}) {
+	if p.warnings != nil {
+		*p.warnings = append(*p.warnings, fmt.Sprintf(format, args...))
+	}
+}
+
+// pdfPageObject matches page objects in raw PDF bytes, but not the /Pages tree nodes
+var pdfPageObject = regexp.MustCompile(`/Type\s*/Page\b`)
+
+// CountPDFPages estimates the page count from the page objects in a PDF file.
+// Pages stored in compressed object streams are not visible, so 0 means unknown.
+func CountPDFPages(data []byte) int {
+	return len(pdfPageObject.FindAllIndex(data, -1))
+}
+
 // ParsePDF calls the Python PDF parser microservice and returns parsed transactions
 func (p *Parser) ParsePDF(file io.Reader) ([]models.ParsedTransaction, error) {
+	transactions, _, err := p.ParsePDFCheckingPages(context.Background(), file, 0)
+	return transactions, err
+}
+
+// ParsePDFCheckingPages parses a PDF like ParsePDF and also returns a warning when the
+// microservice processed fewer pages than expected, which usually means the statement
+// was truncated. expectedPages of 0 falls back to the page count detected in the file;
+// when neither is known no check is made. Cancelling ctx abandons the call and any retries.
+func (p *Parser) ParsePDFCheckingPages(ctx context.Context, file io.Reader, expectedPages int) ([]models.ParsedTransaction, string, error) {
+	if p.pdfServiceURL == "" {
+		return nil, "", ErrPDFServiceNotConfigured
+	}
+
 	// Read file content
 	fileData, err := io.ReadAll(file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read PDF file: %w", err)
+		return nil, "", fmt.Errorf("failed to read PDF file: %w", err)
+	}
+	if expectedPages <= 0 {
+		expectedPages = CountPDFPages(fileData)
+	}
+
+	pdfResponse, err := p.callPDFService(ctx, fileData)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Validate response
+	if len(pdfResponse.Rows) == 0 {
+		return nil, "", fmt.Errorf("no rows returned from PDF parser")
+	}
+
+	// Extract headers and data rows
+	headers := pdfResponse.Rows[0]
+	dataRows := pdfResponse.Rows[1:]
+
+	// Use common parsing logic
+	transactions, err := p.parseRows(headers, dataRows)
+	if err != nil {
+		return nil, "", err
 	}
 
+	var pagesWarning string
+	if expectedPages > 0 && pdfResponse.PagesProcessed < expectedPages {
+		pagesWarning = fmt.Sprintf("only %d of %d pages were processed; the statement may be incomplete",
+			pdfResponse.PagesProcessed, expectedPages)
+	}
+	return transactions, pagesWarning, nil
+}
+
+// callPDFService posts the PDF to the microservice's /parse endpoint, retrying
+// connection errors and 5xx responses with exponential backoff until ctx is done
+func (p *Parser) callPDFService(ctx context.Context, fileData []byte) (*PDFParserResponse, error) {
+	attempts := max(p.pdfAttempts, 1)
+	delay := p.pdfRetryDelay
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+			delay *= 2
+		}
+
+		pdfResponse, retryable, err := p.postPDF(ctx, fileData)
+		if err == nil {
+			return pdfResponse, nil
+		}
+		lastErr = err
+		if !retryable {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// postPDF makes a single call to the microservice. The multipart body is rebuilt from
+// fileData each time since a sent request body cannot be replayed. retryable reports
+// whether the failure was transient (connection error or 5xx).
+func (p *Parser) postPDF(ctx context.Context, fileData []byte) (pdfResponse *PDFParserResponse, retryable bool, err error) {
 	// Create multipart form request
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
 	part, err := writer.CreateFormFile("file", "statement.pdf")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create form file: %w", err)
+		return nil, false, fmt.Errorf("failed to create form file: %w", err)
 	}
 
 	_, err = part.Write(fileData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to write file data: %w", err)
+		return nil, false, fmt.Errorf("failed to write file data: %w", err)
 	}
 
 	err = writer.Close()
 	if err != nil {
-		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
+		return nil, false, fmt.Errorf("failed to close multipart writer: %w", err)
 	}
 
 	// Send POST request to PDF parser service
 	url := p.pdfServiceURL + "/parse"
-	req, err := http.NewRequest("POST", url, body)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call PDF parser service: %w", err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, false, ctxErr
+		}
+		return nil, true, &PDFServiceUnavailableError{Err: fmt.Errorf("failed to call PDF parser service: %w", err)}
 	}
 	defer resp.Body.Close()
 
-	// Check HTTP status
+	// Check HTTP status; gateway errors mean the service is down, not that the file is bad
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return nil, true, &PDFServiceUnavailableError{Err: fmt.Errorf("PDF parser service returned status %d", resp.StatusCode)}
+	}
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("PDF parser service returned error (status %d): %s", resp.StatusCode, string(bodyBytes))
+		return nil, resp.StatusCode >= http.StatusInternalServerError,
+			fmt.Errorf("PDF parser service returned error (status %d): %s", resp.StatusCode, string(bodyBytes))
 	}
 
 	// Decode JSON response
-	var pdfResponse PDFParserResponse
-	if err := json.NewDecoder(resp.Body).Decode(&pdfResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	// Validate response
-	if len(pdfResponse.Rows) == 0 {
-		return nil, fmt.Errorf("no rows returned from PDF parser")
+	var decoded PDFParserResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, false, fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	// Extract headers and data rows
-	headers := pdfResponse.Rows[0]
-	dataRows := pdfResponse.Rows[1:]
-
-	// Use common parsing logic
-	return p.parseRows(headers, dataRows)
+	return &decoded, false, nil
 }
@@ -2,14 +2,17 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/ashmitsharp/cashlens-api/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/xuri/excelize/v2"
@@ -45,6 +48,297 @@ func TestDetectBank_Kotak(t *testing.T) {
 	assert.Equal(t, "Kotak", bank)
 }
 
+func TestDetectBank_YesBank(t *testing.T) {
+	headers := []string{"Transaction Date", "Value Date", "Description", "Cheque No", "Debit", "Credit", "Running Balance"}
+	bank := DetectBank(headers)
+	assert.Equal(t, "Yes Bank", bank)
+}
+
+func TestDetectBank_IDFCFirst(t *testing.T) {
+	headers := []string{"Transaction Date", "Value Date", "Particulars", "Cheque No.", "Debit Amount", "Credit Amount", "Balance"}
+	bank := DetectBank(headers)
+	assert.Equal(t, "IDFC First", bank)
+}
+
+func TestDetectBank_SharedGenericHeaders(t *testing.T) {
+	// Headers shared with Yes Bank/IDFC must keep resolving to the original banks
+	assert.Equal(t, "SBI", DetectBank([]string{"Txn Date", "Description", "Debit", "Credit", "Balance"}))
+	assert.Equal(t, "Kotak", DetectBank([]string{"Date", "Description", "Ref No.", "Debit", "Credit", "Balance"}))
+	assert.Equal(t, "Axis", DetectBank([]string{"Transaction Date", "Particulars", "Dr/Cr", "Amount", "Balance"}))
+	// Yes Bank's generic columns without "Running Balance" are not enough
+	assert.Equal(t, "UNKNOWN", DetectBank([]string{"Transaction Date", "Description", "Debit", "Credit", "Balance"}))
+}
+
+func TestDetectBank_GenericHeadersNotKotak(t *testing.T) {
+	// Another bank's statement that shares Kotak's generic columns but lacks "Ref No."
+	headers := []string{"Date", "Description", "Cheque No", "Debit", "Credit", "Balance"}
+	assert.Equal(t, "UNKNOWN", DetectBank(headers))
+
+	parser := NewParser()
+	csvData := "Date,Description,Cheque No,Debit,Credit,Balance\n" +
+		"15/01/2024,AWS SERVICES,,3500.00,,46500.00\n"
+	_, err := parser.ParseCSV(strings.NewReader(csvData))
+	assert.Error(t, err)
+}
+
+func TestDetectBank_LenientKotak(t *testing.T) {
+	csvData := "Date,Description,Cheque No,Debit,Credit,Balance\n" +
+		"15/01/2024,AWS SERVICES,,3500.00,,46500.00\n"
+
+	parser := NewParser()
+	parser.SetStrictKotakDetection(false)
+	transactions, err := parser.ParseCSV(strings.NewReader(csvData))
+
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, -3500.0, transactions[0].Amount)
+}
+
+func TestNewParserFromConfig(t *testing.T) {
+	parser, err := NewParserFromConfig("../../testdata/bank_schemas.json")
+	require.NoError(t, err)
+
+	file, err := os.Open("../../testdata/federal_sample.csv")
+	require.NoError(t, err)
+	defer file.Close()
+
+	transactions, err := parser.ParseCSV(file)
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "PAYMENT TO AWS SERVICES", transactions[0].Description)
+	assert.Equal(t, -3500.0, transactions[0].Amount)
+	assert.Equal(t, 50000.0, transactions[1].Amount)
+
+	// Built-in banks are still detected
+	kotak, err := os.Open("../../testdata/kotak_sample.csv")
+	require.NoError(t, err)
+	defer kotak.Close()
+	transactions, err = parser.ParseCSV(kotak)
+	require.NoError(t, err)
+	assert.Len(t, transactions, 10)
+
+	// The default parser doesn't know the configured bank
+	federal, err := os.Open("../../testdata/federal_sample.csv")
+	require.NoError(t, err)
+	defer federal.Close()
+	_, err = NewParser().ParseCSV(federal)
+	var unknownErr *UnknownBankFormatError
+	assert.ErrorAs(t, err, &unknownErr)
+}
+
+func TestNewParserFromConfig_YAMLOverridesBuiltin(t *testing.T) {
+	// A configured Kotak without the strict "Ref No." requirement replaces the built-in one
+	path := filepath.Join(t.TempDir(), "banks.yaml")
+	config := `banks:
+  - bank_name: Kotak
+    date_column: Date
+    description_column: Description
+    debit_column: Debit
+    credit_column: Credit
+    has_separate_amounts: true
+    detect_headers: [Date, Description, Debit, Credit, Cheque No]
+`
+	require.NoError(t, os.WriteFile(path, []byte(config), 0o600))
+
+	parser, err := NewParserFromConfig(path)
+	require.NoError(t, err)
+
+	csvData := "Date,Description,Cheque No,Debit,Credit,Balance\n" +
+		"15/01/2024,AWS SERVICES,,3500.00,,46500.00\n"
+	transactions, err := parser.ParseCSV(strings.NewReader(csvData))
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, -3500.0, transactions[0].Amount)
+	assert.Len(t, parser.schemasInOrder(), len(builtinBankSchemas))
+}
+
+func TestNewParserFromConfig_Errors(t *testing.T) {
+	parser, err := NewParserFromConfig("")
+	require.NoError(t, err)
+	assert.Len(t, parser.schemasInOrder(), len(builtinBankSchemas))
+
+	_, err = NewParserFromConfig("../../testdata/does_not_exist.json")
+	assert.Error(t, err)
+
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"Malformed JSON", `{"banks": [`},
+		{"Missing bank name", `{"banks": [{"date_column": "Date", "description_column": "Details", "amount_column": "Amount", "dr_cr_column": "Type", "detect_headers": ["Details"]}]}`},
+		{"No detect headers", `{"banks": [{"bank_name": "X", "date_column": "Date", "description_column": "Details", "amount_column": "Amount", "dr_cr_column": "Type"}]}`},
+		{"Separate amounts without credit column", `{"banks": [{"bank_name": "X", "date_column": "Date", "description_column": "Details", "debit_column": "Debit", "has_separate_amounts": true, "detect_headers": ["Details"]}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "banks.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.config), 0o600))
+			_, err := NewParserFromConfig(path)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDetectBank_HDFCHeaderVariants(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+	}{
+		{"Double space", []string{"Date", "Narration", "Withdrawal  Amt.", "Deposit Amt.", "Closing Balance"}},
+		{"Missing period", []string{"Date", "Narration", "Withdrawal Amt", "Deposit Amt", "Closing Balance"}},
+		{"Padded and upper case", []string{" DATE ", " NARRATION", "WITHDRAWAL AMT. ", "DEPOSIT AMT.", "CLOSING BALANCE"}},
+		{"Tab inside header", []string{"Date", "Narration", "Withdrawal\tAmt.", "Deposit Amt.", "Closing Balance"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, "HDFC", DetectBank(tt.headers))
+		})
+	}
+}
+
+func TestParseCSV_HDFCHeaderVariants(t *testing.T) {
+	csvData := "Date,Narration,Withdrawal  Amt,Deposit Amt,Closing Balance\n" +
+		"15/01/2024,AWS SERVICES,3500.00,,46500.00\n" +
+		"16/01/2024,SALARY CREDIT,,50000.00,96500.00\n"
+
+	parser := NewParser()
+	transactions, err := parser.ParseCSV(strings.NewReader(csvData))
+
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, -3500.0, transactions[0].Amount)
+	assert.Equal(t, 50000.0, transactions[1].Amount)
+}
+
+func TestParseCSV_EmbeddedQuotesAndRaggedRows(t *testing.T) {
+	csvData := "Date,Narration,Withdrawal Amt.,Deposit Amt.,Closing Balance\n" +
+		"15/01/2024,PAYMENT TO M/S \"ACME\" LTD,3500.00,,46500.00\n" +
+		"16/01/2024,SALARY CREDIT,,50000.00\n" +
+		"17/01/2024,SWIGGY,450.00,,96050.00,EXTRA\n"
+
+	parser := NewParser()
+	transactions, err := parser.ParseCSV(strings.NewReader(csvData))
+
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+	assert.Equal(t, `PAYMENT TO M/S "ACME" LTD`, transactions[0].Description)
+	assert.Equal(t, -3500.0, transactions[0].Amount)
+	assert.Equal(t, 50000.0, transactions[1].Amount)
+	assert.Equal(t, -450.0, transactions[2].Amount)
+}
+
+func TestSniffDelimiter(t *testing.T) {
+	tests := []struct {
+		name     string
+		sample   string
+		expected rune
+	}{
+		{"comma", "Date,Narration,Debit\n15/01/2024,AWS,3500.00\n", ','},
+		{"pipe", "Txn Date|Description|Debit\n15-Jan-2024|AWS, INDIA|3500.00\n", '|'},
+		{"semicolon", "Date;Narration;Debit\n15/01/2024;AWS;3500,00\n", ';'},
+		{"tab", "Date\tNarration\tDebit\n15/01/2024\tAWS\t3500.00\n", '\t'},
+		{"quoted pipes in comma file", "Date,Narration,Debit\n15/01/2024,\"A|B|C|D\",3500.00\n", ','},
+		{"empty", "", ','},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SniffDelimiter([]byte(tt.sample)))
+		})
+	}
+}
+
+func TestParseFile_PipeDelimited(t *testing.T) {
+	file, err := os.Open("../../testdata/sbi_pipe_sample.txt")
+	require.NoError(t, err)
+	defer file.Close()
+
+	transactions, err := NewParser().ParseFile(file, "sbi_pipe_sample.txt")
+	require.NoError(t, err)
+	require.Len(t, transactions, 5)
+	assert.Equal(t, "PAYMENT TO AWS SERVICES", transactions[0].Description)
+	assert.Equal(t, -3500.0, transactions[0].Amount)
+	assert.Equal(t, "SALARY FROM ACME CORP, BANGALORE", transactions[1].Description)
+	assert.Equal(t, 50000.0, transactions[1].Amount)
+	assert.Equal(t, "GOOGLE ADS | CAMPAIGN", transactions[3].Description)
+
+	raw, err := os.ReadFile("../../testdata/sbi_pipe_sample.txt")
+	require.NoError(t, err)
+	headerLine, _, _ := strings.Cut(string(raw), "\n")
+	assert.Equal(t, "SBI", DetectBank(strings.Split(headerLine, "|")))
+}
+
+func TestParseFileSkippingRows(t *testing.T) {
+	// The header sits past the rows searched automatically
+	csvData := strings.Repeat("Statement of account\n", maxHeaderScanRows) +
+		"Account No,50100123456789\n" +
+		"Date,Narration,Withdrawal Amt.,Deposit Amt.,Closing Balance\n" +
+		"15/01/2024,AWS SERVICES,3500.00,,46500.00\n"
+
+	parser := NewParser()
+
+	// Without the override the first preamble line is reported as the header row
+	_, err := parser.ParseFile(strings.NewReader(csvData), "statement.csv")
+	require.Error(t, err)
+
+	transactions, err := parser.ParseFileSkippingRows(strings.NewReader(csvData), "statement.csv", maxHeaderScanRows+1)
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "AWS SERVICES", transactions[0].Description)
+
+	_, err = parser.ParseFileSkippingRows(strings.NewReader(csvData), "statement.csv", 30)
+	assert.EqualError(t, err, "empty file")
+}
+
+func TestParseCSV_PreambleRows(t *testing.T) {
+	file, err := os.Open("../../testdata/hdfc_preamble_sample.csv")
+	require.NoError(t, err)
+	defer file.Close()
+
+	parser := NewParser()
+	transactions, err := parser.ParseCSV(file)
+
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+	assert.Equal(t, "AWS SERVICES", transactions[0].Description)
+	assert.Equal(t, -3500.0, transactions[0].Amount)
+	assert.Equal(t, 15, transactions[0].TxnDate.Day())
+	assert.Equal(t, "SALARY CREDIT - ACME CORP", transactions[1].Description)
+	assert.Equal(t, 50000.0, transactions[1].Amount)
+}
+
+func TestParseRows_PreambleWithoutHeader(t *testing.T) {
+	rows := [][]string{
+		{"Account No", "50100123456789"},
+		{"Posting Date", "Details", "Amount"},
+		{"15/01/2024", "AWS SERVICES", "3500.00"},
+	}
+
+	_, err := NewParser().parseRows(rows[0], rows[1:])
+
+	var formatErr *UnknownBankFormatError
+	require.ErrorAs(t, err, &formatErr)
+	assert.Equal(t, []string{"Account No", "50100123456789"}, formatErr.Headers)
+}
+
+func TestParseCSV_StrictHeaderPeriods(t *testing.T) {
+	csvData := "Date,Narration,Withdrawal Amt,Deposit Amt,Closing Balance\n" +
+		"15/01/2024,AWS SERVICES,3500.00,,46500.00\n"
+
+	parser := NewParser()
+	parser.SetStripHeaderPeriods(false)
+	_, err := parser.ParseCSV(strings.NewReader(csvData))
+
+	assert.Error(t, err)
+}
+
+func TestNormalizeHeader(t *testing.T) {
+	assert.Equal(t, "withdrawal amt", NormalizeHeader("  Withdrawal   Amt. ", true))
+	assert.Equal(t, "withdrawal amt.", NormalizeHeader("  Withdrawal   Amt. ", false))
+	assert.Equal(t, "chq./ref.no", NormalizeHeader("Chq./Ref.No.", true))
+}
+
 func TestDetectBank_Unknown(t *testing.T) {
 	headers := []string{"Random", "Headers", "That", "Dont", "Match"}
 	bank := DetectBank(headers)
@@ -75,6 +369,40 @@ func TestParseDate_YYYYMMDD(t *testing.T) {
 	assert.Equal(t, 15, date.Day())
 }
 
+func TestParseDate_FullMonthName(t *testing.T) {
+	date, err := ParseDate("15 January 2024")
+	require.NoError(t, err)
+	assert.Equal(t, 2024, date.Year())
+	assert.Equal(t, time.January, date.Month())
+	assert.Equal(t, 15, date.Day())
+}
+
+func TestParseDate_ConfiguredMonthName(t *testing.T) {
+	parser := NewParser()
+
+	_, err := parser.parseDate("15-Farvari-2024")
+	assert.Error(t, err)
+
+	parser.SetMonthNames(HindiMonthNames)
+	date, err := parser.parseDate("15-Farvari-2024")
+	require.NoError(t, err)
+	assert.Equal(t, 2024, date.Year())
+	assert.Equal(t, time.February, date.Month())
+	assert.Equal(t, 15, date.Day())
+}
+
+func TestParser_SetMonthNameLocales(t *testing.T) {
+	parser := NewParser()
+
+	err := parser.SetMonthNameLocales([]string{"klingon"})
+	assert.Error(t, err)
+
+	require.NoError(t, parser.SetMonthNameLocales([]string{"Hindi"}))
+	date, err := parser.parseDate("15-Farvari-2024")
+	require.NoError(t, err)
+	assert.Equal(t, time.February, date.Month())
+}
+
 func TestParseDate_Invalid(t *testing.T) {
 	_, err := ParseDate("invalid-date")
 	assert.Error(t, err)
@@ -104,6 +432,33 @@ func TestParseAmount_WithRs(t *testing.T) {
 	assert.Equal(t, 3500.0, amount)
 }
 
+func TestParseAmount_WithNBSPSeparators(t *testing.T) {
+	amount, err := ParseAmount("\u00a01\u00a050\u00a0000.00\u00a0")
+	require.NoError(t, err)
+	assert.Equal(t, 150000.0, amount)
+}
+
+func TestParseAmount_WithNarrowSpaces(t *testing.T) {
+	amount, err := ParseAmount("₹ 12\u202f345.50\u2009")
+	require.NoError(t, err)
+	assert.Equal(t, 12345.5, amount)
+}
+
+func TestParseAmount_WithSignPrefix(t *testing.T) {
+	tests := map[string]float64{
+		"+50000":     50000,
+		"-3500":      -3500,
+		"+ 1,250.50": 1250.50,
+		"+₹50,000":   50000,
+		"₹-3,500.00": -3500,
+	}
+	for input, expected := range tests {
+		amount, err := ParseAmount(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, amount, input)
+	}
+}
+
 func TestParseAmount_Empty(t *testing.T) {
 	amount, err := ParseAmount("")
 	require.NoError(t, err)
@@ -115,6 +470,80 @@ func TestParseAmount_Invalid(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestWellFormedGrouping(t *testing.T) {
+	testCases := []struct {
+		amount  string
+		indian  bool
+		western bool
+	}{
+		{"1,23,456.78", true, false},
+		{"12,34,56,789.00", true, false},
+		{"123,456.78", false, true},
+		{"1,234,567.89", false, true},
+		{"12,345.00", true, true},
+		{"999.00", true, true},
+		{"1234567.00", true, true},
+		{"₹ 1,23,456.78", true, false},
+		{"-1,23,456.78 Dr", true, false},
+		{"12,3456.00", false, false},
+		{"1,2,345.00", false, false},
+		{"1,23,45.00", false, false},
+		{",123.00", false, false},
+		{"1,234.5,6", false, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.amount, func(t *testing.T) {
+			assert.Equal(t, tc.indian, WellFormedGrouping(tc.amount, AmountGroupingIndian), "indian")
+			assert.Equal(t, tc.western, WellFormedGrouping(tc.amount, AmountGroupingWestern), "western")
+			assert.Equal(t, tc.indian || tc.western, WellFormedGrouping(tc.amount, AmountGroupingAny), "any")
+		})
+	}
+}
+
+func TestParseCSV_AmountGrouping(t *testing.T) {
+	csvData := "Date,Narration,Chq./Ref.No.,Value Dt,Withdrawal Amt.,Deposit Amt.\n" +
+		"15/01/2024,AWS SERVICES,,15/01/2024,\"1,23,456.00\",\n" +
+		"16/01/2024,RENT,,16/01/2024,\"12,3456.00\",\n" +
+		"17/01/2024,SALARY CREDIT,,17/01/2024,,\"150,000.00\"\n"
+
+	t.Run("Off by default", func(t *testing.T) {
+		transactions, warnings, err := NewParser().ParseFileReportingSkips(strings.NewReader(csvData), "statement.csv", 0, "")
+		require.NoError(t, err)
+		require.Len(t, transactions, 3)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("Indian grouping warns about malformed amounts", func(t *testing.T) {
+		parser := NewParser()
+		require.NoError(t, parser.SetAmountGrouping(AmountGroupingIndian))
+
+		transactions, warnings, err := parser.ParseFileReportingSkips(strings.NewReader(csvData), "statement.csv", 0, "")
+		require.NoError(t, err)
+		require.Len(t, transactions, 3, "malformed amounts are still imported")
+		assert.Equal(t, -123456.0, transactions[1].Amount)
+		assert.Equal(t, []string{
+			`line 3: amount "12,3456.00" has malformed digit grouping; it may have been misparsed`,
+			`line 4: amount "150,000.00" has malformed digit grouping; it may have been misparsed`,
+		}, warnings)
+	})
+
+	t.Run("Any grouping accepts both locales", func(t *testing.T) {
+		parser := NewParser()
+		require.NoError(t, parser.SetAmountGrouping(AmountGroupingAny))
+
+		_, warnings, err := parser.ParseFileReportingSkips(strings.NewReader(csvData), "statement.csv", 0, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			`line 3: amount "12,3456.00" has malformed digit grouping; it may have been misparsed`,
+		}, warnings)
+	})
+
+	t.Run("Unknown grouping is rejected", func(t *testing.T) {
+		assert.Error(t, NewParser().SetAmountGrouping("french"))
+	})
+}
+
 func TestParseCSV_HDFC(t *testing.T) {
 	file, err := os.Open("../../testdata/hdfc_sample.csv")
 	require.NoError(t, err)
@@ -218,6 +647,392 @@ func TestParseCSV_Kotak(t *testing.T) {
 	assert.Equal(t, "debit", transactions[0].TxnType)
 }
 
+func TestParseCSV_YesBank(t *testing.T) {
+	file, err := os.Open("../../testdata/yesbank_sample.csv")
+	require.NoError(t, err)
+	defer file.Close()
+
+	parser := NewParser()
+	transactions, err := parser.ParseCSV(file)
+
+	require.NoError(t, err)
+	assert.Len(t, transactions, 5)
+
+	// Validate first transaction
+	assert.Equal(t, "PAYMENT TO AWS SERVICES", transactions[0].Description)
+	assert.Equal(t, -3500.0, transactions[0].Amount)
+	assert.Equal(t, "debit", transactions[0].TxnType)
+	assert.Equal(t, 50000.0, transactions[1].Amount)
+	assert.Equal(t, "credit", transactions[1].TxnType)
+	assert.Equal(t, 1250.75, transactions[4].Amount)
+}
+
+func TestParseCSV_IDFCFirst(t *testing.T) {
+	file, err := os.Open("../../testdata/idfc_sample.csv")
+	require.NoError(t, err)
+	defer file.Close()
+
+	parser := NewParser()
+	transactions, err := parser.ParseCSV(file)
+
+	require.NoError(t, err)
+	assert.Len(t, transactions, 4)
+
+	// Validate first transaction
+	assert.Equal(t, "PAYMENT TO AWS SERVICES", transactions[0].Description)
+	assert.Equal(t, -3500.0, transactions[0].Amount)
+	assert.Equal(t, 2024, transactions[0].TxnDate.Year())
+	assert.Equal(t, 50000.0, transactions[1].Amount)
+	assert.Equal(t, "NEFT/VENDOR PAYMENT, INV 42", transactions[3].Description)
+}
+
+func TestParseCSV_HDFCCreditCard(t *testing.T) {
+	file, err := os.Open("../../testdata/hdfc_credit_card_sample.csv")
+	require.NoError(t, err)
+	defer file.Close()
+
+	parser := NewParser()
+	transactions, err := parser.ParseCSV(file)
+
+	require.NoError(t, err)
+	require.Len(t, transactions, 5)
+
+	// Charges are debits even though the statement shows them as positive amounts
+	assert.Equal(t, "AMAZON WEB SERVICES AWS.AMAZON.CO", transactions[0].Description)
+	assert.Equal(t, -3500.0, transactions[0].Amount)
+	assert.Equal(t, "debit", transactions[0].TxnType)
+	assert.Equal(t, -450.0, transactions[1].Amount)
+
+	// Payments and refunds are marked Cr
+	assert.Equal(t, 25000.0, transactions[2].Amount)
+	assert.Equal(t, "credit", transactions[2].TxnType)
+	assert.Equal(t, 799.0, transactions[3].Amount)
+	assert.Equal(t, "credit", transactions[3].TxnType)
+	assert.Equal(t, -1250.75, transactions[4].Amount)
+}
+
+func TestParseCSV_ICICICreditCard(t *testing.T) {
+	file, err := os.Open("../../testdata/icici_credit_card_sample.csv")
+	require.NoError(t, err)
+	defer file.Close()
+
+	parser := NewParser()
+	transactions, err := parser.ParseCSV(file)
+
+	require.NoError(t, err)
+	require.Len(t, transactions, 5)
+
+	assert.Equal(t, "GOOGLE CLOUD INDIA MUMBAI", transactions[0].Description)
+	assert.Equal(t, -2400.0, transactions[0].Amount)
+	assert.Equal(t, "debit", transactions[0].TxnType)
+	assert.Equal(t, "74332741015000012345678", transactions[0].ReferenceNo)
+	assert.Equal(t, 15000.0, transactions[2].Amount)
+	assert.Equal(t, "credit", transactions[2].TxnType)
+	assert.Equal(t, -8999.0, transactions[3].Amount)
+	assert.Equal(t, 8999.0, transactions[4].Amount)
+	assert.Equal(t, "credit", transactions[4].TxnType)
+}
+
+func TestDetectBank_CreditCards(t *testing.T) {
+	assert.Equal(t, "HDFC Credit Card", DetectBank([]string{"Date", "Transaction Description", "Amount (in Rs.)"}))
+	assert.Equal(t, "ICICI Credit Card", DetectBank([]string{"Transaction Date", "Details", "Amount (INR)", "Reference Number"}))
+	// Savings account statements keep resolving to the account schemas
+	assert.Equal(t, "ICICI", DetectBank([]string{"Transaction Date", "Transaction Remarks", "Withdrawal Amount (INR)", "Deposit Amount (INR)"}))
+}
+
+func TestParseMarkedAmount(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedAmount float64
+		expectedMarker string
+	}{
+		{"499.00", 499.00, ""},
+		{"1,250.00 Cr", 1250.00, "cr"},
+		{"1,250.00CR", 1250.00, "cr"},
+		{"₹ 3,500.00 Dr", 3500.00, "dr"},
+		{"75.50 Cr.", 75.50, "cr"},
+		{"-120.00", -120.00, ""},
+		{"", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			amount, marker, err := parseMarkedAmount(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedAmount, amount)
+			assert.Equal(t, tt.expectedMarker, marker)
+		})
+	}
+
+	_, _, err := parseMarkedAmount("12 credits")
+	assert.Error(t, err)
+}
+
+func TestParseRows_SignedAmountColumn(t *testing.T) {
+	// A configured bank with one signed amount column and no Dr/Cr column
+	parser := NewParser()
+	parser.registerBankSchema(models.BankSchema{
+		BankName:          "Signed",
+		DateColumn:        "Date",
+		DescriptionColumn: "Memo",
+		AmountColumn:      "Net Amount",
+		DetectHeaders:     []string{"Memo", "Net Amount"},
+	})
+	require.NoError(t, validateBankSchema(parser.bankSchemas["Signed"]))
+
+	transactions, err := parser.parseRows(
+		[]string{"Date", "Memo", "Net Amount"},
+		[][]string{{"15/01/2024", "AWS", "-3500.00"}, {"16/01/2024", "SALARY", "50000.00"}},
+	)
+
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, -3500.0, transactions[0].Amount)
+	assert.Equal(t, "debit", transactions[0].TxnType)
+	assert.Equal(t, 50000.0, transactions[1].Amount)
+	assert.Equal(t, "credit", transactions[1].TxnType)
+}
+
+func TestParseRows_SignedAmountPrefix(t *testing.T) {
+	// An export whose single Amount column always carries a +/- prefix
+	parser := NewParser()
+	parser.registerBankSchema(models.BankSchema{
+		BankName:          "Prefixed",
+		DateColumn:        "Date",
+		DescriptionColumn: "Details",
+		AmountColumn:      "Amount",
+		SignedAmount:      true,
+		DetectHeaders:     []string{"Details", "Amount"},
+	})
+	require.NoError(t, validateBankSchema(parser.bankSchemas["Prefixed"]))
+
+	transactions, warnings, err := parser.ParseFileReportingSkips(strings.NewReader(
+		"Date,Details,Amount\n"+
+			"15/01/2024,SALARY,+50000\n"+
+			"16/01/2024,AWS,-3500\n"+
+			"17/01/2024,REFUND,\"+₹1,200.50\"\n"+
+			"18/01/2024,SWIGGY,450\n"),
+		"statement.csv", 0, "")
+
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+	assert.Equal(t, 50000.0, transactions[0].Amount)
+	assert.Equal(t, "credit", transactions[0].TxnType)
+	assert.Equal(t, -3500.0, transactions[1].Amount)
+	assert.Equal(t, "debit", transactions[1].TxnType)
+	assert.Equal(t, 1200.50, transactions[2].Amount)
+	assert.Equal(t, "credit", transactions[2].TxnType)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], `line 5`)
+	assert.Contains(t, warnings[0], `amount "450" has no +/- sign`)
+
+	err = validateBankSchema(models.BankSchema{
+		BankName:          "Conflicting",
+		DateColumn:        "Date",
+		DescriptionColumn: "Details",
+		AmountColumn:      "Amount",
+		SignedAmount:      true,
+		InvertAmountSign:  true,
+		DetectHeaders:     []string{"Details"},
+	})
+	assert.Error(t, err)
+}
+
+func TestExtractReferenceNo(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		expected    string
+	}{
+		{"UPI slash separated", "UPI/123456789/ZOMATO/PAYMENT", "123456789"},
+		{"UPI with VPA first", "UPI-ZOMATO-ZOMATO@HDFCBANK-HDFC0000001-401234567890-ORDER", "401234567890"},
+		{"NEFT number only", "NEFT/789012", "789012"},
+		{"NEFT skips IFSC", "NEFT CR-HDFC0000001-ACME CORP-N012345678", "N012345678"},
+		{"IMPS", "IMPS/P2A/412345678901/JOHN", "412345678901"},
+		{"RTGS lowercase", "rtgs/utr123456789/vendor", "UTR123456789"},
+		{"No reference", "PAYMENT TO AWS SERVICES", ""},
+		{"Rail without number", "UPI/SWIGGY/ORDER", ""},
+		{"Rail inside a word", "UPIXYZ 123456789", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ExtractReferenceNo(tt.description))
+		})
+	}
+}
+
+func TestParseCSV_ReferenceNo(t *testing.T) {
+	csvData := "Date,Narration,Chq./Ref.No.,Value Dt,Withdrawal Amt.,Deposit Amt.,Closing Balance\n" +
+		"15/01/2024,AWS SERVICES,UPI/123456,15/01/2024,3500.00,,450000.00\n" +
+		"16/01/2024,UPI/987654321/ZOMATO/PAYMENT,0000000000000000,16/01/2024,450.00,,449550.00\n" +
+		"17/01/2024,NEFT/789012/ACME CORP,,17/01/2024,,50000.00,499550.00\n" +
+		"18/01/2024,CASH DEPOSIT,,18/01/2024,,1000.00,500550.00\n"
+
+	parser := NewParser()
+	transactions, err := parser.ParseCSV(strings.NewReader(csvData))
+	require.NoError(t, err)
+	require.Len(t, transactions, 4)
+
+	assert.Equal(t, "123456", transactions[0].ReferenceNo, "reference column wins")
+	assert.Equal(t, "987654321", transactions[1].ReferenceNo, "zero-filled column falls back to the narration")
+	assert.Equal(t, "789012", transactions[2].ReferenceNo)
+	assert.Empty(t, transactions[3].ReferenceNo)
+}
+
+func TestParseCSV_RunningBalance(t *testing.T) {
+	header := "Date,Narration,Chq./Ref.No.,Value Dt,Withdrawal Amt.,Deposit Amt.,Closing Balance\n"
+
+	t.Run("Corrupted balance row is flagged", func(t *testing.T) {
+		// Row 3's balance should be 449550.00
+		csvData := header +
+			"15/01/2024,AWS SERVICES,,15/01/2024,3500.00,,450000.00\n" +
+			"16/01/2024,ZOMATO,,16/01/2024,450.00,,449550.00\n" +
+			"17/01/2024,SWIGGY,,17/01/2024,250.00,,494300.00\n" +
+			"18/01/2024,SALARY CREDIT,,18/01/2024,,50000.00,499300.00\n"
+
+		transactions, err := NewParser().ParseCSV(strings.NewReader(csvData))
+		require.NoError(t, err)
+		require.Len(t, transactions, 4)
+
+		assert.Equal(t, 494300.0, transactions[2].Balance)
+		assert.Contains(t, transactions[2].Flags, models.FlagReconciliationMismatch)
+		for _, i := range []int{0, 1, 3} {
+			assert.NotContains(t, transactions[i].Flags, models.FlagReconciliationMismatch, "row %d", i+1)
+		}
+	})
+
+	t.Run("Misparsed amount is flagged", func(t *testing.T) {
+		// 1,250.00 in the source was exported as 125.00
+		csvData := header +
+			"15/01/2024,AWS SERVICES,,15/01/2024,3500.00,,450000.00\n" +
+			"16/01/2024,ZOMATO,,16/01/2024,125.00,,448750.00\n" +
+			"17/01/2024,SWIGGY,,17/01/2024,250.00,,448500.00\n"
+
+		transactions, err := NewParser().ParseCSV(strings.NewReader(csvData))
+		require.NoError(t, err)
+		require.Len(t, transactions, 3)
+
+		assert.Contains(t, transactions[1].Flags, models.FlagReconciliationMismatch)
+		assert.NotContains(t, transactions[2].Flags, models.FlagReconciliationMismatch)
+	})
+
+	t.Run("Newest first within tolerance", func(t *testing.T) {
+		csvData := header +
+			"17/01/2024,SWIGGY,,17/01/2024,250.00,,449300.50\n" +
+			"16/01/2024,ZOMATO,,16/01/2024,450.00,,449550.00\n" +
+			"15/01/2024,AWS SERVICES,,15/01/2024,3500.00,,450000.00\n"
+
+		transactions, err := NewParser().ParseCSV(strings.NewReader(csvData))
+		require.NoError(t, err)
+		require.Len(t, transactions, 3)
+
+		for _, txn := range transactions {
+			assert.NotContains(t, txn.Flags, models.FlagReconciliationMismatch)
+		}
+	})
+
+	t.Run("Configured tolerance", func(t *testing.T) {
+		// Row 2's balance is 5 rupees off
+		csvData := header +
+			"15/01/2024,AWS SERVICES,,15/01/2024,3500.00,,450000.00\n" +
+			"16/01/2024,ZOMATO,,16/01/2024,450.00,,449555.00\n"
+
+		parser := NewParser()
+		transactions, err := parser.ParseCSV(strings.NewReader(csvData))
+		require.NoError(t, err)
+		assert.Contains(t, transactions[1].Flags, models.FlagReconciliationMismatch)
+
+		parser.SetBalanceTolerance(5)
+		transactions, err = parser.ParseCSV(strings.NewReader(csvData))
+		require.NoError(t, err)
+		assert.NotContains(t, transactions[1].Flags, models.FlagReconciliationMismatch)
+	})
+}
+
+func TestParseCSV_ZeroAmountRows(t *testing.T) {
+	// The second row is a declined payment logged with zero debit and credit
+	csvData := "Statement of account,,,,,\n" +
+		"Date,Narration,Chq./Ref.No.,Value Dt,Withdrawal Amt.,Deposit Amt.\n" +
+		"15/01/2024,AWS SERVICES,,15/01/2024,3500.00,\n" +
+		"16/01/2024,DECLINED POS AMAZON,,16/01/2024,0.00,\n" +
+		"17/01/2024,SALARY CREDIT,,17/01/2024,,50000.00\n"
+
+	t.Run("Skip drops the row silently", func(t *testing.T) {
+		parser := NewParser()
+		require.NoError(t, parser.SetZeroAmountRows(ZeroAmountSkip))
+
+		transactions, warnings, err := parser.ParseFileReportingSkips(strings.NewReader(csvData), "statement.csv", 1, "")
+		require.NoError(t, err)
+		require.Len(t, transactions, 2)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("Warn drops the row and reports it by default", func(t *testing.T) {
+		transactions, warnings, err := NewParser().ParseFileReportingSkips(strings.NewReader(csvData), "statement.csv", 1, "")
+		require.NoError(t, err)
+		require.Len(t, transactions, 2)
+		assert.Equal(t, "SALARY CREDIT", transactions[1].Description)
+		assert.Equal(t, []string{"line 4: skipped row: both debit and credit are zero"}, warnings)
+	})
+
+	t.Run("Keep imports a zero-amount transaction", func(t *testing.T) {
+		parser := NewParser()
+		require.NoError(t, parser.SetZeroAmountRows(ZeroAmountKeep))
+
+		transactions, warnings, err := parser.ParseFileReportingSkips(strings.NewReader(csvData), "statement.csv", 1, "")
+		require.NoError(t, err)
+		require.Len(t, transactions, 3)
+		assert.Empty(t, warnings)
+
+		assert.Equal(t, "DECLINED POS AMAZON", transactions[1].Description)
+		assert.Equal(t, 0.0, transactions[1].Amount)
+		assert.Contains(t, transactions[1].Flags, models.FlagZeroAmount)
+	})
+
+	t.Run("Unknown mode is rejected", func(t *testing.T) {
+		err := NewParser().SetZeroAmountRows("drop")
+		assert.Error(t, err)
+	})
+}
+
+func TestParseCSV_DuplicateHeaders(t *testing.T) {
+	// An Axis statement with the Amount column accidentally exported twice
+	csvData := "Transaction Date,Particulars,Dr/Cr,Amount,Amount,Balance,Notes,Notes\n" +
+		"15/01/2024,AWS SERVICES,DR,3500.00,99.00,46500.00,,\n" +
+		"16/01/2024,SALARY CREDIT,CR,50000.00,1.00,96500.00,,\n"
+
+	t.Run("Rejected by default", func(t *testing.T) {
+		_, err := NewParser().ParseCSV(strings.NewReader(csvData))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `column "Amount" appears more than once`)
+	})
+
+	t.Run("First reads the first column", func(t *testing.T) {
+		parser := NewParser()
+		require.NoError(t, parser.SetDuplicateHeaders(DuplicateHeadersFirst))
+
+		transactions, err := parser.ParseCSV(strings.NewReader(csvData))
+		require.NoError(t, err)
+		require.Len(t, transactions, 2)
+		assert.Equal(t, -3500.0, transactions[0].Amount)
+		assert.Equal(t, 50000.0, transactions[1].Amount)
+	})
+
+	t.Run("Repeated unread columns are ignored", func(t *testing.T) {
+		csvData := "Transaction Date,Particulars,Dr/Cr,Amount,Balance,Notes,Notes\n" +
+			"15/01/2024,AWS SERVICES,DR,3500.00,46500.00,,\n"
+
+		transactions, err := NewParser().ParseCSV(strings.NewReader(csvData))
+		require.NoError(t, err)
+		require.Len(t, transactions, 1)
+	})
+
+	t.Run("Unknown mode is rejected", func(t *testing.T) {
+		assert.Error(t, NewParser().SetDuplicateHeaders("last"))
+	})
+}
+
 func TestParseCSV_EmptyFile(t *testing.T) {
 	// Create temporary empty file
 	tmpFile, err := os.CreateTemp("", "empty-*.csv")
@@ -248,6 +1063,18 @@ func TestParseCSV_InvalidFormat(t *testing.T) {
 	assert.Contains(t, err.Error(), "unknown bank format")
 }
 
+func TestParseCSV_UnknownFormatListsHeaders(t *testing.T) {
+	csvData := "Posting Date, Details ,Amount\n15/01/2024,AWS SERVICES,3500.00\n"
+
+	parser := NewParser()
+	_, err := parser.ParseCSV(strings.NewReader(csvData))
+
+	var formatErr *UnknownBankFormatError
+	require.ErrorAs(t, err, &formatErr)
+	assert.Equal(t, []string{"Posting Date", "Details", "Amount"}, formatErr.UnmatchedHeaders())
+	assert.Contains(t, err.Error(), "Posting Date, Details, Amount")
+}
+
 // XLSX Parser Tests
 
 func TestParseXLSX_HDFC(t *testing.T) {
@@ -275,6 +1102,19 @@ func TestParseXLSX_HDFC(t *testing.T) {
 	assert.Equal(t, "credit", transactions[1].TxnType)
 }
 
+func TestParseFile_LegacyXLS(t *testing.T) {
+	file, err := os.Open("../../testdata/legacy_sample.xls")
+	require.NoError(t, err)
+	defer file.Close()
+
+	parser := NewParser()
+	transactions, err := parser.ParseFile(file, "statement.xls")
+
+	assert.Nil(t, transactions)
+	assert.ErrorIs(t, err, ErrLegacyXLS)
+	assert.Contains(t, err.Error(), "save it as .xlsx")
+}
+
 func TestParseXLSX_ICICI(t *testing.T) {
 	file, err := os.Open("../../testdata/icici_sample.xlsx")
 	require.NoError(t, err)
@@ -353,6 +1193,73 @@ func TestParseXLSX_Kotak(t *testing.T) {
 	assert.Equal(t, "debit", transactions[0].TxnType)
 }
 
+func TestParseXLSX_SkipsCoverSheet(t *testing.T) {
+	file, err := os.Open("../../testdata/hdfc_multi_sheet.xlsx")
+	require.NoError(t, err)
+	defer file.Close()
+
+	parser := NewParser()
+	transactions, err := parser.ParseXLSX(file)
+
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+	assert.Equal(t, "AWS SERVICES", transactions[0].Description)
+	assert.Equal(t, -3500.0, transactions[0].Amount)
+	assert.Equal(t, 50000.0, transactions[1].Amount)
+}
+
+func TestParseXLSXSheet(t *testing.T) {
+	parser := NewParser()
+
+	t.Run("Sheet name is case-insensitive", func(t *testing.T) {
+		file, err := os.Open("../../testdata/hdfc_multi_sheet.xlsx")
+		require.NoError(t, err)
+		defer file.Close()
+
+		transactions, err := parser.ParseXLSXSheet(file, "account statement", 0)
+		require.NoError(t, err)
+		assert.Len(t, transactions, 3)
+	})
+
+	t.Run("Unrecognized hinted sheet falls back to the others", func(t *testing.T) {
+		file, err := os.Open("../../testdata/hdfc_multi_sheet.xlsx")
+		require.NoError(t, err)
+		defer file.Close()
+
+		transactions, err := parser.ParseXLSXSheet(file, "Cover", 0)
+		require.NoError(t, err)
+		assert.Len(t, transactions, 3)
+	})
+
+	t.Run("Missing sheet", func(t *testing.T) {
+		file, err := os.Open("../../testdata/hdfc_multi_sheet.xlsx")
+		require.NoError(t, err)
+		defer file.Close()
+
+		_, err = parser.ParseXLSXSheet(file, "Transactions", 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `sheet "Transactions" not found`)
+		assert.Contains(t, err.Error(), "Cover, Account Statement")
+	})
+}
+
+func TestParseXLSX_NoSheetMatches(t *testing.T) {
+	f := excelize.NewFile()
+	f.SetCellValue("Sheet1", "A1", "Statement of Account")
+	f.NewSheet("Data")
+	f.SetCellValue("Data", "A1", "Posting Date")
+	f.SetCellValue("Data", "B1", "Details")
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, f.Write(buf))
+
+	_, err := NewParser().ParseXLSX(buf)
+
+	var formatErr *UnknownBankFormatError
+	require.ErrorAs(t, err, &formatErr)
+	assert.Equal(t, []string{"Statement of Account"}, formatErr.Headers)
+}
+
 func TestParseXLSX_EmptyFile(t *testing.T) {
 	// Create temporary empty XLSX file
 	f := excelize.NewFile()
@@ -641,10 +1548,12 @@ func TestParsePDF_EmptyResponse(t *testing.T) {
 func TestParsePDF_ServiceUnavailable(t *testing.T) {
 	// Use invalid URL to simulate service unavailable
 	parser := NewParserWithPDFClient("http://localhost:9999")
+	parser.SetPDFRetry(3, time.Millisecond)
 	pdfContent := strings.NewReader("mock pdf content")
 	_, err := parser.ParsePDF(pdfContent)
 
 	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrPDFServiceUnavailable)
 	assert.Contains(t, err.Error(), "failed to call PDF parser service")
 }
 
@@ -671,6 +1580,7 @@ func TestParsePDF_HTTPError(t *testing.T) {
 	defer mockServer.Close()
 
 	parser := NewParserWithPDFClient(mockServer.URL)
+	parser.SetPDFRetry(3, time.Millisecond)
 	pdfContent := strings.NewReader("mock pdf content")
 	_, err := parser.ParsePDF(pdfContent)
 
@@ -678,6 +1588,138 @@ func TestParsePDF_HTTPError(t *testing.T) {
 	assert.Contains(t, err.Error(), "PDF parser service returned error")
 }
 
+func TestParsePDF_GatewayStatus(t *testing.T) {
+	t.Run("Gateway error from service", func(t *testing.T) {
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer mockServer.Close()
+
+		parser := NewParserWithPDFClient(mockServer.URL)
+		parser.SetPDFRetry(3, time.Millisecond)
+		_, err := parser.ParsePDF(strings.NewReader("mock pdf content"))
+
+		assert.ErrorIs(t, err, ErrPDFServiceUnavailable)
+	})
+
+	t.Run("Internal error is not an outage", func(t *testing.T) {
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer mockServer.Close()
+
+		parser := NewParserWithPDFClient(mockServer.URL)
+		parser.SetPDFRetry(3, time.Millisecond)
+		_, err := parser.ParsePDF(strings.NewReader("mock pdf content"))
+
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrPDFServiceUnavailable)
+	})
+}
+
+func TestParsePDF_Retry(t *testing.T) {
+	hdfcRows := [][]string{
+		{"Date", "Narration", "Chq./Ref.No.", "Value Dt", "Withdrawal Amt.", "Deposit Amt.", "Closing Balance"},
+		{"15/01/2024", "AWS SERVICES", "UPI/123456", "15/01/2024", "3500.00", "", "450000.00"},
+	}
+
+	t.Run("Succeeds after transient failures", func(t *testing.T) {
+		calls := 0
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			// The whole file must arrive on every attempt
+			_, header, err := r.FormFile("file")
+			require.NoError(t, err)
+			assert.Equal(t, int64(len("mock pdf content")), header.Size)
+
+			if calls <= 2 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(PDFParserResponse{Rows: hdfcRows, PagesProcessed: 1})
+		}))
+		defer mockServer.Close()
+
+		parser := NewParserWithPDFClient(mockServer.URL)
+		parser.SetPDFRetry(3, time.Millisecond)
+		transactions, err := parser.ParsePDF(strings.NewReader("mock pdf content"))
+
+		require.NoError(t, err)
+		assert.Len(t, transactions, 1)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("Gives up after the configured attempts", func(t *testing.T) {
+		calls := 0
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer mockServer.Close()
+
+		parser := NewParserWithPDFClient(mockServer.URL)
+		parser.SetPDFRetry(2, time.Millisecond)
+		_, err := parser.ParsePDF(strings.NewReader("mock pdf content"))
+
+		assert.ErrorIs(t, err, ErrPDFServiceUnavailable)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Cancelled context stops waiting", func(t *testing.T) {
+		calls := 0
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer mockServer.Close()
+
+		parser := NewParserWithPDFClient(mockServer.URL)
+		parser.SetPDFRetry(3, time.Hour)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, _, err := parser.ParsePDFCheckingPages(ctx, strings.NewReader("mock pdf content"), 0)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Client errors are not retried", func(t *testing.T) {
+		calls := 0
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}))
+		defer mockServer.Close()
+
+		parser := NewParserWithPDFClient(mockServer.URL)
+		parser.SetPDFRetry(3, time.Millisecond)
+		_, err := parser.ParsePDF(strings.NewReader("mock pdf content"))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "status 422")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Unknown bank format is not retried", func(t *testing.T) {
+		calls := 0
+		mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(PDFParserResponse{Rows: [][]string{{"Foo", "Bar"}, {"1", "2"}}})
+		}))
+		defer mockServer.Close()
+
+		parser := NewParserWithPDFClient(mockServer.URL)
+		parser.SetPDFRetry(3, time.Millisecond)
+		_, err := parser.ParsePDF(strings.NewReader("mock pdf content"))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown bank format")
+		assert.Equal(t, 1, calls)
+	})
+}
+
 func TestParsePDF_UnknownBankFormat(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		response := PDFParserResponse{
@@ -700,6 +1742,82 @@ func TestParsePDF_UnknownBankFormat(t *testing.T) {
 	assert.Contains(t, err.Error(), "unknown bank format")
 }
 
+func TestParsePDFCheckingPages(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := PDFParserResponse{
+			Rows: [][]string{
+				{"Date", "Narration", "Chq./Ref.No.", "Value Dt", "Withdrawal Amt.", "Deposit Amt.", "Closing Balance"},
+				{"15/01/2024", "AWS SERVICES", "UPI/123456", "15/01/2024", "3500.00", "", "450000.00"},
+			},
+			PagesProcessed: 2,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	threePagePDF := "%PDF-1.4\n1 0 obj << /Type /Pages /Count 3 >> endobj\n" +
+		"2 0 obj << /Type /Page >> endobj\n3 0 obj << /Type/Page >> endobj\n4 0 obj << /Type /Page >> endobj\n"
+
+	testCases := []struct {
+		name          string
+		content       string
+		expectedPages int
+		warning       string
+	}{
+		{"Fewer pages than expected", "mock pdf content", 5, "only 2 of 5 pages were processed; the statement may be incomplete"},
+		{"All expected pages processed", "mock pdf content", 2, ""},
+		{"Fewer pages than detected", threePagePDF, 0, "only 2 of 3 pages were processed; the statement may be incomplete"},
+		{"Page count unknown", "mock pdf content", 0, ""},
+	}
+
+	parser := NewParserWithPDFClient(mockServer.URL)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transactions, warning, err := parser.ParsePDFCheckingPages(context.Background(), strings.NewReader(tc.content), tc.expectedPages)
+
+			require.NoError(t, err)
+			assert.Len(t, transactions, 1)
+			assert.Equal(t, tc.warning, warning)
+		})
+	}
+}
+
+func TestCountPDFPages(t *testing.T) {
+	assert.Equal(t, 0, CountPDFPages([]byte("not a pdf")))
+	assert.Equal(t, 0, CountPDFPages([]byte("<< /Type /Pages /Kids [] /Count 0 >>")))
+	assert.Equal(t, 2, CountPDFPages([]byte("<< /Type /Page /Parent 1 0 R >> << /Type/Page>>")))
+}
+
+func TestParser_SetPDFService(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/parse", r.URL.Path)
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slow.Close()
+
+	t.Run("URL and timeout are applied", func(t *testing.T) {
+		parser := NewParser()
+		parser.SetPDFService(slow.URL+"/", 50*time.Millisecond)
+		parser.SetPDFRetry(1, 0)
+
+		_, err := parser.ParsePDF(strings.NewReader("mock pdf content"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to call PDF parser service")
+		assert.Contains(t, err.Error(), "Timeout")
+	})
+
+	t.Run("Empty URL disables PDF parsing", func(t *testing.T) {
+		parser := NewParser()
+		parser.SetPDFService("", 0)
+
+		_, err := parser.ParseFile(strings.NewReader("mock pdf content"), "statement.pdf")
+		assert.ErrorIs(t, err, ErrPDFServiceNotConfigured)
+		assert.Equal(t, DefaultPDFServiceTimeout, parser.httpClient.Timeout)
+	})
+}
+
 func TestParseFile_PDF_RoutesToParsePDF(t *testing.T) {
 	// Mock Python microservice
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -723,3 +1841,68 @@ func TestParseFile_PDF_RoutesToParsePDF(t *testing.T) {
 	assert.Len(t, transactions, 1)
 	assert.Equal(t, "AWS SERVICES", transactions[0].Description)
 }
+
+func TestImportFlags(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		txn      models.ParsedTransaction
+		expected []string
+	}{
+		{"Normal transaction", models.ParsedTransaction{TxnDate: time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC), Amount: -500}, nil},
+		{"Future date", models.ParsedTransaction{TxnDate: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), Amount: -500}, []string{models.FlagBadDate}},
+		{"Implausibly old date", models.ParsedTransaction{TxnDate: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), Amount: -500}, []string{models.FlagBadDate}},
+		{"Zero amount", models.ParsedTransaction{TxnDate: time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC), Amount: 0}, []string{models.FlagZeroAmount}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, importFlags(tt.txn, now))
+		})
+	}
+}
+
+func TestParseCSV_MultiAccountStatement(t *testing.T) {
+	file, err := os.Open("../../testdata/hdfc_multi_account.csv")
+	require.NoError(t, err)
+	defer file.Close()
+
+	parser := NewParser()
+	transactions, err := parser.ParseCSV(file)
+
+	require.NoError(t, err)
+	require.Len(t, transactions, 5)
+
+	byAccount := map[string][]string{}
+	for _, txn := range transactions {
+		byAccount[txn.Account] = append(byAccount[txn.Account], txn.Description)
+	}
+	assert.Equal(t, map[string][]string{
+		"XXXXXXXX6789": {"AWS SERVICES", "SALARY CREDIT - ACME CORP", "RAZORPAY PAYMENT GATEWAY"},
+		"XXXXXXXX4321": {"GST PAYMENT", "CLIENT PAYMENT - GLOBEX"},
+	}, byAccount)
+}
+
+func TestAccountHeaderNumber(t *testing.T) {
+	tests := []struct {
+		name     string
+		row      []string
+		expected string
+		ok       bool
+	}{
+		{"Number in same cell", []string{"Account Number: 50100123456789", "", ""}, "50100123456789", true},
+		{"Number in next cell", []string{"A/C No.", "XXXX1234", ""}, "XXXX1234", true},
+		{"Masked number", []string{"Account No - ****5678"}, "****5678", true},
+		{"Transaction row", []string{"15/01/2024", "ACCOUNT NUMBER 1234 TRANSFER", "500.00"}, "", false},
+		{"Summary row", []string{"Closing Balance", "", "497500.00"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			number, ok := accountHeaderNumber(tt.row)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, number)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultDescriptionPrefixes are payment channel words banks put in front of the
+// merchant ("POS ZOMATO", "UPI-SWIGGY"). They say nothing about the category and
+// dilute substring scores, so they are trimmed before matching.
+var DefaultDescriptionPrefixes = []string{"POS", "ECOM", "ATM", "UPI", "NEFT", "IMPS", "RTGS", "ACH", "NACH"}
+
+// SetDescriptionPrefixes replaces the leading words trimmed from descriptions
+// before non-regex matching; an empty list turns trimming off
+func (c *Categorizer) SetDescriptionPrefixes(prefixes []string) {
+	upper := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		if prefix = strings.ToUpper(strings.TrimSpace(prefix)); prefix != "" {
+			upper = append(upper, prefix)
+		}
+	}
+	c.prefixes = upper
+}
+
+// matchText is a description in the forms rules are matched against
+type matchText struct {
+	upper     string // Uppercased, for regex rules
+	lower     string // Lowercased with channel prefixes trimmed
+	lowerFull string // Lowercased with channel prefixes kept
+}
+
+// prepareText builds the matchText of a description
+func (c *Categorizer) prepareText(description string) matchText {
+	upper := strings.ToUpper(strings.TrimSpace(description))
+	return matchText{
+		upper:     upper,
+		lower:     strings.ToLower(c.trimPrefixes(upper)),
+		lowerFull: strings.ToLower(upper),
+	}
+}
+
+// trimPrefixes strips configured prefix words, and the separators after them, from the
+// start of an uppercased description. A prefix only counts as a whole word ("POS" does
+// not trim "POSTMAN"), and a description made only of prefixes is kept as is.
+// The original description is left for regex rules, which may anchor on the prefix.
+func (c *Categorizer) trimPrefixes(descUpper string) string {
+	trimmed := descUpper
+	for {
+		stripped := false
+		for _, prefix := range c.prefixes {
+			rest, ok := strings.CutPrefix(trimmed, prefix)
+			if !ok || rest == "" || !isPrefixSeparator(rune(rest[0])) {
+				continue
+			}
+			trimmed = strings.TrimLeftFunc(rest, isPrefixSeparator)
+			stripped = true
+			break
+		}
+		if !stripped {
+			break
+		}
+	}
+	if trimmed == "" {
+		return descUpper
+	}
+	return trimmed
+}
+
+// isPrefixSeparator reports whether r separates a channel prefix from the rest of a description
+func isPrefixSeparator(r rune) bool {
+	return unicode.IsSpace(r) || r == '-' || r == '/' || r == ':' || r == '*'
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategorizer_PrefixTrimmingRaisesScore(t *testing.T) {
+	rules := []Rule{{Keyword: "zomato", Category: "Food & Dining", MatchType: "substring", Priority: 10}}
+
+	untrimmed := &Categorizer{}
+	rule, before, ok := untrimmed.bestMatch("POS ZOMATO", nil, rules)
+	assert.True(t, ok)
+	assert.Equal(t, "Food & Dining", rule.Category)
+	assert.InDelta(t, 0.6, before, 0.001) // "zomato" is 6 of "pos zomato"'s 10 characters
+
+	trimmed := &Categorizer{}
+	trimmed.SetDescriptionPrefixes(DefaultDescriptionPrefixes)
+	rule, after, ok := trimmed.bestMatch("POS ZOMATO", nil, rules)
+	assert.True(t, ok)
+	assert.Equal(t, "Food & Dining", rule.Category)
+	assert.Equal(t, 1.0, after)
+	assert.Greater(t, after, before)
+}
+
+func TestCategorizer_TrimPrefixes(t *testing.T) {
+	c := &Categorizer{}
+	c.SetDescriptionPrefixes([]string{"pos", " ecom ", "UPI", ""})
+
+	tests := []struct {
+		description string
+		expected    string
+	}{
+		{"POS ZOMATO", "ZOMATO"},
+		{"UPI-SWIGGY-401234567890", "SWIGGY-401234567890"},
+		{"UPI/POS  AMAZON", "AMAZON"},
+		{"ECOM*NETFLIX", "NETFLIX"},
+		{"POSTMAN SUBSCRIPTION", "POSTMAN SUBSCRIPTION"}, // Not a whole word
+		{"ATM WDL 1234", "ATM WDL 1234"},                 // ATM isn't configured here
+		{"POS", "POS"},                                   // Nothing left to match
+		{"POS - ", "POS - "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Equal(t, tt.expected, c.trimPrefixes(tt.description))
+		})
+	}
+}
+
+func TestCategorizer_PrefixTrimmingKeepsRegexOriginal(t *testing.T) {
+	c := &Categorizer{}
+	c.SetDescriptionPrefixes(DefaultDescriptionPrefixes)
+
+	// Regex rules anchor on the channel prefix, so they still see it
+	rules := []Rule{{Keyword: `^ATM WDL\b`, Category: "Cash Withdrawal", MatchType: "regex", Priority: 10}}
+	rule, _, ok := c.bestMatch("ATM WDL 401234 MG ROAD", nil, rules)
+	assert.True(t, ok)
+	assert.Equal(t, "Cash Withdrawal", rule.Category)
+
+	// Exact rules compare the trimmed description
+	rules = []Rule{{Keyword: "netflix", Category: "Entertainment", MatchType: "exact", Priority: 10}}
+	_, _, ok = c.bestMatch("ECOM NETFLIX", nil, rules)
+	assert.True(t, ok)
+
+	c.SetDescriptionPrefixes(nil)
+	_, _, ok = c.bestMatch("ECOM NETFLIX", nil, rules)
+	assert.False(t, ok)
+}
+
+// TestCategorizer_PrefixTrimmingKeepsPrefixedKeywords tests that the global rules seeded
+// in migration 004 whose keywords start with a channel prefix still match
+func TestCategorizer_PrefixTrimmingKeepsPrefixedKeywords(t *testing.T) {
+	c := &Categorizer{}
+	c.SetDescriptionPrefixes(DefaultDescriptionPrefixes)
+	rules := []Rule{
+		{Keyword: "imps salary", Category: "Salaries", MatchType: "substring", Priority: 10},
+		{Keyword: "neft salary", Category: "Salaries", MatchType: "substring", Priority: 10},
+		{Keyword: "atm fee", Category: "Banking Fees", MatchType: "substring", Priority: 9},
+	}
+
+	tests := []struct {
+		description string
+		expected    string
+	}{
+		{"NEFT SALARY ACME CORP", "Salaries"},
+		{"IMPS SALARY CREDIT JAN", "Salaries"},
+		{"ATM FEE CHARGES", "Banking Fees"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			rule, _, ok := c.bestMatch(tt.description, nil, rules)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, rule.Category)
+		})
+	}
+
+	// A prefixed exact keyword compares the untrimmed description
+	rules = []Rule{{Keyword: "atm fee", Category: "Banking Fees", MatchType: "exact", Priority: 9}}
+	rule, score, ok := c.bestMatch("ATM FEE", nil, rules)
+	assert.True(t, ok)
+	assert.Equal(t, "Banking Fees", rule.Category)
+	assert.Equal(t, 1.0, score)
+}
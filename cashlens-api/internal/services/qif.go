@@ -0,0 +1,223 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+)
+
+// ErrNotQIF is returned when a file has no QIF headers, or records before the first one
+var ErrNotQIF = errors.New("not a QIF file: missing !Type header")
+
+// ErrUnsupportedQIF is returned for QIF files with no bank, cash or credit card
+// transactions, such as investment or category lists
+var ErrUnsupportedQIF = errors.New("unsupported QIF section: only Bank, CCard and Cash transactions can be imported")
+
+// qifTransactionTypes lists the QIF sections holding plain bank-style transactions
+var qifTransactionTypes = map[string]bool{
+	"bank":  true,
+	"ccard": true,
+	"cash":  true,
+}
+
+// qifRecord holds the fields of one QIF record, up to its "^" terminator
+type qifRecord struct {
+	date, amount, payee, memo, number string
+	lines                             []string
+}
+
+// ParseQIF parses a QIF bank, cash or credit card export. Other sections, such as
+// !Account, !Type:Cat or !Type:Invst blocks, are skipped, but a file with no
+// transaction section is an error. QIF dates are month-first unless a date in the
+// file only makes sense day-first.
+func (p *Parser) ParseQIF(file io.Reader) ([]models.ParsedTransaction, error) {
+	scanner := bufio.NewScanner(utf8Reader(file))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var records []qifRecord
+	var current qifRecord
+	var inSection, inTransactions bool
+	var sawTransactions bool
+	var unsupported string // First skipped section header, reported when nothing is importable
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if line[0] == '!' {
+			header := strings.ToLower(line[1:])
+			if strings.HasPrefix(header, "option:") || strings.HasPrefix(header, "clear:") {
+				continue // Quicken export switches; they carry no data
+			}
+			typeName, isType := strings.CutPrefix(header, "type:")
+			inSection = true
+			inTransactions = isType && qifTransactionTypes[strings.TrimSpace(typeName)]
+			if inTransactions {
+				sawTransactions = true
+			} else if unsupported == "" {
+				unsupported = line
+			}
+			current = qifRecord{}
+			continue
+		}
+
+		if !inSection {
+			return nil, ErrNotQIF
+		}
+		if line[0] == '^' {
+			if inTransactions {
+				records = append(records, current)
+			}
+			current = qifRecord{}
+			continue
+		}
+
+		current.lines = append(current.lines, line)
+		value := strings.TrimSpace(line[1:])
+		switch line[0] {
+		case 'D':
+			current.date = value
+		case 'T', 'U':
+			if current.amount == "" {
+				current.amount = value
+			}
+		case 'P':
+			current.payee = value
+		case 'M':
+			current.memo = value
+		case 'N':
+			current.number = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read QIF file: %w", err)
+	}
+	if !inSection {
+		return nil, ErrNotQIF
+	}
+	if !sawTransactions {
+		return nil, fmt.Errorf("%w (found %s)", ErrUnsupportedQIF, unsupported)
+	}
+	// A final record without its "^" terminator still counts
+	if inTransactions && len(current.lines) > 0 {
+		records = append(records, current)
+	}
+
+	dayFirst := qifDatesDayFirst(records)
+	var transactions []models.ParsedTransaction
+	for i, record := range records {
+		txn, err := parseQIFRecord(record, dayFirst)
+		if err != nil {
+			// Log error but continue parsing, as for CSV rows
+			fmt.Printf("Warning: skipping QIF record %d: %v\n", i+1, err)
+			continue
+		}
+		txn.Flags = importFlags(txn, time.Now())
+		transactions = append(transactions, txn)
+	}
+
+	return transactions, nil
+}
+
+// parseQIFRecord maps one QIF record onto a transaction
+func parseQIFRecord(record qifRecord, dayFirst bool) (models.ParsedTransaction, error) {
+	var txn models.ParsedTransaction
+
+	date, err := parseQIFDate(record.date, dayFirst)
+	if err != nil {
+		return txn, err
+	}
+	txn.TxnDate = date
+
+	if record.amount == "" {
+		return txn, fmt.Errorf("missing amount")
+	}
+	amount, err := ParseAmount(record.amount)
+	if err != nil {
+		return txn, fmt.Errorf("failed to parse amount: %w", err)
+	}
+	txn.Amount = amount
+	if amount < 0 {
+		txn.TxnType = "debit"
+	} else {
+		txn.TxnType = "credit"
+	}
+
+	txn.Description = joinPayeeMemo(record.payee, record.memo)
+
+	// The N field is a cheque number, or a label such as "ATM" or "EFT"
+	if _, err := strconv.Atoi(record.number); err == nil {
+		txn.ReferenceNo = record.number
+	} else {
+		txn.ReferenceNo = ExtractReferenceNo(txn.Description)
+	}
+
+	txn.RawData = strings.Join(record.lines, ";")
+	return txn, nil
+}
+
+// qifDateParts splits a QIF date such as "1/15'24", "01/15/2024" or "15.01.2024"
+// into its three numeric parts
+func qifDateParts(value string) ([3]int, bool) {
+	var parts [3]int
+	fields := strings.FieldsFunc(strings.ReplaceAll(value, " ", ""), func(r rune) bool {
+		return r == '/' || r == '-' || r == '.' || r == '\''
+	})
+	if len(fields) != 3 {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// qifDatesDayFirst reports whether any record's date only parses day-first
+func qifDatesDayFirst(records []qifRecord) bool {
+	for _, record := range records {
+		parts, ok := qifDateParts(record.date)
+		if ok && parts[0] <= 31 && parts[0] > 12 {
+			return true
+		}
+	}
+	return false
+}
+
+// parseQIFDate parses a QIF date. Two-digit years are taken as 20xx, and a leading
+// four-digit year is read as year-month-day.
+func parseQIFDate(value string, dayFirst bool) (time.Time, error) {
+	parts, ok := qifDateParts(value)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unable to parse date: %s", value)
+	}
+
+	var year, month, day int
+	switch {
+	case parts[0] > 31:
+		year, month, day = parts[0], parts[1], parts[2]
+	case dayFirst:
+		day, month, year = parts[0], parts[1], parts[2]
+	default:
+		month, day, year = parts[0], parts[1], parts[2]
+	}
+	if year < 100 {
+		year += 2000
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Year() != year || int(date.Month()) != month || date.Day() != day {
+		return time.Time{}, fmt.Errorf("unable to parse date: %s", value)
+	}
+	return date, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQIF_Bank(t *testing.T) {
+	file, err := os.Open("../../testdata/sample.qif")
+	require.NoError(t, err)
+	defer file.Close()
+
+	transactions, err := NewParser().ParseQIF(file)
+	require.NoError(t, err)
+	require.Len(t, transactions, 3, "the category list is skipped")
+
+	assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), transactions[0].TxnDate)
+	assert.Equal(t, "AWS SERVICES", transactions[0].Description)
+	assert.Equal(t, -3500.0, transactions[0].Amount)
+	assert.Equal(t, "debit", transactions[0].TxnType)
+	assert.Equal(t, "D01/15'24;T-3,500.00;PAWS SERVICES;LCloud & Hosting", transactions[0].RawData)
+
+	assert.Equal(t, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), transactions[1].TxnDate)
+	assert.Equal(t, "ACME CORP - NEFT/789012/SALARY JAN", transactions[1].Description)
+	assert.Equal(t, 50000.0, transactions[1].Amount)
+	assert.Equal(t, "credit", transactions[1].TxnType)
+	assert.Equal(t, "789012", transactions[1].ReferenceNo)
+
+	assert.Equal(t, "451", transactions[2].ReferenceNo)
+}
+
+func TestParseQIF_DayFirstDates(t *testing.T) {
+	qif := "!Account\nNSavings\nTBank\n^\n!Type:Bank\n" +
+		"D05/01/2024\nT-100.00\nPTEA\n^\n" +
+		"D25/01/2024\nT-200.00\nPCOFFEE\n" // Last record without its terminator
+
+	transactions, err := NewParser().ParseQIF(strings.NewReader(qif))
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), transactions[0].TxnDate)
+	assert.Equal(t, time.Date(2024, 1, 25, 0, 0, 0, 0, time.UTC), transactions[1].TxnDate)
+}
+
+func TestParseQIF_Unsupported(t *testing.T) {
+	t.Run("Investment account", func(t *testing.T) {
+		qif := "!Type:Invst\nD01/15/2024\nNBuy\nYINFY\nI1500.00\nQ10\nT15000.00\n^\n"
+		_, err := NewParser().ParseQIF(strings.NewReader(qif))
+		assert.ErrorIs(t, err, ErrUnsupportedQIF)
+		assert.Contains(t, err.Error(), "!Type:Invst")
+	})
+
+	t.Run("Missing type header", func(t *testing.T) {
+		_, err := NewParser().ParseQIF(strings.NewReader("D01/15/2024\nT-100.00\n^\n"))
+		assert.ErrorIs(t, err, ErrNotQIF)
+	})
+
+	t.Run("Empty file", func(t *testing.T) {
+		_, err := NewParser().ParseQIF(strings.NewReader(""))
+		assert.ErrorIs(t, err, ErrNotQIF)
+	})
+}
@@ -0,0 +1,48 @@
+package services
+
+import "math"
+
+// DefaultBalanceTolerance is the largest balance difference, in rupees, put down to
+// bank rounding rather than a misparsed or missing row
+const DefaultBalanceTolerance = 1.0
+
+// BalanceReconciler compares balances and totals computed from imported transactions
+// against the figures a bank statement reports, allowing for rounding
+type BalanceReconciler struct {
+	tolerancePaise int64
+}
+
+// NewBalanceReconciler creates a reconciler that accepts differences up to tolerance
+// rupees (inclusive). A negative tolerance uses DefaultBalanceTolerance; zero only
+// accepts amounts equal to the paisa.
+func NewBalanceReconciler(tolerance float64) *BalanceReconciler {
+	if tolerance < 0 {
+		tolerance = DefaultBalanceTolerance
+	}
+	return &BalanceReconciler{tolerancePaise: toPaise(tolerance)}
+}
+
+// Tolerance returns the accepted difference in rupees
+func (r *BalanceReconciler) Tolerance() float64 {
+	return float64(r.tolerancePaise) / 100
+}
+
+// Discrepancy returns actual minus expected, rounded to the paisa, and whether it
+// is larger than the tolerance. Amounts are compared in paise so float noise from
+// summing many transactions can't push a difference over the boundary.
+func (r *BalanceReconciler) Discrepancy(expected, actual float64) (float64, bool) {
+	diff := toPaise(actual) - toPaise(expected)
+	exceeds := diff > r.tolerancePaise || -diff > r.tolerancePaise
+	return float64(diff) / 100, exceeds
+}
+
+// Matches reports whether actual is within the tolerance of expected
+func (r *BalanceReconciler) Matches(expected, actual float64) bool {
+	_, exceeds := r.Discrepancy(expected, actual)
+	return !exceeds
+}
+
+// toPaise converts a rupee amount to whole paise
+func toPaise(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
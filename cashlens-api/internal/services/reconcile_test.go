@@ -0,0 +1,51 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalanceReconciler_ToleranceBoundary(t *testing.T) {
+	reconciler := NewBalanceReconciler(DefaultBalanceTolerance)
+
+	tests := []struct {
+		name        string
+		expected    float64
+		actual      float64
+		wantDiff    float64
+		wantExceeds bool
+	}{
+		{"Exact", 46500.00, 46500.00, 0, false},
+		{"Paisa rounding", 46500.00, 46500.01, 0.01, false},
+		{"Just under tolerance", 46500.00, 46500.99, 0.99, false},
+		{"At tolerance", 46500.00, 46501.00, 1.00, false},
+		{"At tolerance below", 46500.00, 46499.00, -1.00, false},
+		{"Just over tolerance", 46500.00, 46501.01, 1.01, true},
+		{"Just over tolerance below", 46500.00, 46498.99, -1.01, true},
+		{"Missing row", 46500.00, 50000.00, 3500.00, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, exceeds := reconciler.Discrepancy(tt.expected, tt.actual)
+			assert.InDelta(t, tt.wantDiff, diff, 0.001)
+			assert.Equal(t, tt.wantExceeds, exceeds)
+			assert.Equal(t, !tt.wantExceeds, reconciler.Matches(tt.expected, tt.actual))
+		})
+	}
+}
+
+func TestBalanceReconciler_SummedAmounts(t *testing.T) {
+	// 0.1 + 0.2 != 0.3 in floating point; paise comparison keeps this an exact match
+	reconciler := NewBalanceReconciler(0)
+	assert.True(t, reconciler.Matches(0.3, 0.1+0.2))
+	assert.False(t, reconciler.Matches(0.3, 0.31))
+}
+
+func TestNewBalanceReconciler_Tolerance(t *testing.T) {
+	assert.Equal(t, 1.0, NewBalanceReconciler(-1).Tolerance())
+	assert.Equal(t, 0.0, NewBalanceReconciler(0).Tolerance())
+	assert.Equal(t, 5.0, NewBalanceReconciler(5).Tolerance())
+	assert.Equal(t, 0.5, NewBalanceReconciler(0.5).Tolerance())
+}
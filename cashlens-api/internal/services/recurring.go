@@ -0,0 +1,198 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+)
+
+const (
+	minMonthlyGapDays          = 25   // Shortest gap between occurrences still considered monthly
+	maxMonthlyGapDays          = 35   // Longest gap between occurrences still considered monthly
+	minQuarterlyGapDays        = 80   // Shortest gap between occurrences still considered quarterly
+	maxQuarterlyGapDays        = 100  // Longest gap between occurrences still considered quarterly
+	recurringAmountTol         = 0.20 // Allowed deviation of each amount in FindMonthlySeries
+	recurringDescriptionSimMin = 0.85 // Minimum similarity for two counterparties to be grouped
+)
+
+// RecurringSeries is a group of same-sign transactions from one counterparty
+// that recur on a regular cadence with similar amounts
+type RecurringSeries struct {
+	Counterparty  string
+	Cadence       string               // models.CadenceMonthly or models.CadenceQuarterly
+	Months        int                  // Distinct calendar months with an occurrence
+	Transactions  []models.Transaction // Sorted by date
+	AverageAmount float64
+}
+
+// SeriesOptions tunes FindRecurringSeries
+type SeriesOptions struct {
+	MinOccurrences  int     // Occurrences a series needs, counting repeats within one cycle once
+	AmountTolerance float64 // Allowed relative difference of an amount from the series average
+	Quarterly       bool    // Also accept series on a quarterly cadence
+}
+
+// recurringCluster collects transactions while grouping
+type recurringCluster struct {
+	counterparty string
+	debit        bool
+	total        float64 // Sum of absolute amounts
+	txns         []models.Transaction
+}
+
+// FindMonthlySeries returns the series of transactions from one counterparty and
+// direction that occur at least minOccurrences times about a month apart
+func FindMonthlySeries(transactions []models.Transaction, minOccurrences int) []RecurringSeries {
+	return FindRecurringSeries(transactions, SeriesOptions{
+		MinOccurrences:  minOccurrences,
+		AmountTolerance: recurringAmountTol,
+	})
+}
+
+// FindRecurringSeries clusters transactions by direction, similar counterparty and
+// similar amount, and returns the clusters with at least MinOccurrences occurrences
+// whose median gap is monthly (or quarterly), ordered by counterparty
+func FindRecurringSeries(transactions []models.Transaction, opts SeriesOptions) []RecurringSeries {
+	sorted := make([]models.Transaction, len(transactions))
+	copy(sorted, transactions)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].TxnDate.Before(sorted[j].TxnDate.Time) })
+
+	// 1. Cluster transactions with similar counterparties and amounts
+	var clusters []*recurringCluster
+	for _, txn := range sorted {
+		counterparty := counterpartyKey(txn.Description)
+		if counterparty == "" || txn.Amount == 0 {
+			continue
+		}
+		if cluster := findCluster(clusters, counterparty, txn.Amount, opts.AmountTolerance); cluster != nil {
+			cluster.txns = append(cluster.txns, txn)
+			cluster.total += math.Abs(txn.Amount)
+			continue
+		}
+		clusters = append(clusters, &recurringCluster{
+			counterparty: counterparty,
+			debit:        txn.Amount < 0,
+			total:        math.Abs(txn.Amount),
+			txns:         []models.Transaction{txn},
+		})
+	}
+
+	// 2. Keep clusters with enough occurrences on an accepted cadence
+	var series []RecurringSeries
+	for _, cluster := range clusters {
+		occurrences := spacedOccurrences(cluster.txns)
+		if len(occurrences) < 2 || len(occurrences) < opts.MinOccurrences {
+			continue
+		}
+		cadence, ok := estimateCadence(occurrences)
+		if !ok || (cadence == models.CadenceQuarterly && !opts.Quarterly) {
+			continue
+		}
+
+		average := cluster.total / float64(len(cluster.txns))
+		if cluster.debit {
+			average = -average
+		}
+		series = append(series, RecurringSeries{
+			Counterparty:  cluster.counterparty,
+			Cadence:       cadence,
+			Months:        distinctMonths(cluster.txns),
+			Transactions:  cluster.txns,
+			AverageAmount: average,
+		})
+	}
+
+	sort.SliceStable(series, func(i, j int) bool { return series[i].Counterparty < series[j].Counterparty })
+	return series
+}
+
+// findCluster returns the cluster in the same direction whose counterparty is similar
+// and whose average amount is within tolerance, or nil
+func findCluster(clusters []*recurringCluster, counterparty string, amount, tolerance float64) *recurringCluster {
+	for _, cluster := range clusters {
+		if cluster.debit != (amount < 0) {
+			continue
+		}
+		average := cluster.total / float64(len(cluster.txns))
+		if math.Abs(math.Abs(amount)-average) > average*tolerance {
+			continue
+		}
+		if cluster.counterparty == counterparty ||
+			calculateSimilarity(cluster.counterparty, counterparty) >= recurringDescriptionSimMin {
+			return cluster
+		}
+	}
+	return nil
+}
+
+// spacedOccurrences drops date-sorted transactions less than minMonthlyGapDays after
+// the previous kept one, so repeats within one billing cycle count once. Unlike
+// calendar months, it keeps month-end dates that drift into the next month apart.
+func spacedOccurrences(txns []models.Transaction) []models.Transaction {
+	var result []models.Transaction
+	for _, txn := range txns {
+		if len(result) > 0 && daysBetween(result[len(result)-1].TxnDate.Time, txn.TxnDate.Time) < minMonthlyGapDays {
+			continue
+		}
+		result = append(result, txn)
+	}
+	return result
+}
+
+// distinctMonths counts the calendar months with at least one transaction
+func distinctMonths(txns []models.Transaction) int {
+	seen := make(map[string]bool)
+	for _, txn := range txns {
+		seen[txn.TxnDate.Format("2006-01")] = true
+	}
+	return len(seen)
+}
+
+// estimateCadence classifies the median gap between date-sorted occurrences
+func estimateCadence(txns []models.Transaction) (string, bool) {
+	gaps := make([]int, 0, len(txns)-1)
+	for i := 1; i < len(txns); i++ {
+		gaps = append(gaps, daysBetween(txns[i-1].TxnDate.Time, txns[i].TxnDate.Time))
+	}
+	sort.Ints(gaps)
+	median := gaps[len(gaps)/2]
+
+	switch {
+	case median >= minMonthlyGapDays && median <= maxMonthlyGapDays:
+		return models.CadenceMonthly, true
+	case median >= minQuarterlyGapDays && median <= maxQuarterlyGapDays:
+		return models.CadenceQuarterly, true
+	}
+	return "", false
+}
+
+// counterpartyKey reduces a description to the words identifying the counterparty,
+// dropping tokens that vary between occurrences (dates, references, month names)
+func counterpartyKey(description string) string {
+	words := strings.Fields(nonAlphanumeric.ReplaceAllString(strings.ToUpper(description), " "))
+	kept := words[:0]
+	for _, word := range words {
+		if strings.ContainsAny(word, "0123456789") {
+			continue
+		}
+		if _, isMonth := EnglishMonthNames[strings.ToLower(word)]; isMonth {
+			continue
+		}
+		if isShortMonthName(word) {
+			continue
+		}
+		kept = append(kept, word)
+	}
+	return strings.Join(kept, " ")
+}
+
+// isShortMonthName reports whether word is a three-letter month abbreviation
+func isShortMonthName(word string) bool {
+	switch word {
+	case "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultRecurringAmountTolerance is the allowed relative difference between amounts in a group
+	DefaultRecurringAmountTolerance = 0.05
+	// DefaultRecurringMinMonths is the number of monthly or quarterly occurrences a group needs
+	DefaultRecurringMinMonths = 3
+)
+
+// RecurringDetector flags transactions that repeat monthly or quarterly with a similar
+// description and amount (subscriptions, rent, EMIs)
+type RecurringDetector struct {
+	opts SeriesOptions
+}
+
+// NewRecurringDetector creates a detector; non-positive arguments fall back to the defaults
+func NewRecurringDetector(amountTolerance float64, minMonths int) *RecurringDetector {
+	if amountTolerance <= 0 {
+		amountTolerance = DefaultRecurringAmountTolerance
+	}
+	if minMonths <= 0 {
+		minMonths = DefaultRecurringMinMonths
+	}
+	return &RecurringDetector{opts: SeriesOptions{
+		MinOccurrences:  minMonths,
+		AmountTolerance: amountTolerance,
+		Quarterly:       true,
+	}}
+}
+
+// Detect returns the monthly and quarterly series found by FindRecurringSeries,
+// ordered by next expected date
+func (d *RecurringDetector) Detect(transactions []models.Transaction) []models.RecurringGroup {
+	groups := make([]models.RecurringGroup, 0)
+	for _, series := range FindRecurringSeries(transactions, d.opts) {
+		groups = append(groups, newRecurringGroup(series))
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].NextExpectedDate.Before(groups[j].NextExpectedDate.Time)
+	})
+	return groups
+}
+
+// newRecurringGroup summarizes a series, projecting the next occurrence from the last one
+func newRecurringGroup(series RecurringSeries) models.RecurringGroup {
+	last := series.Transactions[len(series.Transactions)-1]
+	next := last.TxnDate.AddDate(0, 1, 0)
+	if series.Cadence == models.CadenceQuarterly {
+		next = last.TxnDate.AddDate(0, 3, 0)
+	}
+
+	direction := "credit"
+	if series.AverageAmount < 0 {
+		direction = "debit"
+	}
+
+	group := models.RecurringGroup{
+		Description:      last.Description,
+		Counterparty:     series.Counterparty,
+		Direction:        direction,
+		Cadence:          series.Cadence,
+		Occurrences:      len(series.Transactions),
+		Months:           series.Months,
+		AverageAmount:    math.Round(series.AverageAmount*100) / 100,
+		LastDate:         last.TxnDate,
+		NextExpectedDate: models.NewDate(next),
+		TransactionIDs:   make([]uuid.UUID, 0, len(series.Transactions)),
+	}
+	for _, txn := range series.Transactions {
+		group.TransactionIDs = append(group.TransactionIDs, txn.ID)
+	}
+	return group
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recurringTxn(description string, amount float64, date time.Time) models.Transaction {
+	return models.Transaction{
+		ID:          uuid.New(),
+		TxnDate:     models.NewDate(date),
+		Description: description,
+		Amount:      amount,
+	}
+}
+
+func TestRecurringDetector_MonthlySubscriptionWithNoise(t *testing.T) {
+	start := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	var txns []models.Transaction
+
+	// Netflix-like charges with varying references, slightly different wording and a price rise within 5%
+	netflix := []struct {
+		description string
+		amount      float64
+	}{
+		{"POS NETFLIX.COM 4512XX1234", -649.00},
+		{"POS NETFLIX COM 4512XX9876", -649.00},
+		{"POS NETFLX.COM 4512XX5555", -649.00},
+		{"POS NETFLIX.COM 4512XX0001", -669.00},
+	}
+	for i, charge := range netflix {
+		txns = append(txns, recurringTxn(charge.description, charge.amount, start.AddDate(0, i, i%2)))
+	}
+
+	// Noise: one-off purchases, irregular merchant spend, and a same-merchant charge of a different size
+	txns = append(txns,
+		recurringTxn("AMAZON PAY ORDER 1234", -1299.00, start.AddDate(0, 0, 3)),
+		recurringTxn("SWIGGY ORDER 9988", -450.00, start.AddDate(0, 0, 10)),
+		recurringTxn("SWIGGY ORDER 1122", -310.00, start.AddDate(0, 1, 2)),
+		recurringTxn("SWIGGY ORDER 3344", -780.00, start.AddDate(0, 2, 20)),
+		recurringTxn("POS NETFLIX.COM GIFT CARD", -2000.00, start.AddDate(0, 1, 12)),
+		recurringTxn("ACME PAYROLL JAN", 85000.00, start.AddDate(0, 0, 25)),
+	)
+
+	groups := NewRecurringDetector(DefaultRecurringAmountTolerance, DefaultRecurringMinMonths).Detect(txns)
+	require.Len(t, groups, 1)
+
+	group := groups[0]
+	assert.Equal(t, "POS NETFLIX COM", group.Counterparty)
+	assert.Equal(t, "POS NETFLIX.COM 4512XX0001", group.Description)
+	assert.Equal(t, "debit", group.Direction)
+	assert.Equal(t, models.CadenceMonthly, group.Cadence)
+	assert.Equal(t, 4, group.Occurrences)
+	assert.Equal(t, 4, group.Months)
+	assert.InDelta(t, -654.00, group.AverageAmount, 0.001)
+	assert.Equal(t, "2024-04-06", group.LastDate.String())
+	assert.Equal(t, "2024-05-06", group.NextExpectedDate.String())
+	assert.Len(t, group.TransactionIDs, 4)
+}
+
+func TestRecurringDetector_Quarterly(t *testing.T) {
+	start := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	var txns []models.Transaction
+	for i := 0; i < 3; i++ {
+		txns = append(txns, recurringTxn("LIC PREMIUM POLICY", -5200.00, start.AddDate(0, 3*i, 0)))
+	}
+
+	groups := NewRecurringDetector(0, 0).Detect(txns)
+	require.Len(t, groups, 1)
+	assert.Equal(t, models.CadenceQuarterly, groups[0].Cadence)
+	assert.Equal(t, "2024-10-10", groups[0].NextExpectedDate.String())
+}
+
+// TestFindMonthlySeries_SharesDetectorEngine tests that the monthly-only series used by the
+// salary and EMI suggesters leave quarterly series to the detector
+func TestFindMonthlySeries_SharesDetectorEngine(t *testing.T) {
+	start := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	var txns []models.Transaction
+	for i := 0; i < 3; i++ {
+		txns = append(txns, recurringTxn("LIC PREMIUM POLICY", -5200.00, start.AddDate(0, 3*i, 0)))
+		txns = append(txns, recurringTxn("RENT PAYMENT", -25000.00, start.AddDate(0, i, 0)))
+	}
+
+	series := FindMonthlySeries(txns, 3)
+	require.Len(t, series, 1)
+	assert.Equal(t, "RENT PAYMENT", series[0].Counterparty)
+	assert.Equal(t, models.CadenceMonthly, series[0].Cadence)
+
+	assert.Len(t, NewRecurringDetector(0, 0).Detect(txns), 2)
+}
+
+func TestRecurringDetector_RequiresDistinctMonths(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	txns := []models.Transaction{
+		recurringTxn("SPOTIFY", -119.00, start),
+		recurringTxn("SPOTIFY", -119.00, start.AddDate(0, 0, 5)), // Same month
+		recurringTxn("SPOTIFY", -119.00, start.AddDate(0, 1, 0)),
+	}
+
+	assert.Empty(t, NewRecurringDetector(0, 0).Detect(txns))
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"container/list"
+	"regexp"
+	"sync"
+)
+
+// DefaultRegexCacheSize is the number of compiled patterns kept when no size is configured
+const DefaultRegexCacheSize = 512
+
+// RegexCache is a concurrency-safe, size-capped cache of compiled regular expressions.
+// When full, the least recently used pattern is evicted.
+type RegexCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // Front is most recently used
+	entries  map[string]*list.Element
+}
+
+// regexCacheEntry is a cached compilation result; invalid patterns cache their error
+type regexCacheEntry struct {
+	pattern string
+	re      *regexp.Regexp
+	err     error
+}
+
+// NewRegexCache creates a cache holding at most capacity patterns
+func NewRegexCache(capacity int) *RegexCache {
+	if capacity < 1 {
+		capacity = DefaultRegexCacheSize
+	}
+	return &RegexCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns the compiled pattern, compiling and caching it on first use.
+// A nil cache compiles every time.
+func (rc *RegexCache) Get(pattern string) (*regexp.Regexp, error) {
+	if rc == nil {
+		return regexp.Compile(pattern)
+	}
+
+	rc.mu.Lock()
+	if elem, ok := rc.entries[pattern]; ok {
+		rc.order.MoveToFront(elem)
+		entry := elem.Value.(*regexCacheEntry)
+		rc.mu.Unlock()
+		return entry.re, entry.err
+	}
+	rc.mu.Unlock()
+
+	// Compile outside the lock; a concurrent miss on the same pattern just compiles twice
+	re, err := regexp.Compile(pattern)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if elem, ok := rc.entries[pattern]; ok {
+		rc.order.MoveToFront(elem)
+		return re, err
+	}
+	rc.entries[pattern] = rc.order.PushFront(&regexCacheEntry{pattern: pattern, re: re, err: err})
+	for rc.order.Len() > rc.capacity {
+		oldest := rc.order.Back()
+		rc.order.Remove(oldest)
+		delete(rc.entries, oldest.Value.(*regexCacheEntry).pattern)
+	}
+	return re, err
+}
+
+// Len returns the number of cached patterns
+func (rc *RegexCache) Len() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.order.Len()
+}
+
+// Contains reports whether a pattern is cached, without affecting its recency
+func (rc *RegexCache) Contains(pattern string) bool {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	_, ok := rc.entries[pattern]
+	return ok
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegexCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewRegexCache(2)
+
+	_, err := cache.Get("^SWIGGY")
+	require.NoError(t, err)
+	_, err = cache.Get("^ZOMATO")
+	require.NoError(t, err)
+
+	// Touch SWIGGY so ZOMATO becomes the least recently used
+	_, err = cache.Get("^SWIGGY")
+	require.NoError(t, err)
+	_, err = cache.Get("^UBER")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, cache.Len())
+	assert.True(t, cache.Contains("^SWIGGY"))
+	assert.True(t, cache.Contains("^UBER"))
+	assert.False(t, cache.Contains("^ZOMATO"))
+}
+
+func TestRegexCache_CachesInvalidPatterns(t *testing.T) {
+	cache := NewRegexCache(4)
+
+	_, err := cache.Get("[unclosed")
+	assert.Error(t, err)
+	_, err = cache.Get("[unclosed")
+	assert.Error(t, err)
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestNewRegexCache_NonPositiveSizeUsesDefault(t *testing.T) {
+	assert.Equal(t, DefaultRegexCacheSize, NewRegexCache(0).capacity)
+}
+
+func TestCategorizer_RegexCacheConcurrentMatching(t *testing.T) {
+	c := NewCategorizer(nil)
+	c.SetRegexCacheSize(8)
+
+	// More distinct patterns than the cap forces evictions while goroutines match
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				n := (g + i) % 32
+				matched, _, _ := c.matchRegex(fmt.Sprintf("UPI-MERCHANT%d-PAYMENT", n), fmt.Sprintf("MERCHANT%d-", n))
+				assert.True(t, matched)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, c.regexCache.Len(), 8)
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// SuggestionPattern is a built-in regex rule a RegexSuggester can suggest. Patterns are
+// written against uppercased descriptions, like the categorizer's regex matching.
+type SuggestionPattern struct {
+	Label    string
+	Pattern  string
+	Category string
+}
+
+// RegexSuggester suggests built-in regex rules for the transactions of one direction
+// that match a pattern but are not yet in the pattern's category
+type RegexSuggester struct {
+	direction string // models.DirectionDebit or models.DirectionCredit
+	patterns  []SuggestionPattern
+	compiled  []*regexp.Regexp
+	keep      func(matched []models.Transaction) []models.Transaction // Optional filter of each pattern's matches
+}
+
+// NewRegexSuggester compiles patterns into a suggester of direction-only rules
+func NewRegexSuggester(direction string, patterns []SuggestionPattern) *RegexSuggester {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		compiled[i] = regexp.MustCompile(p.Pattern)
+	}
+	return &RegexSuggester{direction: direction, patterns: patterns, compiled: compiled}
+}
+
+// Suggest returns one regex rule suggestion per pattern that matches at least one
+// transaction of the suggester's direction not already in the pattern's category.
+// A transaction is counted under the first pattern it matches.
+func (s *RegexSuggester) Suggest(transactions []models.Transaction) []models.RuleSuggestion {
+	matched := make([][]models.Transaction, len(s.compiled))
+	for _, txn := range transactions {
+		if s.direction == models.DirectionDebit && txn.Amount >= 0 ||
+			s.direction == models.DirectionCredit && txn.Amount <= 0 {
+			continue
+		}
+		desc := strings.ToUpper(strings.TrimSpace(txn.Description))
+		for i, re := range s.compiled {
+			if re.MatchString(desc) {
+				matched[i] = append(matched[i], txn)
+				break
+			}
+		}
+	}
+
+	suggestions := []models.RuleSuggestion{}
+	for i, txns := range matched {
+		// Categorized transactions still count toward the filter (an EMI series), they
+		// are only left out of the suggestion itself
+		if s.keep != nil {
+			txns = s.keep(txns)
+		}
+
+		var ids []uuid.UUID
+		total := 0.0
+		for _, txn := range txns {
+			if txn.Category != nil && *txn.Category == s.patterns[i].Category {
+				continue
+			}
+			ids = append(ids, txn.ID)
+			total += txn.Amount
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		suggestions = append(suggestions, models.RuleSuggestion{
+			Category:       s.patterns[i].Category,
+			Keyword:        s.patterns[i].Pattern,
+			MatchType:      "regex",
+			Direction:      s.direction,
+			Counterparty:   s.patterns[i].Label,
+			Occurrences:    len(ids),
+			AverageAmount:  total / float64(len(ids)),
+			TransactionIDs: ids,
+		})
+	}
+
+	return suggestions
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultReparseBatchSize = 500
+	maxReparseBatchSize     = 5000
+)
+
+// ReparseStore is the subset of db.Queries needed to re-parse stored transactions
+type ReparseStore interface {
+	ListTransactionsForReparse(ctx context.Context, arg db.ListTransactionsForReparseParams) ([]db.ListTransactionsForReparseRow, error)
+	UpdateTransactionParsedFields(ctx context.Context, arg db.UpdateTransactionParsedFieldsParams) error
+}
+
+// ReparseService re-runs the parser over stored raw_data and corrects parsed fields
+type ReparseService struct {
+	store  ReparseStore
+	parser *Parser
+}
+
+// NewReparseService creates a new reparse service instance
+func NewReparseService(store ReparseStore, parser *Parser) *ReparseService {
+	return &ReparseService{
+		store:  store,
+		parser: parser,
+	}
+}
+
+// ReparseBatch re-parses one batch of transactions after opts.AfterID and updates
+// those whose parsed fields changed. Pass the returned NextCursor as AfterID to resume.
+func (s *ReparseService) ReparseBatch(ctx context.Context, opts models.ReparseOptions) (models.ReparseResult, error) {
+	var result models.ReparseResult
+
+	if opts.Bank == "" {
+		return result, fmt.Errorf("bank is required")
+	}
+	if _, ok := s.parser.bankSchemas[opts.Bank]; !ok {
+		return result, fmt.Errorf("unknown bank: %s", opts.Bank)
+	}
+	if len(opts.Headers) == 0 {
+		return result, fmt.Errorf("headers are required")
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultReparseBatchSize
+	}
+	if batchSize > maxReparseBatchSize {
+		batchSize = maxReparseBatchSize
+	}
+
+	// Always scope to the bank's own uploads; another bank's rows may have the same
+	// column count and would be silently mis-mapped by this schema
+	params := db.ListTransactionsForReparseParams{
+		ID:      pgtype.UUID{Bytes: opts.AfterID, Valid: true},
+		Column2: opts.Bank,
+		Limit:   int32(batchSize),
+	}
+	if opts.From != nil {
+		params.Column3 = pgtype.Date{Time: *opts.From, Valid: true}
+	}
+	if opts.To != nil {
+		params.Column4 = pgtype.Date{Time: *opts.To, Valid: true}
+	}
+
+	rows, err := s.store.ListTransactionsForReparse(ctx, params)
+	if err != nil {
+		return result, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	for _, row := range rows {
+		result.Scanned++
+		result.NextCursor = uuid.UUID(row.ID.Bytes).String()
+
+		txn, err := s.parser.ReparseRawData(opts.Bank, opts.Headers, row.RawData.String)
+		if err != nil {
+			result.Failed++
+			continue
+		}
+
+		if !parsedFieldsChanged(row, txn) {
+			continue
+		}
+
+		var pgAmount pgtype.Numeric
+		if err := pgAmount.Scan(fmt.Sprintf("%.2f", txn.Amount)); err != nil {
+			result.Failed++
+			continue
+		}
+
+		err = s.store.UpdateTransactionParsedFields(ctx, db.UpdateTransactionParsedFieldsParams{
+			ID:          row.ID,
+			TxnDate:     pgtype.Date{Time: txn.TxnDate, Valid: true},
+			Description: txn.Description,
+			Amount:      pgAmount,
+			TxnType:     txn.TxnType,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to update transaction %s: %w", result.NextCursor, err)
+		}
+		result.Updated++
+	}
+
+	result.Done = len(rows) < batchSize
+	return result, nil
+}
+
+// parsedFieldsChanged reports whether a re-parse produced different values than those stored
+func parsedFieldsChanged(row db.ListTransactionsForReparseRow, txn models.ParsedTransaction) bool {
+	amount, err := row.Amount.Float64Value()
+	if err != nil || !amount.Valid || math.Abs(amount.Float64-txn.Amount) >= 0.005 {
+		return true
+	}
+	return !row.TxnDate.Time.Equal(txn.TxnDate) ||
+		row.Description != txn.Description ||
+		row.TxnType != txn.TxnType
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReparseStore serves rows ordered by id and records updates
+type fakeReparseStore struct {
+	rows    []db.ListTransactionsForReparseRow
+	updates map[uuid.UUID]db.UpdateTransactionParsedFieldsParams
+	banks   []string // Bank filter of each list call
+}
+
+func (f *fakeReparseStore) ListTransactionsForReparse(ctx context.Context, arg db.ListTransactionsForReparseParams) ([]db.ListTransactionsForReparseRow, error) {
+	f.banks = append(f.banks, arg.Column2)
+	items := []db.ListTransactionsForReparseRow{}
+	for _, row := range f.rows {
+		if bytes.Compare(row.ID.Bytes[:], arg.ID.Bytes[:]) > 0 && len(items) < int(arg.Limit) {
+			items = append(items, row)
+		}
+	}
+	return items, nil
+}
+
+func (f *fakeReparseStore) UpdateTransactionParsedFields(ctx context.Context, arg db.UpdateTransactionParsedFieldsParams) error {
+	f.updates[uuid.UUID(arg.ID.Bytes)] = arg
+	return nil
+}
+
+func newReparseRow(t *testing.T, date time.Time, description string, amount float64, txnType, rawData string) db.ListTransactionsForReparseRow {
+	var pgAmount pgtype.Numeric
+	require.NoError(t, pgAmount.Scan(fmt.Sprintf("%.2f", amount)))
+	return db.ListTransactionsForReparseRow{
+		ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		TxnDate:     pgtype.Date{Time: date, Valid: true},
+		Description: description,
+		Amount:      pgAmount,
+		TxnType:     txnType,
+		RawData:     pgtype.Text{String: rawData, Valid: true},
+	}
+}
+
+func TestReparseService_CorrectsAmounts(t *testing.T) {
+	ctx := context.Background()
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	headers := []string{"Date", "Narration", "Chq./Ref.No.", "Value Dt", "Withdrawal Amt.", "Deposit Amt.", "Closing Balance"}
+
+	// Amounts stored by a buggy parser run, alongside one correct and one unparseable row
+	store := &fakeReparseStore{
+		rows: []db.ListTransactionsForReparseRow{
+			newReparseRow(t, date, "AWS SERVICES", -35.00, "debit", "15/01/2024,AWS SERVICES,REF1,15/01/2024,3500.00,,46500.00"),
+			newReparseRow(t, date, "SALARY CREDIT", 500.00, "credit", "15/01/2024,SALARY CREDIT,REF2,15/01/2024,,50000.00,96500.00"),
+			newReparseRow(t, date, "SWIGGY", -450.00, "debit", "15/01/2024,SWIGGY,REF3,15/01/2024,450.00,,96050.00"),
+			newReparseRow(t, date, "BROKEN ROW", -1.00, "debit", "15/01/2024,BROKEN ROW"),
+		},
+		updates: make(map[uuid.UUID]db.UpdateTransactionParsedFieldsParams),
+	}
+	sort.Slice(store.rows, func(i, j int) bool {
+		return bytes.Compare(store.rows[i].ID.Bytes[:], store.rows[j].ID.Bytes[:]) < 0
+	})
+
+	svc := NewReparseService(store, NewParser())
+	opts := models.ReparseOptions{Bank: "HDFC", Headers: headers, BatchSize: 3}
+
+	// First batch stops short of the end and returns a cursor
+	first, err := svc.ReparseBatch(ctx, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 3, first.Scanned)
+	assert.False(t, first.Done)
+	assert.Equal(t, uuid.UUID(store.rows[2].ID.Bytes).String(), first.NextCursor)
+
+	// Resuming from the cursor processes the remainder
+	opts.AfterID = uuid.MustParse(first.NextCursor)
+	second, err := svc.ReparseBatch(ctx, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 1, second.Scanned)
+	assert.True(t, second.Done)
+
+	// Both batches only read rows from the bank's own uploads
+	assert.Equal(t, []string{"HDFC", "HDFC"}, store.banks)
+
+	assert.Equal(t, 2, first.Updated+second.Updated)
+	assert.Equal(t, 1, first.Failed+second.Failed)
+
+	corrected := map[string]float64{}
+	for _, update := range store.updates {
+		amount, err := update.Amount.Float64Value()
+		require.NoError(t, err)
+		corrected[update.Description] = amount.Float64
+	}
+	assert.Equal(t, map[string]float64{"AWS SERVICES": -3500.00, "SALARY CREDIT": 50000.00}, corrected)
+}
+
+func TestReparseService_UnknownBank(t *testing.T) {
+	svc := NewReparseService(&fakeReparseStore{}, NewParser())
+	_, err := svc.ReparseBatch(context.Background(), models.ReparseOptions{Bank: "NOPE", Headers: []string{"Date"}})
+	assert.Error(t, err)
+
+	_, err = svc.ReparseBatch(context.Background(), models.ReparseOptions{Headers: []string{"Date"}})
+	assert.EqualError(t, err, "bank is required")
+}
+
+// TestReparseRawData_CommaFields tests that rows with commas inside fields keep their columns
+func TestReparseRawData_CommaFields(t *testing.T) {
+	csvData := "Date,Narration,Chq./Ref.No.,Value Dt,Withdrawal Amt.,Deposit Amt.,Closing Balance\n" +
+		"15/01/24,\"ACME CORP, BANGALORE\",REF1,15/01/24,\"3,500.00\",,\"4,50,000.00\"\n"
+	headers := []string{"Date", "Narration", "Chq./Ref.No.", "Value Dt", "Withdrawal Amt.", "Deposit Amt.", "Closing Balance"}
+
+	parser := NewParser()
+	transactions, err := parser.ParseFile(bytes.NewReader([]byte(csvData)), "statement.csv")
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+
+	txn, err := parser.ReparseRawData("HDFC", headers, transactions[0].RawData)
+	require.NoError(t, err)
+	assert.Equal(t, "ACME CORP, BANGALORE", txn.Description)
+	assert.InDelta(t, -3500.00, txn.Amount, 0.001)
+
+	// Rows stored as plain comma-joined text still reparse
+	txn, err = parser.ReparseRawData("HDFC", headers, "15/01/24,SWIGGY,REF3,15/01/24,450.00,,96050.00")
+	require.NoError(t, err)
+	assert.InDelta(t, -450.00, txn.Amount, 0.001)
+}
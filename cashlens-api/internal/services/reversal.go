@@ -0,0 +1,87 @@
+package services
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+)
+
+// reversalMarkers are words banks add to the description of a reversing entry
+var reversalMarkers = map[string]bool{
+	"REV":      true,
+	"REVERSAL": true,
+	"REVERSED": true,
+	"RVSL":     true,
+	"RVSD":     true,
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// ReversalDetector pairs debits with credits of the same amount and merchant that
+// follow within a configurable number of days, so both sides net to zero in reports
+type ReversalDetector struct {
+	windowDays int
+}
+
+// NewReversalDetector creates a detector that pairs entries at most windowDays apart
+func NewReversalDetector(windowDays int) *ReversalDetector {
+	if windowDays < 0 {
+		windowDays = 0
+	}
+	return &ReversalDetector{windowDays: windowDays}
+}
+
+// FindReversals returns, for each transaction, whether it is part of a reversal pair.
+// A debit is paired with the earliest unpaired credit of the same amount and merchant
+// dated on or up to windowDays after it; each transaction is paired at most once.
+func (d *ReversalDetector) FindReversals(transactions []models.ParsedTransaction) []bool {
+	flags := make([]bool, len(transactions))
+
+	merchants := make([]string, len(transactions))
+	for i, txn := range transactions {
+		merchants[i] = reversalMerchantKey(txn.Description)
+	}
+
+	for i, debit := range transactions {
+		if debit.Amount >= 0 || flags[i] {
+			continue
+		}
+
+		match := -1
+		for j, credit := range transactions {
+			if j == i || flags[j] || credit.Amount <= 0 {
+				continue
+			}
+			if math.Abs(credit.Amount+debit.Amount) >= 0.005 || merchants[i] != merchants[j] {
+				continue
+			}
+			if credit.TxnDate.Before(debit.TxnDate) || daysBetween(debit.TxnDate, credit.TxnDate) > d.windowDays {
+				continue
+			}
+			if match == -1 || credit.TxnDate.Before(transactions[match].TxnDate) {
+				match = j
+			}
+		}
+
+		if match != -1 {
+			flags[i] = true
+			flags[match] = true
+		}
+	}
+
+	return flags
+}
+
+// reversalMerchantKey normalizes a description so an entry and its reversal compare equal
+func reversalMerchantKey(description string) string {
+	words := strings.Fields(nonAlphanumeric.ReplaceAllString(strings.ToUpper(description), " "))
+	kept := words[:0]
+	for _, word := range words {
+		if !reversalMarkers[word] {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(kept, " ")
+}
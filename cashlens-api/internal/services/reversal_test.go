@@ -0,0 +1,84 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReversalDetector_FindReversals(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name         string
+		windowDays   int
+		transactions []models.ParsedTransaction
+		expected     []bool
+	}{
+		{
+			name:       "Debit reversed by matching credit next day",
+			windowDays: 2,
+			transactions: []models.ParsedTransaction{
+				{TxnDate: day(10), Description: "POS AMAZON PAY INDIA", Amount: -4999.00},
+				{TxnDate: day(11), Description: "REV POS AMAZON PAY INDIA", Amount: 4999.00},
+				{TxnDate: day(11), Description: "SALARY MAR", Amount: 85000.00},
+			},
+			expected: []bool{true, true, false},
+		},
+		{
+			name:       "Lookalike credit from a different merchant",
+			windowDays: 2,
+			transactions: []models.ParsedTransaction{
+				{TxnDate: day(10), Description: "POS AMAZON PAY INDIA", Amount: -4999.00},
+				{TxnDate: day(11), Description: "NEFT CR FLIPKART", Amount: 4999.00},
+			},
+			expected: []bool{false, false},
+		},
+		{
+			name:       "Matching credit outside the window",
+			windowDays: 2,
+			transactions: []models.ParsedTransaction{
+				{TxnDate: day(10), Description: "POS AMAZON PAY INDIA", Amount: -4999.00},
+				{TxnDate: day(20), Description: "REVERSAL POS AMAZON PAY INDIA", Amount: 4999.00},
+			},
+			expected: []bool{false, false},
+		},
+		{
+			name:       "Different amount is not a reversal",
+			windowDays: 2,
+			transactions: []models.ParsedTransaction{
+				{TxnDate: day(10), Description: "POS AMAZON PAY INDIA", Amount: -4999.00},
+				{TxnDate: day(10), Description: "REV POS AMAZON PAY INDIA", Amount: 499.90},
+			},
+			expected: []bool{false, false},
+		},
+		{
+			name:       "Credit before the debit is not a reversal",
+			windowDays: 2,
+			transactions: []models.ParsedTransaction{
+				{TxnDate: day(9), Description: "REV POS AMAZON PAY INDIA", Amount: 4999.00},
+				{TxnDate: day(10), Description: "POS AMAZON PAY INDIA", Amount: -4999.00},
+			},
+			expected: []bool{false, false},
+		},
+		{
+			name:       "Each credit reverses only one debit",
+			windowDays: 2,
+			transactions: []models.ParsedTransaction{
+				{TxnDate: day(10), Description: "UPI ZOMATO", Amount: -350.00},
+				{TxnDate: day(10), Description: "UPI ZOMATO", Amount: -350.00},
+				{TxnDate: day(10), Description: "UPI ZOMATO RVSL", Amount: 350.00},
+			},
+			expected: []bool{true, false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewReversalDetector(tt.windowDays)
+			assert.Equal(t, tt.expected, d.FindReversals(tt.transactions))
+		})
+	}
+}
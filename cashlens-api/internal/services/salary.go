@@ -0,0 +1,90 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// SalarySuggester spots recurring large monthly credits from one counterparty and
+// suggests categorizing them as Salary, with a rule the user can create
+type SalarySuggester struct {
+	minAmount      float64
+	minOccurrences int
+}
+
+// NewSalarySuggester creates a suggester for credits of at least minAmount
+// seen in at least minOccurrences consecutive months
+func NewSalarySuggester(minAmount float64, minOccurrences int) *SalarySuggester {
+	if minOccurrences < 2 {
+		minOccurrences = 2
+	}
+	return &SalarySuggester{
+		minAmount:      minAmount,
+		minOccurrences: minOccurrences,
+	}
+}
+
+// Suggest returns Salary rule suggestions for the user's transactions.
+// Series already fully categorized as Salary are skipped.
+func (s *SalarySuggester) Suggest(transactions []models.Transaction) []models.RuleSuggestion {
+	suggestions := []models.RuleSuggestion{}
+
+	for _, series := range FindMonthlySeries(transactions, s.minOccurrences) {
+		if series.AverageAmount < s.minAmount {
+			continue
+		}
+
+		ids := make([]uuid.UUID, 0, len(series.Transactions))
+		descriptions := make([]string, 0, len(series.Transactions))
+		alreadySalary := true
+		for _, txn := range series.Transactions {
+			ids = append(ids, txn.ID)
+			descriptions = append(descriptions, txn.Description)
+			if txn.Category == nil || *txn.Category != models.CategorySalary {
+				alreadySalary = false
+			}
+		}
+		if alreadySalary {
+			continue
+		}
+
+		suggestions = append(suggestions, models.RuleSuggestion{
+			Category:       models.CategorySalary,
+			Keyword:        suggestedKeyword(series.Counterparty, descriptions),
+			MatchType:      "substring",
+			Counterparty:   series.Counterparty,
+			Occurrences:    len(series.Transactions),
+			AverageAmount:  series.AverageAmount,
+			TransactionIDs: ids,
+		})
+	}
+
+	return suggestions
+}
+
+// suggestedKeyword picks a substring keyword matching every description: the whole
+// counterparty when it appears verbatim, otherwise its longest word
+func suggestedKeyword(counterparty string, descriptions []string) string {
+	matchesAll := func(keyword string) bool {
+		for _, description := range descriptions {
+			if !strings.Contains(strings.ToUpper(description), keyword) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if matchesAll(counterparty) {
+		return strings.ToLower(counterparty)
+	}
+
+	longest := ""
+	for _, word := range strings.Fields(counterparty) {
+		if len(word) > len(longest) && matchesAll(word) {
+			longest = word
+		}
+	}
+	return strings.ToLower(longest)
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func monthlySeries(description string, amounts []float64, start time.Time, category *string) []models.Transaction {
+	txns := make([]models.Transaction, len(amounts))
+	for i, amount := range amounts {
+		date := start.AddDate(0, i, 0)
+		txns[i] = models.Transaction{
+			ID:          uuid.New(),
+			TxnDate:     models.NewDate(date),
+			Description: description + " " + date.Format("Jan 2006"),
+			Amount:      amount,
+			Category:    category,
+		}
+	}
+	return txns
+}
+
+func TestSalarySuggester_MonthlySalarySeries(t *testing.T) {
+	start := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	var txns []models.Transaction
+	txns = append(txns, monthlySeries("NEFT CR ACME CORP SALARY", []float64{85000, 85000, 87500, 85000}, start, nil)...)
+	// Small recurring credit: monthly but below the salary threshold
+	txns = append(txns, monthlySeries("INTEREST CREDIT", []float64{120, 118, 121, 119}, start, nil)...)
+	// Recurring debit: rent is monthly and large but not a credit
+	txns = append(txns, monthlySeries("RENT PAYMENT", []float64{-25000, -25000, -25000, -25000}, start, nil)...)
+	// Large credits from one counterparty with irregular amounts
+	txns = append(txns, monthlySeries("CLIENT PAYMENT GLOBEX", []float64{40000, 150000, 12000, 90000}, start, nil)...)
+
+	suggestions := NewSalarySuggester(10000, 3).Suggest(txns)
+
+	require.Len(t, suggestions, 1)
+	suggestion := suggestions[0]
+	assert.Equal(t, models.CategorySalary, suggestion.Category)
+	assert.Equal(t, "NEFT CR ACME CORP SALARY", suggestion.Counterparty)
+	assert.Equal(t, "neft cr acme corp salary", suggestion.Keyword)
+	assert.Equal(t, "substring", suggestion.MatchType)
+	assert.Equal(t, 4, suggestion.Occurrences)
+	assert.InDelta(t, 85625.0, suggestion.AverageAmount, 0.01)
+	assert.Len(t, suggestion.TransactionIDs, 4)
+}
+
+func TestSalarySuggester_TooFewOccurrences(t *testing.T) {
+	start := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	txns := monthlySeries("NEFT CR ACME CORP SALARY", []float64{85000, 85000}, start, nil)
+
+	assert.Empty(t, NewSalarySuggester(10000, 3).Suggest(txns))
+}
+
+func TestSalarySuggester_SkipsAlreadyCategorized(t *testing.T) {
+	start := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	salary := models.CategorySalary
+	txns := monthlySeries("NEFT CR ACME CORP SALARY", []float64{85000, 85000, 85000}, start, &salary)
+
+	assert.Empty(t, NewSalarySuggester(10000, 3).Suggest(txns))
+}
+
+func TestCounterpartyKey(t *testing.T) {
+	assert.Equal(t, "SALARY CREDIT ACME CORP", counterpartyKey("SALARY CREDIT - JAN 2024 - ACME CORP"))
+	assert.Equal(t, "NEFT CR ACME", counterpartyKey("NEFT/CR/N123456789/ACME/February"))
+}
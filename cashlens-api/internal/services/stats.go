@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/ashmitsharp/cashlens-api/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// StatsStore is the subset of db.Queries needed to compute user statistics
+type StatsStore interface {
+	GetTransactionStats(ctx context.Context, userID pgtype.UUID) (db.GetTransactionStatsRow, error)
+	ListUserIDs(ctx context.Context) ([]pgtype.UUID, error)
+	RefreshUserUploadStats(ctx context.Context) error
+}
+
+// DefaultStatsCacheTTL is how long cached stats are served before they are recomputed
+const DefaultStatsCacheTTL = 5 * time.Minute
+
+// StatsService caches per-user transaction statistics and recomputes them on demand.
+// The cache is per process, so entries expire after a TTL to pick up changes made
+// through other instances.
+type StatsService struct {
+	store      StatsStore
+	cache      map[uuid.UUID]models.UserStats
+	cacheMutex sync.RWMutex
+	ttl        time.Duration
+}
+
+// NewStatsService creates a new stats service instance
+func NewStatsService(store StatsStore) *StatsService {
+	return &StatsService{
+		store: store,
+		cache: make(map[uuid.UUID]models.UserStats),
+		ttl:   DefaultStatsCacheTTL,
+	}
+}
+
+// SetCacheTTL sets how long cached stats are served (0 keeps them until invalidated)
+func (s *StatsService) SetCacheTTL(ttl time.Duration) {
+	s.ttl = ttl
+}
+
+// GetUserStats returns cached stats for a user, computing them on a cache miss or
+// when the cached entry is older than the TTL
+func (s *StatsService) GetUserStats(ctx context.Context, userID uuid.UUID) (models.UserStats, error) {
+	s.cacheMutex.RLock()
+	stats, ok := s.cache[userID]
+	s.cacheMutex.RUnlock()
+
+	if ok && (s.ttl <= 0 || time.Since(stats.ComputedAt) < s.ttl) {
+		return stats, nil
+	}
+
+	return s.RecomputeUserStats(ctx, userID)
+}
+
+// RecomputeUserStats recomputes a user's stats from the database and replaces the cached value.
+// It is idempotent: calling it repeatedly without data changes yields the same stats.
+func (s *StatsService) RecomputeUserStats(ctx context.Context, userID uuid.UUID) (models.UserStats, error) {
+	// Convert uuid.UUID to pgtype.UUID
+	var pgUserID pgtype.UUID
+	pgUserID.Bytes = userID
+	pgUserID.Valid = true
+
+	row, err := s.store.GetTransactionStats(ctx, pgUserID)
+	if err != nil {
+		return models.UserStats{}, fmt.Errorf("failed to compute stats: %w", err)
+	}
+
+	// Convert pgtype.Numeric to float64
+	accuracy, _ := row.AccuracyPercent.Float64Value()
+
+	stats := models.UserStats{
+		TotalCount:         row.TotalCount,
+		CategorizedCount:   row.CategorizedCount,
+		UncategorizedCount: row.UncategorizedCount,
+		AccuracyPercent:    accuracy.Float64,
+		ComputedAt:         time.Now(),
+	}
+
+	s.cacheMutex.Lock()
+	s.cache[userID] = stats
+	s.cacheMutex.Unlock()
+
+	return stats, nil
+}
+
+// InvalidateUserStats drops a user's cached stats so the next read recomputes them
+func (s *StatsService) InvalidateUserStats(userID uuid.UUID) {
+	s.cacheMutex.Lock()
+	delete(s.cache, userID)
+	s.cacheMutex.Unlock()
+}
+
+// InvalidateAllUserStats drops every cached entry, for changes spanning many users
+func (s *StatsService) InvalidateAllUserStats() {
+	s.cacheMutex.Lock()
+	s.cache = make(map[uuid.UUID]models.UserStats)
+	s.cacheMutex.Unlock()
+}
+
+// RecomputeAllUserStats recomputes stats for every user and refreshes the
+// user_upload_stats materialized view. Returns the number of users recomputed.
+func (s *StatsService) RecomputeAllUserStats(ctx context.Context) (int, error) {
+	userIDs, err := s.store.ListUserIDs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	recomputed := 0
+	for _, pgUserID := range userIDs {
+		// Convert pgtype.UUID to uuid.UUID
+		var userID uuid.UUID
+		copy(userID[:], pgUserID.Bytes[:])
+
+		if _, err := s.RecomputeUserStats(ctx, userID); err != nil {
+			return recomputed, err
+		}
+		recomputed++
+	}
+
+	if err := s.store.RefreshUserUploadStats(ctx); err != nil {
+		return recomputed, fmt.Errorf("failed to refresh upload stats view: %w", err)
+	}
+
+	return recomputed, nil
+}
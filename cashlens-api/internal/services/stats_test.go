@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ashmitsharp/cashlens-api/internal/database/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStatsStore computes stats from an in-memory list of categories (nil = uncategorized)
+type fakeStatsStore struct {
+	categories map[uuid.UUID][]*string
+	refreshed  int
+}
+
+func (f *fakeStatsStore) GetTransactionStats(ctx context.Context, userID pgtype.UUID) (db.GetTransactionStatsRow, error) {
+	var row db.GetTransactionStatsRow
+	for _, category := range f.categories[uuid.UUID(userID.Bytes)] {
+		row.TotalCount++
+		if category != nil {
+			row.CategorizedCount++
+		} else {
+			row.UncategorizedCount++
+		}
+	}
+	if row.TotalCount > 0 {
+		row.AccuracyPercent.Scan(fmt.Sprintf("%.2f", float64(row.CategorizedCount)/float64(row.TotalCount)*100))
+	}
+	return row, nil
+}
+
+func (f *fakeStatsStore) ListUserIDs(ctx context.Context) ([]pgtype.UUID, error) {
+	ids := []pgtype.UUID{}
+	for id := range f.categories {
+		ids = append(ids, pgtype.UUID{Bytes: id, Valid: true})
+	}
+	return ids, nil
+}
+
+func (f *fakeStatsStore) RefreshUserUploadStats(ctx context.Context) error {
+	f.refreshed++
+	return nil
+}
+
+func TestStatsService_RecomputeAfterBulkChange(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	category := "Travel"
+	store := &fakeStatsStore{
+		categories: map[uuid.UUID][]*string{
+			userID: {nil, nil, &category, nil},
+		},
+	}
+	svc := NewStatsService(store)
+
+	before, err := svc.GetUserStats(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), before.CategorizedCount)
+
+	// Bulk categorize every transaction; the cache is now stale
+	store.categories[userID] = []*string{&category, &category, &category, &category}
+	stale, err := svc.GetUserStats(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stale.CategorizedCount)
+
+	recomputed, err := svc.RecomputeUserStats(ctx, userID)
+	require.NoError(t, err)
+
+	fresh, err := store.GetTransactionStats(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	require.NoError(t, err)
+	assert.Equal(t, fresh.TotalCount, recomputed.TotalCount)
+	assert.Equal(t, fresh.CategorizedCount, recomputed.CategorizedCount)
+	assert.Equal(t, fresh.UncategorizedCount, recomputed.UncategorizedCount)
+	assert.Equal(t, 100.0, recomputed.AccuracyPercent)
+
+	// Recomputing again without data changes is idempotent
+	again, err := svc.RecomputeUserStats(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, recomputed.CategorizedCount, again.CategorizedCount)
+	assert.Equal(t, recomputed.AccuracyPercent, again.AccuracyPercent)
+
+	cached, err := svc.GetUserStats(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), cached.CategorizedCount)
+}
+
+func TestStatsService_RecomputeAllUserStats(t *testing.T) {
+	category := "Rent & Lease"
+	store := &fakeStatsStore{
+		categories: map[uuid.UUID][]*string{
+			uuid.New(): {&category},
+			uuid.New(): {nil, &category},
+		},
+	}
+	svc := NewStatsService(store)
+
+	count, err := svc.RecomputeAllUserStats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 1, store.refreshed)
+}
+
+func TestStatsService_TTLAndInvalidation(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	category := "Travel"
+	store := &fakeStatsStore{
+		categories: map[uuid.UUID][]*string{userID: {nil}},
+	}
+	svc := NewStatsService(store)
+
+	stats, err := svc.GetUserStats(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stats.CategorizedCount)
+
+	// Another instance categorizes the transaction; invalidation drops the stale entry
+	store.categories[userID] = []*string{&category}
+	svc.InvalidateUserStats(userID)
+	stats, err = svc.GetUserStats(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.CategorizedCount)
+
+	store.categories[userID] = []*string{&category, nil}
+	svc.InvalidateAllUserStats()
+	stats, err = svc.GetUserStats(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.TotalCount)
+
+	// Entries older than the TTL are recomputed without an explicit invalidation
+	store.categories[userID] = []*string{&category, nil, nil}
+	svc.SetCacheTTL(time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	stats, err = svc.GetUserStats(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.TotalCount)
+}
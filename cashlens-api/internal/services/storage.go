@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ashmitsharp/cashlens-api/internal/models"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
@@ -159,6 +160,38 @@ func (s *StorageService) GeneratePresignedURL(key, contentType string, expiryMin
 	return presignedReq.URL, nil
 }
 
+// GeneratePresignedDownloadURL generates a presigned GET URL for downloading an uploaded file
+func (s *StorageService) GeneratePresignedDownloadURL(key string, expiryMinutes int) (string, error) {
+	// Validate inputs
+	if key == "" {
+		return "", fmt.Errorf("key cannot be empty")
+	}
+	if expiryMinutes <= 0 {
+		return "", fmt.Errorf("expiryMinutes must be greater than 0")
+	}
+	if s.s3Client == nil {
+		return "", fmt.Errorf("s3 client is not initialized")
+	}
+
+	// Create presign client
+	presignClient := s3.NewPresignClient(s.s3Client)
+
+	// Generate presigned URL
+	presignedReq, err := presignClient.PresignGetObject(
+		context.Background(),
+		&s3.GetObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+		},
+		s3.WithPresignExpires(time.Duration(expiryMinutes)*time.Minute),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate presigned download URL: %w", err)
+	}
+
+	return presignedReq.URL, nil
+}
+
 // DownloadFile downloads a file from S3 and returns a reader
 func (s *StorageService) DownloadFile(key string) (io.ReadCloser, error) {
 	// Validate inputs
@@ -202,3 +235,59 @@ func (s *StorageService) DeleteFile(key string) error {
 
 	return nil
 }
+
+// ListUserFiles lists every object under the user's uploads/{userID}/ prefix,
+// following continuation tokens until all pages are read
+func (s *StorageService) ListUserFiles(userID string) ([]models.FileInfo, error) {
+	// Validate inputs
+	if userID == "" {
+		return nil, fmt.Errorf("userID cannot be empty")
+	}
+	if s.s3Client == nil {
+		return nil, fmt.Errorf("s3 client is not initialized")
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(fmt.Sprintf("uploads/%s/", userID)),
+	}
+
+	files := []models.FileInfo{}
+	for {
+		page, err := s.s3Client.ListObjectsV2(context.Background(), input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files in S3: %w", err)
+		}
+		for _, object := range page.Contents {
+			files = append(files, models.FileInfo{
+				Key:          aws.ToString(object.Key),
+				Size:         aws.ToInt64(object.Size),
+				LastModified: aws.ToTime(object.LastModified),
+			})
+		}
+
+		if !aws.ToBool(page.IsTruncated) || page.NextContinuationToken == nil {
+			return files, nil
+		}
+		input.ContinuationToken = page.NextContinuationToken
+	}
+}
+
+// DeleteUserFiles deletes every object under the user's uploads/{userID}/ prefix
+// and returns how many were deleted
+func (s *StorageService) DeleteUserFiles(userID string) (int, error) {
+	files, err := s.ListUserFiles(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, file := range files {
+		if err := s.DeleteFile(file.Key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
@@ -222,6 +222,45 @@ func TestGeneratePresignedURL(t *testing.T) {
 	}
 }
 
+// TestGeneratePresignedDownloadURL tests presigned GET URL generation
+func TestGeneratePresignedDownloadURL(t *testing.T) {
+	// Skip if LocalStack is not available
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	service, err := NewStorageService("cashlens-uploads", "us-east-1", "http://localhost:4566")
+	require.NoError(t, err, "Failed to create storage service")
+
+	tests := []struct {
+		name          string
+		key           string
+		expiryMinutes int
+		wantErr       bool
+	}{
+		{"valid presigned download URL", "uploads/user123/test.csv", 15, false},
+		{"empty key", "", 15, true},
+		{"invalid expiry (0 minutes)", "uploads/user123/test.csv", 0, true},
+		{"invalid expiry (negative)", "uploads/user123/test.csv", -10, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, err := service.GeneratePresignedDownloadURL(tt.key, tt.expiryMinutes)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Empty(t, url)
+			} else {
+				assert.NoError(t, err)
+				for _, contain := range []string{"uploads", "user123", "test.csv", "X-Amz-Algorithm", "X-Amz-Expires=900"} {
+					assert.Contains(t, url, contain, fmt.Sprintf("URL should contain '%s'", contain))
+				}
+			}
+		})
+	}
+}
+
 // TestDownloadFile tests file download functionality
 func TestDownloadFile(t *testing.T) {
 	// Skip if LocalStack is not available
@@ -484,3 +523,83 @@ func TestStorageService_ProductionConfig(t *testing.T) {
 	assert.Equal(t, "cashlens-uploads", service.bucket)
 	assert.Equal(t, "ap-south-1", service.region)
 }
+
+// TestStorageService_ListUserFiles_Integration tests listing a user's files across pages
+func TestStorageService_ListUserFiles_Integration(t *testing.T) {
+	// Skip if LocalStack is not available
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	// Create service
+	service, err := NewStorageService("cashlens-uploads", "us-east-1", "http://localhost:4566")
+	require.NoError(t, err, "Failed to create storage service")
+
+	ctx := context.Background()
+
+	// Create bucket
+	_, err = service.s3Client.CreateBucket(ctx, &s3.CreateBucketInput{
+		Bucket: aws.String(service.bucket),
+	})
+	// Ignore error if bucket already exists
+
+	userID := "list-test-user"
+	otherUserID := "list-test-user-other"
+
+	// Start from an empty prefix
+	_, err = service.DeleteUserFiles(userID)
+	require.NoError(t, err)
+
+	// More files than one 1000-key page so the continuation token is followed
+	const fileCount = 1005
+	for i := 0; i < fileCount; i++ {
+		_, err = service.s3Client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(service.bucket),
+			Key:    aws.String(fmt.Sprintf("uploads/%s/%04d.csv", userID, i)),
+			Body:   strings.NewReader("content"),
+		})
+		require.NoError(t, err, "Failed to upload file")
+	}
+	otherKey := fmt.Sprintf("uploads/%s/other.csv", otherUserID)
+	_, err = service.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(service.bucket),
+		Key:    aws.String(otherKey),
+		Body:   strings.NewReader("content"),
+	})
+	require.NoError(t, err, "Failed to upload file")
+
+	files, err := service.ListUserFiles(userID)
+	require.NoError(t, err)
+	require.Len(t, files, fileCount)
+	for _, file := range files {
+		assert.True(t, strings.HasPrefix(file.Key, "uploads/"+userID+"/"), file.Key)
+		assert.Equal(t, int64(len("content")), file.Size)
+		assert.False(t, file.LastModified.IsZero())
+	}
+
+	// Deleting the user's files leaves the other user's alone
+	deleted, err := service.DeleteUserFiles(userID)
+	require.NoError(t, err)
+	assert.Equal(t, fileCount, deleted)
+
+	files, err = service.ListUserFiles(userID)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	files, err = service.ListUserFiles(otherUserID)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, otherKey, files[0].Key)
+	require.NoError(t, service.DeleteFile(otherKey))
+}
+
+// TestListUserFiles_Validation tests input validation without S3
+func TestListUserFiles_Validation(t *testing.T) {
+	service := &StorageService{bucket: "test-bucket", region: "us-east-1"}
+
+	_, err := service.ListUserFiles("")
+	assert.Error(t, err)
+
+	_, err = service.ListUserFiles("user-123")
+	assert.Error(t, err, "uninitialized client should error")
+}
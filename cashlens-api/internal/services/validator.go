@@ -36,17 +36,23 @@ var fileMagicBytes = map[string][]byte{
 // Allowed MIME types for file uploads
 var allowedMimeTypes = map[string]bool{
 	"text/csv":                 true,
+	"text/plain":               true,
 	"application/vnd.ms-excel": true,
 	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": true,
-	"application/pdf": true,
+	"application/pdf":   true,
+	"application/x-ofx": true,
+	"application/x-qif": true,
 }
 
 // Allowed file extensions
 var allowedExtensions = map[string]bool{
 	".csv":  true,
+	".txt":  true,
 	".xlsx": true,
 	".xls":  true,
 	".pdf":  true,
+	".ofx":  true,
+	".qif":  true,
 }
 
 // NewFileValidator creates a new file validator with the specified maximum file size
@@ -58,6 +64,11 @@ func NewFileValidator(maxSizeBytes int64) *FileValidator {
 	}
 }
 
+// MaxSize returns the largest file size in bytes the validator accepts
+func (v *FileValidator) MaxSize() int64 {
+	return v.maxSizeBytes
+}
+
 // ValidateFile performs comprehensive validation on an uploaded file
 func (v *FileValidator) ValidateFile(reader io.Reader, filename, contentType string) (*ValidationResult, error) {
 	result := &ValidationResult{
@@ -204,7 +215,9 @@ func (v *FileValidator) ValidateFileSize(size int64) error {
 func (v *FileValidator) isContentTypeMatch(contentType, detectedType string) bool {
 	switch detectedType {
 	case "CSV":
-		return contentType == "text/csv"
+		// Delimited exports are often saved as .txt; OFX and QIF are text too
+		return contentType == "text/csv" || contentType == "text/plain" ||
+			contentType == "application/x-ofx" || contentType == "application/x-qif"
 	case "XLSX":
 		// Both XLSX and XLS use similar structures
 		return contentType == "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" ||
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// ErrInvalidCursor is returned for cursors that are malformed or were modified by the client
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursorPayload is the JSON inside a cursor: the keyset fields and their signature
+type cursorPayload struct {
+	Fields    []string `json:"f"`
+	Signature []byte   `json:"s"`
+}
+
+var (
+	cursorKeyMu sync.RWMutex
+	cursorKey   = []byte("cashlens-cursor") // Replaced via SetCursorSecret in production
+)
+
+// SetCursorSecret sets the key signing pagination cursors. Every API instance must use
+// the same secret so cursors issued by one are accepted by the others.
+func SetCursorSecret(secret string) {
+	if secret == "" {
+		return
+	}
+	cursorKeyMu.Lock()
+	defer cursorKeyMu.Unlock()
+	cursorKey = []byte(secret)
+}
+
+// EncodeCursor builds an opaque pagination cursor from keyset fields (e.g. the last
+// row's date and ID). The cursor is URL-safe base64 JSON signed with HMAC-SHA256.
+func EncodeCursor(fields ...string) string {
+	if fields == nil {
+		fields = []string{}
+	}
+	payload, _ := json.Marshal(cursorPayload{Fields: fields, Signature: signCursor(fields)})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// DecodeCursor returns the fields of a cursor made by EncodeCursor. Cursors that are
+// not valid base64 JSON, or whose fields don't match the signature, return ErrInvalidCursor.
+func DecodeCursor(cursor string) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.Fields == nil {
+		return nil, ErrInvalidCursor
+	}
+	if !hmac.Equal(payload.Signature, signCursor(payload.Fields)) {
+		return nil, ErrInvalidCursor
+	}
+	return payload.Fields, nil
+}
+
+// signCursor returns the HMAC of the fields' JSON encoding
+func signCursor(fields []string) []byte {
+	encoded, _ := json.Marshal(fields)
+
+	cursorKeyMu.RLock()
+	mac := hmac.New(sha256.New, cursorKey)
+	cursorKeyMu.RUnlock()
+
+	mac.Write(encoded)
+	return mac.Sum(nil)
+}
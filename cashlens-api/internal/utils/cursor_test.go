@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		name   string
+		fields []string
+	}{
+		{"Date and ID", []string{"2024-01-15", "7f3c9a2e-8d41-4b6a-9c0e-2f5d8b1a3e47"}},
+		{"Single field", []string{"42"}},
+		{"No fields", []string{}},
+		{"Special characters", []string{"AWS \"SERVICES\" / ₹3,500", ""}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cursor := EncodeCursor(tc.fields...)
+			assert.NotContains(t, cursor, "+")
+			assert.NotContains(t, cursor, "/")
+
+			fields, err := DecodeCursor(cursor)
+			require.NoError(t, err)
+			assert.Equal(t, tc.fields, fields)
+		})
+	}
+}
+
+func TestDecodeCursor_RejectsTampering(t *testing.T) {
+	cursor := EncodeCursor("2024-01-15", "7f3c9a2e-8d41-4b6a-9c0e-2f5d8b1a3e47")
+
+	// Rewrite the fields but keep the original signature
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	require.NoError(t, err)
+	var payload cursorPayload
+	require.NoError(t, json.Unmarshal(data, &payload))
+	payload.Fields[0] = "2020-01-01"
+	forged, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	unsigned, err := json.Marshal(map[string][]string{"f": {"2024-01-15"}})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		cursor string
+	}{
+		{"Modified fields", base64.RawURLEncoding.EncodeToString(forged)},
+		{"Missing signature", base64.RawURLEncoding.EncodeToString(unsigned)},
+		{"Not base64", "not a cursor!"},
+		{"Not JSON", base64.RawURLEncoding.EncodeToString([]byte("2024-01-15"))},
+		{"Truncated", cursor[:len(cursor)-4]},
+		{"Empty", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fields, err := DecodeCursor(tc.cursor)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+			assert.Nil(t, fields)
+		})
+	}
+}
+
+func TestSetCursorSecret(t *testing.T) {
+	defer SetCursorSecret(string(cursorKey))
+
+	cursor := EncodeCursor("page-2")
+
+	SetCursorSecret("rotated-secret")
+	_, err := DecodeCursor(cursor)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	fields, err := DecodeCursor(EncodeCursor("page-2"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"page-2"}, fields)
+}
@@ -18,6 +18,7 @@ func main() {
 	generateSBIFixture()
 	generateAxisFixture()
 	generateKotakFixture()
+	generateMultiSheetFixture()
 	fmt.Println("\n✅ All XLSX fixtures generated successfully!")
 }
 
@@ -215,3 +216,46 @@ func generateKotakFixture() {
 	}
 	fmt.Println("✓ Generated", path)
 }
+
+// generateMultiSheetFixture writes a workbook whose first sheet is a cover page
+// and whose transactions are on a named "Account Statement" sheet
+func generateMultiSheetFixture() {
+	f := excelize.NewFile()
+	cover := "Cover"
+	sheet := "Account Statement"
+	f.SetSheetName("Sheet1", cover)
+	f.NewSheet(sheet)
+
+	// Cover page
+	f.SetCellValue(cover, "A1", "HDFC BANK LTD")
+	f.SetCellValue(cover, "A2", "Statement of Account")
+	f.SetCellValue(cover, "A3", "Account No: XXXXXXXX1234")
+	f.SetCellValue(cover, "A4", "Period: 15/01/2024 to 17/01/2024")
+
+	// Headers
+	headers := []string{"Date", "Narration", "Chq./Ref.No.", "Value Dt", "Withdrawal Amt.", "Deposit Amt.", "Closing Balance"}
+	for i, h := range headers {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		f.SetCellValue(sheet, cell, h)
+	}
+
+	// Data rows
+	data := [][]interface{}{
+		{"15/01/2024", "AWS SERVICES", "UPI/123456", "15/01/2024", 3500.00, "", 450000.00},
+		{"16/01/2024", "SALARY CREDIT - ACME CORP", "NEFT/789012", "16/01/2024", "", 50000.00, 500000.00},
+		{"17/01/2024", "RAZORPAY PAYMENT GATEWAY", "UPI/234567", "17/01/2024", 2500.00, "", 497500.00},
+	}
+
+	for rowIdx, row := range data {
+		for colIdx, val := range row {
+			cell, _ := excelize.CoordinatesToCellName(colIdx+1, rowIdx+2)
+			f.SetCellValue(sheet, cell, val)
+		}
+	}
+
+	path := filepath.Join("testdata", "hdfc_multi_sheet.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("✓ Generated", path)
+}